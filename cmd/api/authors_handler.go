@@ -2,10 +2,10 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
-	"strconv"
+	"strings"
 
-	"github.com/julienschmidt/httprouter"
 	"github.com/justinbachtell/quote-table-go/internal/models"
 	"github.com/justinbachtell/quote-table-go/internal/validator"
 )
@@ -13,12 +13,80 @@ import (
 // Struct to represent the author form data
 type authorCreateForm struct {
 	Name string `form:"name"`
+	authorDetailsForm
+	// Set to add an author even though authors like them have been suggested
+	ConfirmNew  bool            `form:"confirm_new"`
+	Suggestions []models.Author `form:"-"`
 	validator.Validator `form:"-"`
 }
 
+// Struct to represent the optional details of the create and edit author forms
+type authorDetailsForm struct {
+	Bio               string `form:"bio"`
+	BirthYear         int    `form:"birth_year"`
+	BirthCalendarTime string `form:"birth_calendar_time"`
+	DeathYear         int    `form:"death_year"`
+	DeathCalendarTime string `form:"death_calendar_time"`
+	Nationality       string `form:"nationality"`
+	Occupation        string `form:"occupation"`
+	// The reference links, one per line
+	Links string `form:"links"`
+}
+
+// Returns the form for an author's current details
+func newAuthorDetailsForm(d models.AuthorDetails) authorDetailsForm {
+	return authorDetailsForm{
+		Bio:               d.Bio,
+		BirthYear:         d.BirthYear,
+		BirthCalendarTime: d.BirthCalendarTime,
+		DeathYear:         d.DeathYear,
+		DeathCalendarTime: d.DeathCalendarTime,
+		Nationality:       d.Nationality,
+		Occupation:        d.Occupation,
+		Links:             strings.Join(d.Links, "\n"),
+	}
+}
+
+// Returns the trimmed details entered in the form, without blank links or
+// the calendar time of unknown years
+func (f authorDetailsForm) details() models.AuthorDetails {
+	d := models.AuthorDetails{
+		Bio:               strings.TrimSpace(f.Bio),
+		BirthYear:         f.BirthYear,
+		BirthCalendarTime: f.BirthCalendarTime,
+		DeathYear:         f.DeathYear,
+		DeathCalendarTime: f.DeathCalendarTime,
+		Nationality:       strings.TrimSpace(f.Nationality),
+		Occupation:        strings.TrimSpace(f.Occupation),
+	}
+	if d.BirthYear == 0 {
+		d.BirthCalendarTime = ""
+	}
+	if d.DeathYear == 0 {
+		d.DeathCalendarTime = ""
+	}
+	for _, link := range strings.Split(f.Links, "\n") {
+		if link = strings.TrimSpace(link); link != "" {
+			d.Links = append(d.Links, link)
+		}
+	}
+	return d
+}
+
+// Checks the details entered in the form are valid
+func validateAuthorDetails(v *validator.Validator, d models.AuthorDetails) {
+	validator.ValidateAuthorDetails(v, d.Bio, d.BirthYear, d.BirthCalendarTime, d.DeathYear, d.DeathCalendarTime, d.Nationality, d.Occupation, d.Links)
+}
+
 // Handler for the authors page
 func (app *application) authorList(w http.ResponseWriter, r *http.Request) {
-	authors, err := app.authors.GetAllWithCounts()
+	filters, err := app.readFilters(r, models.AuthorSorts)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	authors, metadata, err := app.authors.ListWithCounts(r.Context(), filters)
 	if err != nil {
 		app.serverError(w, r, err)
 		return
@@ -26,33 +94,161 @@ func (app *application) authorList(w http.ResponseWriter, r *http.Request) {
 
 	data := app.newTemplateData(r)
 	data.Authors = authors
+	data.Filters = filters
+	data.Metadata = metadata
+	data.Sorts = models.AuthorSorts
 
 	app.render(w, r, http.StatusOK, "authors.go.tmpl", data)
 }
 
+// Struct to represent the forms of the edit author page, which share their field errors
+type authorEditForm struct {
+	Name    string `form:"name"`
+	authorDetailsForm
+	Into    int    `form:"into"`
+	Alias   string `form:"alias"`
+	AliasID int    `form:"alias_id"`
+	validator.Validator `form:"-"`
+}
+
 // Handler for the view author page
 func (app *application) authorView(w http.ResponseWriter, r *http.Request) {
-	// Extract the author ID from the URL
-	params := httprouter.ParamsFromContext(r.Context())
-	id, err := strconv.Atoi(params.ByName("id"))
-	if err != nil || id < 1 {
+	// Fetch the author named in the URL
+	author, ok := app.authorFromParam(w, r)
+	if !ok {
+		return
+	}
+
+	// Fetch books by this author, which are listed in the order they were published
+	books, err := app.books.GetByAuthorID(r.Context(), author.ID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+	models.SortChronologically(books)
+
+	// Fetch the other names the author is known by
+	author.Aliases, err = app.authors.GetAliases(r.Context(), author.ID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Author = author
+	data.Books = books
+
+	app.render(w, r, http.StatusOK, "view-author.go.tmpl", data)
+}
+
+// Handler for the create author page
+func (app *application) authorCreate(w http.ResponseWriter, r *http.Request) {
+	data := app.newTemplateData(r)
+	data.Form = authorCreateForm{}
+
+	app.render(w, r, http.StatusOK, "create-author.go.tmpl", data)
+}
+
+// Handler to process and post the author data
+func (app *application) authorCreatePost(w http.ResponseWriter, r *http.Request) {
+	var form authorCreateForm
+
+	err := app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.Name = strings.TrimSpace(form.Name)
+	err = app.validateAuthorName(r, &form.Validator, form.Name, 0)
+	if err != nil {
 		app.serverError(w, r, err)
 		return
 	}
+	details := form.details()
+	validateAuthorDetails(&form.Validator, details)
+
+	// Check the author hasn't already been added under a similar name
+	if form.ValidField() && !form.ConfirmNew {
+		form.Suggestions, err = app.similarAuthors(r.Context(), form.Name)
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+		if len(form.Suggestions) > 0 {
+			form.AddFieldError("name", "There are already authors with similar names. Did you mean one of them?")
+		}
+	}
+
+	if !form.ValidField() {
+		data := app.newTemplateData(r)
+		data.Form = form
+		app.render(w, r, http.StatusUnprocessableEntity, "create-author.go.tmpl", data)
+		return
+	}
+
+	id, err := app.authors.Insert(r.Context(), form.Name, details, app.contextGetUserID(r))
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Author successfully created")
+
+	http.Redirect(w, r, fmt.Sprintf("/author/view/%d", id), http.StatusSeeOther)
+}
+
+// Checks an author's name is valid and isn't taken by any author other than id
+func (app *application) validateAuthorName(r *http.Request, v *validator.Validator, name string, id int) error {
+	v.CheckField(validator.NotBlank(name), "name", "This field cannot be blank")
+	v.CheckField(validator.MaxChars(name, 100), "name", "This field cannot be more than 100 characters long")
+	v.CheckField(validator.NoInvalidCharacters(name), "name", "This field contains invalid characters")
+	if !v.ValidField() {
+		return nil
+	}
+
+	// Check the author hasn't already been added
+	existing, err := app.authors.GetByName(r.Context(), name)
+	if err == nil && existing.ID != id {
+		v.AddFieldError("name", "An author with this name already exists")
+	} else if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		return err
+	}
+
+	return nil
+}
+
+// Returns the author named by the id parameter, writing a 404 or 500
+// response and returning false when it can't be found
+func (app *application) authorFromParam(w http.ResponseWriter, r *http.Request) (models.Author, bool) {
+	id, err := app.readIDParam(r)
+	if err != nil || id < 1 {
+		app.notFoundResponse(w, r)
+		return models.Author{}, false
+	}
 
-	// Fetch the author
-	author, err := app.authors.Get(id)
+	author, err := app.authors.Get(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, models.ErrNoRecord) {
-			app.serverError(w, r, ErrNoRecord)
+			app.notFoundResponse(w, r)
 		} else {
 			app.serverError(w, r, err)
 		}
+		return models.Author{}, false
+	}
+
+	return author, true
+}
+
+// Renders the edit author page, with the author's aliases and the other authors to merge into
+func (app *application) renderAuthorEdit(w http.ResponseWriter, r *http.Request, status int, author models.Author, form authorEditForm) {
+	authors, err := app.authors.GetAllWithCounts(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
 		return
 	}
 
-	// Fetch books by this author
-	books, err := app.books.GetByAuthorID(id)
+	author.Aliases, err = app.authors.GetAliases(r.Context(), author.ID)
 	if err != nil {
 		app.serverError(w, r, err)
 		return
@@ -60,7 +256,203 @@ func (app *application) authorView(w http.ResponseWriter, r *http.Request) {
 
 	data := app.newTemplateData(r)
 	data.Author = author
-	data.Books = books
+	data.Authors = authors
+	data.Form = form
 
-	app.render(w, r, http.StatusOK, "view-author.go.tmpl", data)
-}
\ No newline at end of file
+	app.render(w, r, status, "edit-author.go.tmpl", data)
+}
+
+// Handler for the edit author page
+func (app *application) authorEdit(w http.ResponseWriter, r *http.Request) {
+	author, ok := app.authorFromParam(w, r)
+	if !ok {
+		return
+	}
+
+	app.renderAuthorEdit(w, r, http.StatusOK, author, authorEditForm{Name: author.Name, authorDetailsForm: newAuthorDetailsForm(author.AuthorDetails)})
+}
+
+// Handler to process and post the author data
+func (app *application) authorEditPost(w http.ResponseWriter, r *http.Request) {
+	author, ok := app.authorFromParam(w, r)
+	if !ok {
+		return
+	}
+
+	var form authorEditForm
+	err := app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.Name = strings.TrimSpace(form.Name)
+	err = app.validateAuthorName(r, &form.Validator, form.Name, author.ID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+	details := form.details()
+	validateAuthorDetails(&form.Validator, details)
+
+	if !form.ValidField() {
+		app.renderAuthorEdit(w, r, http.StatusUnprocessableEntity, author, form)
+		return
+	}
+
+	_, err = app.authors.Update(r.Context(), author.ID, form.Name, details)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Author successfully updated")
+
+	http.Redirect(w, r, fmt.Sprintf("/author/view/%d", author.ID), http.StatusSeeOther)
+}
+
+// Handler to merge an author into another, moving all of their quotes
+func (app *application) authorMergePost(w http.ResponseWriter, r *http.Request) {
+	author, ok := app.authorFromParam(w, r)
+	if !ok {
+		return
+	}
+
+	var form authorEditForm
+	err := app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+	form.Name, form.authorDetailsForm = author.Name, newAuthorDetailsForm(author.AuthorDetails)
+
+	form.CheckField(form.Into > 0, "into", "Please select an author to merge into")
+	form.CheckField(form.Into != author.ID, "into", "An author can't be merged into themselves")
+
+	moved := 0
+	if form.ValidField() {
+		moved, err = app.authors.Merge(r.Context(), author.ID, form.Into)
+		if errors.Is(err, models.ErrNoRecord) {
+			form.AddFieldError("into", "The selected author no longer exists")
+		} else if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	if !form.ValidField() {
+		app.renderAuthorEdit(w, r, http.StatusUnprocessableEntity, author, form)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", fmt.Sprintf("Author merged, moving %d quotes", moved))
+
+	http.Redirect(w, r, fmt.Sprintf("/author/view/%d", form.Into), http.StatusSeeOther)
+}
+
+// Handler to delete an author, which is refused while they have quotes or are credited on books
+func (app *application) authorDeletePost(w http.ResponseWriter, r *http.Request) {
+	author, ok := app.authorFromParam(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.authors.Delete(r.Context(), author.ID)
+	if errors.Is(err, models.ErrAuthorHasQuotes) {
+		form := authorEditForm{Name: author.Name, authorDetailsForm: newAuthorDetailsForm(author.AuthorDetails)}
+		form.AddFieldError("delete", "This author still has quotes. Merge them into another author before deleting.")
+		app.renderAuthorEdit(w, r, http.StatusConflict, author, form)
+		return
+	} else if errors.Is(err, models.ErrAuthorHasBooks) {
+		form := authorEditForm{Name: author.Name, authorDetailsForm: newAuthorDetailsForm(author.AuthorDetails)}
+		form.AddFieldError("delete", "This author is still credited on books. Merge them into another author before deleting.")
+		app.renderAuthorEdit(w, r, http.StatusConflict, author, form)
+		return
+	} else if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Author successfully deleted")
+
+	http.Redirect(w, r, "/authors", http.StatusSeeOther)
+}
+
+// Handler to add another name an author is known by
+func (app *application) authorAliasPost(w http.ResponseWriter, r *http.Request) {
+	author, ok := app.authorFromParam(w, r)
+	if !ok {
+		return
+	}
+
+	var form authorEditForm
+	err := app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+	form.Name, form.authorDetailsForm = author.Name, newAuthorDetailsForm(author.AuthorDetails)
+
+	form.Alias = strings.TrimSpace(form.Alias)
+	form.CheckField(validator.NotBlank(form.Alias), "alias", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.Alias, 100), "alias", "This field cannot be more than 100 characters long")
+	form.CheckField(validator.NoInvalidCharacters(form.Alias), "alias", "This field contains invalid characters")
+
+	// Check no author already has the name or alias, so a name only ever finds one author
+	if form.ValidField() {
+		_, err = app.authors.GetByName(r.Context(), form.Alias)
+		if err == nil {
+			form.AddFieldError("alias", "An author or alias with this name already exists")
+		} else if !errors.Is(err, models.ErrNoRecord) {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	if form.ValidField() {
+		_, err = app.authors.AddAlias(r.Context(), author.ID, form.Alias)
+		if errors.Is(err, models.ErrDuplicateAlias) {
+			form.AddFieldError("alias", "An author or alias with this name already exists")
+		} else if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	if !form.ValidField() {
+		app.renderAuthorEdit(w, r, http.StatusUnprocessableEntity, author, form)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Alias successfully added")
+
+	http.Redirect(w, r, fmt.Sprintf("/author/edit/%d", author.ID), http.StatusSeeOther)
+}
+
+// Handler to remove one of an author's aliases
+func (app *application) authorUnaliasPost(w http.ResponseWriter, r *http.Request) {
+	author, ok := app.authorFromParam(w, r)
+	if !ok {
+		return
+	}
+
+	var form authorEditForm
+	err := app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	err = app.authors.DeleteAlias(r.Context(), author.ID, form.AliasID)
+	if errors.Is(err, models.ErrNoRecord) {
+		app.notFoundResponse(w, r)
+		return
+	} else if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Alias successfully removed")
+
+	http.Redirect(w, r, fmt.Sprintf("/author/edit/%d", author.ID), http.StatusSeeOther)
+}
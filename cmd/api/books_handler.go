@@ -5,7 +5,12 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/justinbachtell/quote-table-go/internal/lookup"
 	"github.com/justinbachtell/quote-table-go/internal/models"
 	"github.com/justinbachtell/quote-table-go/internal/validator"
 )
@@ -17,9 +22,166 @@ type bookCreateForm struct {
 	CalendarTime string `form:"calendar_time"`
 	ISBN         string `form:"isbn"`
 	Source       string `form:"source"`
+	MediaType    models.MediaType `form:"media_type"`
+	DOI          string `form:"doi"`
+	Venue        string `form:"venue"`
+	Date         string `form:"work_date"`
+	PageCount    int    `form:"page_count"`
+	bookContributorsForm
+	// Set to add a book even though books like it have been suggested
+	ConfirmNew   bool          `form:"confirm_new"`
+	Suggestions  []models.Book `form:"-"`
+	// The authors an ISBN lookup credited who haven't been added yet
+	UnknownAuthors []string    `form:"-"`
 	validator.Validator `form:"-"`
 }
 
+// Clears the fields the form's media type doesn't use, treating a form
+// without one as a book, writes a valid ISBN as the ISBN-13 it is stored as,
+// and takes the publish year of a dated work from its
+// date when the year isn't given
+func (f *bookCreateForm) normalizeWork() {
+	if f.MediaType == "" {
+		f.MediaType = models.MediaBook
+	}
+	if !f.MediaType.UsesISBN() {
+		f.ISBN = ""
+	} else if isbn, ok := validator.NormalizeISBN(f.ISBN); ok {
+		f.ISBN = isbn
+	}
+	if !f.MediaType.UsesDOI() {
+		f.DOI = ""
+	}
+	if !f.MediaType.UsesVenue() {
+		f.Venue = ""
+	}
+	if !f.MediaType.UsesDate() {
+		f.Date = ""
+	}
+	if !f.MediaType.UsesPageCount() {
+		f.PageCount = 0
+	}
+
+	if date, err := time.Parse(time.DateOnly, f.Date); err == nil && f.PublishYear == 0 {
+		f.PublishYear = date.Year()
+		f.CalendarTime = "A.D."
+	}
+}
+
+// Validates the fields shared by the create and edit book forms
+func (f *bookCreateForm) validate() {
+	f.normalizeWork()
+
+	f.CheckField(validator.NotBlank(f.Title), "title", "This field cannot be blank")
+	f.CheckField(validator.MaxChars(f.Title, 200), "title", "This field cannot be more than 200 characters long")
+	f.CheckField(validator.PermittedInt(f.PublishYear, 1, 9999), "publish_year", "This field must be between 1 and 9999")
+	f.CheckField(validator.PermittedValues(f.CalendarTime, "A.D.", "B.C."), "calendar_time", "This field must be either A.D. or B.C.")
+	f.CheckField(validator.MaxChars(f.Source, 500), "source", "This field cannot be more than 500 characters long")
+	validator.ValidateWork(&f.Validator, string(f.MediaType), f.ISBN, f.Source, f.DOI, f.Venue, f.Date, f.PageCount)
+}
+
+// Returns the work details given on the form
+func (f bookCreateForm) workDetails() models.WorkDetails {
+	return models.WorkDetails{MediaType: f.MediaType, DOI: f.DOI, Venue: f.Venue, Date: f.Date, PageCount: f.PageCount}
+}
+
+// Returns the media type asked for in the query string, used to switch the
+// book forms between media types, or the fallback if none or an unknown one is given
+func readMediaType(r *http.Request, fallback models.MediaType) models.MediaType {
+	mediaType := models.MediaType(r.URL.Query().Get("media_type"))
+	if !slices.Contains(models.MediaTypes, mediaType) {
+		return fallback
+	}
+	return mediaType
+}
+
+// The contributor rows of the book forms, given as the author and role of
+// each row in the order they are credited. Rows without an author are ignored.
+type bookContributorsForm struct {
+	ContributorAuthors []int    `form:"contributor_author"`
+	ContributorRoles   []string `form:"contributor_role"`
+}
+
+// The number of blank contributor rows shown below the filled-in ones
+const blankContributorRows = 2
+
+// Returns the contributor fields filled in with a book's contributors
+func newBookContributorsForm(contributors []models.BookContributor) bookContributorsForm {
+	var f bookContributorsForm
+	for _, c := range contributors {
+		f.ContributorAuthors = append(f.ContributorAuthors, c.AuthorID)
+		f.ContributorRoles = append(f.ContributorRoles, string(c.Role))
+	}
+	return f
+}
+
+// Returns the contributors given on the form in order, leaving out the rows without an author
+func (f bookContributorsForm) contributors() []models.BookContributor {
+	var contributors []models.BookContributor
+	for i, authorID := range f.ContributorAuthors {
+		if authorID == 0 {
+			continue
+		}
+		role := ""
+		if i < len(f.ContributorRoles) {
+			role = strings.TrimSpace(f.ContributorRoles[i])
+		}
+		contributors = append(contributors, models.BookContributor{AuthorID: authorID, Role: models.ContributorRole(role)})
+	}
+	return contributors
+}
+
+// ContributorRows returns the rows to show on the form: the contributors
+// given so far followed by blank rows for adding more
+func (f bookContributorsForm) ContributorRows() []models.BookContributor {
+	rows := f.contributors()
+	for i := 0; i < blankContributorRows; i++ {
+		rows = append(rows, models.BookContributor{Role: models.ContributorAuthor})
+	}
+	return rows
+}
+
+// Validates the book's contributors, checking each one is a known author
+func (app *application) validateContributors(r *http.Request, v *validator.Validator, contributors []models.BookContributor) error {
+	authorIDs := make([]int, len(contributors))
+	roles := make([]string, len(contributors))
+	for i, c := range contributors {
+		authorIDs[i], roles[i] = c.AuthorID, string(c.Role)
+	}
+	validator.ValidateContributors(v, authorIDs, roles)
+
+	for _, c := range contributors {
+		if !v.ValidField() {
+			break
+		}
+		exists, err := app.authors.Exists(r.Context(), c.AuthorID)
+		if err != nil {
+			return err
+		}
+		v.CheckField(exists, "contributors", "Each contributor must be an existing author")
+	}
+
+	return nil
+}
+
+// Renders the create or edit book page with the authors and roles the contributor rows offer
+func (app *application) renderBookForm(w http.ResponseWriter, r *http.Request, status int, page string, book models.Book, form bookCreateForm) {
+	authors, err := app.authors.GetAllWithCounts(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Book = book
+	data.Authors = authors
+	data.ContributorRoles = models.ContributorRoles
+	data.MediaTypes = models.MediaTypes
+	data.Form = form
+
+	app.render(w, r, status, page, data)
+}
+
 // Handler for the view book page
 func (app *application) bookView(w http.ResponseWriter, r *http.Request) {
     id, err := app.readIDParam(r)
@@ -28,7 +190,7 @@ func (app *application) bookView(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    book, err := app.books.Get(int(id))
+    book, err := app.books.Get(r.Context(), int(id))
     if err != nil {
         if errors.Is(err, models.ErrNoRecord) {
             app.notFoundResponse(w, r)
@@ -39,25 +201,56 @@ func (app *application) bookView(w http.ResponseWriter, r *http.Request) {
     }
 
     // Fetch quotes for this book
-    quotes, err := app.quotes.GetByBookID(id)
+    quotes, err := app.quotes.GetByBookID(r.Context(), id)
     if err != nil {
         // Log the error but don't fail the request
         log.Printf("Error fetching quotes for book %d: %v", id, err)
         quotes = []models.Quote{} // Set an empty slice of quotes
     }
 
+    editions, err := app.editions.GetByBookID(r.Context(), id)
+    if err != nil {
+        app.serverError(w, r, err)
+        return
+    }
+
+    // The quotes of every edition are shown together, unless ?edition= picks one
+    var edition models.Edition
+    if editionID, err := strconv.Atoi(r.URL.Query().Get("edition")); err == nil {
+        edition = findEdition(editions, editionID)
+    }
+    if edition.ID != 0 {
+        quotes = slices.DeleteFunc(quotes, func(q models.Quote) bool { return q.EditionID != edition.ID })
+    }
+
+    // Show the quotes in the order they appear in the book
+    models.SortByLocation(quotes)
     book.Quotes = quotes
 
     data := app.newTemplateData(r)
     data.Book = book
 	data.Quotes = quotes
+	data.Editions = editions
+	data.Edition = edition
+	data.CanModify = app.canModify(r, book.UserID)
+	// Each edition can be changed by whoever added it, as well as moderators and admins
+	data.CanModifyEditions = make(map[int]bool, len(editions))
+	for _, e := range editions {
+		data.CanModifyEditions[e.ID] = app.canModify(r, e.UserID)
+	}
 
     app.render(w, r, http.StatusOK, "view-book.go.tmpl", data)
 }
 
 // Handler for the books page
 func (app *application) bookList(w http.ResponseWriter, r *http.Request) {
-	books, err := app.books.GetAllWithAuthors()
+	filters, err := app.readFilters(r, models.BookSorts)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	books, metadata, err := app.books.GetAllWithAuthors(r.Context(), filters)
 	if err != nil {
 		app.serverError(w, r, err)
 		return
@@ -65,16 +258,18 @@ func (app *application) bookList(w http.ResponseWriter, r *http.Request) {
 
 	data := app.newTemplateData(r)
 	data.Books = books
+	data.Filters = filters
+	data.Metadata = metadata
+	data.Sorts = models.BookSorts
 
 	app.render(w, r, http.StatusOK, "books.go.tmpl", data)
 }
 
 // Handler for the create book page
 func (app *application) bookCreate(w http.ResponseWriter, r *http.Request) {
-	data := app.newTemplateData(r)
-	data.Form = bookCreateForm{}
+	form := bookCreateForm{MediaType: readMediaType(r, models.MediaBook)}
 
-	app.render(w, r, http.StatusOK, "create-book.go.tmpl", data)
+	app.renderBookForm(w, r, http.StatusOK, "create-book.go.tmpl", models.Book{}, form)
 }
 
 // Handler to process and post the book data
@@ -87,22 +282,46 @@ func (app *application) bookCreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	form.CheckField(validator.NotBlank(form.Title), "title", "This field cannot be blank")
-	form.CheckField(validator.MaxChars(form.Title, 200), "title", "This field cannot be more than 200 characters long")
-	form.CheckField(validator.PermittedInt(form.PublishYear, 1, 9999), "publish_year", "This field must be between 1 and 9999")
-	form.CheckField(validator.PermittedValues(form.CalendarTime, "A.D.", "B.C."), "calendar_time", "This field must be either A.D. or B.C.")
-	form.CheckField(validator.NotBlank(form.ISBN), "isbn", "This field cannot be blank")
-	form.CheckField(validator.Matches(form.ISBN, validator.ISBNRegex), "isbn", "This field must be a valid ISBN")
-	form.CheckField(validator.MaxChars(form.Source, 500), "source", "This field cannot be more than 500 characters long")
+	form.validate()
+
+	contributors := form.contributors()
+	err = app.validateContributors(r, &form.Validator, contributors)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	// Check no edition of a book has the ISBN
+	taken, err := app.editionHasISBN(r.Context(), form.ISBN)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+	form.CheckField(!taken, "isbn", "An edition with this ISBN has already been added")
+
+	// Check the book hasn't already been added under a similar title or the same ISBN
+	if form.ValidField() && !form.ConfirmNew {
+		form.Suggestions, err = app.similarBooks(r.Context(), form.Title, form.ISBN)
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+		if len(form.Suggestions) > 0 {
+			form.AddFieldError("title", "There are already books with similar titles or the same ISBN. Did you mean one of them?")
+		}
+	}
 
 	if !form.ValidField() {
-		data := app.newTemplateData(r)
-		data.Form = form
-		app.render(w, r, http.StatusUnprocessableEntity, "create-book.go.tmpl", data)
+		app.renderBookForm(w, r, http.StatusUnprocessableEntity, "create-book.go.tmpl", models.Book{}, form)
 		return
 	}
 
-	id, err := app.books.Insert(form.Title, form.PublishYear, form.CalendarTime, form.ISBN, form.Source)
+	id, err := app.books.Insert(r.Context(), form.Title, form.PublishYear, form.CalendarTime, form.ISBN, form.Source, form.workDetails(), contributors, app.contextGetUserID(r))
+	if errors.Is(err, models.ErrDuplicateISBN) {
+		form.AddFieldError("isbn", "A book with this ISBN has already been added")
+		app.renderBookForm(w, r, http.StatusUnprocessableEntity, "create-book.go.tmpl", models.Book{}, form)
+		return
+	}
 	if err != nil {
 		app.serverError(w, r, err)
 		return
@@ -113,6 +332,55 @@ func (app *application) bookCreatePost(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, fmt.Sprintf("/book/view/%d", id), http.StatusSeeOther)
 }
 
+// Handler to look a book up by the ISBN given by ?isbn=, showing the create
+// book form filled in with what was found and crediting the book's authors
+// who have already been added. The form is swapped into the page by htmx, so
+// an ISBN that can't be looked up is shown as an error on the form.
+func (app *application) bookLookup(w http.ResponseWriter, r *http.Request) {
+	if app.isbnLookup == nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	form := bookCreateForm{MediaType: models.MediaBook, CalendarTime: "A.D.", ISBN: r.URL.Query().Get("isbn")}
+	isbn, ok := validator.NormalizeISBN(form.ISBN)
+	if !ok {
+		validator.ValidateISBN(&form.Validator, form.ISBN)
+		app.renderBookForm(w, r, http.StatusOK, "create-book.go.tmpl", models.Book{}, form)
+		return
+	}
+	form.ISBN = isbn
+
+	book, err := app.isbnLookup.LookupISBN(r.Context(), isbn)
+	switch {
+	case errors.Is(err, lookup.ErrNotFound):
+		form.AddFieldError("isbn", "No book was found with this ISBN, so its details have to be entered by hand")
+	case err != nil:
+		app.logError(r, err)
+		form.AddFieldError("isbn", "Books can't be looked up right now, so its details have to be entered by hand")
+	default:
+		form.Title = book.Title
+		form.PublishYear = book.PublishYear
+		form.PageCount = book.PageCount
+		form.Source = book.URL
+		for _, name := range book.Authors {
+			author, err := app.authors.GetByName(r.Context(), name)
+			if errors.Is(err, models.ErrNoRecord) {
+				form.UnknownAuthors = append(form.UnknownAuthors, name)
+				continue
+			}
+			if err != nil {
+				app.serverError(w, r, err)
+				return
+			}
+			form.ContributorAuthors = append(form.ContributorAuthors, author.ID)
+			form.ContributorRoles = append(form.ContributorRoles, string(models.ContributorAuthor))
+		}
+	}
+
+	app.renderBookForm(w, r, http.StatusOK, "create-book.go.tmpl", models.Book{}, form)
+}
+
 // Handler for the edit book page
 func (app *application) bookEdit(w http.ResponseWriter, r *http.Request) {
 	id, err := app.readIDParam(r)
@@ -121,7 +389,7 @@ func (app *application) bookEdit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	book, err := app.books.Get(id)
+	book, err := app.books.Get(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, models.ErrNoRecord) {
 			app.notFoundResponse(w, r)
@@ -131,18 +399,27 @@ func (app *application) bookEdit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data := app.newTemplateData(r)
-	data.Book = book
+	// Only the user who added the book, or a moderator or admin, can edit it
+	if !app.canModify(r, book.UserID) {
+		app.forbiddenResponse(w, r)
+		return
+	}
 
-	data.Form = bookCreateForm{
-		Title:        book.Title,
-		PublishYear:  book.PublishYear,
-		CalendarTime: book.CalendarTime,
-		ISBN:         book.ISBN,
-		Source:       book.Source,
+	form := bookCreateForm{
+		Title:                book.Title,
+		PublishYear:          book.PublishYear,
+		CalendarTime:         book.CalendarTime,
+		ISBN:                 book.ISBN,
+		Source:               book.Source,
+		MediaType:            readMediaType(r, book.Kind()),
+		DOI:                  book.DOI,
+		Venue:                book.Venue,
+		Date:                 book.Date,
+		PageCount:            book.PageCount,
+		bookContributorsForm: newBookContributorsForm(book.Contributors),
 	}
 
-	app.render(w, r, http.StatusOK, "edit-book.go.tmpl", data)
+	app.renderBookForm(w, r, http.StatusOK, "edit-book.go.tmpl", book, form)
 }
 
 // Handler to process and post the book data
@@ -153,7 +430,7 @@ func (app *application) bookEditPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = app.books.Get(id)
+	book, err := app.books.Get(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, models.ErrNoRecord) {
 			app.notFoundResponse(w, r)
@@ -163,6 +440,12 @@ func (app *application) bookEditPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Only the user who added the book, or a moderator or admin, can edit it
+	if !app.canModify(r, book.UserID) {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
 	var form bookCreateForm
 	err = app.formDecoder.Decode(&form, r.PostForm)
 	if err != nil {
@@ -170,22 +453,34 @@ func (app *application) bookEditPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	form.CheckField(validator.NotBlank(form.Title), "title", "This field cannot be blank")
-	form.CheckField(validator.MaxChars(form.Title, 200), "title", "This field cannot be more than 200 characters long")
-	form.CheckField(validator.PermittedInt(form.PublishYear, 1, 9999), "publish_year", "This field must be between 1 and 9999")
-	form.CheckField(validator.PermittedValues(form.CalendarTime, "A.D.", "B.C."), "calendar_time", "This field must be either A.D. or B.C.")
-	form.CheckField(validator.NotBlank(form.ISBN), "isbn", "This field cannot be blank")
-	form.CheckField(validator.Matches(form.ISBN, validator.ISBNRegex), "isbn", "This field must be a valid ISBN")
-	form.CheckField(validator.MaxChars(form.Source, 500), "source", "This field cannot be more than 500 characters long")
+	form.validate()
+
+	contributors := form.contributors()
+	err = app.validateContributors(r, &form.Validator, contributors)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	// Check no edition of a book has the ISBN
+	taken, err := app.editionHasISBN(r.Context(), form.ISBN)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+	form.CheckField(!taken, "isbn", "An edition with this ISBN has already been added")
 
 	if !form.ValidField() {
-		data := app.newTemplateData(r)
-		data.Form = form
-		app.render(w, r, http.StatusUnprocessableEntity, "edit-book.go.tmpl", data)
+		app.renderBookForm(w, r, http.StatusUnprocessableEntity, "edit-book.go.tmpl", book, form)
 		return
 	}
 
-	err = app.books.Update(id, form.Title, form.PublishYear, form.CalendarTime, form.ISBN, form.Source)
+	err = app.books.Update(r.Context(), id, form.Title, form.PublishYear, form.CalendarTime, form.ISBN, form.Source, form.workDetails(), contributors)
+	if errors.Is(err, models.ErrDuplicateISBN) {
+		form.AddFieldError("isbn", "Another book with this ISBN has already been added")
+		app.renderBookForm(w, r, http.StatusUnprocessableEntity, "edit-book.go.tmpl", book, form)
+		return
+	}
 	if err != nil {
 		app.serverError(w, r, err)
 		return
@@ -204,8 +499,28 @@ func (app *application) bookDeletePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = app.books.Delete(id)
+	// Only the user who added the book, or a moderator or admin, can delete it
+	book, err := app.books.Get(r.Context(), id)
 	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFoundResponse(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return
+	}
+	if !app.canModify(r, book.UserID) {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	// A book can't be deleted while quotes are taken from it
+	err = app.books.Delete(r.Context(), id)
+	if errors.Is(err, models.ErrBookHasQuotes) {
+		app.sessionManager.Put(r.Context(), "flash", "This book still has quotes. Delete them or move them to another book before deleting it.")
+		http.Redirect(w, r, fmt.Sprintf("/book/view/%d", id), http.StatusSeeOther)
+		return
+	} else if err != nil {
 		app.serverError(w, r, err)
 		return
 	}
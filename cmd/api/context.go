@@ -1,4 +1,40 @@
 package main
 
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
 type contextKey string
-const isAuthenticatedContextKey = contextKey("isAuthenticated")
\ No newline at end of file
+const isAuthenticatedContextKey = contextKey("isAuthenticated")
+const authenticatedUserIDContextKey = contextKey("authenticatedUserID")
+const authenticatedUserRoleContextKey = contextKey("authenticatedUserRole")
+
+// Returns a copy of the request with the authenticated user's ID and role added to its context
+func (app *application) contextSetUser(r *http.Request, user models.User) *http.Request {
+	ctx := context.WithValue(r.Context(), isAuthenticatedContextKey, true)
+	ctx = context.WithValue(ctx, authenticatedUserIDContextKey, user.ID)
+	ctx = context.WithValue(ctx, authenticatedUserRoleContextKey, user.Role)
+
+	// Read quotes through the models as the user, so they can see their own private quotes
+	ctx = models.WithViewer(ctx, user.ID)
+	return r.WithContext(ctx)
+}
+
+// Returns the authenticated user ID from the request context, or uuid.Nil for anonymous requests
+func (app *application) contextGetUserID(r *http.Request) uuid.UUID {
+	id, ok := r.Context().Value(authenticatedUserIDContextKey).(uuid.UUID)
+	if !ok {
+		return uuid.Nil
+	}
+	return id
+}
+
+// Returns the authenticated user's role from the request context, or no role for anonymous requests
+func (app *application) contextGetRole(r *http.Request) models.Role {
+	role, _ := r.Context().Value(authenticatedUserRoleContextKey).(models.Role)
+	return role
+}
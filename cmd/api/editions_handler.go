@@ -0,0 +1,264 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/justinbachtell/quote-table-go/internal/models"
+	"github.com/justinbachtell/quote-table-go/internal/validator"
+)
+
+// Struct to represent the edition form data
+type editionForm struct {
+	ISBN         string `form:"isbn"`
+	Publisher    string `form:"publisher"`
+	Language     string `form:"language"`
+	TranslatorID int    `form:"translator_id"`
+	PublishYear  int    `form:"publish_year"`
+	PageCount    int    `form:"page_count"`
+	validator.Validator `form:"-"`
+}
+
+// Returns the edition form filled in with an edition's details
+func newEditionForm(details models.EditionDetails) editionForm {
+	return editionForm{
+		ISBN:         details.ISBN,
+		Publisher:    details.Publisher,
+		Language:     details.Language,
+		TranslatorID: details.TranslatorID,
+		PublishYear:  details.PublishYear,
+		PageCount:    details.PageCount,
+	}
+}
+
+// Returns the edition details given on the form
+func (f editionForm) details() models.EditionDetails {
+	return models.EditionDetails{
+		ISBN:         f.ISBN,
+		Publisher:    f.Publisher,
+		Language:     f.Language,
+		TranslatorID: f.TranslatorID,
+		PublishYear:  f.PublishYear,
+		PageCount:    f.PageCount,
+	}
+}
+
+// Validates the edition form, writing a valid ISBN as the ISBN-13 it is
+// stored as, and checks its ISBN isn't a book's and its translator is a
+// known author
+func (app *application) validateEdition(r *http.Request, form *editionForm) error {
+	if isbn, ok := validator.NormalizeISBN(form.ISBN); ok {
+		form.ISBN = isbn
+	}
+	validator.ValidateEdition(&form.Validator, form.ISBN, form.Publisher, form.Language, form.TranslatorID, form.PublishYear, form.PageCount)
+
+	taken, err := app.bookHasISBN(r.Context(), form.ISBN)
+	if err != nil {
+		return err
+	}
+	form.CheckField(!taken, "isbn", "A book with this ISBN has already been added")
+
+	if form.TranslatorID > 0 {
+		exists, err := app.authors.Exists(r.Context(), form.TranslatorID)
+		if err != nil {
+			return err
+		}
+		form.CheckField(exists, "translator", "The translator must be an existing author")
+	}
+
+	return nil
+}
+
+// Renders the create or edit edition page with the authors who can be credited as its translator
+func (app *application) renderEditionForm(w http.ResponseWriter, r *http.Request, status int, page string, book models.Book, edition models.Edition, form editionForm) {
+	authors, err := app.authors.GetAllWithCounts(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Book = book
+	data.Edition = edition
+	data.Authors = authors
+	data.Form = form
+
+	app.render(w, r, status, page, data)
+}
+
+// Fetches the book in the URL, writing the error response and returning false
+// if there is no such book
+func (app *application) editionBook(w http.ResponseWriter, r *http.Request) (models.Book, bool) {
+	id, err := app.readIDParam(r)
+	if err != nil || id < 1 {
+		app.notFoundResponse(w, r)
+		return models.Book{}, false
+	}
+
+	book, err := app.books.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFoundResponse(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return models.Book{}, false
+	}
+
+	return book, true
+}
+
+// Fetches the edition in the URL and its book, writing the error response and
+// returning false unless the user can modify the edition
+func (app *application) editionToModify(w http.ResponseWriter, r *http.Request) (models.Edition, models.Book, bool) {
+	id, err := app.readIDParam(r)
+	if err != nil || id < 1 {
+		app.notFoundResponse(w, r)
+		return models.Edition{}, models.Book{}, false
+	}
+
+	edition, err := app.editions.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFoundResponse(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return models.Edition{}, models.Book{}, false
+	}
+
+	// Only the user who added the edition, or a moderator or admin, can change it
+	if !app.canModify(r, edition.UserID) {
+		app.forbiddenResponse(w, r)
+		return models.Edition{}, models.Book{}, false
+	}
+
+	book, err := app.books.Get(r.Context(), edition.BookID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return models.Edition{}, models.Book{}, false
+	}
+
+	return edition, book, true
+}
+
+// Handler for the page to add an edition of a book
+func (app *application) editionCreate(w http.ResponseWriter, r *http.Request) {
+	book, ok := app.editionBook(w, r)
+	if !ok {
+		return
+	}
+
+	app.renderEditionForm(w, r, http.StatusOK, "create-edition.go.tmpl", book, models.Edition{}, editionForm{})
+}
+
+// Handler to process and post the edition data
+func (app *application) editionCreatePost(w http.ResponseWriter, r *http.Request) {
+	book, ok := app.editionBook(w, r)
+	if !ok {
+		return
+	}
+
+	var form editionForm
+	err := app.formDecoder.Decode(&form, r.PostForm)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	err = app.validateEdition(r, &form)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	if !form.ValidField() {
+		app.renderEditionForm(w, r, http.StatusUnprocessableEntity, "create-edition.go.tmpl", book, models.Edition{}, form)
+		return
+	}
+
+	_, err = app.editions.Insert(r.Context(), book.ID, form.details(), app.contextGetUserID(r))
+	if errors.Is(err, models.ErrDuplicateISBN) {
+		form.AddFieldError("isbn", "An edition with this ISBN has already been added")
+		app.renderEditionForm(w, r, http.StatusUnprocessableEntity, "create-edition.go.tmpl", book, models.Edition{}, form)
+		return
+	}
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Edition successfully added")
+
+	http.Redirect(w, r, fmt.Sprintf("/book/view/%d", book.ID), http.StatusSeeOther)
+}
+
+// Handler for the edit edition page
+func (app *application) editionEdit(w http.ResponseWriter, r *http.Request) {
+	edition, book, ok := app.editionToModify(w, r)
+	if !ok {
+		return
+	}
+
+	app.renderEditionForm(w, r, http.StatusOK, "edit-edition.go.tmpl", book, edition, newEditionForm(edition.EditionDetails))
+}
+
+// Handler to process and post the edition data
+func (app *application) editionEditPost(w http.ResponseWriter, r *http.Request) {
+	edition, book, ok := app.editionToModify(w, r)
+	if !ok {
+		return
+	}
+
+	var form editionForm
+	err := app.formDecoder.Decode(&form, r.PostForm)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	err = app.validateEdition(r, &form)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	if !form.ValidField() {
+		app.renderEditionForm(w, r, http.StatusUnprocessableEntity, "edit-edition.go.tmpl", book, edition, form)
+		return
+	}
+
+	err = app.editions.Update(r.Context(), edition.ID, form.details())
+	if errors.Is(err, models.ErrDuplicateISBN) {
+		form.AddFieldError("isbn", "Another edition with this ISBN has already been added")
+		app.renderEditionForm(w, r, http.StatusUnprocessableEntity, "edit-edition.go.tmpl", book, edition, form)
+		return
+	}
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Edition successfully updated")
+
+	http.Redirect(w, r, fmt.Sprintf("/book/view/%d", book.ID), http.StatusSeeOther)
+}
+
+// Handler to delete an edition, leaving its quotes with the book
+func (app *application) editionDeletePost(w http.ResponseWriter, r *http.Request) {
+	edition, book, ok := app.editionToModify(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.editions.Delete(r.Context(), edition.ID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Edition successfully deleted")
+
+	http.Redirect(w, r, fmt.Sprintf("/book/view/%d", book.ID), http.StatusSeeOther)
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"runtime/debug"
+
+	"github.com/justinbachtell/quote-table-go/internal/models"
 )
 
 // Define a list of common errors
@@ -27,6 +29,16 @@ func (app *application) logError(r *http.Request, err error) {
 
 // Define a server error helper to log the error message and return a generic error message
 func (app *application) serverError(w http.ResponseWriter, r *http.Request, err error) {
+	// Map model queries that ran out of time to their own status codes
+	switch {
+	case errors.Is(err, models.ErrQueryTimeout):
+		app.gatewayTimeoutResponse(w, r, err)
+		return
+	case errors.Is(err, models.ErrQueryCanceled):
+		app.serviceUnavailableResponse(w, r, err)
+		return
+	}
+
 	var (
 		method = r.Method
 		uri    = r.URL.RequestURI()
@@ -39,6 +51,20 @@ func (app *application) serverError(w http.ResponseWriter, r *http.Request, err
 	app.errorResponse(w, r, http.StatusInternalServerError, message)
 }
 
+// Define a gateway timeout helper for model queries that exceeded their deadline
+func (app *application) gatewayTimeoutResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.logError(r, err)
+	message := "The server took too long to process your request"
+	app.errorResponse(w, r, http.StatusGatewayTimeout, message)
+}
+
+// Define a service unavailable helper for model queries that were canceled
+func (app *application) serviceUnavailableResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.logger.Warn(err.Error(), "method", r.Method, "uri", r.URL.RequestURI())
+	message := "The server is unable to process your request right now"
+	app.errorResponse(w, r, http.StatusServiceUnavailable, message)
+}
+
 // Define a client error helper to return a specific status code and message
 func (app *application) clientError(w http.ResponseWriter, status int) {
 	http.Error(w, http.StatusText(status), status)
@@ -64,6 +90,12 @@ func (app *application) notFoundResponse(w http.ResponseWriter, r *http.Request)
 	app.errorResponse(w, r, http.StatusNotFound, message)
 }
 
+// Define a forbidden helper to return a 403 status code and message
+func (app *application) forbiddenResponse(w http.ResponseWriter, r *http.Request) {
+	message := "You do not have permission to change this resource"
+	app.errorResponse(w, r, http.StatusForbidden, message)
+}
+
 // Define a method not allowed helper to return a 405 status code and message
 func (app *application) methodNotAllowedResponse(w http.ResponseWriter, r *http.Request) {
 	message := fmt.Sprintf("The %s method is not supported for this resource", r.Method)
@@ -6,6 +6,8 @@ import (
 	"strconv"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+	"github.com/justinbachtell/quote-table-go/internal/validator"
 )
 
 func (app *application) readIDParam(r *http.Request) (int, error) {
@@ -19,24 +21,57 @@ func (app *application) readIDParam(r *http.Request) (int, error) {
 	return int(id), nil
 }
 
+// Reads the page and sort order of a listing from the query string, where
+// sorts holds the orders the listing accepts with its default first
+func (app *application) readFilters(r *http.Request, sorts []string) (models.Filters, error) {
+	query := r.URL.Query()
+	filters := models.Filters{Page: 1, PageSize: models.DefaultPageSize, Sort: sorts[0]}
+
+	// Check the page is a positive number if one was given
+	if page := query.Get("page"); page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 || n > 10_000_000 {
+			return models.Filters{}, errors.New("invalid page parameter")
+		}
+		filters.Page = n
+	}
+
+	// Check the sort order is one the listing accepts if one was given
+	if sort := query.Get("sort"); sort != "" {
+		if !validator.PermittedValue(sort, sorts...) {
+			return models.Filters{}, errors.New("invalid sort parameter")
+		}
+		filters.Sort = sort
+	}
+
+	return filters, nil
+}
+
 // Handler for the home page
 func (app *application) home(w http.ResponseWriter, r *http.Request) {
-	// Get the latest quotes from the database
-	quotes, err := app.quotes.Latest()
+	// Read the page and sort order from the query string
+	filters, err := app.readFilters(r, models.QuoteSorts)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	// Get a page of the latest quotes from the database
+	quotes, metadata, err := app.quotes.Latest(r.Context(), filters)
 	if err != nil {
 		app.serverError(w, r, err)
 		return
 	}
 
 	// Get all authors
-	authors, err := app.authors.GetAllWithCounts()
+	authors, err := app.authors.GetAllWithCounts(r.Context())
 	if err != nil {
 		app.serverError(w, r, err)
 		return
 	}
 
 	// Get all books
-	books, err := app.books.GetAll()
+	books, err := app.books.GetAll(r.Context())
 	if err != nil {
 		app.serverError(w, r, err)
 		return
@@ -47,6 +82,9 @@ func (app *application) home(w http.ResponseWriter, r *http.Request) {
 	data.Quotes = quotes
 	data.Authors = authors
 	data.Books = books
+	data.Filters = filters
+	data.Metadata = metadata
+	data.Sorts = models.QuoteSorts
 
 	// render the home page
 	app.render(w, r, http.StatusOK, "home.go.tmpl", data)
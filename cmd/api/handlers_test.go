@@ -1,11 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/justinbachtell/quote-table-go/internal/assert"
+	"github.com/justinbachtell/quote-table-go/internal/lookup"
+	"github.com/justinbachtell/quote-table-go/internal/models"
 )
 
 // Tests the ping route
@@ -45,7 +55,7 @@ func TestQuoteView(t *testing.T) {
 		wantCode int
 		wantBody string
 	}{
-		{"Valid ID", "/quote/view/1", http.StatusOK, ""},
+		{"Valid ID", "/quote/view/1", http.StatusOK, "To be or not to be, that is the question."},
 		{"Non-existent ID", "/quote/view/10000000", http.StatusNotFound, ""},
 		{"Negative ID", "/quote/view/-1", http.StatusNotFound, ""},
 		{"Decimal ID", "/quote/view/1.23", http.StatusNotFound, ""},
@@ -70,6 +80,1384 @@ func TestQuoteView(t *testing.T) {
 	}
 }
 
+// Tests the page and sort parameters of the listing routes
+func TestListingFilters(t *testing.T) {
+	// Create a new test application
+	app := newTestApplication(t)
+
+	// Establish a new test server
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	// Set up test data to check responses
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{"Quotes", "/", http.StatusOK, "To be or not to be, that is the question."},
+		{"Quotes sorted", "/?sort=author&page=1", http.StatusOK, `<option value="author" selected>Author</option>`},
+		{"Quotes past the end", "/?page=2", http.StatusOK, "There's nothing to see here yet!"},
+		{"Books sorted", "/books?sort=most-quoted", http.StatusOK, "Hamlet"},
+		{"Authors sorted", "/authors?sort=most-quoted", http.StatusOK, "William Shakespeare"},
+		{"Zero page", "/?page=0", http.StatusBadRequest, ""},
+		{"String page", "/books?page=foo", http.StatusBadRequest, ""},
+		{"Unknown sort", "/authors?sort=title", http.StatusBadRequest, ""},
+	}
+
+	// Loop through each test case
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.get(t, tt.urlPath)
+
+			// Check the status code
+			assert.Equal(t, code, tt.wantCode)
+
+			// Check the response body if it is expected
+			if tt.wantBody != "" {
+				assert.StringContains(t, body, tt.wantBody)
+			}
+		})
+	}
+}
+
+// Tests the search route
+func TestSearch(t *testing.T) {
+	// Create a new test application
+	app := newTestApplication(t)
+	err := app.quotes.SetTags(context.Background(), 1, []string{"hamlet", "soliloquy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Establish a new test server
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	// Set up test data to check responses
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{"Empty form", "/search", http.StatusOK, "Search quotes, authors and books"},
+		{"Quote match", "/search?q=QUEST", http.StatusOK, `rounded-sm">question</mark>`},
+		{"Author match", "/search?q=shakespeare+be", http.StatusOK, `rounded-sm">Shakespeare</mark>`},
+		{"Filtered out", "/search?q=question&year_to=1500", http.StatusOK, "No quotes match your search."},
+		{"Page past the end", "/search?q=question&page=2", http.StatusOK, "No quotes match your search."},
+		{"Blank query", "/search?q=+", http.StatusUnprocessableEntity, "Enter something to search for"},
+		{"Own quotes when logged out", "/search?q=question&scope=mine", http.StatusUnprocessableEntity, "Log in to search your own quotes"},
+		{"Backwards year range", "/search?q=question&year_from=1700&year_to=1600", http.StatusUnprocessableEntity, "This year cannot be before the from year"},
+		{"Author operator", "/search?q=author%3A%22William+Shakespeare%22", http.StatusOK, "To be or not to be"},
+		{"Author operator no match", "/search?q=author%3Amarcus", http.StatusOK, "No quotes match your search."},
+		{"Exact phrase", "/search?q=%22not+to+be%22", http.StatusOK, `rounded-sm">not</mark>`},
+		{"Excluded word", "/search?q=be+-question", http.StatusOK, "No quotes match your search."},
+		{"Year operator", "/search?q=year%3A%3E1600", http.StatusOK, "To be or not to be"},
+		{"Unclosed phrase", "/search?q=%22to+be", http.StatusUnprocessableEntity, "There is a mistake in the search: the quote starting at"},
+		{"Tag operator", "/search?q=tag%3ASoliloquy", http.StatusOK, "To be or not to be"},
+		{"Tag operator no match", "/search?q=tag%3Astoic", http.StatusOK, "No quotes match your search."},
+		{"Own private quotes when logged out", "/search?q=is%3Aprivate", http.StatusUnprocessableEntity, "Log in to search your own quotes"},
+		{"Invalid year", "/search?q=question&year_from=soon", http.StatusBadRequest, ""},
+		{"Unknown sort", "/search?q=question&sort=title", http.StatusBadRequest, ""},
+	}
+
+	// Loop through each test case
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.get(t, tt.urlPath)
+
+			// Check the status code
+			assert.Equal(t, code, tt.wantCode)
+
+			// Check the response body if it is expected
+			if tt.wantBody != "" {
+				assert.StringContains(t, body, tt.wantBody)
+			}
+		})
+	}
+}
+
+// Tests only the user who added a quote or book, or a moderator, can change it
+func TestOwnership(t *testing.T) {
+	const (
+		ownerEmail     = "duplicate@example.com"
+		otherEmail     = "other@example.com"
+		premiumEmail   = "premium@example.com"
+		moderatorEmail = "moderator@example.com"
+		adminEmail     = "admin@example.com"
+		password       = "pa$$word"
+	)
+
+	tests := []struct {
+		name       string
+		email      string
+		wantCode   int
+		wantButton bool
+	}{
+		{"Anonymous", "", http.StatusSeeOther, false},
+		{"Another user", otherEmail, http.StatusForbidden, false},
+		{"Premium user", premiumEmail, http.StatusForbidden, false},
+		{"Owner", ownerEmail, http.StatusOK, true},
+		{"Moderator", moderatorEmail, http.StatusOK, true},
+		{"Admin", adminEmail, http.StatusOK, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Add a user with each role alongside the owner of the test data
+			app := newTestApplication(t)
+			insertUserWithRole(t, app, "Other User", otherEmail, password, models.RoleUser)
+			insertUserWithRole(t, app, "Premium User", premiumEmail, password, models.RolePremium)
+			insertUserWithRole(t, app, "Moderator User", moderatorEmail, password, models.RoleModerator)
+			insertUserWithRole(t, app, "Admin User", adminEmail, password, models.RoleAdmin)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			// Log in, or take a CSRF token from the login page when anonymous
+			var csrfToken string
+			if tt.email != "" {
+				csrfToken = ts.login(t, tt.email, password)
+			} else {
+				_, _, body := ts.get(t, "/user/login")
+				csrfToken = extractCSRFToken(t, body)
+			}
+
+			// Check the edit buttons are only shown to users who can use them
+			_, _, body := ts.get(t, "/quote/view/1")
+			assert.Equal(t, strings.Contains(body, `href="/quote/edit/1"`), tt.wantButton)
+			_, _, body = ts.get(t, "/book/view/1")
+			assert.Equal(t, strings.Contains(body, `href="/book/edit/1"`), tt.wantButton)
+
+			// Check the edit pages
+			code, _, _ := ts.get(t, "/quote/edit/1")
+			assert.Equal(t, code, tt.wantCode)
+			code, _, _ = ts.get(t, "/book/edit/1")
+			assert.Equal(t, code, tt.wantCode)
+
+			// Check editing the quote keeps its owner
+			code, _, _ = ts.postForm(t, "/quote/edit/1", url.Values{
+				"quote":           {"To be, or not to be, that is the question."},
+				"author-selector": {"1"},
+				"book-selector":   {"1"},
+				"csrf_token":      {csrfToken},
+			})
+			wantCode := tt.wantCode
+			if wantCode == http.StatusOK {
+				wantCode = http.StatusSeeOther
+			}
+			assert.Equal(t, code, wantCode)
+
+			quote, err := app.quotes.Get(context.Background(), 1)
+			assert.NilError(t, err)
+			owner, err := app.users.GetByEmail(context.Background(), ownerEmail)
+			assert.NilError(t, err)
+			assert.Equal(t, quote.UserID, owner.ID)
+
+			// Check deleting the quote and book
+			code, _, _ = ts.postForm(t, "/quote/delete/1", url.Values{"csrf_token": {csrfToken}})
+			assert.Equal(t, code, wantCode)
+			code, _, _ = ts.postForm(t, "/book/delete/1", url.Values{"csrf_token": {csrfToken}})
+			assert.Equal(t, code, wantCode)
+
+			exists, err := app.quotes.Exists(context.Background(), 1)
+			assert.NilError(t, err)
+			assert.Equal(t, exists, !tt.wantButton)
+		})
+	}
+}
+
+// Tests a book can't be deleted while quotes are taken from it
+func TestBookDeleteWithQuotes(t *testing.T) {
+	app := newTestApplication(t)
+	insertUserWithRole(t, app, "Admin User", "admin@example.com", "pa$$word", models.RoleAdmin)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	csrfToken := ts.login(t, "admin@example.com", "pa$$word")
+
+	// Check the delete is refused with a message rather than an error
+	code, header, _ := ts.postForm(t, "/book/delete/1", url.Values{"csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/book/view/1")
+
+	_, _, body := ts.get(t, "/book/view/1")
+	assert.StringContains(t, body, "This book still has quotes.")
+
+	exists, err := app.books.Exists(context.Background(), 1)
+	assert.NilError(t, err)
+	assert.Equal(t, exists, true)
+
+	// Check the book can be deleted once its quote is gone
+	code, _, _ = ts.postForm(t, "/quote/delete/1", url.Values{"csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusSeeOther)
+	code, header, _ = ts.postForm(t, "/book/delete/1", url.Values{"csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/")
+}
+
+// Tests only moderators and admins can create authors
+func TestAuthorCreate(t *testing.T) {
+	const (
+		moderatorEmail = "moderator@example.com"
+		adminEmail     = "admin@example.com"
+		password       = "pa$$word"
+	)
+
+	tests := []struct {
+		name     string
+		email    string
+		wantCode int
+	}{
+		{"Anonymous", "", http.StatusSeeOther},
+		{"User", "duplicate@example.com", http.StatusForbidden},
+		{"Moderator", moderatorEmail, http.StatusOK},
+		{"Admin", adminEmail, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			insertUserWithRole(t, app, "Moderator User", moderatorEmail, password, models.RoleModerator)
+			insertUserWithRole(t, app, "Admin User", adminEmail, password, models.RoleAdmin)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			// Log in, or take a CSRF token from the login page when anonymous
+			var csrfToken string
+			if tt.email != "" {
+				csrfToken = ts.login(t, tt.email, password)
+			} else {
+				_, _, body := ts.get(t, "/user/login")
+				csrfToken = extractCSRFToken(t, body)
+			}
+
+			// Check the add button is only shown to moderators and admins
+			_, _, body := ts.get(t, "/authors")
+			assert.Equal(t, strings.Contains(body, `href="/author/create"`), tt.wantCode == http.StatusOK)
+
+			code, _, _ := ts.get(t, "/author/create")
+			assert.Equal(t, code, tt.wantCode)
+
+			// Check the author is only created by moderators and admins
+			form := url.Values{"name": {"Marcus Aurelius"}, "csrf_token": {csrfToken}}
+			code, header, _ := ts.postForm(t, "/author/create", form)
+			_, err := app.authors.GetByName(context.Background(), "Marcus Aurelius")
+			if tt.wantCode != http.StatusOK {
+				assert.Equal(t, code, tt.wantCode)
+				assert.Equal(t, errors.Is(err, models.ErrNoRecord), true)
+				return
+			}
+			assert.Equal(t, code, http.StatusSeeOther)
+			assert.StringContains(t, header.Get("Location"), "/author/view/")
+			assert.NilError(t, err)
+
+			// Check the same author can't be added twice
+			code, _, body = ts.postForm(t, "/author/create", form)
+			assert.Equal(t, code, http.StatusUnprocessableEntity)
+			assert.StringContains(t, body, "An author with this name already exists")
+		})
+	}
+}
+
+// Tests moderators can edit authors and admins can also merge and delete them
+func TestAuthorManagement(t *testing.T) {
+	const (
+		userEmail      = "duplicate@example.com"
+		moderatorEmail = "moderator@example.com"
+		adminEmail     = "admin@example.com"
+		password       = "pa$$word"
+	)
+
+	app := newTestApplication(t)
+	ctx := context.Background()
+	insertUserWithRole(t, app, "Moderator User", moderatorEmail, password, models.RoleModerator)
+	adminID := insertUserWithRole(t, app, "Admin User", adminEmail, password, models.RoleAdmin)
+
+	// Add a duplicate author with a private quote, and an author without quotes
+	duplicateID, err := app.authors.Insert(ctx, "W. Shakespeare", models.AuthorDetails{}, adminID)
+	assert.NilError(t, err)
+	quoteID, err := app.quotes.Insert(ctx, "All the world's a stage.", duplicateID, 1, 0, models.Location{}, models.VisibilityPrivate, adminID)
+	assert.NilError(t, err)
+	unquotedID, err := app.authors.Insert(ctx, "Anonymous", models.AuthorDetails{}, adminID)
+	assert.NilError(t, err)
+
+	// Check plain users can't reach the edit page or see its link
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+	ts.login(t, userEmail, password)
+	_, _, body := ts.get(t, "/author/view/1")
+	assert.Equal(t, strings.Contains(body, `href="/author/edit/1"`), false)
+	code, _, _ := ts.get(t, "/author/edit/1")
+	assert.Equal(t, code, http.StatusForbidden)
+
+	// Check moderators can reach the edit page but can't merge or delete authors
+	ts = newTestServer(t, app.routes())
+	defer ts.Close()
+	csrfToken := ts.login(t, moderatorEmail, password)
+	_, _, body = ts.get(t, "/author/view/1")
+	assert.StringContains(t, body, `href="/author/edit/1"`)
+	code, _, body = ts.get(t, "/author/edit/1")
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, strings.Contains(body, `action="/author/merge/1"`), false)
+	assert.Equal(t, strings.Contains(body, `action="/author/delete/1"`), false)
+	code, _, _ = ts.postForm(t, "/author/merge/"+strconv.Itoa(duplicateID), url.Values{"into": {"1"}, "csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusForbidden)
+	code, _, _ = ts.postForm(t, "/author/delete/"+strconv.Itoa(unquotedID), url.Values{"csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusForbidden)
+
+	// Log in as an admin
+	ts = newTestServer(t, app.routes())
+	defer ts.Close()
+	csrfToken = ts.login(t, adminEmail, password)
+	_, _, body = ts.get(t, "/author/view/1")
+	assert.StringContains(t, body, `href="/author/edit/1"`)
+	code, _, body = ts.get(t, "/author/edit/1")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, `action="/author/merge/1"`)
+	code, _, _ = ts.get(t, "/author/edit/99")
+	assert.Equal(t, code, http.StatusNotFound)
+
+	// Check the name is validated and can't be taken from another author
+	duplicatePath := strconv.Itoa(duplicateID)
+	code, _, _ = ts.postForm(t, "/author/edit/"+duplicatePath, url.Values{"name": {""}, "csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+	code, _, body = ts.postForm(t, "/author/edit/"+duplicatePath, url.Values{"name": {"William Shakespeare"}, "csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+	assert.StringContains(t, body, "An author with this name already exists")
+
+	// Check renaming an author
+	code, _, _ = ts.postForm(t, "/author/edit/"+duplicatePath, url.Values{"name": {"Will Shakespeare"}, "csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusSeeOther)
+	author, err := app.authors.Get(ctx, duplicateID)
+	assert.NilError(t, err)
+	assert.Equal(t, author.Name, "Will Shakespeare")
+
+	// Check an author with quotes can't be deleted
+	code, _, body = ts.postForm(t, "/author/delete/"+duplicatePath, url.Values{"csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusConflict)
+	assert.StringContains(t, body, "This author still has quotes")
+
+	// Check an author can't be merged into themselves
+	code, _, _ = ts.postForm(t, "/author/merge/"+duplicatePath, url.Values{"into": {duplicatePath}, "csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+	code, _, _ = ts.postForm(t, "/author/merge/"+duplicatePath, url.Values{"into": {"99"}, "csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+
+	// Check merging moves the quote and deletes the duplicate
+	code, header, _ := ts.postForm(t, "/author/merge/"+duplicatePath, url.Values{"into": {"1"}, "csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/author/view/1")
+	quote, err := app.quotes.Get(models.WithViewer(ctx, adminID), quoteID)
+	assert.NilError(t, err)
+	assert.Equal(t, quote.AuthorID, 1)
+	code, _, _ = ts.get(t, "/author/view/"+duplicatePath)
+	assert.Equal(t, code, http.StatusNotFound)
+
+	// Check an author without quotes can be deleted
+	code, _, _ = ts.postForm(t, "/author/delete/"+strconv.Itoa(unquotedID), url.Values{"csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusSeeOther)
+	exists, err := app.authors.Exists(ctx, unquotedID)
+	assert.NilError(t, err)
+	assert.Equal(t, exists, false)
+}
+
+func TestAuthorAliases(t *testing.T) {
+	app := newTestApplication(t)
+	insertUserWithRole(t, app, "Moderator User", "moderator@example.com", "pa$$word", models.RoleModerator)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	// Check only moderators and admins can add aliases
+	csrfToken := ts.login(t, "duplicate@example.com", "pa$$word")
+	code, _, _ := ts.postForm(t, "/author/alias/1", url.Values{"alias": {"The Bard"}, "csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusForbidden)
+
+	ts.postForm(t, "/user/logout", url.Values{"csrf_token": {csrfToken}})
+	csrfToken = ts.login(t, "moderator@example.com", "pa$$word")
+
+	code, header, _ := ts.postForm(t, "/author/alias/1", url.Values{"alias": {" The Bard "}, "csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/author/edit/1")
+
+	// Check the alias is shown and resolves to the author
+	_, _, body := ts.get(t, "/author/view/1")
+	assert.StringContains(t, body, "Also known as The Bard")
+
+	author, err := app.authors.GetByName(context.Background(), "The Bard")
+	assert.NilError(t, err)
+	assert.Equal(t, author.ID, 1)
+
+	_, _, body = ts.get(t, "/search?q=author%3Abard")
+	assert.StringContains(t, body, "To be or not to be")
+
+	// Check a name already in use can't become an alias or a new author
+	tests := []struct {
+		name  string
+		alias string
+	}{
+		{"Blank", " "},
+		{"Same alias", "The Bard"},
+		{"Author's name", "William Shakespeare"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, _ := ts.postForm(t, "/author/alias/1", url.Values{"alias": {tt.alias}, "csrf_token": {csrfToken}})
+			assert.Equal(t, code, http.StatusUnprocessableEntity)
+		})
+	}
+
+	code, _, body = ts.postForm(t, "/author/create", url.Values{"name": {"The Bard"}, "csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+	assert.StringContains(t, body, "An author with this name already exists")
+
+	// Check the alias can be removed, but only once
+	aliases, err := app.authors.GetAliases(context.Background(), 1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(aliases), 1)
+
+	form := url.Values{"alias_id": {strconv.Itoa(aliases[0].ID)}, "csrf_token": {csrfToken}}
+	code, _, _ = ts.postForm(t, "/author/unalias/1", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	code, _, _ = ts.postForm(t, "/author/unalias/1", form)
+	assert.Equal(t, code, http.StatusNotFound)
+
+	_, _, body = ts.get(t, "/author/view/1")
+	assert.Equal(t, strings.Contains(body, "Also known as"), false)
+}
+
+func TestAuthorDetails(t *testing.T) {
+	app := newTestApplication(t)
+	ctx := context.Background()
+	adminID := insertUserWithRole(t, app, "Admin User", "admin@example.com", "pa$$word", models.RoleAdmin)
+
+	// Add an earlier book with two quotes, to come before Hamlet on the timeline
+	bookID, err := app.books.Insert(ctx, "Venus and Adonis", 1593, "A.D.", "9780140714845", "", models.WorkDetails{MediaType: models.MediaBook}, []models.BookContributor{{AuthorID: 1, Role: models.ContributorAuthor}}, adminID)
+	assert.NilError(t, err)
+	for _, quote := range []string{"Love comforteth like sunshine after rain.", "Love is a spirit all compact of fire."} {
+		_, err = app.quotes.Insert(ctx, quote, 1, bookID, 0, models.Location{}, models.VisibilityPublic, adminID)
+		assert.NilError(t, err)
+	}
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+	csrfToken := ts.login(t, "admin@example.com", "pa$$word")
+
+	// Check invalid details are refused, and the form shows them again
+	tests := []struct {
+		name   string
+		field  string
+		value  string
+		errMsg string
+	}{
+		{"Year out of range", "birth_year", "3001", "This field must be between 1 and 3000"},
+		{"Unknown calendar time", "birth_calendar_time", "C.E.", "This field must be either A.D. or B.C."},
+		{"Death before birth", "death_year", "1500", "The year of death cannot be before the year of birth"},
+		{"Link without a scheme", "links", "en.wikipedia.org/wiki/William_Shakespeare", "Each link must be a web address"},
+		{"Unsafe link", "links", "javascript:alert(1)", "Each link must be a web address"},
+		{"Repeated link", "links", "https://example.com\nhttps://example.com", "Each link can only be given once"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{
+				"name":                {"William Shakespeare"},
+				"birth_year":          {"1564"},
+				"birth_calendar_time": {"A.D."},
+				"csrf_token":          {csrfToken},
+			}
+			form.Set(tt.field, tt.value)
+
+			code, _, body := ts.postForm(t, "/author/edit/1", form)
+			assert.Equal(t, code, http.StatusUnprocessableEntity)
+			assert.StringContains(t, body, tt.errMsg)
+		})
+	}
+
+	// Check valid details are saved, with blank lines between the links dropped
+	form := url.Values{
+		"name":                {"William Shakespeare"},
+		"bio":                 {"English playwright and poet."},
+		"birth_year":          {"1564"},
+		"birth_calendar_time": {"A.D."},
+		"death_year":          {"1616"},
+		"death_calendar_time": {"A.D."},
+		"nationality":         {"English"},
+		"occupation":          {"Playwright"},
+		"links":               {"https://en.wikipedia.org/wiki/William_Shakespeare\r\n\r\nhttps://www.folger.edu/"},
+		"csrf_token":          {csrfToken},
+	}
+	code, header, _ := ts.postForm(t, "/author/edit/1", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/author/view/1")
+
+	author, err := app.authors.Get(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, author.Lifespan(), "1564 A.D. – 1616 A.D.")
+	assert.Equal(t, len(author.Links), 2)
+
+	// Check the author page shows the details and lists the books in the order they were published
+	_, _, body := ts.get(t, "/author/view/1")
+	assert.StringContains(t, body, "English playwright and poet.")
+	assert.StringContains(t, body, "1564 A.D. – 1616 A.D.")
+	assert.StringContains(t, body, "English · Playwright")
+	assert.StringContains(t, body, `href="https://www.folger.edu/"`)
+	assert.Equal(t, strings.Index(body, "Venus and Adonis") < strings.Index(body, "Hamlet"), true)
+	assert.StringContains(t, body, "2 quotes")
+	assert.StringContains(t, body, "1 quote<")
+
+	// Check the edit page is filled in with the saved details
+	_, _, body = ts.get(t, "/author/edit/1")
+	assert.StringContains(t, body, `value="1616"`)
+	assert.StringContains(t, body, "English playwright and poet.")
+
+	// Check a new author can be created with B.C. years
+	form = url.Values{
+		"name":                {"Sophocles"},
+		"birth_year":          {"497"},
+		"birth_calendar_time": {"B.C."},
+		"death_year":          {"406"},
+		"death_calendar_time": {"B.C."},
+		"csrf_token":          {csrfToken},
+	}
+	code, header, _ = ts.postForm(t, "/author/create", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+
+	_, _, body = ts.get(t, header.Get("Location"))
+	assert.StringContains(t, body, "497 B.C. – 406 B.C.")
+}
+
+func TestBookContributors(t *testing.T) {
+	app := newTestApplication(t)
+	ctx := context.Background()
+	adminID := insertUserWithRole(t, app, "Admin User", "admin@example.com", "pa$$word", models.RoleAdmin)
+	translatorID, err := app.authors.Insert(ctx, "Jane Translator", models.AuthorDetails{}, adminID)
+	assert.NilError(t, err)
+	translatorPath := strconv.Itoa(translatorID)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+	csrfToken := ts.login(t, "admin@example.com", "pa$$word")
+
+	// Check the form offers the authors and roles
+	_, _, body := ts.get(t, "/book/create")
+	assert.StringContains(t, body, `<option value="1" >William Shakespeare</option>`)
+	assert.StringContains(t, body, `<option value="translator" >Translator</option>`)
+
+	bookForm := func(authors, roles []string) url.Values {
+		return url.Values{
+			"title":              {"The Tempest"},
+			"publish_year":       {"1611"},
+			"calendar_time":      {"A.D."},
+			"isbn":               {"9780743482837"},
+			"contributor_author": authors,
+			"contributor_role":   roles,
+			"csrf_token":         {csrfToken},
+		}
+	}
+
+	// Check invalid contributors are refused
+	tests := []struct {
+		name    string
+		authors []string
+		roles   []string
+		errMsg  string
+	}{
+		{"Unknown role", []string{"1"}, []string{"narrator"}, "Each contributor&#39;s role must be author, editor, translator or illustrator"},
+		{"Missing author", []string{"99"}, []string{"author"}, "Each contributor must be an existing author"},
+		{"Same role twice", []string{"1", "1"}, []string{"author", "author"}, "An author can only be credited once in each role"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.postForm(t, "/book/create", bookForm(tt.authors, tt.roles))
+			assert.Equal(t, code, http.StatusUnprocessableEntity)
+			assert.StringContains(t, body, tt.errMsg)
+		})
+	}
+
+	// Check the translator is credited after the author, skipping the blank row
+	code, header, _ := ts.postForm(t, "/book/create", bookForm([]string{translatorPath, "", "1"}, []string{"translator", "author", "author"}))
+	assert.Equal(t, code, http.StatusSeeOther)
+	id, err := strconv.Atoi(strings.TrimPrefix(header.Get("Location"), "/book/view/"))
+	assert.NilError(t, err)
+	book, err := app.books.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, book.Author.Name, "William Shakespeare")
+	assert.Equal(t, len(book.Contributors), 2)
+	assert.Equal(t, book.Contributors[0].Role, models.ContributorTranslator)
+
+	_, _, body = ts.get(t, header.Get("Location"))
+	assert.StringContains(t, body, "Jane Translator</a> (Translator)")
+
+	// Check the book is on the translator's page along with their role
+	_, _, body = ts.get(t, "/author/view/"+translatorPath)
+	assert.StringContains(t, body, "The Tempest")
+	assert.StringContains(t, body, ">Translator</span>")
+
+	// Check a credited author can't be deleted
+	code, _, body = ts.postForm(t, "/author/delete/"+translatorPath, url.Values{"csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusConflict)
+	assert.StringContains(t, body, "This author is still credited on books")
+
+	// Check editing replaces the contributors
+	form := bookForm([]string{translatorPath}, []string{"editor"})
+	code, _, _ = ts.postForm(t, "/book/edit/"+strconv.Itoa(id), form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	book, err = app.books.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, book.Author.Name, "Unknown")
+	assert.Equal(t, len(book.Contributors), 1)
+	assert.Equal(t, book.Contributors[0].Role, models.ContributorEditor)
+}
+
+func TestBookMediaTypes(t *testing.T) {
+	app := newTestApplication(t)
+	ctx := context.Background()
+	insertUserWithRole(t, app, "Admin User", "admin@example.com", "pa$$word", models.RoleAdmin)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+	csrfToken := ts.login(t, "admin@example.com", "pa$$word")
+
+	// Check the form swaps in the fields of the media type asked for
+	_, _, body := ts.get(t, "/book/create")
+	assert.StringContains(t, body, `name="isbn"`)
+	_, _, body = ts.get(t, "/book/create?media_type=paper")
+	assert.StringContains(t, body, `name="doi"`)
+	assert.StringContains(t, body, `<option value="paper" selected>Paper</option>`)
+
+	workForm := func(mediaType string, extra url.Values) url.Values {
+		form := url.Values{
+			"title":         {"On the Shortness of Life"},
+			"calendar_time": {"A.D."},
+			"media_type":    {mediaType},
+			"csrf_token":    {csrfToken},
+		}
+		for key, values := range extra {
+			form[key] = values
+		}
+		return form
+	}
+
+	// Check each media type's own fields are required
+	tests := []struct {
+		name      string
+		mediaType string
+		extra     url.Values
+		errMsg    string
+	}{
+		{"Book without an ISBN", "book", url.Values{"publish_year": {"49"}}, "The ISBN field cannot be blank"},
+		{"Paper with an invalid DOI", "paper", url.Values{"publish_year": {"2020"}, "doi": {"doi:1234"}}, "This field must be a valid DOI"},
+		{"Speech without a venue", "speech", url.Values{"work_date": {"1963-08-28"}}, "The venue field cannot be blank"},
+		{"Podcast without a URL", "podcast", url.Values{"publish_year": {"2023"}}, "The URL cannot be blank"},
+		{"Video with an invalid date", "video", url.Values{"source": {"https://example.com/v"}, "work_date": {"last week"}}, "This field must be a date"},
+		{"Unknown media type", "opera", url.Values{"publish_year": {"1607"}}, "This field must be a known media type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.postForm(t, "/book/create", workForm(tt.mediaType, tt.extra))
+			assert.Equal(t, code, http.StatusUnprocessableEntity)
+			assert.StringContains(t, body, tt.errMsg)
+		})
+	}
+
+	// Check a podcast takes its year from its release date and drops the fields it doesn't use
+	code, header, _ := ts.postForm(t, "/book/create", workForm("podcast", url.Values{
+		"source":    {"https://example.com/episodes/12"},
+		"work_date": {"2023-05-01"},
+		"isbn":      {"9780143036326"},
+	}))
+	assert.Equal(t, code, http.StatusSeeOther)
+	id, err := strconv.Atoi(strings.TrimPrefix(header.Get("Location"), "/book/view/"))
+	assert.NilError(t, err)
+	book, err := app.books.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, book.MediaType, models.MediaPodcast)
+	assert.Equal(t, book.PublishYear, 2023)
+	assert.Equal(t, book.ISBN, "")
+
+	_, _, body = ts.get(t, header.Get("Location"))
+	assert.StringContains(t, body, "Podcast")
+	assert.StringContains(t, body, "2023-05-01")
+
+	// Check editing can change the media type
+	code, _, _ = ts.postForm(t, "/book/edit/"+strconv.Itoa(id), workForm("speech", url.Values{
+		"venue":     {"Lincoln Memorial"},
+		"work_date": {"1963-08-28"},
+	}))
+	assert.Equal(t, code, http.StatusSeeOther)
+	book, err = app.books.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, book.WorkDetails, models.WorkDetails{MediaType: models.MediaSpeech, Venue: "Lincoln Memorial", Date: "1963-08-28"})
+	assert.Equal(t, book.PublishYear, 1963)
+}
+
+func TestBookISBNs(t *testing.T) {
+	app := newTestApplication(t)
+	ctx := context.Background()
+	insertUserWithRole(t, app, "Admin User", "admin@example.com", "pa$$word", models.RoleAdmin)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+	csrfToken := ts.login(t, "admin@example.com", "pa$$word")
+
+	bookForm := func(title, isbn string) url.Values {
+		return url.Values{
+			"title":         {title},
+			"publish_year":  {"1980"},
+			"calendar_time": {"A.D."},
+			"media_type":    {"book"},
+			"isbn":          {isbn},
+			"confirm_new":   {"true"},
+			"csrf_token":    {csrfToken},
+		}
+	}
+
+	// Check ISBN-10s and ISBN-13s are accepted with hyphens or spaces and stored as ISBN-13s
+	tests := []struct {
+		name     string
+		isbn     string
+		wantISBN string
+		errMsg   string
+	}{
+		{name: "ISBN-13 with hyphens", isbn: "978-0-306-40615-7", wantISBN: "9780306406157"},
+		{name: "ISBN-10 with spaces", isbn: " 0 14 044210 3 ", wantISBN: "9780140442106"},
+		{name: "ISBN-10 ending in X", isbn: "0-8044-2957-X", wantISBN: "9780804429573"},
+		{name: "ISBN-10 with a lowercase x", isbn: "155404295x", wantISBN: "9781554042951"},
+		{name: "Wrong ISBN-13 check digit", isbn: "978-0-306-40615-8", errMsg: "This field must be a valid ISBN-10 or ISBN-13"},
+		{name: "Wrong ISBN-10 check digit", isbn: "0-306-40615-3", errMsg: "This field must be a valid ISBN-10 or ISBN-13"},
+		{name: "X inside an ISBN-10", isbn: "08044X9573", errMsg: "This field must be a valid ISBN-10 or ISBN-13"},
+		{name: "ISBN-13 without a book prefix", isbn: "1234567890128", errMsg: "This field must be a valid ISBN-10 or ISBN-13"},
+		{name: "Too short", isbn: "978030640615", errMsg: "This field must be a valid ISBN-10 or ISBN-13"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, header, body := ts.postForm(t, "/book/create", bookForm(tt.name, tt.isbn))
+			if tt.errMsg != "" {
+				assert.Equal(t, code, http.StatusUnprocessableEntity)
+				assert.StringContains(t, body, tt.errMsg)
+				return
+			}
+			assert.Equal(t, code, http.StatusSeeOther)
+			id, err := strconv.Atoi(strings.TrimPrefix(header.Get("Location"), "/book/view/"))
+			assert.NilError(t, err)
+			book, err := app.books.Get(ctx, id)
+			assert.NilError(t, err)
+			assert.Equal(t, book.ISBN, tt.wantISBN)
+		})
+	}
+
+	// Check an ISBN-10 is the same edition as its ISBN-13, whether adding or editing a book
+	code, _, body := ts.postForm(t, "/book/create", bookForm("The Art of Computer Programming", "0306406152"))
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+	assert.StringContains(t, body, "A book with this ISBN has already been added")
+
+	code, _, body = ts.postForm(t, "/book/edit/1", bookForm("Hamlet", "0-306-40615-2"))
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+	assert.StringContains(t, body, "Another book with this ISBN has already been added")
+}
+
+func TestBookLookup(t *testing.T) {
+	// Stub the Open Library books API with one book, counting the lookups made
+	lookups := 0
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("bibkeys") != "ISBN:9780743477109" {
+			w.Write([]byte(`{}`))
+			return
+		}
+		w.Write([]byte(`{"ISBN:9780743477109": {
+			"url": "https://openlibrary.org/books/OL3952436M/Macbeth",
+			"title": "Macbeth",
+			"authors": [{"name": "William Shakespeare"}, {"name": "Barbara A. Mowat"}],
+			"publish_date": "2003",
+			"number_of_pages": 272
+		}}`))
+	}))
+	defer stub.Close()
+
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+	ts.login(t, "duplicate@example.com", "pa$$word")
+
+	// Check the lookup is only offered when it is turned on
+	code, _, _ := ts.get(t, "/book/lookup?isbn=9780743477109")
+	assert.Equal(t, code, http.StatusNotFound)
+	_, _, body := ts.get(t, "/book/create")
+	assert.Equal(t, strings.Contains(body, `hx-get="/book/lookup"`), false)
+
+	app.isbnLookup = lookup.NewCache(lookup.NewOpenLibrary(stub.URL, time.Second), time.Hour, 100)
+	_, _, body = ts.get(t, "/book/create")
+	assert.StringContains(t, body, `hx-get="/book/lookup"`)
+
+	// Check a found book fills in the form, crediting the authors already added
+	code, _, body = ts.get(t, "/book/lookup?isbn=0-7434-7710-3")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, `id="book-form"`)
+	assert.StringContains(t, body, `name="isbn" placeholder="978-0-14-044933-4" value="9780743477109"`)
+	assert.StringContains(t, body, `name="title" value="Macbeth"`)
+	assert.StringContains(t, body, `value="2003"`)
+	assert.StringContains(t, body, `value="272"`)
+	assert.StringContains(t, body, `value="https://openlibrary.org/books/OL3952436M/Macbeth"`)
+	assert.StringContains(t, body, `<option value="1" selected>William Shakespeare</option>`)
+	assert.StringContains(t, body, "Barbara A. Mowat")
+
+	// Check lookups are cached
+	ts.get(t, "/book/lookup?isbn=9780743477109")
+	assert.Equal(t, lookups, 1)
+
+	// Check invalid and unknown ISBNs are shown on the form
+	tests := []struct {
+		name   string
+		isbn   string
+		errMsg string
+	}{
+		{"Invalid ISBN", "978-0-7434-7710-8", "This field must be a valid ISBN-10 or ISBN-13"},
+		{"Unknown ISBN", "9780306406157", "No book was found with this ISBN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.get(t, "/book/lookup?isbn="+url.QueryEscape(tt.isbn))
+			assert.Equal(t, code, http.StatusOK)
+			assert.StringContains(t, body, tt.errMsg)
+		})
+	}
+
+	// Check a failed lookup leaves the details to be entered by hand
+	stub.Close()
+	_, _, body = ts.get(t, "/book/lookup?isbn=9780140449334")
+	assert.StringContains(t, body, "Books can&#39;t be looked up right now")
+}
+
+func TestQuoteLocations(t *testing.T) {
+	app := newTestApplication(t)
+	ctx := context.Background()
+	owner, err := app.users.GetByEmail(ctx, "duplicate@example.com")
+	assert.NilError(t, err)
+	bookID, err := app.books.Insert(ctx, "Macbeth", 1623, "A.D.", "9780743477109", "", models.WorkDetails{MediaType: models.MediaBook, PageCount: 120}, []models.BookContributor{{AuthorID: 1, Role: models.ContributorAuthor}}, owner.ID)
+	assert.NilError(t, err)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+	csrfToken := ts.login(t, "duplicate@example.com", "pa$$word")
+
+	quoteForm := func(text, locationType, location string) url.Values {
+		return url.Values{
+			"quote":           {text},
+			"author-selector": {"1"},
+			"book-selector":   {strconv.Itoa(bookID)},
+			"location_type":   {locationType},
+			"location":        {location},
+			"visibility":      {"public"},
+			"csrf_token":      {csrfToken},
+		}
+	}
+
+	// Check invalid locations and pages past the end of the book are rejected
+	tests := []struct {
+		name         string
+		locationType string
+		location     string
+		errMsg       string
+	}{
+		{"Page past the end", "page", "121", "This work only has 120 pages"},
+		{"Range past the end", "page", "118-125", "This work only has 120 pages"},
+		{"Backwards range", "page", "15-12", "A range of pages cannot end before it starts"},
+		{"Percentage over 100", "percent", "150", "A percentage must be a whole number from 0 to 100"},
+		{"Invalid timestamp", "timestamp", "four minutes", "A timestamp must be given as minutes and seconds"},
+		{"Unknown type", "line", "12", "The location must be a page"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.postForm(t, "/quote/create", quoteForm("Out, damned spot!", tt.locationType, tt.location))
+			assert.Equal(t, code, http.StatusUnprocessableEntity)
+			assert.StringContains(t, body, tt.errMsg)
+		})
+	}
+
+	// Check locations are saved and shown, and the book lists its quotes in the order they appear
+	for _, q := range []struct{ text, locationType, location string }{
+		{"Out, damned spot!", "chapter", "Act 5"},
+		{"Fair is foul, and foul is fair.", "page", "12 - 15"},
+		{"Something wicked this way comes.", "page", "80"},
+	} {
+		code, _, _ := ts.postForm(t, "/quote/create", quoteForm(q.text, q.locationType, q.location))
+		assert.Equal(t, code, http.StatusSeeOther)
+	}
+
+	_, _, body := ts.get(t, "/book/view/"+strconv.Itoa(bookID))
+	assert.StringContains(t, body, "pp. 12–15")
+	assert.StringContains(t, body, "Pages:</span> 120")
+	fair := strings.Index(body, "Fair is foul")
+	wicked := strings.Index(body, "Something wicked")
+	spot := strings.Index(body, "Out, damned spot!")
+	assert.Equal(t, fair < wicked && wicked < spot, true)
+}
+
+// Tests editions can be added to a book and quotes taken from them
+func TestEditions(t *testing.T) {
+	app := newTestApplication(t)
+	ctx := context.Background()
+	insertUserWithRole(t, app, "Other User", "other@example.com", "pa$$word", models.RoleUser)
+	insertUserWithRole(t, app, "Moderator User", "moderator@example.com", "pa$$word", models.RoleModerator)
+	translatorID, err := app.authors.Insert(ctx, "August Wilhelm Schlegel", models.AuthorDetails{}, uuid.Nil)
+	assert.NilError(t, err)
+	owner, err := app.users.GetByEmail(ctx, "duplicate@example.com")
+	assert.NilError(t, err)
+	macbethID, err := app.books.Insert(ctx, "Macbeth", 1623, "A.D.", "9780743477109", "", models.WorkDetails{MediaType: models.MediaBook}, []models.BookContributor{{AuthorID: 1, Role: models.ContributorAuthor}}, owner.ID)
+	assert.NilError(t, err)
+	macbethEditionID, err := app.editions.Insert(ctx, macbethID, models.EditionDetails{Language: "German"}, owner.ID)
+	assert.NilError(t, err)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+	csrfToken := ts.login(t, "duplicate@example.com", "pa$$word")
+
+	editionForm := func(isbn string) url.Values {
+		return url.Values{
+			"isbn":          {isbn},
+			"publisher":     {"Reclam"},
+			"language":      {"German"},
+			"translator_id": {strconv.Itoa(translatorID)},
+			"publish_year":  {"1986"},
+			"csrf_token":    {csrfToken},
+		}
+	}
+
+	// Check a translation of Hamlet can be added
+	code, header, _ := ts.postForm(t, "/book/edition/1", editionForm("978-3-15-000031-1"))
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/book/view/1")
+
+	edition, err := app.editions.GetByISBN(ctx, "9783150000311")
+	assert.NilError(t, err)
+	assert.Equal(t, edition.BookID, 1)
+	assert.Equal(t, edition.TranslatorID, translatorID)
+
+	// Check invalid editions and ISBNs already taken are rejected
+	tests := []struct {
+		name   string
+		form   url.Values
+		errMsg string
+	}{
+		{"No details", url.Values{"csrf_token": {csrfToken}}, "Give at least one of the ISBN"},
+		{"Another edition's ISBN", editionForm("9783150000311"), "An edition with this ISBN has already been added"},
+		{"The book's ISBN", editionForm("9780743477123"), "A book with this ISBN has already been added"},
+		{"Unknown translator", url.Values{"language": {"German"}, "translator_id": {"999"}, "csrf_token": {csrfToken}}, "The translator must be an existing author"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.postForm(t, "/book/edition/1", tt.form)
+			assert.Equal(t, code, http.StatusUnprocessableEntity)
+			assert.StringContains(t, body, tt.errMsg)
+		})
+	}
+
+	// Check a book can't be added with an edition's ISBN
+	code, _, body := ts.postForm(t, "/book/edit/1", url.Values{
+		"title":         {"Hamlet"},
+		"publish_year":  {"1603"},
+		"calendar_time": {"A.D."},
+		"media_type":    {"book"},
+		"isbn":          {"9783150000311"},
+		"csrf_token":    {csrfToken},
+	})
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+	assert.StringContains(t, body, "An edition with this ISBN has already been added")
+
+	// Check a quote can only be taken from one of its book's editions
+	quoteForm := func(text string, editionID int) url.Values {
+		return url.Values{
+			"quote":            {text},
+			"author-selector":  {"1"},
+			"book-selector":    {"1"},
+			"edition-selector": {strconv.Itoa(editionID)},
+			"location_type":    {"page"},
+			"location":         {"12"},
+			"visibility":       {"public"},
+			"csrf_token":       {csrfToken},
+		}
+	}
+
+	code, _, body = ts.postForm(t, "/quote/create", quoteForm("Sein oder Nichtsein", macbethEditionID))
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+	assert.StringContains(t, body, "The edition must be one of the selected book&#39;s")
+
+	code, header, _ = ts.postForm(t, "/quote/create", quoteForm("Sein oder Nichtsein, das ist hier die Frage.", edition.ID))
+	assert.Equal(t, code, http.StatusSeeOther)
+	id, err := strconv.Atoi(strings.TrimPrefix(header.Get("Location"), "/quote/view/"))
+	assert.NilError(t, err)
+	quote, err := app.quotes.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, quote.EditionID, edition.ID)
+
+	_, _, body = ts.get(t, fmt.Sprintf("/quote/view/%d", id))
+	assert.StringContains(t, body, "Reclam, 1986, German, translated by August Wilhelm Schlegel")
+
+	// Check the book shows the quotes from every edition, or only those from the one chosen
+	_, _, body = ts.get(t, "/book/view/1")
+	assert.StringContains(t, body, "To be or not to be")
+	assert.StringContains(t, body, "Sein oder Nichtsein")
+	assert.StringContains(t, body, "Edition: Reclam, 1986, German, translated by August Wilhelm Schlegel")
+
+	_, _, body = ts.get(t, fmt.Sprintf("/book/view/1?edition=%d", edition.ID))
+	assert.StringContains(t, body, "Quotes from this Edition")
+	assert.StringContains(t, body, "Sein oder Nichtsein")
+	assert.Equal(t, strings.Contains(body, "To be or not to be"), false)
+
+	_, _, body = ts.get(t, fmt.Sprintf("/book/view/1?edition=%d", macbethEditionID))
+	assert.StringContains(t, body, "To be or not to be")
+	assert.StringContains(t, body, "Sein oder Nichtsein")
+
+	// Check only the user who added the edition can change it
+	other := newTestServer(t, app.routes())
+	defer other.Close()
+	otherToken := other.login(t, "other@example.com", "pa$$word")
+
+	editLink := fmt.Sprintf(`href="/edition/edit/%d"`, edition.ID)
+	_, _, body = other.get(t, "/book/view/1")
+	assert.Equal(t, strings.Contains(body, editLink), false)
+	code, _, _ = other.get(t, fmt.Sprintf("/edition/edit/%d", edition.ID))
+	assert.Equal(t, code, http.StatusForbidden)
+	code, _, _ = other.postForm(t, fmt.Sprintf("/edition/delete/%d", edition.ID), url.Values{"csrf_token": {otherToken}})
+	assert.Equal(t, code, http.StatusForbidden)
+
+	// Check a moderator is offered the edit link as well as being allowed to edit
+	moderator := newTestServer(t, app.routes())
+	defer moderator.Close()
+	moderator.login(t, "moderator@example.com", "pa$$word")
+
+	_, _, body = moderator.get(t, "/book/view/1")
+	assert.StringContains(t, body, editLink)
+	code, _, _ = moderator.get(t, fmt.Sprintf("/edition/edit/%d", edition.ID))
+	assert.Equal(t, code, http.StatusOK)
+
+	_, _, body = ts.get(t, "/book/view/1")
+	assert.StringContains(t, body, editLink)
+
+	code, _, _ = ts.get(t, fmt.Sprintf("/edition/edit/%d", edition.ID))
+	assert.Equal(t, code, http.StatusOK)
+	code, _, _ = ts.postForm(t, fmt.Sprintf("/edition/delete/%d", edition.ID), url.Values{"csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusSeeOther)
+
+	// Check deleting the edition leaves its quote with the book
+	quote, err = app.quotes.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, quote.EditionID, 0)
+	assert.Equal(t, quote.BookID, 1)
+}
+
+func TestQuoteTags(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+	csrfToken := ts.login(t, "duplicate@example.com", "pa$$word")
+
+	quoteForm := func(tags string) url.Values {
+		return url.Values{
+			"quote":           {"The rest is silence."},
+			"author-selector": {"1"},
+			"book-selector":   {"1"},
+			"tags":            {tags},
+			"visibility":      {"public"},
+			"csrf_token":      {csrfToken},
+		}
+	}
+
+	// Check invalid tags are rejected
+	code, _, body := ts.postForm(t, "/quote/create", quoteForm("death, last-words!"))
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+	assert.StringContains(t, body, "A tag can only contain letters, numbers and hyphens between words")
+
+	// Check tags are stored lowercase once each, shown on the quote and found by a search
+	code, header, _ := ts.postForm(t, "/quote/create", quoteForm(" Death,last-words death "))
+	assert.Equal(t, code, http.StatusSeeOther)
+	id, err := strconv.Atoi(strings.TrimPrefix(header.Get("Location"), "/quote/view/"))
+	assert.NilError(t, err)
+
+	_, _, body = ts.get(t, fmt.Sprintf("/quote/view/%d", id))
+	assert.StringContains(t, body, "#death")
+	assert.StringContains(t, body, "#last-words")
+
+	_, _, body = ts.get(t, fmt.Sprintf("/quote/edit/%d", id))
+	assert.StringContains(t, body, `value="death, last-words"`)
+
+	_, _, body = ts.get(t, "/search?q=tag%3Alast-words")
+	assert.StringContains(t, body, "The rest is silence.")
+
+	// Check editing the quote replaces its tags
+	code, _, _ = ts.postForm(t, fmt.Sprintf("/quote/edit/%d", id), quoteForm("silence"))
+	assert.Equal(t, code, http.StatusSeeOther)
+	tags, err := app.quotes.GetTags(context.Background(), id)
+	assert.NilError(t, err)
+	assert.Equal(t, fmt.Sprint(tags), "[silence]")
+}
+
+func TestDuplicateSuggestions(t *testing.T) {
+	app := newTestApplication(t)
+	insertUserWithRole(t, app, "Admin User", "admin@example.com", "pa$$word", models.RoleAdmin)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	csrfToken := ts.login(t, "admin@example.com", "pa$$word")
+
+	// Counts the authors and books in the database
+	counts := func() (int, int) {
+		authors, err := app.authors.GetAll(context.Background())
+		assert.NilError(t, err)
+		books, err := app.books.GetAll(context.Background())
+		assert.NilError(t, err)
+		return len(authors), len(books)
+	}
+
+	quoteForm := func(extra url.Values) url.Values {
+		form := url.Values{
+			"quote":                  {"Brevity is the soul of wit."},
+			"new_author_name":        {"william shakspeare"},
+			"new_book_title":         {"The Hamlet"},
+			"new_book_publish_year":  {"1603"},
+			"new_book_calendar_time": {"A.D."},
+			"new_book_isbn":          {"9780141396507"},
+			"csrf_token":             {csrfToken},
+		}
+		for key, values := range extra {
+			form[key] = values
+		}
+		return form
+	}
+
+	t.Run("Quote form suggests existing records", func(t *testing.T) {
+		code, _, body := ts.postForm(t, "/quote/create", quoteForm(nil))
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+		assert.StringContains(t, body, "Did you mean")
+		assert.StringContains(t, body, `name="author_choice" value="1"> William Shakespeare`)
+		assert.StringContains(t, body, `name="book_choice" value="1"> Hamlet`)
+
+		authors, books := counts()
+		assert.Equal(t, authors, 1)
+		assert.Equal(t, books, 1)
+	})
+
+	t.Run("Quote form uses the chosen suggestions", func(t *testing.T) {
+		code, header, _ := ts.postForm(t, "/quote/create", quoteForm(url.Values{"author_choice": {"1"}, "book_choice": {"1"}}))
+		assert.Equal(t, code, http.StatusSeeOther)
+
+		id, err := strconv.Atoi(strings.TrimPrefix(header.Get("Location"), "/quote/view/"))
+		assert.NilError(t, err)
+		quote, err := app.quotes.Get(context.Background(), id)
+		assert.NilError(t, err)
+		assert.Equal(t, quote.AuthorID, 1)
+		assert.Equal(t, quote.BookID, 1)
+
+		authors, books := counts()
+		assert.Equal(t, authors, 1)
+		assert.Equal(t, books, 1)
+	})
+
+	t.Run("Quote form adds new records when confirmed", func(t *testing.T) {
+		code, _, _ := ts.postForm(t, "/quote/create", quoteForm(url.Values{"author_choice": {"new"}, "book_choice": {"new"}}))
+		assert.Equal(t, code, http.StatusSeeOther)
+
+		authors, books := counts()
+		assert.Equal(t, authors, 2)
+		assert.Equal(t, books, 2)
+	})
+
+	t.Run("Quote form adds nothing when the new book's ISBN is taken", func(t *testing.T) {
+		form := quoteForm(url.Values{"new_author_name": {"Thomas Kyd"}, "author_choice": {"new"}, "book_choice": {"new"}})
+		code, _, body := ts.postForm(t, "/quote/create", form)
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+		assert.StringContains(t, body, "A book with this ISBN has already been added")
+
+		authors, books := counts()
+		assert.Equal(t, authors, 2)
+		assert.Equal(t, books, 2)
+	})
+
+	t.Run("Quote form rejects choices that weren't added", func(t *testing.T) {
+		code, _, body := ts.postForm(t, "/quote/create", quoteForm(url.Values{"author_choice": {"99"}, "book_choice": {"99"}}))
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+		assert.StringContains(t, body, "Invalid author selection")
+		assert.StringContains(t, body, "Invalid book selection")
+
+		code, _, body = ts.postForm(t, "/quote/create", url.Values{"quote": {"Brevity is the soul of wit."}, "author-selector": {"99"}, "book-selector": {"1"}, "csrf_token": {csrfToken}})
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+		assert.StringContains(t, body, "Invalid author selection")
+	})
+
+	t.Run("Book form suggests existing books", func(t *testing.T) {
+		form := url.Values{
+			"title":         {"Macbeth"},
+			"publish_year":  {"1623"},
+			"calendar_time": {"A.D."},
+			"isbn":          {"9780743477123"},
+			"csrf_token":    {csrfToken},
+		}
+		code, _, body := ts.postForm(t, "/book/create", form)
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+		assert.StringContains(t, body, `<a href="/book/view/1" class="underline">Hamlet</a>`)
+
+		// Confirming can't add a second book with the same ISBN
+		form.Set("confirm_new", "true")
+		code, _, body = ts.postForm(t, "/book/create", form)
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+		assert.StringContains(t, body, "A book with this ISBN has already been added")
+
+		form.Set("isbn", "0-7434-7710-3")
+		code, _, _ = ts.postForm(t, "/book/create", form)
+		assert.Equal(t, code, http.StatusSeeOther)
+	})
+
+	t.Run("Author form suggests existing authors", func(t *testing.T) {
+		form := url.Values{"name": {"Shakespeare"}, "csrf_token": {csrfToken}}
+		code, _, body := ts.postForm(t, "/author/create", form)
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+		assert.StringContains(t, body, `<a href="/author/view/1" class="underline">William Shakespeare</a>`)
+
+		form.Set("confirm_new", "true")
+		code, _, _ = ts.postForm(t, "/author/create", form)
+		assert.Equal(t, code, http.StatusSeeOther)
+	})
+}
+
+func TestPrivateQuotes(t *testing.T) {
+	const (
+		ownerEmail = "duplicate@example.com"
+		otherEmail = "other@example.com"
+		password   = "pa$$word"
+		text       = "Something is rotten in the state of Denmark."
+	)
+
+	tests := []struct {
+		name     string
+		email    string
+		wantCode int
+	}{
+		{"Anonymous", "", http.StatusNotFound},
+		{"Another user", otherEmail, http.StatusNotFound},
+		{"Owner", ownerEmail, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Add a private quote for the owner of the test data, and another user
+			app := newTestApplication(t)
+			owner, err := app.users.GetByEmail(context.Background(), ownerEmail)
+			assert.NilError(t, err)
+			id, err := app.quotes.Insert(context.Background(), text, 1, 1, 0, models.Location{}, models.VisibilityPrivate, owner.ID)
+			assert.NilError(t, err)
+			_, err = app.users.Insert(context.Background(), "Other User", otherEmail, password)
+			assert.NilError(t, err)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			if tt.email != "" {
+				ts.login(t, tt.email, password)
+			}
+
+			// Check the quote can only be viewed by its owner
+			code, _, _ := ts.get(t, "/quote/view/"+strconv.Itoa(id))
+			assert.Equal(t, code, tt.wantCode)
+
+			// Check the quote is left out of the listings for everyone else
+			visible := tt.wantCode == http.StatusOK
+			for _, path := range []string{"/", "/book/view/1"} {
+				_, _, body := ts.get(t, path)
+				assert.Equal(t, strings.Contains(body, text), visible)
+			}
+		})
+	}
+}
+
+func TestShareLinks(t *testing.T) {
+	const (
+		email    = "duplicate@example.com"
+		password = "pa$$word"
+	)
+
+	app := newTestApplication(t)
+	owner, err := app.users.GetByEmail(context.Background(), email)
+	assert.NilError(t, err)
+	ownerCtx := models.WithViewer(context.Background(), owner.ID)
+	editionID, err := app.editions.Insert(context.Background(), 1, models.EditionDetails{Publisher: "Folio Society"}, owner.ID)
+	assert.NilError(t, err)
+
+	// Log in as the owner on one server, and read anonymously from another
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+	csrfToken := ts.login(t, email, password)
+	anonymous := newTestServer(t, app.routes())
+	defer anonymous.Close()
+
+	// Check a quote can be created unlisted, but not with an unknown visibility
+	form := url.Values{
+		"quote":            {"Brevity is the soul of wit."},
+		"author-selector":  {"1"},
+		"book-selector":    {"1"},
+		"edition-selector": {strconv.Itoa(editionID)},
+		"tags":             {"wit"},
+		"visibility":       {"secret"},
+		"csrf_token":      {csrfToken},
+	}
+	code, _, _ := ts.postForm(t, "/quote/create", form)
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+	form.Set("visibility", "unlisted")
+	code, header, _ := ts.postForm(t, "/quote/create", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	path := header.Get("Location")
+	id, err := strconv.Atoi(strings.TrimPrefix(path, "/quote/view/"))
+	assert.NilError(t, err)
+
+	// Check only the owner can see the unlisted quote before it is shared
+	code, _, _ = ts.get(t, path)
+	assert.Equal(t, code, http.StatusOK)
+	code, _, _ = anonymous.get(t, path)
+	assert.Equal(t, code, http.StatusNotFound)
+
+	// Shares the quote and returns its new share link
+	share := func() string {
+		code, _, _ := ts.postForm(t, "/quote/share/"+strconv.Itoa(id), url.Values{"csrf_token": {csrfToken}})
+		assert.Equal(t, code, http.StatusSeeOther)
+		quote, err := app.quotes.Get(ownerCtx, id)
+		assert.NilError(t, err)
+		return "/q/s/" + quote.ShareToken
+	}
+
+	// Check the share link is shown to the owner and works for anyone
+	link := share()
+	_, _, body := ts.get(t, "/quote/edit/"+strconv.Itoa(id))
+	assert.StringContains(t, body, link)
+	code, _, body = anonymous.get(t, link)
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "Brevity is the soul of wit.")
+
+	// Check the shared quote shows its tags and edition like the quote's own page
+	assert.StringContains(t, body, "#wit")
+	assert.StringContains(t, body, "Folio Society")
+
+	// Check rotating the link stops the old one working
+	rotated := share()
+	code, _, _ = anonymous.get(t, link)
+	assert.Equal(t, code, http.StatusNotFound)
+	code, _, _ = anonymous.get(t, rotated)
+	assert.Equal(t, code, http.StatusOK)
+
+	// Check revoking the link stops it working
+	code, _, _ = ts.postForm(t, "/quote/unshare/"+strconv.Itoa(id), url.Values{"csrf_token": {csrfToken}})
+	assert.Equal(t, code, http.StatusSeeOther)
+	code, _, _ = anonymous.get(t, rotated)
+	assert.Equal(t, code, http.StatusNotFound)
+
+}
+
 // Tests the user signup route
 func TestUserSignup(t *testing.T) {
 	// Create a new test application
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/go-playground/form/v4"
 	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/models"
 	"github.com/justinas/nosurf"
 )
 
@@ -47,17 +49,18 @@ func (app *application) newTemplateData(r *http.Request) templateData {
         CurrentYear:     time.Now().Year(),
         Flash:           app.sessionManager.PopString(r.Context(), "flash"),
         IsAuthenticated: app.isAuthenticated(r),
+        IsModerator:     app.hasRole(r, models.RoleModerator),
+        IsAdmin:         app.hasRole(r, models.RoleAdmin),
         CSRFToken:       nosurf.Token(r),
+        Query:           r.URL.Query(),
+        ISBNLookup:      app.isbnLookup != nil,
     }
 
     if data.IsAuthenticated {
-		userIdStr := app.sessionManager.GetString(r.Context(), "authenticatedUserID")
-		userId, err := uuid.Parse(userIdStr)
-		if err != nil {
-			app.logger.Error("Failed to parse the user ID", "error", err)
-		} else {
+		userId := app.contextGetUserID(r)
+		if userId != uuid.Nil {
 			data.AuthenticatedUserID = userId
-			user, err := app.users.Get(userId)
+			user, err := app.users.Get(r.Context(), userId)
 			if err == nil {
 				data.User = &user
 			} else {
@@ -97,6 +100,30 @@ func (app *application) isAuthenticated(r *http.Request) bool {
 	return app.sessionManager.Exists(r.Context(), "authenticatedUserID")
 }
 
+// Returns true if the current request is from a user with at least the given role
+func (app *application) hasRole(r *http.Request, role models.Role) bool {
+	return app.contextGetRole(r).AtLeast(role)
+}
+
+// Returns true if the current user added the record owned by ownerID, or is a
+// moderator or admin
+func (app *application) canModify(r *http.Request, ownerID uuid.UUID) bool {
+	userID := app.contextGetUserID(r)
+	if userID == uuid.Nil {
+		return false
+	}
+	return userID == ownerID || app.hasRole(r, models.RoleModerator)
+}
+
+// Returns the full share link of a quote, or the empty string if it has none.
+// The server only listens over HTTPS, so links always use it.
+func (app *application) shareURL(r *http.Request, q models.Quote) string {
+	if q.ShareToken == "" {
+		return ""
+	}
+	return "https://" + r.Host + "/q/s/" + q.ShareToken
+}
+
 // Helper function to urlize a string
 func (app *application) urlize(s string) string {
     // Convert to lowercase and replace spaces with hyphens
@@ -183,4 +210,63 @@ func (app *application) readJSON(w http.ResponseWriter, r *http.Request, dst any
 // Converts a string to a UUID
 func (app *application) convertStringToUUID(s string) (uuid.UUID, error) {
 	return uuid.Parse(s)
-}
\ No newline at end of file
+}
+
+// Returns the authors whose names are similar to name, closest first, after
+// any author who has name as an alias
+func (app *application) similarAuthors(ctx context.Context, name string) ([]models.Author, error) {
+	authors, err := app.authors.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	similar := models.SimilarAuthors(name, authors)
+
+	// An alias is as good as a match on the author's own name
+	aliased, err := app.authors.GetByName(ctx, name)
+	if errors.Is(err, models.ErrNoRecord) {
+		return similar, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, a := range similar {
+		if a.ID == aliased.ID {
+			return similar, nil
+		}
+	}
+	return append([]models.Author{aliased}, similar...), nil
+}
+
+// Returns the books whose titles are similar to title or which have the same
+// ISBN, closest first
+func (app *application) similarBooks(ctx context.Context, title, isbn string) ([]models.Book, error) {
+	books, err := app.books.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return models.SimilarBooks(title, isbn, books), nil
+}
+
+// Reports whether a book was added with the ISBN. Books and their editions
+// are kept apart, so an edition can't have the ISBN of a book.
+func (app *application) bookHasISBN(ctx context.Context, isbn string) (bool, error) {
+	if isbn == "" {
+		return false, nil
+	}
+	_, err := app.books.GetByISBN(ctx, isbn)
+	if errors.Is(err, models.ErrNoRecord) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Reports whether an edition has the ISBN, so a book can't be given it
+func (app *application) editionHasISBN(ctx context.Context, isbn string) (bool, error) {
+	if isbn == "" {
+		return false, nil
+	}
+	_, err := app.editions.GetByISBN(ctx, isbn)
+	if errors.Is(err, models.ErrNoRecord) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -1,8 +1,8 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
-	"database/sql"
 	"encoding/gob"
 	"flag"
 	"fmt"
@@ -13,24 +13,38 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/lookup"
 	"github.com/justinbachtell/quote-table-go/internal/models"
 
-	"github.com/alexedwards/scs/postgresstore"
 	"github.com/alexedwards/scs/v2"
 	"github.com/go-playground/form/v4"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
-	"github.com/supabase-community/supabase-go"
+	"github.com/supabase-community/postgrest-go"
 )
 
 // Application version
 const version = "1.0.0"
 
+// The most ISBN lookups kept in the cache
+const isbnLookupCacheSize = 1000
+
 // Define a struct to hold the application configuration
 type config struct {
 	addr string
 	port int
 	env string
+	storage string
+	db struct {
+		dsn          string
+		readTimeout  time.Duration
+		writeTimeout time.Duration
+	}
+	isbnLookup struct {
+		url      string
+		timeout  time.Duration
+		cacheTTL time.Duration
+	}
 }
 
 // Define struct to hold application-wide dependencies
@@ -40,12 +54,15 @@ type application struct {
 	quotes        models.QuoteModelInterface
 	authors       models.AuthorModelInterface
 	books         models.BookModelInterface
+	editions      models.EditionModelInterface
 	users         models.UserModelInterface
 	templateCache map[string]*template.Template
-	client        *supabase.Client
-	authClient    *supabase.Client
+	client        *postgrest.Client
+	authClient    *postgrest.Client
 	formDecoder   *form.Decoder
 	sessionManager *scs.SessionManager
+	// Looks up books by ISBN to fill in the create book form, nil when turned off
+	isbnLookup    lookup.Provider
 }
 
 func main() {
@@ -61,6 +78,19 @@ func main() {
 	// Read the environment from the command-line flag
 	flag.StringVar(&cfg.env, "env", "development", "Environment (staging|production)")
 
+	// Read the storage backend and its connection string from the command-line flags
+	flag.StringVar(&cfg.storage, "storage", storageSupabase, "Storage backend (supabase|postgres|sqlite|memory)")
+	flag.StringVar(&cfg.db.dsn, "db-dsn", "", "PostgreSQL DSN or SQLite file path (defaults to DATABASE_URL)")
+
+	// Read the per-query database deadlines from the command-line flags
+	flag.DurationVar(&cfg.db.readTimeout, "db-read-timeout", models.DefaultTimeouts.Read, "Deadline for database read queries")
+	flag.DurationVar(&cfg.db.writeTimeout, "db-write-timeout", models.DefaultTimeouts.Write, "Deadline for database write queries")
+
+	// Read the ISBN lookup server and how long to wait for it and keep its answers from the command-line flags
+	flag.StringVar(&cfg.isbnLookup.url, "isbn-lookup-url", lookup.DefaultOpenLibraryURL, "Open Library compatible server to look up books by ISBN (empty to turn off)")
+	flag.DurationVar(&cfg.isbnLookup.timeout, "isbn-lookup-timeout", 5*time.Second, "Deadline for ISBN lookups")
+	flag.DurationVar(&cfg.isbnLookup.cacheTTL, "isbn-lookup-cache-ttl", 24*time.Hour, "How long ISBN lookups are cached")
+
 	// Parse the command-line flags
 	flag.Parse()
 
@@ -78,42 +108,47 @@ func main() {
 		logger.Error("error loading .env file")
 	}
 
-	// Log the environment variables to check if they are loaded correctly
-	supabaseURL := os.Getenv("SUPABASE_URL")
-	supabaseKey := os.Getenv("SUPABASE_PUBLIC_KEY")
-	supabaseSecretKey := os.Getenv("SUPABASE_SECRET_KEY")
-	supabaseURIString := os.Getenv("SUPABASE_URI_STRING")
+	// Fall back to the DATABASE_URL environment variable, then to the default sqlite file
+	if cfg.db.dsn == "" {
+		cfg.db.dsn = os.Getenv("DATABASE_URL")
+	}
+	if cfg.db.dsn == "" && cfg.storage == storageSQLite {
+		cfg.db.dsn = defaultSQLitePath
+	}
 
-	// Check if the environment variables are set
-	if supabaseURL == "" || supabaseKey == "" || supabaseSecretKey == "" {
-		logger.Error("database environment variables are not set")
-		os.Exit(1)
+	// Run the migrate subcommand instead of the server when it is given
+	if flag.Arg(0) == "migrate" {
+		err := runMigrate(cfg, flag.Args()[1:], os.Stdout)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+		return
 	}
 
-	// Initialize database connection
-	db, err := sql.Open("postgres", supabaseURIString)
+	// Open the selected storage backend
+	store, err := openStorage(logger, cfg)
 	if err != nil {
-		logger.Error("error connecting to the database for initialization")
+		logger.Error(err.Error())
 		os.Exit(1)
-	} else {
-		logger.Info("connected to database for initialization")
 	}
 	// Close the database connection when the main function returns and print a message
 	defer func() {
-		if err := db.Close(); err != nil {
-			logger.Error("error closing the database initialization connection")
+		if err := store.Close(); err != nil {
+			logger.Error("error closing the database connection")
 		} else {
-			logger.Info("database initialization connection closed")
+			logger.Info("database connection closed")
 		}
 	}()
 
-	// Initialize supabase client
-	client, authClient, err := connectSupabase(logger, supabaseURL, supabaseKey, supabaseSecretKey)
-	if err != nil {
-		logger.Error(err.Error())
-		os.Exit(1)
-	} else {
-		logger.Info("connected to database for rest and auth api")
+	// Run the role subcommand instead of the server when it is given
+	if flag.Arg(0) == "role" {
+		err := runRole(context.Background(), store.users, flag.Args()[1:], os.Stdout)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+		return
 	}
 
 	// TODO; close the supabase client when the main function returns and print a message
@@ -128,26 +163,34 @@ func main() {
 	// Initialize form decoder instance
 	formDecoder := form.NewDecoder()
 
-	// Initialize session manager and configure it to use postgres store
+	// Initialize session manager and configure it to use the storage backend's session store
 	sessionManager := scs.New()
-	sessionManager.Store = postgresstore.New(db)
+	sessionManager.Store = store.sessionStore
 	sessionManager.Lifetime = 12 * time.Hour
 	sessionManager.Cookie.Secure = true
 	sessionManager.Cookie.SameSite = http.SameSiteStrictMode
 
+	// Initialize the ISBN lookup, caching its answers, unless it is turned off
+	var isbnLookup lookup.Provider
+	if cfg.isbnLookup.url != "" {
+		isbnLookup = lookup.NewCache(lookup.NewOpenLibrary(cfg.isbnLookup.url, cfg.isbnLookup.timeout), cfg.isbnLookup.cacheTTL, isbnLookupCacheSize)
+	}
+
 	// Initialize a new instance of application struct dependencies
 	app := &application{
 		config:        cfg,
 		logger:        logger,
-		quotes:        &models.QuoteModel{Client: client, AuthClient: authClient, AuthUserID: uuid.Nil},
-		authors:       &models.AuthorModel{Client: client},
-		books:         &models.BookModel{Client: client, AuthUserID: uuid.Nil},
-		users:         &models.UserModel{Client: client, AuthClient: authClient, AuthUserID: uuid.Nil},
+		quotes:        store.quotes,
+		authors:       store.authors,
+		books:         store.books,
+		editions:      store.editions,
+		users:         store.users,
 		templateCache: templateCache,
-		client:        client,
-		authClient:    authClient,
+		client:        store.client,
+		authClient:    store.authClient,
 		sessionManager: sessionManager,
 		formDecoder:   formDecoder,
+		isbnLookup:    isbnLookup,
 	}
 
 	// Initialize a tls config struct to configure the tls settings
@@ -178,17 +221,17 @@ func main() {
 	os.Exit(1)
 }
 
-// Connect to the supabase database
-func connectSupabase(logger *slog.Logger, supabaseURL string, supabaseKey string, supabaseSecretKey string) (*supabase.Client, *supabase.Client, error) {
+// Connect to the supabase database, with its requests bounded by the timeouts
+func connectSupabase(logger *slog.Logger, supabaseURL string, supabaseKey string, supabaseSecretKey string, timeouts models.Timeouts) (*postgrest.Client, *postgrest.Client, error) {
 	// Initialize supabase client
-	client, err := supabase.NewClient(supabaseURL, supabaseKey, nil)
+	client, err := models.NewClient(supabaseURL, supabaseKey, timeouts)
 	if err != nil {
 		logger.Error("error connecting to the database for rest api")
 		return nil, nil, err
 	}
 
 	// Initialize supabase auth client
-	authClient, err := supabase.NewClient(supabaseURL, supabaseSecretKey, nil)
+	authClient, err := models.NewClient(supabaseURL, supabaseSecretKey, timeouts)
 	if err != nil {
 		logger.Error("error connecting to the database for auth api")
 		return nil, nil, err
@@ -1,11 +1,13 @@
 package main
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+	"github.com/justinas/alice"
 	"github.com/justinas/nosurf"
 )
 
@@ -69,6 +71,22 @@ func (app *application) requireAuthentication(next http.Handler) http.Handler {
 	})
 }
 
+// Requires the user to have at least the given role, after requireAuthentication
+// has made sure they are logged in
+func (app *application) requireRole(role models.Role) alice.Constructor {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !app.hasRole(r, role) {
+				app.forbiddenResponse(w, r)
+				return
+			}
+
+			// Call the next handler in the chain
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // NoSurf middleware to protect against CSRF attacks
 func noSurf(next http.Handler) http.Handler {
 	csrfHandler := nosurf.New(next)
@@ -96,22 +114,18 @@ func (app *application) authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		// Use the UUID to check if the user exists
-		exists, err := app.users.Exists(id)
-		if err != nil {
+		// Use the UUID to look up the user, who may have been deleted since logging in
+		user, err := app.users.Get(r.Context(), id)
+		if err != nil && !errors.Is(err, models.ErrNoRecord) {
 			app.serverError(w, r, err)
 			return
 		}
 
-		// If the user exists, add the user to the request context
-		if exists {
-			// Set the actual user ID in the context
-			ctx := context.WithValue(r.Context(), isAuthenticatedContextKey, id)
-			r = r.WithContext(ctx)
-
-			// Update the UserModel and QuoteModel with the authenticated user ID
-			app.users.SetAuthUserID(id)
-			app.quotes.SetAuthUserID(id)
+		// If the user exists, add the user to the request context so that
+		// concurrent requests never share the acting user, and their role is
+		// read afresh on every request
+		if err == nil {
+			r = app.contextSetUser(r, user)
 		}
 
 		// Call the next handler
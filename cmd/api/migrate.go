@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/justinbachtell/quote-table-go/internal/migrate"
+	"github.com/justinbachtell/quote-table-go/internal/models/sqlite"
+	"github.com/justinbachtell/quote-table-go/migrations"
+)
+
+// Opens the database of a storage backend and the embedded migrations for its dialect
+func openMigrations(backend string, dsn string) (*sql.DB, fs.FS, error) {
+	if dsn == "" {
+		return nil, nil, fmt.Errorf("no database connection string is set for %s storage", backend)
+	}
+
+	// Open the database with the driver of the backend
+	var db *sql.DB
+	var dialect string
+	var err error
+	switch backend {
+	case storageSupabase, storagePostgres:
+		db, err = sql.Open("postgres", dsn)
+		dialect = "postgres"
+	case storageSQLite:
+		db, err = sqlite.Open(dsn)
+		dialect = "sqlite"
+	default:
+		return nil, nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files, err := fs.Sub(migrations.Files, dialect)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return db, files, nil
+}
+
+// Runs the migrate up, down and status subcommands
+func runMigrate(cfg config, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up | down [steps] | status")
+	}
+	if cfg.storage == storageMemory {
+		return errors.New("memory storage has no schema to migrate")
+	}
+
+	// Supabase keeps its direct connection string apart from the -db-dsn flag
+	dsn := cfg.db.dsn
+	if cfg.storage == storageSupabase {
+		dsn = os.Getenv("SUPABASE_URI_STRING")
+	}
+
+	db, files, err := openMigrations(cfg.storage, dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, files)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Fprintf(out, "applied %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(out, "no pending migrations")
+		}
+		return nil
+
+	case "down":
+		// Roll back a single migration unless a number of steps is given
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, m := range rolledBack {
+			fmt.Fprintf(out, "rolled back %d_%s\n", m.Version, m.Name)
+		}
+		if errors.Is(err, migrate.ErrNoChange) {
+			fmt.Fprintln(out, "no applied migrations")
+			return nil
+		}
+		return err
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Print a table of every migration and when it was applied
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return tw.Flush()
+
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/julienschmidt/httprouter"
 	"github.com/justinbachtell/quote-table-go/internal/models"
 	"github.com/justinbachtell/quote-table-go/internal/validator"
 )
@@ -22,8 +24,19 @@ type quoteCreateForm struct {
 	NewBookCalendarTime string `form:"new_book_calendar_time"`
 	NewBookISBN string `form:"new_book_isbn"`
 	NewBookSource string `form:"new_book_source"`
-	PageNumber string `form:"page_number"`
-	IsPrivate bool `form:"is_private"`
+	// The edition of the book the quote was taken from, if it is known
+	EditionID int `form:"edition-selector"`
+	// Where the quote is in its work: the type of location and the text entered for it
+	LocationType string `form:"location_type"`
+	Location string `form:"location"`
+	// The quote's tags, separated by commas
+	Tags string `form:"tags"`
+	Visibility models.Visibility `form:"visibility"`
+	// The answers to "did you mean": an existing ID, or "new" to add the new author or book anyway
+	AuthorChoice string `form:"author_choice"`
+	BookChoice string `form:"book_choice"`
+	AuthorSuggestions []models.Author `form:"-"`
+	BookSuggestions []models.Book `form:"-"`
 	CreatedAt time.Time `form:"created_at"`
 	UpdatedAt time.Time `form:"updated_at"`
 	validator.Validator `form:"-"`
@@ -39,7 +52,7 @@ func (app *application) quoteView(w http.ResponseWriter, r *http.Request) {
     }
 
 	// Get the quote
-    quote, err := app.quotes.GetWithAuthorAndBook(int(id))
+    quote, err := app.quotes.GetWithAuthorAndBook(r.Context(), int(id))
     if err != nil {
         if errors.Is(err, models.ErrNoRecord) {
             app.notFoundResponse(w, r)
@@ -49,21 +62,46 @@ func (app *application) quoteView(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+	// Render the quote with its tags and edition
+	app.renderQuote(w, r, quote)
+}
+
+// Renders the view quote page for a quote loaded with its author and book,
+// along with its tags and the edition it was taken from
+func (app *application) renderQuote(w http.ResponseWriter, r *http.Request, quote models.Quote) {
 	// Initialize the template data
-    data := app.newTemplateData(r)
-    data.Quote = quote
+	data := app.newTemplateData(r)
+	data.Quote = quote
 	data.Author = quote.Author
+	data.CanModify = app.canModify(r, quote.UserID)
+
+	// Show the quote's tags
+	var err error
+	data.Quote.Tags, err = app.quotes.GetTags(r.Context(), quote.ID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	// Show the edition the quote was taken from, if it is known
+	if quote.EditionID != 0 {
+		data.Edition, err = app.editions.Get(r.Context(), quote.EditionID)
+		if err != nil && !errors.Is(err, models.ErrNoRecord) {
+			app.serverError(w, r, err)
+			return
+		}
+	}
 
 	// Render the view quote page
-    app.render(w, r, http.StatusOK, "view-quote.go.tmpl", data)
+	app.render(w, r, http.StatusOK, "view-quote.go.tmpl", data)
 }
 
 // Handler for the create quote page
 func (app *application) quoteCreate(w http.ResponseWriter, r *http.Request) {
     data := app.newTemplateData(r)
-    data.Form = quoteCreateForm{}
+    data.Form = quoteCreateForm{Visibility: models.VisibilityPublic}
 
-    authors, err := app.authors.GetAllWithCounts()
+    authors, err := app.authors.GetAllWithCounts(r.Context())
     if err != nil {
         app.serverError(w, r, err)
         return
@@ -71,7 +109,7 @@ func (app *application) quoteCreate(w http.ResponseWriter, r *http.Request) {
     data.Authors = authors
 
     // Fetch all books
-    books, err := app.books.GetAll()
+    books, err := app.books.GetAll(r.Context())
     if err != nil {
         app.serverError(w, r, err)
         return
@@ -80,6 +118,13 @@ func (app *application) quoteCreate(w http.ResponseWriter, r *http.Request) {
     // Add the authors and books to the template data
     data.Books = books
 
+    // Fetch all editions, which are offered under their books
+    data.Editions, err = app.editions.GetAll(r.Context())
+    if err != nil {
+        app.serverError(w, r, err)
+        return
+    }
+
     // Render the create quote page
     app.render(w, r, http.StatusOK, "create-quote.go.tmpl", data)
 }
@@ -95,12 +140,8 @@ func (app *application) quoteCreatePost(w http.ResponseWriter, r *http.Request)
     var bookID int
     var err error
 
-    // Fetch the authenticated user
-    user, err := app.users.Get(data.AuthenticatedUserID)
-    if err != nil {
-        app.serverError(w, r, err)
-        return
-    }
+    // Get the authenticated user from the request context
+    userID := app.contextGetUserID(r)
 
     // Decode the form
     err = app.formDecoder.Decode(&form, r.PostForm)
@@ -110,75 +151,70 @@ func (app *application) quoteCreatePost(w http.ResponseWriter, r *http.Request)
         return
     }
 
-    // Validate the form
+    // Validate the form, with new quotes public unless another visibility is chosen
     validator.ValidateQuote(&form.Validator, form.Quote)
     validator.ValidateCharacters(form.Quote)
+    if form.Visibility == "" {
+        form.Visibility = models.VisibilityPublic
+    }
+    form.CheckField(validator.PermittedValue(form.Visibility, models.Visibilities...), "visibility", "This field must be public, unlisted or private")
+    tags := validator.NormalizeTags(form.Tags)
+    validator.ValidateTags(&form.Validator, tags)
 
-    // Handle author
-    authorSelector := r.PostForm.Get("author-selector")
-    if authorSelector != "" {
-        authorID, err = strconv.Atoi(authorSelector)
-        if err != nil {
-            form.AddFieldError("author", "Invalid author selection")
-        }
-    } else if form.NewAuthorName != "" {
-        validator.ValidateAuthor(&form.Validator, form.NewAuthorName)
-        if form.ValidField() {
-            authorID, err = app.authors.Insert(form.NewAuthorName)
-            if err != nil {
-                app.serverError(w, r, err)
-                return
-            }
-        }
-    } else {
-        form.AddFieldError("author", "Please select an author or enter a new one.")
+    edition, err := app.quoteEdition(r, &form)
+    if err != nil {
+        app.serverError(w, r, err)
+        return
     }
 
-    // Handle book
-    bookSelector := r.PostForm.Get("book-selector")
-    if bookSelector != "" {
-        bookID, err = strconv.Atoi(bookSelector)
-        if err != nil {
-            form.AddFieldError("book", "Invalid book selection")
-        }
-    } else if form.NewBookTitle != "" {
-        validator.ValidateBook(&form.Validator, form.NewBookTitle, form.NewBookPublishYear, form.NewBookCalendarTime, form.NewBookISBN, form.NewBookSource)
-        if form.ValidField() {
-            bookID, err = app.books.Insert(form.NewBookTitle, form.NewBookPublishYear, form.NewBookCalendarTime, form.NewBookISBN, form.NewBookSource)
-            if err != nil {
-                app.serverError(w, r, err)
-                return
-            }
-        }
-    } else {
-        form.AddFieldError("book", "Please select a book or enter a new one.")
+    location, err := app.quoteLocation(r, &form, edition)
+    if err != nil {
+        app.serverError(w, r, err)
+        return
+    }
+
+    // Find or add the author and book
+    authorID, bookID, err = app.quoteAuthorAndBook(r, &form)
+    if err != nil {
+        app.serverError(w, r, err)
+        return
     }
 
     // If the form is not valid, re-render the form
     if !form.ValidField() {
         data.Form = form
         // Fetch all authors and books for the dropdowns
-        authors, err := app.authors.GetAllWithCounts()
+        authors, err := app.authors.GetAllWithCounts(r.Context())
         if err != nil {
             app.serverError(w, r, err)
             return
         }
-        books, err := app.books.GetAll()
+        books, err := app.books.GetAll(r.Context())
         if err != nil {
             app.serverError(w, r, err)
             return
         }
         data.Authors = authors
         data.Books = books
+        data.Editions, err = app.editions.GetAll(r.Context())
+        if err != nil {
+            app.serverError(w, r, err)
+            return
+        }
         app.render(w, r, http.StatusUnprocessableEntity, "create-quote.go.tmpl", data)
         return
     }
 
     // Insert the quote
-    id, err := app.quotes.Insert(form.Quote, authorID, bookID, form.PageNumber, form.IsPrivate, user.ID)
+    id, err := app.quotes.Insert(r.Context(), form.Quote, authorID, bookID, form.EditionID, location, form.Visibility, userID)
     if err != nil {
-        app.logError(r, err)
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        app.serverError(w, r, err)
+        return
+    }
+
+    err = app.quotes.SetTags(r.Context(), id, tags)
+    if err != nil {
+        app.serverError(w, r, err)
         return
     }
 
@@ -198,26 +234,44 @@ func (app *application) quoteEdit(w http.ResponseWriter, r *http.Request) {
     }
 
     // Get the quote
-	quote, err := app.quotes.GetWithAuthorAndBook(id)
+	quote, err := app.quotes.GetWithAuthorAndBook(r.Context(), id)
     if err != nil {
         if errors.Is(err, models.ErrNoRecord) {
             app.notFoundResponse(w, r)
         } else {
-			app.logError(r, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+            app.serverError(w, r, err)
         }
         return
     }
 
+	// Only the user who added the quote, or a moderator or admin, can edit it
+	if !app.canModify(r, quote.UserID) {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
     // Fetch all authors
-    authors, err := app.authors.GetAllWithCounts()
+    authors, err := app.authors.GetAllWithCounts(r.Context())
     if err != nil {
         app.serverError(w, r, err)
         return
     }
 
 	// Fetch all books
-	books, err := app.books.GetAll()
+	books, err := app.books.GetAll(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	// Fetch all editions, which are offered under their books
+	editions, err := app.editions.GetAll(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	tags, err := app.quotes.GetTags(r.Context(), quote.ID)
 	if err != nil {
 		app.serverError(w, r, err)
 		return
@@ -228,14 +282,19 @@ func (app *application) quoteEdit(w http.ResponseWriter, r *http.Request) {
     data.Quote = quote
     data.Authors = authors
 	data.Books = books
+	data.Editions = editions
+	data.ShareURL = app.shareURL(r, quote)
 
 	// Initialize the form
     data.Form = quoteCreateForm{
         Quote:    quote.Quote,
         AuthorID: quote.AuthorID,
 		BookID: quote.BookID,
-		PageNumber: quote.PageNumber,
-		IsPrivate: quote.IsPrivate,
+		EditionID: quote.EditionID,
+		LocationType: string(quote.Location.Type),
+		Location: quote.Location.Value(),
+		Tags: strings.Join(tags, ", "),
+		Visibility: quote.Visibility,
     }
 
 	// Render the edit quote page
@@ -251,7 +310,7 @@ func (app *application) quoteEditPost(w http.ResponseWriter, r *http.Request) {
     }
 
     // Fetch the original quote
-    originalQuote, err := app.quotes.GetWithAuthorAndBook(id)
+    originalQuote, err := app.quotes.GetWithAuthorAndBook(r.Context(), id)
     if err != nil {
         if errors.Is(err, models.ErrNoRecord) {
             app.notFoundResponse(w, r)
@@ -261,6 +320,12 @@ func (app *application) quoteEditPost(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+	// Only the user who added the quote, or a moderator or admin, can edit it
+	if !app.canModify(r, originalQuote.UserID) {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
 	// Initialize the form
     var form quoteCreateForm
 
@@ -275,62 +340,32 @@ func (app *application) quoteEditPost(w http.ResponseWriter, r *http.Request) {
     validator.ValidateQuote(&form.Validator, form.Quote)
     validator.ValidateCharacters(form.Quote)
 
-	// Initialize authorID and bookID
-	var authorID int
-	var bookID int
-	var pageNumber string
-	var isPrivate bool
-
-	// Handle author
-    authorSelector := r.PostForm.Get("author-selector")
-    if authorSelector != "" {
-        authorID, err = strconv.Atoi(authorSelector)
-        if err != nil {
-            form.AddFieldError("author", "Invalid author selection")
-        }
-    } else if form.NewAuthorName != "" {
-        validator.ValidateAuthor(&form.Validator, form.NewAuthorName)
-        if form.ValidField() {
-            authorID, err = app.authors.Insert(form.NewAuthorName)
-            if err != nil {
-                app.serverError(w, r, err)
-                return
-            }
-        }
-    } else {
-        form.AddFieldError("author", "Please select an author or enter a new one.")
-    }
+	// Keep the quote's visibility unless another one is chosen
+	if form.Visibility == "" {
+		form.Visibility = originalQuote.Visibility
+	}
+	form.CheckField(validator.PermittedValue(form.Visibility, models.Visibilities...), "visibility", "This field must be public, unlisted or private")
+	tags := validator.NormalizeTags(form.Tags)
+	validator.ValidateTags(&form.Validator, tags)
 
-	// Handle book
-    bookSelector := r.PostForm.Get("book-selector")
-    if bookSelector != "" {
-        bookID, err = strconv.Atoi(bookSelector)
-        if err != nil {
-            form.AddFieldError("book", "Invalid book selection")
-        }
-    } else if form.NewBookTitle != "" {
-        validator.ValidateBook(&form.Validator, form.NewBookTitle, form.NewBookPublishYear, form.NewBookCalendarTime, form.NewBookISBN, form.NewBookSource)
-		if form.ValidField() {
-			bookID, err = app.books.Insert(form.NewBookTitle, form.NewBookPublishYear, form.NewBookCalendarTime, form.NewBookISBN, form.NewBookSource)
-			if err != nil {
-				app.serverError(w, r, err)
-				return
-			}
-		}
-    } else {
-        form.AddFieldError("book", "Please select a book or enter a new one.")
+	edition, err := app.quoteEdition(r, &form)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
 	}
 
-	// Handle page number
-	pageNumber = r.PostForm.Get("page_number")
-	if pageNumber != "" {
-		form.PageNumber = pageNumber
+	location, err := app.quoteLocation(r, &form, edition)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
 	}
 
-	// Handle isPrivate
-	isPrivate = r.PostForm.Get("is_private") == "on"
-	form.IsPrivate = isPrivate
-	
+	// Find or add the author and book
+	authorID, bookID, err := app.quoteAuthorAndBook(r, &form)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
 
 	// If the form is not valid, re-render the form
     if !form.ValidField() {
@@ -338,13 +373,19 @@ func (app *application) quoteEditPost(w http.ResponseWriter, r *http.Request) {
         data.Form = form
         data.Quote = originalQuote
         
-        authors, err := app.authors.GetAllWithCounts()
+        authors, err := app.authors.GetAllWithCounts(r.Context())
         if err != nil {
             app.serverError(w, r, err)
             return
         }
 
-		books, err := app.books.GetAll()
+		books, err := app.books.GetAll(r.Context())
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+
+		data.Editions, err = app.editions.GetAll(r.Context())
 		if err != nil {
 			app.serverError(w, r, err)
 			return
@@ -353,18 +394,25 @@ func (app *application) quoteEditPost(w http.ResponseWriter, r *http.Request) {
 		data.Authors = authors
 		data.Books = books
 		data.Quote = originalQuote
-		
+		data.ShareURL = app.shareURL(r, originalQuote)
+
 		app.render(w, r, http.StatusUnprocessableEntity, "edit-quote.go.tmpl", data)
         return
     }
 
-	// Update the quote
-    _, err = app.quotes.Update(id, form.Quote, authorID, bookID, form.PageNumber, form.IsPrivate)
+	// Update the quote, keeping its owner when a moderator or admin edits it
+    _, err = app.quotes.Update(r.Context(), id, form.Quote, authorID, bookID, form.EditionID, location, form.Visibility, originalQuote.UserID)
     if err != nil {
         app.serverError(w, r, err)
         return
     }
 
+	err = app.quotes.SetTags(r.Context(), id, tags)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
 	// Add a flash message
     app.sessionManager.Put(r.Context(), "flash", "Quote updated successfully!")
 
@@ -372,6 +420,204 @@ func (app *application) quoteEditPost(w http.ResponseWriter, r *http.Request) {
     http.Redirect(w, r, fmt.Sprintf("/quote/view/%d", id), http.StatusSeeOther)
 }
 
+// Returns the edition chosen on a quote form, checking it is one of the
+// selected book's. A new book has no editions yet.
+func (app *application) quoteEdition(r *http.Request, form *quoteCreateForm) (models.Edition, error) {
+	if form.EditionID == 0 {
+		return models.Edition{}, nil
+	}
+	if form.BookID == 0 {
+		form.AddFieldError("edition", "A new book has no editions yet, so none can be chosen")
+		return models.Edition{}, nil
+	}
+
+	edition, err := app.editions.Get(r.Context(), form.EditionID)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		return models.Edition{}, err
+	}
+	if edition.BookID != form.BookID {
+		form.AddFieldError("edition", "The edition must be one of the selected book's")
+		return models.Edition{}, nil
+	}
+
+	return edition, nil
+}
+
+// Returns the location given on a quote form, treating a location without a
+// type as a page. The location is checked against the page count of the
+// chosen edition, or else of the selected book, so a quote can't be from a
+// page the book doesn't have.
+func (app *application) quoteLocation(r *http.Request, form *quoteCreateForm, edition models.Edition) (models.Location, error) {
+	if form.LocationType == "" {
+		form.LocationType = string(models.LocationPage)
+	}
+
+	pageCount := edition.PageCount
+	if pageCount == 0 && form.BookID > 0 && form.LocationType == string(models.LocationPage) {
+		book, err := app.books.Get(r.Context(), form.BookID)
+		if err != nil && !errors.Is(err, models.ErrNoRecord) {
+			return models.Location{}, err
+		}
+		pageCount = book.PageCount
+	}
+
+	validator.ValidateLocation(&form.Validator, form.LocationType, form.Location, pageCount)
+	location, err := models.ParseLocation(models.LocationType(form.LocationType), form.Location)
+	if err != nil {
+		form.AddFieldError("location", "This field must be a valid location")
+	}
+
+	return location, nil
+}
+
+// Returns the author and book of a submitted quote form: the ones selected, or
+// new ones from the form's new author and book fields. A new author or book
+// that looks like one already added isn't added straight away: the form is
+// given the similar ones as suggestions instead, and the user picks one of
+// them or chooses to add theirs anyway. Nothing is added unless the whole
+// form is valid.
+func (app *application) quoteAuthorAndBook(r *http.Request, form *quoteCreateForm) (int, int, error) {
+	authorID, bookID := form.AuthorID, form.BookID
+	addAuthor, addBook := false, false
+
+	// Handle author
+	switch {
+	case authorID > 0:
+	case form.NewAuthorName != "":
+		validator.ValidateAuthor(&form.Validator, form.NewAuthorName)
+		if _, invalid := form.FieldErrors["author"]; invalid {
+			break
+		}
+		switch form.AuthorChoice {
+		case "new":
+			addAuthor = true
+		case "":
+			similar, err := app.similarAuthors(r.Context(), form.NewAuthorName)
+			if err != nil {
+				return 0, 0, err
+			}
+			if len(similar) > 0 {
+				form.AuthorSuggestions = similar
+				form.AddFieldError("author", "There are already authors with similar names. Did you mean one of them?")
+			} else {
+				addAuthor = true
+			}
+		default:
+			id, err := strconv.Atoi(form.AuthorChoice)
+			if err != nil || id < 1 {
+				form.AddFieldError("author", "Invalid author selection")
+			}
+			authorID = id
+		}
+	default:
+		form.AddFieldError("author", "Please select an author or enter a new one.")
+	}
+
+	// Handle book
+	switch {
+	case bookID > 0:
+	case form.NewBookTitle != "":
+		if isbn, ok := validator.NormalizeISBN(form.NewBookISBN); ok {
+			form.NewBookISBN = isbn
+		}
+		var v validator.Validator
+		validator.ValidateBook(&v, form.NewBookTitle, form.NewBookPublishYear, form.NewBookCalendarTime, form.NewBookISBN, form.NewBookSource)
+		if !v.ValidField() {
+			for key, message := range v.FieldErrors {
+				form.AddFieldError(key, message)
+			}
+			break
+		}
+		taken, err := app.editionHasISBN(r.Context(), form.NewBookISBN)
+		if err != nil {
+			return 0, 0, err
+		}
+		if taken {
+			form.AddFieldError("isbn", "An edition with this ISBN has already been added")
+			break
+		}
+		taken, err = app.bookHasISBN(r.Context(), form.NewBookISBN)
+		if err != nil {
+			return 0, 0, err
+		}
+		if taken && form.BookChoice == "new" {
+			form.AddFieldError("isbn", "A book with this ISBN has already been added")
+			break
+		}
+		switch form.BookChoice {
+		case "new":
+			addBook = true
+		case "":
+			similar, err := app.similarBooks(r.Context(), form.NewBookTitle, form.NewBookISBN)
+			if err != nil {
+				return 0, 0, err
+			}
+			if len(similar) > 0 {
+				form.BookSuggestions = similar
+				form.AddFieldError("book", "There are already books with similar titles or the same ISBN. Did you mean one of them?")
+			} else {
+				addBook = true
+			}
+		default:
+			id, err := strconv.Atoi(form.BookChoice)
+			if err != nil || id < 1 {
+				form.AddFieldError("book", "Invalid book selection")
+			}
+			bookID = id
+		}
+	default:
+		form.AddFieldError("book", "Please select a book or enter a new one.")
+	}
+
+	// Check the author and book picked were added, so a new author isn't
+	// added for a quote that can't be saved
+	if authorID > 0 {
+		exists, err := app.authors.Exists(r.Context(), authorID)
+		if err != nil {
+			return 0, 0, err
+		}
+		if !exists {
+			form.AddFieldError("author", "Invalid author selection")
+		}
+	}
+	if bookID > 0 {
+		_, err := app.books.Get(r.Context(), bookID)
+		if errors.Is(err, models.ErrNoRecord) {
+			form.AddFieldError("book", "Invalid book selection")
+		} else if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if !form.ValidField() {
+		return 0, 0, nil
+	}
+
+	// Add the new author and book now the form is known to be valid
+	userID := app.contextGetUserID(r)
+	var err error
+	if addAuthor {
+		authorID, err = app.authors.Insert(r.Context(), form.NewAuthorName, models.AuthorDetails{}, userID)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if addBook {
+		// Credit the new book to the quote's author
+		contributors := []models.BookContributor{{AuthorID: authorID, Role: models.ContributorAuthor}}
+		bookID, err = app.books.Insert(r.Context(), form.NewBookTitle, form.NewBookPublishYear, form.NewBookCalendarTime, form.NewBookISBN, form.NewBookSource, models.WorkDetails{MediaType: models.MediaBook}, contributors, userID)
+		if errors.Is(err, models.ErrDuplicateISBN) {
+			form.AddFieldError("isbn", "A book with this ISBN has already been added")
+			return 0, 0, nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return authorID, bookID, nil
+}
+
 // Handler to delete a quote
 func (app *application) quoteDeletePost(w http.ResponseWriter, r *http.Request) {
     idStr := r.URL.Path[len("/quote/delete/"):]
@@ -381,14 +627,122 @@ func (app *application) quoteDeletePost(w http.ResponseWriter, r *http.Request)
         return
     }
 
-    err = app.quotes.Delete(id)
+    // Only the user who added the quote, or a moderator or admin, can delete it
+    quote, err := app.quotes.Get(r.Context(), id)
     if err != nil {
-        app.logError(r, err)
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        if errors.Is(err, models.ErrNoRecord) {
+            app.notFoundResponse(w, r)
+        } else {
+            app.serverError(w, r, err)
+        }
+        return
+    }
+    if !app.canModify(r, quote.UserID) {
+        app.forbiddenResponse(w, r)
+        return
+    }
+
+    err = app.quotes.Delete(r.Context(), id)
+    if err != nil {
+        app.serverError(w, r, err)
         return
     }
 
     app.sessionManager.Put(r.Context(), "flash", "Quote deleted successfully")
 
     http.Redirect(w, r, "/", http.StatusSeeOther)
-}
\ No newline at end of file
+}
+
+// Handler for the page a quote's share link opens, which anyone with the link can read
+func (app *application) quoteShared(w http.ResponseWriter, r *http.Request) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	// Get the quote, which isn't found once its link is revoked or replaced
+	quote, err := app.quotes.GetByShareToken(r.Context(), params.ByName("token"))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFoundResponse(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return
+	}
+
+	// Render the quote with its tags and edition
+	app.renderQuote(w, r, quote)
+}
+
+// Handler to create a share link for a quote, replacing any link it already has
+func (app *application) quoteSharePost(w http.ResponseWriter, r *http.Request) {
+	quote, ok := app.quoteToShare(w, r)
+	if !ok {
+		return
+	}
+
+	// Generate a new token, so any earlier link stops working
+	token, err := models.NewShareToken()
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	err = app.quotes.SetShareToken(r.Context(), quote.ID, token)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	if quote.ShareToken != "" {
+		app.sessionManager.Put(r.Context(), "flash", "Share link replaced, so the old link no longer works")
+	} else {
+		app.sessionManager.Put(r.Context(), "flash", "Share link created")
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/quote/edit/%d", quote.ID), http.StatusSeeOther)
+}
+
+// Handler to revoke a quote's share link
+func (app *application) quoteUnsharePost(w http.ResponseWriter, r *http.Request) {
+	quote, ok := app.quoteToShare(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.quotes.SetShareToken(r.Context(), quote.ID, "")
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Share link revoked")
+
+	http.Redirect(w, r, fmt.Sprintf("/quote/edit/%d", quote.ID), http.StatusSeeOther)
+}
+
+// Fetches the quote whose share link is being changed, writing the error
+// response and returning false unless the user can modify the quote
+func (app *application) quoteToShare(w http.ResponseWriter, r *http.Request) (models.Quote, bool) {
+	id, err := app.readIDParam(r)
+	if err != nil || id < 1 {
+		app.notFoundResponse(w, r)
+		return models.Quote{}, false
+	}
+
+	quote, err := app.quotes.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFoundResponse(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return models.Quote{}, false
+	}
+
+	// Only the user who added the quote, or a moderator or admin, can share it
+	if !app.canModify(r, quote.UserID) {
+		app.forbiddenResponse(w, r)
+		return models.Quote{}, false
+	}
+
+	return quote, true
+}
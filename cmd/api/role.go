@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+// Runs the role subcommand, which shows or changes the role of the user with an email address
+func runRole(ctx context.Context, users models.UserModelInterface, args []string, out io.Writer) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errors.New("usage: role email [user | premium | moderator | admin]")
+	}
+
+	user, err := users.GetByEmail(ctx, args[0])
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			return fmt.Errorf("no user has the email %s", args[0])
+		}
+		return err
+	}
+
+	// Show the current role unless a new one is given
+	if len(args) == 1 {
+		fmt.Fprintf(out, "%s is %s\n", user.Email, user.Role)
+		return nil
+	}
+
+	role := models.Role(args[1])
+	if !role.Valid() {
+		return fmt.Errorf("unknown role %q", args[1])
+	}
+
+	err = users.SetRole(ctx, user.ID, role)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%s changed from %s to %s\n", user.Email, user.Role, role)
+	return nil
+}
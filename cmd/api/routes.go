@@ -3,6 +3,7 @@ package main
 import (
 	"net/http"
 
+	"github.com/justinbachtell/quote-table-go/internal/models"
 	"github.com/justinbachtell/quote-table-go/ui"
 
 	"github.com/julienschmidt/httprouter"
@@ -33,10 +34,12 @@ func (app *application) routes() http.Handler {
 	// Register the unprotected app routes
 	router.Handler("GET", "/", dynamicRouter.ThenFunc(app.home))
 	router.Handler("GET", "/quote/view/:id", dynamicRouter.ThenFunc(app.quoteView))
+	router.Handler("GET", "/q/s/:token", dynamicRouter.ThenFunc(app.quoteShared))
 	router.Handler("GET", "/authors", dynamicRouter.ThenFunc(app.authorList))
 	router.Handler("GET", "/author/view/:id", dynamicRouter.ThenFunc(app.authorView))
 	router.Handler("GET", "/books", dynamicRouter.ThenFunc(app.bookList))
 	router.Handler("GET", "/book/view/:id", dynamicRouter.ThenFunc(app.bookView))
+	router.Handler("GET", "/search", dynamicRouter.ThenFunc(app.search))
 	router.Handler("GET", "/user/signup", dynamicRouter.ThenFunc(app.userSignup))
 	router.Handler("POST", "/user/signup", dynamicRouter.ThenFunc(app.userSignupPost))
 	router.Handler("GET", "/user/login", dynamicRouter.ThenFunc(app.userLogin))
@@ -54,13 +57,19 @@ func (app *application) routes() http.Handler {
 	router.Handler("GET", "/quote/edit/:id", protected.ThenFunc(app.quoteEdit))
 	router.Handler("POST", "/quote/edit/:id", protected.ThenFunc(app.quoteEditPost))
 	router.Handler("POST", "/quote/delete/:id", protected.ThenFunc(app.quoteDeletePost))
-	//router.Handler("GET", "/author/create", protected.ThenFunc(app.authorCreate))
-	//router.Handler("POST", "/author/create", protected.ThenFunc(app.authorCreatePost))
+	router.Handler("POST", "/quote/share/:id", protected.ThenFunc(app.quoteSharePost))
+	router.Handler("POST", "/quote/unshare/:id", protected.ThenFunc(app.quoteUnsharePost))
 	router.Handler("GET", "/book/create", protected.ThenFunc(app.bookCreate))
 	router.Handler("POST", "/book/create", protected.ThenFunc(app.bookCreatePost))
+	router.Handler("GET", "/book/lookup", protected.ThenFunc(app.bookLookup))
 	router.Handler("GET", "/book/edit/:id", protected.ThenFunc(app.bookEdit))
 	router.Handler("POST", "/book/edit/:id", protected.ThenFunc(app.bookEditPost))
 	router.Handler("POST", "/book/delete/:id", protected.ThenFunc(app.bookDeletePost))
+	router.Handler("GET", "/book/edition/:id", protected.ThenFunc(app.editionCreate))
+	router.Handler("POST", "/book/edition/:id", protected.ThenFunc(app.editionCreatePost))
+	router.Handler("GET", "/edition/edit/:id", protected.ThenFunc(app.editionEdit))
+	router.Handler("POST", "/edition/edit/:id", protected.ThenFunc(app.editionEditPost))
+	router.Handler("POST", "/edition/delete/:id", protected.ThenFunc(app.editionDeletePost))
 	router.Handler("POST", "/user/logout", protected.ThenFunc(app.userLogout))
 	router.Handler("GET", "/user/profile/edit", protected.ThenFunc(app.userEditProfile))
 	router.Handler("POST", "/user/profile/edit", protected.ThenFunc(app.userEditProfilePost))
@@ -68,6 +77,22 @@ func (app *application) routes() http.Handler {
 	router.Handler("POST", "/user/profile/change-password", protected.ThenFunc(app.userChangePasswordPost))
 	router.Handler("GET", "/user/profile/view/:urlName", protected.ThenFunc(app.userProfileView))
 
+	// Create middleware chains for routes only moderators and admins, or only admins, can use
+	moderator := protected.Append(app.requireRole(models.RoleModerator))
+	admin := protected.Append(app.requireRole(models.RoleAdmin))
+
+	// Register the moderator app routes, which add and edit authors
+	router.Handler("GET", "/author/create", moderator.ThenFunc(app.authorCreate))
+	router.Handler("POST", "/author/create", moderator.ThenFunc(app.authorCreatePost))
+	router.Handler("GET", "/author/edit/:id", moderator.ThenFunc(app.authorEdit))
+	router.Handler("POST", "/author/edit/:id", moderator.ThenFunc(app.authorEditPost))
+	router.Handler("POST", "/author/alias/:id", moderator.ThenFunc(app.authorAliasPost))
+	router.Handler("POST", "/author/unalias/:id", moderator.ThenFunc(app.authorUnaliasPost))
+
+	// Register the admin app routes, which remove authors
+	router.Handler("POST", "/author/merge/:id", admin.ThenFunc(app.authorMergePost))
+	router.Handler("POST", "/author/delete/:id", admin.ThenFunc(app.authorDeletePost))
+
 	// Create middleware chain with standard middleware for every request
 	standard := alice.New(app.recoverPanic, app.logRequest, commonHeaders)
 
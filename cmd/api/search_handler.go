@@ -0,0 +1,112 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/justinbachtell/quote-table-go/internal/models"
+	"github.com/justinbachtell/quote-table-go/internal/validator"
+)
+
+// Struct to represent the search form data
+type searchForm struct {
+	Query               string `form:"q"`
+	AuthorID            int    `form:"author"`
+	BookID              int    `form:"book"`
+	YearFrom            int    `form:"year_from"`
+	YearTo              int    `form:"year_to"`
+	Scope               string `form:"scope"`
+	validator.Validator `form:"-"`
+}
+
+// Handler for the search page
+func (app *application) search(w http.ResponseWriter, r *http.Request) {
+	// Read the page and sort order from the query string
+	filters, err := app.readFilters(r, models.SearchSorts)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	// Decode the search form from the query string
+	var form searchForm
+	err = app.formDecoder.Decode(&form, r.URL.Query())
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+	if form.Scope == "" {
+		form.Scope = models.ScopeAll
+	}
+
+	// Get all authors and books for the filters
+	authors, err := app.authors.GetAllWithCounts(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	books, err := app.books.GetAll(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Authors = authors
+	data.Books = books
+	data.Filters = filters
+	data.Sorts = models.SearchSorts
+
+	// Show the empty form until something is searched for
+	if !r.URL.Query().Has("q") {
+		data.Form = form
+		app.render(w, r, http.StatusOK, "search.go.tmpl", data)
+		return
+	}
+
+	// Parse the query language in the search box, showing any mistake under it
+	query, err := models.ParseSearch(form.Query)
+	if err != nil {
+		form.AddFieldError("q", "There is a mistake in the search: "+err.Error())
+	}
+
+	// The filters chosen in the form apply unless the query sets its own
+	query.AuthorID = form.AuthorID
+	query.BookID = form.BookID
+	if query.YearFrom == 0 && query.YearTo == 0 {
+		query.YearFrom, query.YearTo = form.YearFrom, form.YearTo
+	}
+	ownScope := query.Scope == models.ScopeMine || query.Scope == models.ScopeUnlisted || query.Scope == models.ScopePrivate
+	if query.Scope == "" {
+		query.Scope = form.Scope
+	}
+	query.UserID = app.contextGetUserID(r)
+
+	// Validate the form
+	form.CheckField(validator.NotBlank(form.Query), "q", "Enter something to search for")
+	form.CheckField(validator.MaxChars(form.Query, 200), "q", "This field cannot be more than 200 characters long")
+	form.CheckField(validator.PermittedValue(form.Scope, models.SearchScopes...), "scope", "This field must be all, public or mine")
+	form.CheckField(form.Scope != models.ScopeMine || data.IsAuthenticated, "scope", "Log in to search your own quotes")
+	form.CheckField(!ownScope || data.IsAuthenticated, "q", "Log in to search your own quotes")
+	form.CheckField(form.YearFrom == 0 || form.YearTo == 0 || form.YearFrom <= form.YearTo, "year_to", "This year cannot be before the from year")
+
+	// If there are any errors, redisplay the search page with the errors
+	if !form.ValidField() {
+		data.Form = form
+		app.render(w, r, http.StatusUnprocessableEntity, "search.go.tmpl", data)
+		return
+	}
+
+	// Search the quotes the user can see
+	results, metadata, err := app.quotes.Search(r.Context(), query, filters)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data.Form = form
+	data.Results = results
+	data.Metadata = metadata
+
+	app.render(w, r, http.StatusOK, "search.go.tmpl", data)
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/justinbachtell/quote-table-go/internal/migrate"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+	"github.com/justinbachtell/quote-table-go/internal/models/memory"
+	"github.com/justinbachtell/quote-table-go/internal/models/postgres"
+	"github.com/justinbachtell/quote-table-go/internal/models/sqlite"
+
+	"github.com/alexedwards/scs/postgresstore"
+	"github.com/alexedwards/scs/sqlite3store"
+	"github.com/alexedwards/scs/v2"
+	"github.com/alexedwards/scs/v2/memstore"
+	"github.com/supabase-community/postgrest-go"
+)
+
+// Define the storage backends that can be selected with the -storage flag
+const (
+	storageSupabase = "supabase"
+	storagePostgres = "postgres"
+	storageSQLite   = "sqlite"
+	storageMemory   = "memory"
+)
+
+// The database file used by sqlite storage when no -db-dsn is given
+const defaultSQLitePath = "./quote-table.db"
+
+// Define a struct to hold the models and session store of a storage backend
+type storage struct {
+	quotes       models.QuoteModelInterface
+	authors      models.AuthorModelInterface
+	books        models.BookModelInterface
+	editions     models.EditionModelInterface
+	users        models.UserModelInterface
+	sessionStore scs.Store
+	client       *postgrest.Client
+	authClient   *postgrest.Client
+	db           *sql.DB
+}
+
+// Opens the storage backend selected in the config
+func openStorage(logger *slog.Logger, cfg config) (*storage, error) {
+	timeouts := models.Timeouts{Read: cfg.db.readTimeout, Write: cfg.db.writeTimeout}
+
+	switch cfg.storage {
+	case storageSupabase:
+		return openSupabaseStorage(logger, timeouts)
+	case storagePostgres:
+		return openPostgresStorage(logger, cfg.db.dsn, timeouts)
+	case storageSQLite:
+		return openSQLiteStorage(logger, cfg.db.dsn, timeouts)
+	case storageMemory:
+		return openMemoryStorage(logger)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.storage)
+	}
+}
+
+// Opens the Supabase storage backend, which goes through the PostgREST api
+func openSupabaseStorage(logger *slog.Logger, timeouts models.Timeouts) (*storage, error) {
+	// Log the environment variables to check if they are loaded correctly
+	supabaseURL := os.Getenv("SUPABASE_URL")
+	supabaseKey := os.Getenv("SUPABASE_PUBLIC_KEY")
+	supabaseSecretKey := os.Getenv("SUPABASE_SECRET_KEY")
+	supabaseURIString := os.Getenv("SUPABASE_URI_STRING")
+
+	// Check if the environment variables are set
+	if supabaseURL == "" || supabaseKey == "" || supabaseSecretKey == "" {
+		return nil, errors.New("database environment variables are not set")
+	}
+
+	// Initialize database connection for the session store
+	db, err := sql.Open("postgres", supabaseURIString)
+	if err != nil {
+		logger.Error("error connecting to the database for initialization")
+		return nil, err
+	}
+	logger.Info("connected to database for initialization")
+
+	// Initialize supabase client
+	client, authClient, err := connectSupabase(logger, supabaseURL, supabaseKey, supabaseSecretKey, timeouts)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	logger.Info("connected to database for rest and auth api")
+
+	return &storage{
+		quotes:       &models.QuoteModel{Client: client, AuthClient: authClient, Timeouts: timeouts},
+		authors:      &models.AuthorModel{Client: client, Timeouts: timeouts},
+		books:        &models.BookModel{Client: client, Timeouts: timeouts},
+		editions:     &models.EditionModel{Client: client, Timeouts: timeouts},
+		users:        &models.UserModel{Client: client, AuthClient: authClient, Timeouts: timeouts},
+		sessionStore: postgresstore.New(db),
+		client:       client,
+		authClient:   authClient,
+		db:           db,
+	}, nil
+}
+
+// Opens the native PostgreSQL storage backend, which queries the database directly
+func openPostgresStorage(logger *slog.Logger, dsn string, timeouts models.Timeouts) (*storage, error) {
+	if dsn == "" {
+		return nil, errors.New("the -db-dsn flag or DATABASE_URL environment variable must be set for postgres storage")
+	}
+
+	// Initialize database connection
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the database is reachable before serving requests
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	logger.Info("connected to postgres database")
+
+	return &storage{
+		quotes:       &postgres.QuoteModel{DB: db, Timeouts: timeouts},
+		authors:      &postgres.AuthorModel{DB: db, Timeouts: timeouts},
+		books:        &postgres.BookModel{DB: db, Timeouts: timeouts},
+		editions:     &postgres.EditionModel{DB: db, Timeouts: timeouts},
+		users:        &postgres.UserModel{DB: db, Timeouts: timeouts},
+		sessionStore: postgresstore.New(db),
+		db:           db,
+	}, nil
+}
+
+// Opens the embedded SQLite storage backend and applies any pending migrations
+func openSQLiteStorage(logger *slog.Logger, path string, timeouts models.Timeouts) (*storage, error) {
+	db, files, err := openMigrations(storageSQLite, path)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("opened sqlite database", slog.String("path", path))
+
+	// Bring the schema up to date so a new database file works out of the box
+	migrator, err := migrate.New(db, files)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if len(applied) > 0 {
+		logger.Info("applied sqlite migrations", slog.Int("count", len(applied)))
+	}
+
+	return &storage{
+		quotes:       &sqlite.QuoteModel{DB: db, Timeouts: timeouts},
+		authors:      &sqlite.AuthorModel{DB: db, Timeouts: timeouts},
+		books:        &sqlite.BookModel{DB: db, Timeouts: timeouts},
+		editions:     &sqlite.EditionModel{DB: db, Timeouts: timeouts},
+		users:        &sqlite.UserModel{DB: db, Timeouts: timeouts},
+		sessionStore: sqlite3store.New(db),
+		db:           db,
+	}, nil
+}
+
+// Opens the in-memory demo storage backend, seeded with a demo user and quotes
+func openMemoryStorage(logger *slog.Logger) (*storage, error) {
+	db := memory.New()
+
+	err := memory.SeedDemo(context.Background(), db)
+	if err != nil {
+		return nil, err
+	}
+	logger.Warn("using in-memory demo storage; nothing will be saved",
+		slog.String("email", memory.DemoEmail), slog.String("password", memory.DemoPassword))
+
+	return &storage{
+		quotes:       &memory.QuoteModel{DB: db},
+		authors:      &memory.AuthorModel{DB: db},
+		books:        &memory.BookModel{DB: db},
+		editions:     &memory.EditionModel{DB: db},
+		users:        &memory.UserModel{DB: db},
+		sessionStore: memstore.New(),
+	}, nil
+}
+
+// Closes the database connection of the storage backend
+func (s *storage) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
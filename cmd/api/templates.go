@@ -3,7 +3,9 @@ package main
 import (
 	"html/template"
 	"io/fs"
+	"net/url"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,14 +20,38 @@ type templateData struct {
     Quotes      []models.Quote
 	Author      models.Author
     Authors     []models.AuthorWithCounts
+	// The roles offered for a book's contributors
+	ContributorRoles []models.ContributorRole
+	// The media types offered for a book
+	MediaTypes  []models.MediaType
+	// Whether books can be looked up by ISBN
+	ISBNLookup  bool
 	Book        models.Book
 	Books       []models.Book
+	// The edition being edited, or picked to show the quotes of on the book page
+	Edition     models.Edition
+	Editions    []models.Edition
     User        *models.User
     Form        any
     Flash       string
     IsAuthenticated bool
     CSRFToken   string
     AuthenticatedUserID uuid.UUID
+	// Whether the user is a moderator or admin, who can add and edit authors
+	IsModerator bool
+	// Whether the user is an admin, who can also merge and delete authors
+	IsAdmin     bool
+	// Whether the user can edit and delete the quote or book being viewed
+	CanModify   bool
+	// The IDs of the editions on the book page the user can edit and delete
+	CanModifyEditions map[int]bool
+	// The share link of the quote being edited, if it has one
+	ShareURL    string
+	Filters     models.Filters
+	Metadata    models.Metadata
+	Sorts       []string
+	Query       url.Values
+	Results     []models.SearchResult
 }
 
 // Format the dates to be human readable
@@ -39,9 +65,64 @@ func humanDate(t time.Time) string {
 	return t.UTC().Format("02 Jan 2006 at 15:04")
 }
 
+// The labels shown for the sort orders in the pager
+var sortLabels = map[string]string{
+	models.SortNewest:     "Newest",
+	models.SortOldest:     "Oldest",
+	models.SortTitle:      "Title",
+	models.SortAuthor:     "Author",
+	models.SortMostQuoted: "Most quoted",
+}
+
+// Return the label for a sort order
+func sortLabel(sort string) string {
+	if label, ok := sortLabels[sort]; ok {
+		return label
+	}
+	return sort
+}
+
+// Return the link to a page of a listing in the given order, keeping the rest of the query string
+func pageURL(query url.Values, page int, sort string) string {
+	values := url.Values{}
+	for key, value := range query {
+		values[key] = value
+	}
+	values.Set("page", strconv.Itoa(page))
+	values.Set("sort", sort)
+
+	return "?" + values.Encode()
+}
+
+// Return the editions of a book, for grouping them under it
+func editionsOf(editions []models.Edition, bookID int) []models.Edition {
+	var of []models.Edition
+	for _, e := range editions {
+		if e.BookID == bookID {
+			of = append(of, e)
+		}
+	}
+	return of
+}
+
+// Return the edition with the ID, or the zero edition if there is none
+func findEdition(editions []models.Edition, id int) models.Edition {
+	for _, e := range editions {
+		if e.ID == id {
+			return e
+		}
+	}
+	return models.Edition{}
+}
+
 // Initialize a function map object to store a key/value of functions
 var functions = template.FuncMap{
-	"humanDate": humanDate,
+	"humanDate":   humanDate,
+	"sortLabel":   sortLabel,
+	"add":         func(a, b int) int { return a + b },
+	"pageURL":     pageURL,
+	"editionsOf":  editionsOf,
+	"findEdition": findEdition,
 }
 
 // Parses all the templates and caches them
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"net/url"
 	"testing"
 	"time"
 
@@ -48,4 +49,15 @@ func TestHumanDate(t *testing.T) {
 			assert.Equal(t, hd, tt.want)
 		})
 	}
-}
\ No newline at end of file
+}
+
+// Test the pageURL function
+func TestPageURL(t *testing.T) {
+	query := url.Values{"q": {"amor fati"}, "page": {"3"}, "sort": {"oldest"}}
+
+	// Check the page and sort order are replaced and the rest of the query is kept
+	assert.Equal(t, pageURL(query, 4, "newest"), "?page=4&q=amor+fati&sort=newest")
+
+	// Check the query itself is left alone
+	assert.Equal(t, query.Get("page"), "3")
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"html"
 	"io"
 	"log/slog"
@@ -13,13 +14,15 @@ import (
 	"testing"
 	"time"
 
-	"github.com/justinbachtell/quote-table-go/internal/models/mocks"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+	"github.com/justinbachtell/quote-table-go/internal/models/memory"
 
 	"github.com/alexedwards/scs/v2"
+	"github.com/google/uuid"
 	"github.com/go-playground/form/v4"
 )
 
-// Create a new application struct with mocked dependencies
+// Create a new application struct backed by an in-memory database holding the test data
 func newTestApplication(t *testing.T) *application {
 	// Create an instance of the template cache
 	templateCache, err := newTemplateCache()
@@ -35,18 +38,64 @@ func newTestApplication(t *testing.T) *application {
 	sessionManager.Lifetime = 12 * time.Hour
 	sessionManager.Cookie.Secure = true
 
+	// Create an instance of the in-memory database and add the test data
+	db := memory.New()
+	seedTestData(t, db)
+
 	return &application{
 		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
-		quotes: &mocks.QuoteModel{},
-		authors: &mocks.AuthorModel{},
-		books: &mocks.BookModel{},
-		users: &mocks.UserModel{},
+		quotes: &memory.QuoteModel{DB: db},
+		authors: &memory.AuthorModel{DB: db},
+		books: &memory.BookModel{DB: db},
+		editions: &memory.EditionModel{DB: db},
+		users: &memory.UserModel{DB: db},
 		templateCache: templateCache,
 		formDecoder: formDecoder,
 		sessionManager: sessionManager,
 	}
 }
 
+// Adds a user with the email duplicate@example.com and a quote with ID 1 to the database
+func seedTestData(t *testing.T, db *memory.DB) {
+	ctx := context.Background()
+
+	userID, err := (&memory.UserModel{DB: db}).Insert(ctx, "Jane Doe", "duplicate@example.com", "pa$$word")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authorID, err := (&memory.AuthorModel{DB: db}).Insert(ctx, "William Shakespeare", models.AuthorDetails{}, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bookID, err := (&memory.BookModel{DB: db}).Insert(ctx, "Hamlet", 1603, "A.D.", "9780743477123", "", models.WorkDetails{MediaType: models.MediaBook}, []models.BookContributor{{AuthorID: authorID, Role: models.ContributorAuthor}}, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = (&memory.QuoteModel{DB: db}).Insert(ctx, "To be or not to be, that is the question.", authorID, bookID, 0, models.Location{Type: models.LocationPage, Start: 1}, models.VisibilityPublic, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Adds a user with the given role to the application's database
+func insertUserWithRole(t *testing.T, app *application, name, email, password string, role models.Role) uuid.UUID {
+	t.Helper()
+
+	id, err := app.users.Insert(context.Background(), name, email, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = app.users.SetRole(context.Background(), id, role)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return id
+}
+
 // Define a custom test server struct that embeds a httptest.Server instance
 type testServer struct {
 	*httptest.Server
@@ -126,4 +175,21 @@ func (ts *testServer) postForm(t *testing.T, urlPath string, formValues url.Valu
 
 	// Return the status code, headers, and body
 	return rs.StatusCode, rs.Header, string(body)
-}
\ No newline at end of file
+}
+
+// Logs in to the test server and returns a CSRF token to post forms with
+func (ts *testServer) login(t *testing.T, email, password string) string {
+	_, _, body := ts.get(t, "/user/login")
+	csrfToken := extractCSRFToken(t, body)
+
+	code, _, _ := ts.postForm(t, "/user/login", url.Values{
+		"email":      {email},
+		"password":   {password},
+		"csrf_token": {csrfToken},
+	})
+	if code != http.StatusSeeOther {
+		t.Fatalf("logging in as %s: got status %d; want %d", email, code, http.StatusSeeOther)
+	}
+
+	return csrfToken
+}
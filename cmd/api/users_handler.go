@@ -51,7 +51,7 @@ func (app *application) userSignupPost(w http.ResponseWriter, r *http.Request) {
     }
 
 	// Pass the form data to the users model to insert the user
-    _, err = app.users.Insert(form.Name, form.Email, form.Password)
+    _, err = app.users.Insert(r.Context(), form.Name, form.Email, form.Password)
     if err != nil {
         app.logger.Error("Failed to insert user", "error", err)
         switch {
@@ -110,7 +110,7 @@ func (app *application) userLoginPost(w http.ResponseWriter, r *http.Request) {
     }
 
     // Check if the credentials are valid
-    id, err := app.users.Authenticate(form.Email, form.Password)
+    id, err := app.users.Authenticate(r.Context(), form.Email, form.Password)
     if err != nil {
         if errors.Is(err, models.ErrInvalidCredentials) {
             form.AddNonFieldError("Authentication failed. Please check your credentials and try again.")
@@ -137,7 +137,7 @@ func (app *application) userLoginPost(w http.ResponseWriter, r *http.Request) {
     app.logger.Info("User logged in", "userID", id)
 
 	// Update the user's last signed in at timestamp
-	err = app.users.UpdateLastSignedInAt(id)
+	err = app.users.UpdateLastSignedInAt(r.Context(), id)
 	if err != nil {
 		app.serverError(w, r, err)
 		return
@@ -176,7 +176,7 @@ func (app *application) userProfileView(w http.ResponseWriter, r *http.Request)
     }
 
     // Fetch the user profile
-    user, err := app.users.GetByURLName(urlName)
+    user, err := app.users.GetByURLName(r.Context(), urlName)
     if err != nil {
         if errors.Is(err, models.ErrNoRecord) {
             http.NotFound(w, r)
@@ -200,7 +200,7 @@ func (app *application) userEditProfile(w http.ResponseWriter, r *http.Request)
     data := app.newTemplateData(r)
 
     // Fetch the authenticated user
-    user, err := app.users.Get(data.AuthenticatedUserID)
+    user, err := app.users.Get(r.Context(), data.AuthenticatedUserID)
     if err != nil {
         app.serverError(w, r, err)
         return
@@ -264,7 +264,7 @@ func (app *application) userEditProfilePost(w http.ResponseWriter, r *http.Reque
         return
     }
 
-    err = app.users.Update(data.User.ID, form.Name, form.Email, form.Phone)
+    err = app.users.Update(r.Context(), data.User.ID, form.Name, form.Email, form.Phone)
     if err != nil {
         app.serverError(w, r, err)
         return
@@ -294,7 +294,7 @@ func (app *application) userChangePassword(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	user, err := app.users.Get(userID)
+	user, err := app.users.Get(r.Context(), userID)
 	if err != nil {
 		app.serverError(w, r, err)
 		return
@@ -347,7 +347,7 @@ func (app *application) userChangePasswordPost(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-    err = app.users.ChangePassword(userID, form.CurrentPassword, form.NewPassword)
+    err = app.users.ChangePassword(r.Context(), userID, form.CurrentPassword, form.NewPassword)
     if err != nil {
         if errors.Is(err, models.ErrInvalidCredentials) {
             form.AddFieldError("currentPassword", "Current password is incorrect")
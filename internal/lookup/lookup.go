@@ -0,0 +1,144 @@
+// Package lookup finds a book's details from its ISBN.
+//
+// A Provider looks books up, such as OpenLibrary, which asks a server that
+// speaks the Open Library books API. Wrapping a provider in a Cache keeps its
+// answers so the same ISBN isn't looked up again while it is fresh, and
+// shares a lookup between the callers asking for the same ISBN at once.
+package lookup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned when the provider has no book with the ISBN
+var ErrNotFound = errors.New("lookup: no book found")
+
+// Book is what a provider knows about the edition with an ISBN. Fields the
+// provider doesn't know are left empty.
+type Book struct {
+	ISBN        string
+	Title       string
+	Authors     []string
+	Publisher   string
+	PublishYear int
+	PageCount   int
+	// The provider's page for the book
+	URL string
+}
+
+// Provider looks up a book by its ISBN-13, returning ErrNotFound when it has
+// no book with the ISBN
+type Provider interface {
+	LookupISBN(ctx context.Context, isbn string) (Book, error)
+}
+
+// Cache keeps the books a provider has found, and the ISBNs it has no book
+// for, for TTL. Other errors aren't kept, so the lookup is tried again. When
+// MaxEntries is set and the cache holds that many ISBNs, the oldest is dropped
+// to make room for each new one.
+type Cache struct {
+	Provider   Provider
+	TTL        time.Duration
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	// The lookups in flight, keyed by ISBN
+	calls map[string]*call
+	// Returns the current time, replaced in tests
+	now func() time.Time
+}
+
+// A lookup in flight. Its book and error are set before done is closed.
+type call struct {
+	done chan struct{}
+	book Book
+	err  error
+}
+
+// A cached lookup and when it was made
+type cacheEntry struct {
+	book     Book
+	err      error
+	storedAt time.Time
+}
+
+// NewCache returns a cache in front of the provider
+func NewCache(provider Provider, ttl time.Duration, maxEntries int) *Cache {
+	return &Cache{
+		Provider:   provider,
+		TTL:        ttl,
+		MaxEntries: maxEntries,
+		entries:    make(map[string]cacheEntry),
+		calls:      make(map[string]*call),
+		now:        time.Now,
+	}
+}
+
+// LookupISBN returns the cached lookup of the ISBN while it is fresh, and
+// otherwise asks the provider. Callers missing the cache for the same ISBN at
+// the same time wait on a single lookup, each until their own context is done.
+func (c *Cache) LookupISBN(ctx context.Context, isbn string) (Book, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[isbn]
+	if ok && c.now().Sub(entry.storedAt) < c.TTL {
+		c.mu.Unlock()
+		return entry.book, entry.err
+	}
+
+	// Join the lookup in flight for the ISBN, or start one
+	cl, ok := c.calls[isbn]
+	if !ok {
+		cl = &call{done: make(chan struct{})}
+		c.calls[isbn] = cl
+		go c.lookup(context.WithoutCancel(ctx), isbn, cl)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.book, cl.err
+	case <-ctx.Done():
+		return Book{}, ctx.Err()
+	}
+}
+
+// Asks the provider for the ISBN on behalf of every caller waiting on the
+// call, and keeps its answer. The lookup outlives the request that started it,
+// which others may be waiting on, so only the provider's own timeout ends it.
+func (c *Cache) lookup(ctx context.Context, isbn string, cl *call) {
+	book, err := c.Provider.LookupISBN(ctx, isbn)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.calls, isbn)
+
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		cl.err = err
+		close(cl.done)
+		return
+	}
+
+	if _, ok := c.entries[isbn]; !ok && c.MaxEntries > 0 && len(c.entries) >= c.MaxEntries {
+		c.evictOldest()
+	}
+	c.entries[isbn] = cacheEntry{book: book, err: err, storedAt: c.now()}
+
+	cl.book, cl.err = book, err
+	close(cl.done)
+}
+
+// Drops the entry that was stored first. The caller must hold the lock.
+func (c *Cache) evictOldest() {
+	var oldest string
+	var oldestAt time.Time
+	for isbn, entry := range c.entries {
+		if oldest == "" || entry.storedAt.Before(oldestAt) {
+			oldest, oldestAt = isbn, entry.storedAt
+		}
+	}
+	delete(c.entries, oldest)
+}
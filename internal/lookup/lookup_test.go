@@ -0,0 +1,118 @@
+package lookup
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+)
+
+// A provider that counts its lookups
+type countingProvider struct {
+	Provider
+	lookups int
+}
+
+func (p *countingProvider) LookupISBN(ctx context.Context, isbn string) (Book, error) {
+	p.lookups++
+	return p.Provider.LookupISBN(ctx, isbn)
+}
+
+func TestCache(t *testing.T) {
+	ts := newStubOpenLibrary(t)
+	provider := &countingProvider{Provider: NewOpenLibrary(ts.URL, time.Second)}
+	c := NewCache(provider, time.Hour, 2)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	lookup := func(isbn string) error {
+		_, err := c.LookupISBN(ctx, isbn)
+		return err
+	}
+
+	// Check found books and missing ISBNs are only looked up once while fresh
+	assert.NilError(t, lookup("9780140449334"))
+	assert.NilError(t, lookup("9780140449334"))
+	assert.Equal(t, lookup("9780306406157"), ErrNotFound)
+	assert.Equal(t, lookup("9780306406157"), ErrNotFound)
+	assert.Equal(t, provider.lookups, 2)
+
+	// Check failed lookups are tried again
+	lookup("9780000000019")
+	lookup("9780000000019")
+	assert.Equal(t, provider.lookups, 4)
+
+	// Check lookups are made again once they are stale
+	now = now.Add(time.Hour)
+	assert.NilError(t, lookup("9780140449334"))
+	assert.Equal(t, provider.lookups, 5)
+
+	// Check the oldest lookup is dropped to keep to the maximum size
+	now = now.Add(time.Minute)
+	assert.Equal(t, lookup("9780143036326"), ErrNotFound)
+	assert.Equal(t, len(c.entries), 2)
+	assert.NilError(t, lookup("9780140449334"))
+	assert.Equal(t, lookup("9780306406157"), ErrNotFound)
+	assert.Equal(t, provider.lookups, 7)
+}
+
+// A provider that counts its lookups and holds each one until it is released
+type blockingProvider struct {
+	lookups atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingProvider) LookupISBN(ctx context.Context, isbn string) (Book, error) {
+	if p.lookups.Add(1) == 1 {
+		close(p.started)
+	}
+	<-p.release
+	return Book{ISBN: isbn, Title: "Meditations"}, nil
+}
+
+func TestCacheConcurrentLookups(t *testing.T) {
+	provider := &blockingProvider{started: make(chan struct{}), release: make(chan struct{})}
+	c := NewCache(provider, time.Hour, 0)
+	ctx := context.Background()
+
+	// Look the same ISBN up from several requests while the first lookup is held
+	var wg sync.WaitGroup
+	titles := make([]string, 5)
+	for i := range titles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			book, err := c.LookupISBN(ctx, "9780140449334")
+			if err != nil {
+				t.Errorf("Error in LookupISBN method: %v", err)
+			}
+			titles[i] = book.Title
+		}()
+	}
+
+	// Check a request that gives up stops waiting without ending the lookup
+	<-provider.started
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := c.LookupISBN(cancelled, "9780140449334")
+	assert.Equal(t, err, context.Canceled)
+
+	// Check every request gets the book from a single lookup
+	time.Sleep(20 * time.Millisecond)
+	close(provider.release)
+	wg.Wait()
+	for _, title := range titles {
+		assert.Equal(t, title, "Meditations")
+	}
+	assert.Equal(t, provider.lookups.Load(), int32(1))
+
+	// Check the shared lookup was cached
+	_, err = c.LookupISBN(ctx, "9780140449334")
+	assert.NilError(t, err)
+	assert.Equal(t, provider.lookups.Load(), int32(1))
+}
@@ -0,0 +1,102 @@
+package lookup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultOpenLibraryURL is the address of Open Library itself
+const DefaultOpenLibraryURL = "https://openlibrary.org"
+
+// OpenLibrary looks books up with the Open Library books API, at BaseURL
+type OpenLibrary struct {
+	BaseURL   string
+	Client    *http.Client
+	UserAgent string
+}
+
+// NewOpenLibrary returns a provider that asks the Open Library compatible
+// server at baseURL, giving up on a lookup after the timeout
+func NewOpenLibrary(baseURL string, timeout time.Duration) *OpenLibrary {
+	return &OpenLibrary{
+		BaseURL:   strings.TrimSuffix(baseURL, "/"),
+		Client:    &http.Client{Timeout: timeout},
+		UserAgent: "quote-table-go",
+	}
+}
+
+// The parts of a book in the books API's response that are used
+type openLibraryBook struct {
+	URL     string `json:"url"`
+	Title   string `json:"title"`
+	Authors []struct {
+		Name string `json:"name"`
+	} `json:"authors"`
+	Publishers []struct {
+		Name string `json:"name"`
+	} `json:"publishers"`
+	PublishDate   string `json:"publish_date"`
+	NumberOfPages int    `json:"number_of_pages"`
+}
+
+// The year in a publish date such as "2006" or "March 1, 2006"
+var publishYearRX = regexp.MustCompile(`[0-9]{4}`)
+
+// LookupISBN asks the books API for the book with the ISBN
+func (o *OpenLibrary) LookupISBN(ctx context.Context, isbn string) (Book, error) {
+	key := "ISBN:" + isbn
+	query := url.Values{"bibkeys": {key}, "format": {"json"}, "jscmd": {"data"}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.BaseURL+"/api/books?"+query.Encode(), nil)
+	if err != nil {
+		return Book{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", o.UserAgent)
+
+	resp, err := o.Client.Do(req)
+	if err != nil {
+		return Book{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Book{}, fmt.Errorf("lookup: open library responded %s", resp.Status)
+	}
+
+	// The response has the book under its key, and is empty when there is none
+	var books map[string]openLibraryBook
+	err = json.NewDecoder(resp.Body).Decode(&books)
+	if err != nil {
+		return Book{}, fmt.Errorf("lookup: decoding open library response: %w", err)
+	}
+	found, ok := books[key]
+	if !ok || found.Title == "" {
+		return Book{}, ErrNotFound
+	}
+
+	book := Book{
+		ISBN:      isbn,
+		Title:     found.Title,
+		PageCount: found.NumberOfPages,
+		URL:       found.URL,
+	}
+	for _, author := range found.Authors {
+		book.Authors = append(book.Authors, author.Name)
+	}
+	if len(found.Publishers) > 0 {
+		book.Publisher = found.Publishers[0].Name
+	}
+	if year := publishYearRX.FindString(found.PublishDate); year != "" {
+		book.PublishYear, _ = strconv.Atoi(year)
+	}
+
+	return book, nil
+}
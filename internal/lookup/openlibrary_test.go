@@ -0,0 +1,72 @@
+package lookup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+)
+
+// Starts a stub of the Open Library books API that knows one book, and
+// fails with a server error for the ISBN 9780000000019
+func newStubOpenLibrary(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/books" || r.URL.Query().Get("jscmd") != "data" || r.URL.Query().Get("format") != "json" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("bibkeys") {
+		case "ISBN:9780140449334":
+			w.Write([]byte(`{"ISBN:9780140449334": {
+				"url": "https://openlibrary.org/books/OL7353617M/Meditations",
+				"title": "Meditations",
+				"authors": [{"url": "https://openlibrary.org/authors/OL22055A", "name": "Marcus Aurelius"}],
+				"publishers": [{"name": "Penguin Classics"}],
+				"publish_date": "April 27, 2006",
+				"number_of_pages": 304
+			}}`))
+		case "ISBN:9780000000019":
+			http.Error(w, "upstream unavailable", http.StatusBadGateway)
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func TestOpenLibraryLookupISBN(t *testing.T) {
+	ts := newStubOpenLibrary(t)
+	o := NewOpenLibrary(ts.URL+"/", time.Second)
+	ctx := context.Background()
+
+	// Check a known book is read from the response
+	book, err := o.LookupISBN(ctx, "9780140449334")
+	assert.NilError(t, err)
+	assert.Equal(t, book.ISBN, "9780140449334")
+	assert.Equal(t, book.Title, "Meditations")
+	assert.Equal(t, len(book.Authors), 1)
+	assert.Equal(t, book.Authors[0], "Marcus Aurelius")
+	assert.Equal(t, book.Publisher, "Penguin Classics")
+	assert.Equal(t, book.PublishYear, 2006)
+	assert.Equal(t, book.PageCount, 304)
+	assert.Equal(t, book.URL, "https://openlibrary.org/books/OL7353617M/Meditations")
+
+	// Check an empty response is no book, and a failed request an error
+	_, err = o.LookupISBN(ctx, "9780306406157")
+	assert.Equal(t, err, ErrNotFound)
+
+	_, err = o.LookupISBN(ctx, "9780000000019")
+	if err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v, want the server error", err)
+	}
+}
@@ -0,0 +1,270 @@
+// Package migrate applies and rolls back versioned SQL schema migrations.
+//
+// Migrations are pairs of files named <version>_<name>.up.sql and
+// <version>_<name>.down.sql, read from an fs.FS such as the embedded
+// migrations.Files. The versions that have been applied are recorded in the
+// schema_migrations table of the database.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoChange is returned by Down when there are no applied migrations to roll back
+var ErrNoChange = errors.New("migrate: no change")
+
+// Migration is a single versioned change to the schema
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration and the time it was applied, if it has been
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Load reads the migrations in the root of fsys, ordered by version
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the up and down scripts of each version
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		version, name, direction, err := parseFilename(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		script, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("migrate: version %d has two names, %q and %q", version, m.Name, name)
+		}
+
+		if direction == "up" {
+			m.Up = string(script)
+		} else {
+			m.Down = string(script)
+		}
+	}
+
+	// Check that every migration can be applied and rolled back
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migrate: version %d is missing its up or down script", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Splits a filename such as 0001_create_users.up.sql into its parts
+func parseFilename(filename string) (int64, string, string, error) {
+	base := strings.TrimSuffix(filename, ".sql")
+
+	// Split off the direction
+	var direction string
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		direction = "up"
+	case strings.HasSuffix(base, ".down"):
+		direction = "down"
+	default:
+		return 0, "", "", fmt.Errorf("migrate: %s must end in .up.sql or .down.sql", filename)
+	}
+	base = strings.TrimSuffix(base, "."+direction)
+
+	// Split the version from the name
+	versionPart, name, ok := strings.Cut(base, "_")
+	if !ok || name == "" {
+		return 0, "", "", fmt.Errorf("migrate: %s must be named <version>_<name>.%s.sql", filename, direction)
+	}
+
+	version, err := strconv.ParseInt(versionPart, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", "", fmt.Errorf("migrate: %s has an invalid version", filename)
+	}
+
+	return version, name, direction, nil
+}
+
+// Migrator applies a set of migrations to a database
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []Migration
+}
+
+// New loads the migrations in fsys and returns a migrator for db
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{DB: db, Migrations: migrations}, nil
+}
+
+// Creates the table that records the applied migrations
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	stmt := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`
+
+	_, err := m.DB.ExecContext(ctx, stmt)
+	return err
+}
+
+// Returns the applied versions and the time each one was applied
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	err := m.ensureTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := m.DB.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+
+		err := rows.Scan(&version, &appliedAt)
+		if err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// Runs a migration script and records the change in a single transaction
+func (m *Migrator) run(ctx context.Context, script string, record string, args ...any) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// Roll back is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, script)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, record, args...)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Up applies every pending migration in order and returns the ones it applied
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for _, migration := range m.Migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		err := m.run(ctx, migration.Up, `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+			migration.Version, migration.Name, time.Now().UTC())
+		if err != nil {
+			return done, fmt.Errorf("migrate: applying %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Down rolls back the given number of most recently applied migrations and returns the ones it rolled back
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(applied) == 0 {
+		return nil, ErrNoChange
+	}
+
+	done := []Migration{}
+	for i := len(m.Migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.Migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		err := m.run(ctx, migration.Down, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+		if err != nil {
+			return done, fmt.Errorf("migrate: rolling back %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Status returns every known migration and whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(m.Migrations))
+	for i, migration := range m.Migrations {
+		appliedAt, ok := applied[migration.Version]
+		statuses[i] = Status{Migration: migration, Applied: ok, AppliedAt: appliedAt}
+	}
+
+	return statuses, nil
+}
@@ -0,0 +1,195 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+	"github.com/justinbachtell/quote-table-go/internal/models/sqlite"
+	"github.com/justinbachtell/quote-table-go/migrations"
+)
+
+func TestLoad(t *testing.T) {
+	// Set up a tests struct
+	tests := []struct {
+		name         string
+		files        fstest.MapFS
+		wantVersions []int64
+		wantErr      bool
+	}{
+		{
+			name: "Orders by version",
+			files: fstest.MapFS{
+				"0002_second.up.sql":   {Data: []byte("CREATE TABLE b ();")},
+				"0002_second.down.sql": {Data: []byte("DROP TABLE b;")},
+				"0001_first.up.sql":    {Data: []byte("CREATE TABLE a ();")},
+				"0001_first.down.sql":  {Data: []byte("DROP TABLE a;")},
+				"README.md":            {Data: []byte("ignored")},
+			},
+			wantVersions: []int64{1, 2},
+		},
+		{
+			name: "Missing down script",
+			files: fstest.MapFS{
+				"0001_first.up.sql": {Data: []byte("CREATE TABLE a ();")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Missing direction",
+			files: fstest.MapFS{
+				"0001_first.sql": {Data: []byte("CREATE TABLE a ();")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid version",
+			files: fstest.MapFS{
+				"first_table.up.sql":   {Data: []byte("CREATE TABLE a ();")},
+				"first_table.down.sql": {Data: []byte("DROP TABLE a;")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Conflicting names",
+			files: fstest.MapFS{
+				"0001_first.up.sql":   {Data: []byte("CREATE TABLE a ();")},
+				"0001_other.down.sql": {Data: []byte("DROP TABLE a;")},
+			},
+			wantErr: true,
+		},
+	}
+
+	// Loop through each test
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := Load(tt.files)
+			assert.Equal(t, err != nil, tt.wantErr)
+			if tt.wantErr {
+				return
+			}
+
+			assert.Equal(t, len(migrations), len(tt.wantVersions))
+			for i, m := range migrations {
+				assert.Equal(t, m.Version, tt.wantVersions[i])
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	files, err := fs.Sub(migrations.Files, "postgres")
+	assert.NilError(t, err)
+
+	// Check the embedded migrations load and create the users table first
+	loaded, err := Load(files)
+	assert.NilError(t, err)
+	if len(loaded) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+	assert.Equal(t, loaded[0].Name, "create_users")
+}
+
+func TestMigratorUpDown(t *testing.T) {
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	files, err := fs.Sub(migrations.Files, "sqlite")
+	assert.NilError(t, err)
+
+	m, err := New(db, files)
+	assert.NilError(t, err)
+	ctx := context.Background()
+
+	// Check every migration is applied once
+	applied, err := m.Up(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(applied), len(m.Migrations))
+
+	applied, err = m.Up(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(applied), 0)
+
+	// Check rolling back the last migration leaves it pending
+	rolledBack, err := m.Down(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(rolledBack), 1)
+
+	statuses, err := m.Status(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, statuses[len(statuses)-1].Applied, false)
+	assert.Equal(t, statuses[0].Applied, true)
+
+	// Check rolling back everything leaves nothing to roll back
+	_, err = m.Down(ctx, len(m.Migrations))
+	assert.NilError(t, err)
+
+	_, err = m.Down(ctx, 1)
+	assert.Equal(t, err, ErrNoChange)
+}
+
+func TestMigratorISBNConflicts(t *testing.T) {
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	files, err := fs.Sub(migrations.Files, "sqlite")
+	assert.NilError(t, err)
+
+	m, err := New(db, files)
+	assert.NilError(t, err)
+	ctx := context.Background()
+
+	// Add books sharing an ISBN before ISBNs were made unique
+	all := m.Migrations
+	unique := slices.IndexFunc(all, func(migration Migration) bool { return migration.Name == "add_book_isbn_index" })
+	m.Migrations = all[:unique]
+	_, err = m.Up(ctx)
+	assert.NilError(t, err)
+
+	_, err = db.Exec(`INSERT INTO books (title, isbn) VALUES ('Meditations', '978-0-14-044933-4'), ('Meditations', '9780140449334'), ('Letters', '')`)
+	assert.NilError(t, err)
+
+	// Returns the ISBN of each book, with none as the empty string
+	isbns := func() []string {
+		rows, err := db.Query(`SELECT COALESCE(isbn, '') FROM books ORDER BY id`)
+		assert.NilError(t, err)
+		defer rows.Close()
+
+		var isbns []string
+		for rows.Next() {
+			var isbn string
+			assert.NilError(t, rows.Scan(&isbn))
+			isbns = append(isbns, isbn)
+		}
+		return isbns
+	}
+
+	// Check the later duplicate's ISBN is kept aside rather than lost
+	m.Migrations = all
+	_, err = m.Up(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, fmt.Sprint(isbns()), "[9780140449334  ]")
+
+	var bookID int
+	var isbn string
+	err = db.QueryRow(`SELECT book_id, isbn FROM book_isbn_conflicts`).Scan(&bookID, &isbn)
+	assert.NilError(t, err)
+	assert.Equal(t, bookID, 2)
+	assert.Equal(t, isbn, "9780140449334")
+
+	// Check rolling back gives the duplicate its ISBN back
+	_, err = m.Down(ctx, len(all)-unique)
+	assert.NilError(t, err)
+	assert.Equal(t, fmt.Sprint(isbns()), "[9780140449334 9780140449334 ]")
+}
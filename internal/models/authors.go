@@ -1,31 +1,36 @@
 package models
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"log"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
 	"github.com/supabase-community/postgrest-go"
-	"github.com/supabase-community/supabase-go"
 )
 
 // Define an interface for the AuthorModel
 type AuthorModelInterface interface {
-	Insert(name string) (int, error)
-	Get(id int) (Author, error)
-	GetBooksByAuthor(authorID int) ([]Book, error)
-	GetQuotesByAuthor(authorID int) ([]Quote, error)
-	GetWithCounts(id int) (Author, error)
-	GetByName(name string) (Author, error)
-	Update(id int, name string) (int, error)
-	Delete(id int) error
-	Exists(id int) (bool, error)
-	GetAll() ([]Author, error)
-	GetAllWithCounts() ([]AuthorWithCounts, error)
-	SetAuthUserID(id uuid.UUID)
+	Insert(ctx context.Context, name string, details AuthorDetails, userID uuid.UUID) (int, error)
+	Get(ctx context.Context, id int) (Author, error)
+	GetBooksByAuthor(ctx context.Context, authorID int) ([]Book, error)
+	GetQuotesByAuthor(ctx context.Context, authorID int) ([]Quote, error)
+	GetWithCounts(ctx context.Context, id int) (Author, error)
+	GetByName(ctx context.Context, name string) (Author, error)
+	Update(ctx context.Context, id int, name string, details AuthorDetails) (int, error)
+	Delete(ctx context.Context, id int) error
+	Merge(ctx context.Context, fromID, intoID int) (int, error)
+	GetAliases(ctx context.Context, authorID int) ([]AuthorAlias, error)
+	AddAlias(ctx context.Context, authorID int, name string) (int, error)
+	DeleteAlias(ctx context.Context, authorID, aliasID int) error
+	Exists(ctx context.Context, id int) (bool, error)
+	GetAll(ctx context.Context) ([]Author, error)
+	GetAllWithCounts(ctx context.Context) ([]AuthorWithCounts, error)
+	ListWithCounts(ctx context.Context, filters Filters) ([]AuthorWithCounts, Metadata, error)
 }
 
 // Author represents an author in the database
@@ -37,291 +42,500 @@ type Author struct {
 	BookCount int `json:"book_count"`
 	Books []Book `json:"books"`
 	Quotes []Quote `json:"quotes"`
+	Aliases []AuthorAlias `json:"aliases,omitempty"`
+	AuthorDetails
 }
 
-// The model used in the connection pool
-type AuthorModel struct {
-	Client *supabase.Client
-	AuthUserID uuid.UUID
+// AuthorDetails is what is known about an author besides their name, all of
+// which is optional. A year of 0 is unknown, and the calendar time of a known
+// year is either A.D. or B.C., as for a book's publish year.
+type AuthorDetails struct {
+	Bio               string   `json:"bio"`
+	BirthYear         int      `json:"birth_year"`
+	BirthCalendarTime string   `json:"birth_calendar_time"`
+	DeathYear         int      `json:"death_year"`
+	DeathCalendarTime string   `json:"death_calendar_time"`
+	Nationality       string   `json:"nationality"`
+	Occupation        string   `json:"occupation"`
+	Links             []string `json:"links"`
 }
 
-// Insert adds a new author to the database
-func (m *AuthorModel) Insert(name string) (int, error) {
-	// Create a map to hold the author data
-	data := map[string]interface{}{
-		"name": name,
-	}
+// Lifespan formats the author's birth and death years, such as
+// "4 B.C. – 65 A.D.", or returns an empty string when neither is known
+func (d AuthorDetails) Lifespan() string {
+	birth := formatYear(d.BirthYear, d.BirthCalendarTime)
+	death := formatYear(d.DeathYear, d.DeathCalendarTime)
+	switch {
+	case birth != "" && death != "":
+		return birth + " – " + death
+	case birth != "":
+		return "Born " + birth
+	case death != "":
+		return "Died " + death
+	}
+	return ""
+}
 
-	// Insert the author into the database
-	response, _, err := m.Client.From("authors").Insert(data, false, "", "", "").ExecuteString()
-	if err != nil {
-		return 0, err
+// Formats a year with its calendar time, or returns an empty string for an unknown year
+func formatYear(year int, calendarTime string) string {
+	if year == 0 {
+		return ""
 	}
+	return strings.TrimSpace(strconv.Itoa(year) + " " + calendarTime)
+}
 
-	// Parse the JSON response to extract the ID
-	var insertedAuthor []Author
-	err = json.NewDecoder(strings.NewReader(string(response))).Decode(&insertedAuthor)
-	if err != nil {
-		return 0, err
+// The data written to the authors table for an author's name and details
+func authorData(name string, details AuthorDetails) map[string]interface{} {
+	links := details.Links
+	if links == nil {
+		links = []string{}
 	}
 
-	// Check if the author was successfully inserted
-	if len(insertedAuthor) == 0 {
-		return 0, errors.New("no authors returned in response")
+	return map[string]interface{}{
+		"name":                name,
+		"bio":                 details.Bio,
+		"birth_year":          details.BirthYear,
+		"birth_calendar_time": details.BirthCalendarTime,
+		"death_year":          details.DeathYear,
+		"death_calendar_time": details.DeathCalendarTime,
+		"nationality":         details.Nationality,
+		"occupation":          details.Occupation,
+		"links":               links,
 	}
+}
 
-	// Return the ID of the inserted author
-	return insertedAuthor[0].ID, nil
+// AuthorAlias is another name an author is known by, such as a pen name or
+// their birth name
+type AuthorAlias struct {
+	ID       int    `json:"id"`
+	AuthorID int    `json:"author_id"`
+	Name     string `json:"name"`
 }
 
-// Get a single author by ID
-func (m *AuthorModel) Get(id int) (Author, error) {
-	// Initialize a new Author struct to hold the data
-	var a Author
-
-	// Convert id to string
-	idStr := strconv.Itoa(id)
-
-	// Query the database for the author
-	count, err := m.Client.From("authors").Select("*", "exact", false).Eq("id", idStr).Single().ExecuteTo(&a)
-	if err != nil {
-		log.Printf("Error executing query: %v", err)
-		return Author{}, ErrNoRecord
-	} else if count > 1 {
-		log.Printf("Unexpected count > 1 for ID: %d", id)
-		return Author{}, err
-	} else if count == 0 {
-		log.Printf("No record found for ID: %d", id)
-		return Author{}, ErrNoRecord
+// HasName reports whether the author's name or any of their aliases contains
+// the text, ignoring case
+func (a Author) HasName(text string) bool {
+	text = strings.ToLower(text)
+	if strings.Contains(strings.ToLower(a.Name), text) {
+		return true
 	}
+	for _, alias := range a.Aliases {
+		if strings.Contains(strings.ToLower(alias.Name), text) {
+			return true
+		}
+	}
+	return false
+}
 
-	// Return the Author struct
-	return a, nil
+// The model used in the connection pool
+type AuthorModel struct {
+	Client *postgrest.Client
+	Timeouts Timeouts
 }
 
-// Get a single author by name
-func (m *AuthorModel) GetByName(name string) (Author, error) {
-	// Initialize a new Author struct to hold the data
-	var a Author
-
-	// Query the database for the author
-	count, err := m.Client.From("authors").Select("*", "exact", false).Eq("name", name).Single().ExecuteTo(&a)
-	if err != nil {
-		log.Printf("Error executing query: %v", err)
-		return Author{}, ErrNoRecord
-	} else if count > 1 {
-		log.Printf("Unexpected count > 1 for name: %s", name)
-		return Author{}, err
-	} else if count == 0 {
-		log.Printf("No record found for name: %s", name)
-		return Author{}, ErrNoRecord
-	}
+// Insert adds a new author to the database created by the given user
+func (m *AuthorModel) Insert(ctx context.Context, name string, details AuthorDetails, userID uuid.UUID) (int, error) {
+	return write(ctx, func() (int, error) {
+		// Create a map to hold the author data
+		data := authorData(name, details)
+		data["user_id"] = userID
+
+		// Insert the author into the database
+		response, _, err := m.Client.From("authors").Insert(data, false, "", "", "").ExecuteString()
+		if err != nil {
+			return 0, err
+		}
 
-	// Return the Author struct
-	return a, nil
+		// Parse the JSON response to extract the ID
+		var insertedAuthor []Author
+		err = json.NewDecoder(strings.NewReader(string(response))).Decode(&insertedAuthor)
+		if err != nil {
+			return 0, err
+		}
+
+		// Check if the author was successfully inserted
+		if len(insertedAuthor) == 0 {
+			return 0, errors.New("no authors returned in response")
+		}
+
+		// Return the ID of the inserted author
+		return insertedAuthor[0].ID, nil
+	})
 }
 
-// Get books by author
-func (m *AuthorModel) GetBooksByAuthor(authorID int) ([]Book, error) {
-	// Initialize a new Book slice to hold the data
-	var books []Book
+// Get a single author by ID
+func (m *AuthorModel) Get(ctx context.Context, id int) (Author, error) {
+	return query(ctx, m.Timeouts.Read, func() (Author, error) {
+		// Initialize a new Author struct to hold the data
+		var a Author
+
+		// Convert id to string
+		idStr := strconv.Itoa(id)
+
+		// Query the database for the author
+		count, err := m.Client.From("authors").Select("*", "exact", false).Eq("id", idStr).Single().ExecuteTo(&a)
+		if err != nil {
+			log.Printf("Error executing query: %v", err)
+			return Author{}, ErrNoRecord
+		} else if count > 1 {
+			log.Printf("Unexpected count > 1 for ID: %d", id)
+			return Author{}, err
+		} else if count == 0 {
+			log.Printf("No record found for ID: %d", id)
+			return Author{}, ErrNoRecord
+		}
 
-	// Convert authorID to string
-	authorIDStr := strconv.Itoa(authorID)
+		// Return the Author struct
+		return a, nil
+	})
+}
 
-	// Query the database for the books by author
-	response, count, err := m.Client.From("books").Select("*", "exact", false).Order("title", &postgrest.OrderOpts{Ascending: true}).ExecuteString()
-	if err != nil {
-		log.Printf("Error executing query: %v", err)
-		return []Book{}, err
-	}
+// Get a single author by name, or the author with an alias of that name
+func (m *AuthorModel) GetByName(ctx context.Context, name string) (Author, error) {
+	return query(ctx, m.Timeouts.Read, func() (Author, error) {
+		// Query the database for the author, preferring the oldest when names repeat
+		var authors []Author
+		_, err := m.Client.From("authors").Select("*", "", false).Eq("name", name).Order("id", &postgrest.OrderOpts{Ascending: true}).Limit(1, "").ExecuteTo(&authors)
+		if err != nil {
+			log.Printf("Error executing query: %v", err)
+			return Author{}, err
+		}
+		if len(authors) > 0 {
+			return authors[0], nil
+		}
 
-	// If no books were found, return an empty slice
-	if count == 0 {
-		log.Printf("No books found for author ID: %d", authorID)
-		return []Book{}, nil
-	}
+		// Otherwise resolve an alias to the author it belongs to
+		var aliases []AuthorAlias
+		_, err = m.Client.From("author_aliases").Select("*", "", false).Eq("name", name).Limit(1, "").ExecuteTo(&aliases)
+		if err != nil {
+			log.Printf("Error executing query: %v", err)
+			return Author{}, err
+		}
+		if len(aliases) == 0 {
+			return Author{}, ErrNoRecord
+		}
 
-	// Parse the JSON response
-	err = json.NewDecoder(strings.NewReader(string(response))).Decode(&books)
-	if err != nil {
-		log.Printf("Error parsing JSON response: %v", err)
-		return []Book{}, err
-	}
+		found, err := authorsByID(m.Client, []int{aliases[0].AuthorID})
+		if err != nil {
+			return Author{}, err
+		}
+		a, ok := found[aliases[0].AuthorID]
+		if !ok {
+			return Author{}, ErrNoRecord
+		}
 
-	// Query the quotes to get the authorID for each book
-	quotesResponse, _, err := m.Client.From("quotes").Select("*", "exact", false).Eq("author_id", authorIDStr).ExecuteString()
-	if err != nil {
-		log.Printf("Error executing query: %v", err)
-		return []Book{}, err
-	}
+		// Return the Author struct
+		return a, nil
+	})
+}
 
-	// Parse the JSON response
-	var quotes []Quote
-	err = json.NewDecoder(strings.NewReader(string(quotesResponse))).Decode(&quotes)
-	if err != nil {
-		log.Printf("Error parsing JSON response: %v", err)
-		return []Book{}, err
-	}
+// Get the books an author is credited on in any role, ordered by title
+func (m *AuthorModel) GetBooksByAuthor(ctx context.Context, authorID int) ([]Book, error) {
+	return query(ctx, m.Timeouts.Read, func() ([]Book, error) {
+		// Find the books the author is credited on
+		bookIDs, err := creditedBookIDs(m.Client, authorID)
+		if err != nil {
+			return []Book{}, err
+		}
 
-	// Filter the books by the authorID
-	for _, quote := range quotes {
-		for _, book := range books {
-			if quote.BookID == book.ID {
-				// Filter the books by the authorID
-				books = append(books, book)
-				break
-			}
+		// Fetch the books and order them by title
+		books, err := rowsByID(m.Client, "books", bookIDs, func(b Book) int { return b.ID })
+		if err != nil {
+			return []Book{}, err
 		}
-	}
+		sort.Slice(books, func(i, j int) bool {
+			if books[i].Title != books[j].Title {
+				return books[i].Title < books[j].Title
+			}
+			return books[i].ID < books[j].ID
+		})
 
-	// Return the books
-	return books, nil
+		// Return the books
+		return books, nil
+	})
 }
 
 // Get quotes by author
-func (m *AuthorModel) GetQuotesByAuthor(authorID int) ([]Quote, error) {
-	// Initialize a new Quote slice to hold the data
-	var quotes []Quote
-
-	// Convert authorID to string
-	authorIDStr := strconv.Itoa(authorID)
-
-	// Query the database for the quotes by author
-	response, count, err := m.Client.From("quotes").Select("*", "exact", false).Eq("author_id", authorIDStr).Order("quote", &postgrest.OrderOpts{Ascending: true}).ExecuteString()
-	if err != nil {
-		log.Printf("Error executing query: %v", err)
-		return []Quote{}, err
-	}
+func (m *AuthorModel) GetQuotesByAuthor(ctx context.Context, authorID int) ([]Quote, error) {
+	return query(ctx, m.Timeouts.Read, func() ([]Quote, error) {
+		// Initialize a new Quote slice to hold the data
+		var quotes []Quote
+
+		// Convert authorID to string
+		authorIDStr := strconv.Itoa(authorID)
+
+		// Query the database for the quotes by author
+		response, count, err := selectVisibleQuotes(m.Client, Viewer(ctx), "*", "exact").Eq("author_id", authorIDStr).Order("quote", &postgrest.OrderOpts{Ascending: true}).ExecuteString()
+		if err != nil {
+			log.Printf("Error executing query: %v", err)
+			return []Quote{}, err
+		}
 
-	// If no quotes were found, return an empty slice
-	if count == 0 {
-		log.Printf("No quotes found for author ID: %d", authorID)
-		return []Quote{}, nil
-	}
+		// If no quotes were found, return an empty slice
+		if count == 0 {
+			log.Printf("No quotes found for author ID: %d", authorID)
+			return []Quote{}, nil
+		}
 
-	// Parse the JSON response
-	err = json.NewDecoder(strings.NewReader(string(response))).Decode(&quotes)
-	if err != nil {
-		log.Printf("Error parsing JSON response: %v", err)
-		return []Quote{}, err
-	}
+		// Parse the JSON response
+		err = json.NewDecoder(strings.NewReader(string(response))).Decode(&quotes)
+		if err != nil {
+			log.Printf("Error parsing JSON response: %v", err)
+			return []Quote{}, err
+		}
 
-	// Return the quotes
-	return quotes, nil
+		// Return the quotes
+		return quotes, nil
+	})
 }
 
-// Update an author by ID
-func (m *AuthorModel) Update(id int, name string) (int, error) {
-	// Create a map to hold the author data
-	data := map[string]interface{}{
-		"name": name,
-	}
+// Update an author's name and details by ID
+func (m *AuthorModel) Update(ctx context.Context, id int, name string, details AuthorDetails) (int, error) {
+	return write(ctx, func() (int, error) {
+		// Create a map to hold the author data
+		data := authorData(name, details)
 
-	// Convert id to string
-	idStr := strconv.Itoa(id)
+		// Convert id to string
+		idStr := strconv.Itoa(id)
 
-	// Update the author in the database
-	response, _, err := m.Client.From("authors").Update(data, "", "exact").Eq("id", idStr).Execute()
-	if err != nil {
-		log.Printf("Error updating author: %v", err)
-		return 0, err
-	}
+		// Update the author in the database
+		response, _, err := m.Client.From("authors").Update(data, "", "exact").Eq("id", idStr).Execute()
+		if err != nil {
+			log.Printf("Error updating author: %v", err)
+			return 0, err
+		}
 
-	// Parse the JSON response
-	var updatedAuthor []Author
-	err = json.NewDecoder(strings.NewReader(string(response))).Decode(&updatedAuthor)
-	if err != nil {
-		log.Printf("Error parsing JSON response: %v", err)
-		return 0, err
-	}
+		// Parse the JSON response
+		var updatedAuthor []Author
+		err = json.NewDecoder(strings.NewReader(string(response))).Decode(&updatedAuthor)
+		if err != nil {
+			log.Printf("Error parsing JSON response: %v", err)
+			return 0, err
+		}
 
-	// Check if the author was successfully updated
-	if len(updatedAuthor) == 0 {
-		log.Printf("No authors returned in response")
-		return 0, errors.New("no authors returned in response")
-	}
+		// Check if the author was successfully updated
+		if len(updatedAuthor) == 0 {
+			log.Printf("No authors returned in response")
+			return 0, errors.New("no authors returned in response")
+		}
 
-	// Return the ID of the updated author
-	return int(updatedAuthor[0].ID), nil
+		// Return the ID of the updated author
+		return int(updatedAuthor[0].ID), nil
+	})
 }
 
-// Delete an author by ID
-func (m *AuthorModel) Delete(id int) error {
-	// Convert id to string
-	idStr := strconv.Itoa(id)
+// Delete an author by ID, refusing with ErrAuthorHasQuotes while quotes still
+// refer to them and ErrAuthorHasBooks while they are credited on books or
+// translated editions of them
+func (m *AuthorModel) Delete(ctx context.Context, id int) error {
+	return exec(ctx, func() error {
+		// Convert id to string
+		idStr := strconv.Itoa(id)
+
+		// Count the author's quotes, whoever can see them
+		_, count, err := m.Client.From("quotes").Select("id", "exact", true).Eq("author_id", idStr).Execute()
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAuthorHasQuotes
+		}
 
-	// Delete the author from the database
-	_, _, err := m.Client.From("authors").Delete("", "exact").Eq("id", idStr).Execute()
-	if err != nil {
-		log.Printf("Error deleting author: %v", err)
-		return err
-	}
+		// Count the books the author is credited on
+		_, count, err = m.Client.From("book_contributors").Select("book_id", "exact", true).Eq("author_id", idStr).Execute()
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAuthorHasBooks
+		}
 
-	// Return nil
-	return nil
+		// Count the editions the author translated
+		_, count, err = m.Client.From("editions").Select("id", "exact", true).Eq("translator_id", idStr).Execute()
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAuthorHasBooks
+		}
+
+		// Delete the author's aliases, then the author
+		_, _, err = m.Client.From("author_aliases").Delete("", "").Eq("author_id", idStr).Execute()
+		if err != nil {
+			return err
+		}
+		_, _, err = m.Client.From("authors").Delete("", "exact").Eq("id", idStr).Execute()
+		if err != nil {
+			log.Printf("Error deleting author: %v", err)
+			return err
+		}
+
+		// Return nil
+		return nil
+	})
 }
 
-// Check if an author exists by ID
-func (m *AuthorModel) Exists(id int) (bool, error) {
-	// Convert id to string
-	idStr := strconv.Itoa(id)
-
-	// Query the database for the author by id
-	response, count, err := m.Client.From("authors").Select("id", "exact", false).Eq("id", idStr).ExecuteString()
-	if err != nil {
-		if strings.Contains(err.Error(), "PGRST116") {
-			// No rows returned
-			return false, nil
+// Merge moves every quote of the author fromID, whoever can see it, along
+// with their aliases, book credits and translations to the author intoID and then deletes
+// fromID. It returns the number of quotes moved.
+//
+// The merge_authors Postgres function makes every change in one transaction,
+// so a merge that fails part way leaves both authors as they were.
+func (m *AuthorModel) Merge(ctx context.Context, fromID, intoID int) (int, error) {
+	return write(ctx, func() (int, error) {
+		if fromID == intoID {
+			return 0, ErrMergeSameAuthor
 		}
-		return false, err
-	}
 
-	// If count is 0, author doesn't exist
-	if count == 0 {
-		return false, nil
-	}
+		args := map[string]int{"from_author": fromID, "into_author": intoID}
+		var rows []struct {
+			Moved int `json:"quotes_moved"`
+		}
+		err := callFunction(m.Client, "merge_authors", args, &rows)
+		if err != nil {
+			log.Printf("Error merging authors: %v", err)
+			return 0, err
+		}
 
-	// Parse the JSON response
-	var authors []struct {
-		ID int `json:"id"`
-	}
-	err = json.NewDecoder(strings.NewReader(string(response))).Decode(&authors)
-	if err != nil {
-		return false, err
-	}
+		// The function returns no rows when either author doesn't exist
+		if len(rows) == 0 {
+			return 0, ErrNoRecord
+		}
 
-	// Check if any author was found
-	return len(authors) > 0, nil
+		return rows[0].Moved, nil
+	})
 }
 
-// Get all authors
-func (m *AuthorModel) GetAll() ([]Author, error) {
-	// Query the database for all authors
-	response, count, err := m.Client.From("authors").Select("*", "exact", false).ExecuteString()
-	if err != nil {
-		return nil, err
-	}
+// Get the aliases of an author, ordered by name
+func (m *AuthorModel) GetAliases(ctx context.Context, authorID int) ([]AuthorAlias, error) {
+	return query(ctx, m.Timeouts.Read, func() ([]AuthorAlias, error) {
+		aliases := []AuthorAlias{}
+		_, err := m.Client.From("author_aliases").Select("*", "", false).Eq("author_id", strconv.Itoa(authorID)).Order("name", &postgrest.OrderOpts{Ascending: true}).ExecuteTo(&aliases)
+		if err != nil {
+			log.Printf("Error fetching aliases: %v", err)
+			return nil, err
+		}
 
-	// If no authors were found, return an empty slice
-	if count == 0 {
-		return []Author{}, nil
-	}
+		return aliases, nil
+	})
+}
 
-	// Parse the JSON response
-	var authors []Author
-	err = json.NewDecoder(strings.NewReader(string(response))).Decode(&authors)
-	if err != nil {
-		return nil, err
-	}
+// AddAlias gives an author another name, returning the alias's ID. It returns
+// ErrNoRecord if the author doesn't exist and ErrDuplicateAlias if another
+// alias already has the name.
+func (m *AuthorModel) AddAlias(ctx context.Context, authorID int, name string) (int, error) {
+	return write(ctx, func() (int, error) {
+		// Check the author exists before adding to them
+		authors, err := authorsByID(m.Client, []int{authorID})
+		if err != nil {
+			return 0, err
+		}
+		if len(authors) == 0 {
+			return 0, ErrNoRecord
+		}
+
+		data := map[string]interface{}{
+			"author_id": authorID,
+			"name":      name,
+		}
+
+		var inserted []AuthorAlias
+		_, err = m.Client.From("author_aliases").Insert(data, false, "", "", "").ExecuteTo(&inserted)
+		if err != nil {
+			if strings.Contains(err.Error(), "author_aliases_uc_name") {
+				return 0, ErrDuplicateAlias
+			}
+			return 0, err
+		}
+		if len(inserted) == 0 {
+			return 0, errors.New("no aliases returned in response")
+		}
+
+		return inserted[0].ID, nil
+	})
+}
+
+// DeleteAlias removes one of an author's aliases, returning ErrNoRecord if
+// the author has no alias with the ID
+func (m *AuthorModel) DeleteAlias(ctx context.Context, authorID, aliasID int) error {
+	return exec(ctx, func() error {
+		response, _, err := m.Client.From("author_aliases").Delete("", "").Eq("id", strconv.Itoa(aliasID)).Eq("author_id", strconv.Itoa(authorID)).ExecuteString()
+		if err != nil {
+			return err
+		}
+
+		var deleted []AuthorAlias
+		err = json.NewDecoder(strings.NewReader(response)).Decode(&deleted)
+		if err != nil {
+			return err
+		}
+		if len(deleted) == 0 {
+			return ErrNoRecord
+		}
 
-	// Return the authors
-	return authors, nil
+		return nil
+	})
 }
 
-// Set the AuthUserID for the author
-func (m *AuthorModel) SetAuthUserID(id uuid.UUID) {
-	m.AuthUserID = id
+// Check if an author exists by ID
+func (m *AuthorModel) Exists(ctx context.Context, id int) (bool, error) {
+	return query(ctx, m.Timeouts.Read, func() (bool, error) {
+		// Convert id to string
+		idStr := strconv.Itoa(id)
+
+		// Query the database for the author by id
+		response, count, err := m.Client.From("authors").Select("id", "exact", false).Eq("id", idStr).ExecuteString()
+		if err != nil {
+			if strings.Contains(err.Error(), "PGRST116") {
+				// No rows returned
+				return false, nil
+			}
+			return false, err
+		}
+
+		// If count is 0, author doesn't exist
+		if count == 0 {
+			return false, nil
+		}
+
+		// Parse the JSON response
+		var authors []struct {
+			ID int `json:"id"`
+		}
+		err = json.NewDecoder(strings.NewReader(string(response))).Decode(&authors)
+		if err != nil {
+			return false, err
+		}
+
+		// Check if any author was found
+		return len(authors) > 0, nil
+	})
+}
+
+// Get all authors
+func (m *AuthorModel) GetAll(ctx context.Context) ([]Author, error) {
+	return query(ctx, m.Timeouts.Read, func() ([]Author, error) {
+		// Query the database for all authors
+		response, count, err := m.Client.From("authors").Select("*", "exact", false).ExecuteString()
+		if err != nil {
+			return nil, err
+		}
+
+		// If no authors were found, return an empty slice
+		if count == 0 {
+			return []Author{}, nil
+		}
+
+		// Parse the JSON response
+		var authors []Author
+		err = json.NewDecoder(strings.NewReader(string(response))).Decode(&authors)
+		if err != nil {
+			return nil, err
+		}
+
+		// Return the authors
+		return authors, nil
+	})
 }
 
 // AuthorWithCounts represents an author with the count of their books
@@ -332,148 +546,186 @@ type AuthorWithCounts struct {
 }
 
 // GetWithCounts returns an author with their book count
-func (m *AuthorModel) GetWithCounts(id int) (Author, error) {
-    var author Author
-
-    // Convert id to string
-    idStr := strconv.Itoa(id)
-
-    // Query the database for the author
-    count, err := m.Client.From("authors").Select("*", "exact", false).Eq("id", idStr).Single().ExecuteTo(&author)
-    if err != nil {
-        log.Printf("Error executing query: %v", err)
-        return Author{}, ErrNoRecord
-    } else if count == 0 {
-        log.Printf("No record found for ID: %d", id)
-        return Author{}, ErrNoRecord
-    }
-
-    // Get quote count
-    _, quoteCount, err := m.Client.From("quotes").Select("id", "exact", false).Eq("author_id", idStr).Execute()
-    if err != nil {
-        log.Printf("Error getting quote count: %v", err)
-        return Author{}, err
-    }
-    author.QuoteCount = int(quoteCount)
-
-    // Get unique books for this author
-    var books []Book
-    _, err = m.Client.From("quotes").Select("book_id", "exact", false).Eq("author_id", idStr).ExecuteTo(&books)
-    if err != nil {
-        log.Printf("Error getting books for author: %v", err)
-        return Author{}, err
-    }
-
-    author.BookCount = len(books)
-
-    return author, nil
+func (m *AuthorModel) GetWithCounts(ctx context.Context, id int) (Author, error) {
+	return query(ctx, m.Timeouts.Read, func() (Author, error) {
+	    var author Author
+
+	    // Convert id to string
+	    idStr := strconv.Itoa(id)
+
+	    // Query the database for the author
+	    count, err := m.Client.From("authors").Select("*", "exact", false).Eq("id", idStr).Single().ExecuteTo(&author)
+	    if err != nil {
+	        log.Printf("Error executing query: %v", err)
+	        return Author{}, ErrNoRecord
+	    } else if count == 0 {
+	        log.Printf("No record found for ID: %d", id)
+	        return Author{}, ErrNoRecord
+	    }
+
+	    // Get quote count
+	    _, quoteCount, err := selectVisibleQuotes(m.Client, Viewer(ctx), "id", "exact").Eq("author_id", idStr).Execute()
+	    if err != nil {
+	        log.Printf("Error getting quote count: %v", err)
+	        return Author{}, err
+	    }
+	    author.QuoteCount = int(quoteCount)
+
+	    // Get the books the author is credited on
+	    bookIDs, err := creditedBookIDs(m.Client, id)
+	    if err != nil {
+	        log.Printf("Error getting books for author: %v", err)
+	        return Author{}, err
+	    }
+
+	    author.BookCount = len(idList(bookIDs))
+
+	    return author, nil
+	})
 }
 
 // GetAllWithCounts returns all authors with their book count
-func (m *AuthorModel) GetAllWithCounts() ([]AuthorWithCounts, error) {
-	var authorsWithCount []AuthorWithCounts
-
-	// Query the database for authors and their book count
-	authorsResponse, authorCount, err := m.Client.From("authors").Select("*", "exact", false).ExecuteString()
-	if err != nil {
-		log.Printf("Failed to get authors: %v", err)
-		return nil, err
-	}
-
-	if authorCount == 0 {
-		log.Printf("No authors found")
-		return []AuthorWithCounts{}, nil
-	}
+func (m *AuthorModel) GetAllWithCounts(ctx context.Context) ([]AuthorWithCounts, error) {
+	return query(ctx, m.Timeouts.Read, func() ([]AuthorWithCounts, error) {
+		var authorsWithCount []AuthorWithCounts
+
+		// Query the database for authors and their book count
+		authorsResponse, authorCount, err := m.Client.From("authors").Select("*", "exact", false).ExecuteString()
+		if err != nil {
+			log.Printf("Failed to get authors: %v", err)
+			return nil, err
+		}
 
-	// Parse the JSON response
-	var authorData []map[string]interface{}
-	err = json.NewDecoder(strings.NewReader(string(authorsResponse))).Decode(&authorData)
-	if err != nil {
-		log.Printf("Failed to decode authors: %v", err)
-		return nil, err
-	}
+		if authorCount == 0 {
+			log.Printf("No authors found")
+			return []AuthorWithCounts{}, nil
+		}
 
-	// Get the books 
-	booksResponse, bookCount, err := m.Client.From("books").Select("*", "exact", false).Order("title", &postgrest.OrderOpts{Ascending: true}).ExecuteString()
-	if err != nil {
-		log.Printf("Failed to get books: %v", err)
-		return nil, err
-	}
+		// Parse the JSON response
+		var authorData []map[string]interface{}
+		err = json.NewDecoder(strings.NewReader(string(authorsResponse))).Decode(&authorData)
+		if err != nil {
+			log.Printf("Failed to decode authors: %v", err)
+			return nil, err
+		}
 
-	if bookCount == 0 {
-		log.Printf("No books found")
-		return []AuthorWithCounts{}, nil
-	}
+		// Get the books 
+		booksResponse, bookCount, err := m.Client.From("books").Select("*", "exact", false).Order("title", &postgrest.OrderOpts{Ascending: true}).ExecuteString()
+		if err != nil {
+			log.Printf("Failed to get books: %v", err)
+			return nil, err
+		}
 
-	// Parse the JSON response
-	var books []Book
-	err = json.NewDecoder(strings.NewReader(string(booksResponse))).Decode(&books)
-	if err != nil {
-		log.Printf("Failed to decode books: %v", err)
-		return nil, err
-	}
+		if bookCount == 0 {
+			log.Printf("No books found")
+			return []AuthorWithCounts{}, nil
+		}
 
-	// Get the quotes
-	quotesResponse, quoteCount, err := m.Client.From("quotes").Select("*", "exact", false).Order("quote", &postgrest.OrderOpts{Ascending: true}).ExecuteString()
-	if err != nil {
-		log.Printf("Failed to get quotes: %v", err)
-		return nil, err
-	}
+		// Parse the JSON response
+		var books []Book
+		err = json.NewDecoder(strings.NewReader(string(booksResponse))).Decode(&books)
+		if err != nil {
+			log.Printf("Failed to decode books: %v", err)
+			return nil, err
+		}
 
-	if quoteCount == 0 {
-		log.Printf("No quotes found")
-		return []AuthorWithCounts{}, nil
-	}
+		// Get the quotes
+		quotesResponse, quoteCount, err := selectVisibleQuotes(m.Client, Viewer(ctx), "*", "exact").Order("quote", &postgrest.OrderOpts{Ascending: true}).ExecuteString()
+		if err != nil {
+			log.Printf("Failed to get quotes: %v", err)
+			return nil, err
+		}
 
-	// Parse the JSON response
-	var quotes []Quote
-	err = json.NewDecoder(strings.NewReader(string(quotesResponse))).Decode(&quotes)
-	if err != nil {
-		log.Printf("Failed to decode quotes: %v", err)
-		return nil, err
-	}
+		if quoteCount == 0 {
+			log.Printf("No quotes found")
+			return []AuthorWithCounts{}, nil
+		}
 
-	// Create a map to hold the counts for each author
-	quoteCountMap := make(map[int]int)
-	bookCountMap := make(map[int]int)
+		// Parse the JSON response
+		var quotes []Quote
+		err = json.NewDecoder(strings.NewReader(string(quotesResponse))).Decode(&quotes)
+		if err != nil {
+			log.Printf("Failed to decode quotes: %v", err)
+			return nil, err
+		}
 
-	// Iterate through quotes and count the unique books for each author
-	for _, quote := range quotes {
-		quoteCountMap[quote.AuthorID]++
-		bookCountMap[quote.AuthorID]++
-	}
+		// Create a map to hold the counts for each author
+		quoteCountMap := make(map[int]int)
+		for _, quote := range quotes {
+			quoteCountMap[quote.AuthorID]++
+		}
 
-	// Process the raw data to create AuthorWithCounts structs
-	for _, data := range authorData {
-		author := Author{
-			ID:   int(data["id"].(float64)),
-			Name: data["name"].(string),
+		// Count the books each author is credited on
+		bookCountMap, err := creditedBookCounts(m.Client)
+		if err != nil {
+			log.Printf("Failed to get book counts: %v", err)
+			return nil, err
 		}
 
-		// Check if the user_id is in the data
-		if userID, ok := data["user_id"].(string); ok {
-			author.UserID, err = uuid.Parse(userID)
-			if err != nil {
-				log.Printf("Failed to parse user_id: %v", err)
-				continue
+		// Process the raw data to create AuthorWithCounts structs
+		for _, data := range authorData {
+			author := Author{
+				ID:   int(data["id"].(float64)),
+				Name: data["name"].(string),
 			}
+
+			// Check if the user_id is in the data
+			if userID, ok := data["user_id"].(string); ok {
+				author.UserID, err = uuid.Parse(userID)
+				if err != nil {
+					log.Printf("Failed to parse user_id: %v", err)
+					continue
+				}
+			}
+
+			// Create an AuthorWithCounts struct
+			authorWithCount := AuthorWithCounts{
+				Author: author,
+				QuoteCount: quoteCountMap[author.ID],
+				BookCount: bookCountMap[author.ID],
+			}
+
+			// Add the struct to the slice
+			authorsWithCount = append(authorsWithCount, authorWithCount)
+
+			// Add the counts to the author
+			author.QuoteCount = quoteCountMap[author.ID]
+			author.BookCount = bookCountMap[author.ID]
 		}
 
-		// Create an AuthorWithCounts struct
-		authorWithCount := AuthorWithCounts{
-			Author: author,
-			QuoteCount: quoteCountMap[author.ID],
-			BookCount: bookCountMap[author.ID],
+		// Return the authors with their book count
+		return authorsWithCount, nil
+	})
+}
+
+// ListWithCounts returns a page of authors with their quote and book counts, ordered by name unless another order is asked for
+func (m *AuthorModel) ListWithCounts(ctx context.Context, filters Filters) ([]AuthorWithCounts, Metadata, error) {
+	page, err := query(ctx, m.Timeouts.Read, func() (listing[AuthorWithCounts], error) {
+		// PostgREST can't count or order by a count, so the list_authors function pages the authors with their counts
+		rows, total, err := listPage(m.Client, "list_authors", listArgs{Viewer: Viewer(ctx), Sort: filters.Sort, Limit: filters.Limit(), Offset: filters.Offset()})
+		if err != nil {
+			return listing[AuthorWithCounts]{}, err
 		}
 
-		// Add the struct to the slice
-		authorsWithCount = append(authorsWithCount, authorWithCount)
+		// Fetch the authors on the page, keeping the function's order
+		authors, err := rowsByID(m.Client, "authors", pageIDs(rows), func(a Author) int { return a.ID })
+		if err != nil {
+			return listing[AuthorWithCounts]{}, err
+		}
 
-		// Add the counts to the author
-		author.QuoteCount = quoteCountMap[author.ID]
-		author.BookCount = bookCountMap[author.ID]
-	}
+		counts := make(map[int]pageRow, len(rows))
+		for _, row := range rows {
+			counts[row.ID] = row
+		}
+
+		authorsWithCounts := make([]AuthorWithCounts, len(authors))
+		for i, author := range authors {
+			author.QuoteCount = counts[author.ID].QuoteCount
+			author.BookCount = counts[author.ID].BookCount
+			authorsWithCounts[i] = AuthorWithCounts{Author: author, QuoteCount: author.QuoteCount, BookCount: author.BookCount}
+		}
 
-	// Return the authors with their book count
-	return authorsWithCount, nil
-}
\ No newline at end of file
+		return listing[AuthorWithCounts]{authorsWithCounts, CalculateMetadata(total, filters.Page, filters.PageSize)}, nil
+	})
+	return page.rows, page.metadata, err
+}
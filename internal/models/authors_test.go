@@ -1,17 +1,18 @@
 package models
 
 import (
+	"context"
+	"fmt"
 	"strconv"
 	"testing"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+	"github.com/justinbachtell/quote-table-go/internal/postgresttest"
 )
 
 // Test Author Model Exists
 func TestAuthorModelExists(t *testing.T) {
-	// Skip the test if the "-short" flag is passed
-	if testing.Short() {
-		t.Skip("models: skipping integration tests in short mode")
-	}
-
 	// Set up a tests struct
 	tests := []struct {
 		name    string
@@ -43,7 +44,7 @@ func TestAuthorModelExists(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Call the Exists method to check if the author exists
-			exists, err := m.Exists(tt.authorID)
+			exists, err := m.Exists(context.Background(), tt.authorID)
 
 			// Check for error first
 			if err != nil {
@@ -64,4 +65,226 @@ func TestAuthorModelExists(t *testing.T) {
 			t.Errorf("Failed to delete test author: %v", err)
 		}
 	})
-}
\ No newline at end of file
+}
+
+func TestAuthorModelListWithCounts(t *testing.T) {
+	// Seed the fake server with a library of books
+	ts, db := newTestServer(t)
+	seedTestLibrary(t, ts, 12)
+
+	// Create a new AuthorModel instance
+	m := AuthorModel{Client: db}
+
+	tests := []struct {
+		name      string
+		filters   Filters
+		wantNames []string
+	}{
+		{"By name", Filters{Page: 1, PageSize: 2, Sort: SortAuthor}, []string{"Author 1", "Author 2"}},
+		{"By name second page", Filters{Page: 2, PageSize: 2, Sort: SortAuthor}, []string{"Author 3", "Author 4"}},
+		{"Newest", Filters{Page: 1, PageSize: 2, Sort: SortNewest}, []string{"Author 5", "Author 4"}},
+		{"Most quoted", Filters{Page: 1, PageSize: 3, Sort: SortMostQuoted}, []string{"Author 2", "Author 3", "Author 1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authors, metadata, err := m.ListWithCounts(context.Background(), tt.filters)
+			if err != nil {
+				t.Fatalf("Error in ListWithCounts method: %v", err)
+			}
+
+			names := []string{}
+			for _, a := range authors {
+				names = append(names, a.Name)
+			}
+			if fmt.Sprint(names) != fmt.Sprint(tt.wantNames) {
+				t.Errorf("got authors %v, want %v", names, tt.wantNames)
+			}
+			if metadata.TotalRecords != 5 || metadata.LastPage != (5+tt.filters.PageSize-1)/tt.filters.PageSize {
+				t.Errorf("got metadata %+v, want 5 records", metadata)
+			}
+		})
+	}
+}
+
+func TestAuthorModelMergeAndDelete(t *testing.T) {
+	ts, db := newTestServer(t)
+	owner := uuid.New()
+
+	// Seed an author and a duplicate with a private quote
+	_, err := ts.Insert("authors", postgresttest.Row{"name": "Marcus Aurelius"}, postgresttest.Row{"name": "Marcus Aurelius Antoninus"}, postgresttest.Row{"name": "Epictetus"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Insert("quotes",
+		postgresttest.Row{"quote": "You have power over your mind.", "author_id": 1, "user_id": owner.String()},
+		postgresttest.Row{"quote": "The best revenge is not to be like that.", "author_id": 2, "user_id": owner.String(), "visibility": "private"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Credit both authors on the same book, and the duplicate on another
+	_, err = ts.Insert("books", postgresttest.Row{"title": "Meditations"}, postgresttest.Row{"title": "Thoughts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Insert("book_contributors",
+		postgresttest.Row{"book_id": 1, "author_id": 1, "role": "author", "position": 0},
+		postgresttest.Row{"book_id": 1, "author_id": 2, "role": "author", "position": 1},
+		postgresttest.Row{"book_id": 2, "author_id": 2, "role": "translator", "position": 0},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := AuthorModel{Client: db}
+	ctx := context.Background()
+
+	// Check an author is kept while they have quotes, even private ones
+	err = m.Delete(ctx, 2)
+	assert.Equal(t, err, ErrAuthorHasQuotes)
+
+	// Check an author can't be merged into themselves or a missing author
+	_, err = m.Merge(ctx, 2, 2)
+	assert.Equal(t, err, ErrMergeSameAuthor)
+	_, err = m.Merge(ctx, 2, 99)
+	assert.Equal(t, err, ErrNoRecord)
+
+	// Check merging moves every quote and deletes the duplicate
+	moved, err := m.Merge(ctx, 2, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, moved, 1)
+
+	exists, err := m.Exists(ctx, 2)
+	assert.NilError(t, err)
+	assert.Equal(t, exists, false)
+
+	quotes, err := m.GetQuotesByAuthor(WithViewer(ctx, owner), 1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(quotes), 2)
+
+	// Check the book credits are moved without crediting an author twice in the same role
+	books, err := m.GetBooksByAuthor(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(books), 2)
+	assert.Equal(t, len(ts.Rows("book_contributors")), 2)
+
+	// Check an author without quotes can be deleted
+	assert.NilError(t, m.Delete(ctx, 3))
+	exists, err = m.Exists(ctx, 3)
+	assert.NilError(t, err)
+	assert.Equal(t, exists, false)
+}
+
+func TestAuthorModelAliases(t *testing.T) {
+	ts, db := newTestServer(t)
+
+	_, err := ts.Insert("authors", postgresttest.Row{"name": "Mark Twain"}, postgresttest.Row{"name": "George Eliot"}, postgresttest.Row{"name": "S. L. Clemens"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Insert("quotes",
+		postgresttest.Row{"quote": "The secret of getting ahead is getting started.", "author_id": 1},
+		postgresttest.Row{"quote": "It is never too late to be what you might have been.", "author_id": 2},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := AuthorModel{Client: db}
+	ctx := context.Background()
+
+	// Check an alias can only be added to an author who exists, and only once
+	id, err := m.AddAlias(ctx, 1, "Samuel Clemens")
+	assert.NilError(t, err)
+	_, err = m.AddAlias(ctx, 2, "Samuel Clemens")
+	assert.Equal(t, err, ErrDuplicateAlias)
+	_, err = m.AddAlias(ctx, 99, "Nobody")
+	assert.Equal(t, err, ErrNoRecord)
+
+	// Check the name and the alias both find the author
+	author, err := m.GetByName(ctx, "Samuel Clemens")
+	assert.NilError(t, err)
+	assert.Equal(t, author.ID, 1)
+	author, err = m.GetByName(ctx, "Mark Twain")
+	assert.NilError(t, err)
+	assert.Equal(t, author.ID, 1)
+
+	// Check searching by author name also matches aliases
+	results, _, err := (&QuoteModel{Client: db}).Search(ctx, SearchQuery{AuthorName: "clemens"}, Filters{Page: 1, PageSize: 20})
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 1)
+	assert.Equal(t, results[0].Quote.AuthorID, 1)
+
+	// Check merging keeps the aliases of the author merged
+	_, err = m.AddAlias(ctx, 3, "Sam Clemens")
+	assert.NilError(t, err)
+	_, err = m.Merge(ctx, 3, 1)
+	assert.NilError(t, err)
+
+	aliases, err := m.GetAliases(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(aliases), 2)
+	assert.Equal(t, aliases[0].Name, "Sam Clemens")
+
+	// Check an alias is only deleted from its own author
+	err = m.DeleteAlias(ctx, 2, id)
+	assert.Equal(t, err, ErrNoRecord)
+	assert.NilError(t, m.DeleteAlias(ctx, 1, id))
+
+	_, err = m.GetByName(ctx, "Samuel Clemens")
+	assert.Equal(t, err, ErrNoRecord)
+}
+
+func TestAuthorModelDetails(t *testing.T) {
+	ts, db := newTestServer(t)
+
+	_, err := ts.Insert("authors", postgresttest.Row{"name": "Marcus Aurelius"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := AuthorModel{Client: db}
+	ctx := context.Background()
+
+	// Check the details are saved with a new author, B.C. years included
+	details := AuthorDetails{
+		Bio:               "Stoic philosopher and statesman.",
+		BirthYear:         4,
+		BirthCalendarTime: "B.C.",
+		DeathYear:         65,
+		DeathCalendarTime: "A.D.",
+		Nationality:       "Roman",
+		Occupation:        "Philosopher",
+		Links:             []string{"https://en.wikipedia.org/wiki/Seneca_the_Younger", "https://plato.stanford.edu/entries/seneca/"},
+	}
+	id, err := m.Insert(ctx, "Lucius Annaeus Seneca", details, uuid.New())
+	assert.NilError(t, err)
+
+	author, err := m.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, author.Bio, details.Bio)
+	assert.Equal(t, author.Lifespan(), "4 B.C. – 65 A.D.")
+	assert.Equal(t, author.Nationality, "Roman")
+	assert.Equal(t, author.Occupation, "Philosopher")
+	assert.Equal(t, len(author.Links), 2)
+	assert.Equal(t, author.Links[1], details.Links[1])
+
+	// Check updating replaces every detail, and the links can be cleared
+	_, err = m.Update(ctx, id, "Seneca the Younger", AuthorDetails{BirthYear: 4, BirthCalendarTime: "B.C."})
+	assert.NilError(t, err)
+
+	author, err = m.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, author.Name, "Seneca the Younger")
+	assert.Equal(t, author.Bio, "")
+	assert.Equal(t, author.Lifespan(), "Born 4 B.C.")
+	assert.Equal(t, len(author.Links), 0)
+
+	// Check authors added before the details existed have none
+	author, err = m.Get(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, author.Lifespan(), "")
+	assert.Equal(t, len(author.Links), 0)
+}
@@ -1,31 +1,35 @@
 package models
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"log"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/supabase-community/supabase-go"
+	"github.com/supabase-community/postgrest-go"
 )
 
 // Define an interface for the BookModel
 type BookModelInterface interface {
-	Insert(title string, publishYear int, calendarTime string, isbn string, source string) (int, error)
-	Get(id int) (Book, error)
-	GetByAuthorID(authorID int) ([]Book, error)
-	GetAllWithAuthors() ([]Book, error)
-	Update(id int, title string, publishYear int, calendarTime string, isbn string, source string) error
-	Delete(id int) error
-	GetAll() ([]Book, error)
-	Exists(id int) (bool, error)
-	SetAuthUserID(id uuid.UUID)
+	Insert(ctx context.Context, title string, publishYear int, calendarTime string, isbn string, source string, details WorkDetails, contributors []BookContributor, userID uuid.UUID) (int, error)
+	Get(ctx context.Context, id int) (Book, error)
+	GetByAuthorID(ctx context.Context, authorID int) ([]Book, error)
+	GetAllWithAuthors(ctx context.Context, filters Filters) ([]Book, Metadata, error)
+	Update(ctx context.Context, id int, title string, publishYear int, calendarTime string, isbn string, source string, details WorkDetails, contributors []BookContributor) error
+	Delete(ctx context.Context, id int) error
+	GetAll(ctx context.Context) ([]Book, error)
+	Exists(ctx context.Context, id int) (bool, error)
+	GetByISBN(ctx context.Context, isbn string) (Book, error)
 }
 
-// Book represents a book in the database
+// Book represents a work quotes are taken from in the database. Despite the
+// name it can be any media type, such as a speech or a podcast, with
+// WorkDetails holding the fields that only some media types use.
 type Book struct {
 	ID           int       `json:"id"`
 	Title        string    `json:"title"`
@@ -38,348 +42,553 @@ type Book struct {
 	UpdatedAt    time.Time `json:"updated_at"`
 	Author       Author    `json:"author"`
 	Quotes       []Quote   `json:"quotes"`
+	Contributors []BookContributor `json:"contributors,omitempty"`
+	WorkDetails
 }
 
-// The model used in the connection pool
-type BookModel struct {
-	Client *supabase.Client
-	AuthUserID uuid.UUID
-}
+// MediaType is the kind of work a book is
+type MediaType string
+
+// The media types a work can have
+const (
+	MediaBook    MediaType = "book"
+	MediaArticle MediaType = "article"
+	MediaPaper   MediaType = "paper"
+	MediaLetter  MediaType = "letter"
+	MediaSpeech  MediaType = "speech"
+	MediaFilm    MediaType = "film"
+	MediaVideo   MediaType = "video"
+	MediaPodcast MediaType = "podcast"
+)
 
-// Insert adds a new book to the database
-func (m *BookModel) Insert(title string, publishYear int, calendarTime string, isbn string, source string) (int, error) {
-	data := map[string]interface{}{
-		"title":         title,
-		"publish_year":  publishYear,
-		"calendar_time": calendarTime,
-		"isbn":          isbn,
-		"source":        source,
-		"created_at":    time.Now(),
-		"updated_at":    time.Now(),
-	}
+// MediaTypes lists every media type in the order they are offered
+var MediaTypes = []MediaType{MediaBook, MediaArticle, MediaPaper, MediaLetter, MediaSpeech, MediaFilm, MediaVideo, MediaPodcast}
 
-	response, _, err := m.Client.From("books").Insert(data, false, "", "", "").ExecuteString()
-	if err != nil {
-		return 0, err
+// Title returns the media type's name for display, such as "Podcast"
+func (t MediaType) Title() string {
+	if t == "" {
+		return ""
 	}
+	return strings.ToUpper(string(t[:1])) + string(t[1:])
+}
 
-	var insertedBook []Book
-	err = json.NewDecoder(strings.NewReader(string(response))).Decode(&insertedBook)
-	if err != nil {
-		return 0, err
+// UsesISBN reports whether works of this media type have an ISBN
+func (t MediaType) UsesISBN() bool {
+	return t == MediaBook
+}
+
+// UsesDOI reports whether works of this media type have a DOI
+func (t MediaType) UsesDOI() bool {
+	return t == MediaPaper
+}
+
+// UsesVenue reports whether works of this media type have a venue
+func (t MediaType) UsesVenue() bool {
+	return t == MediaSpeech
+}
+
+// UsesDate reports whether works of this media type have a date, which books
+// and papers don't as they are dated by their publish year
+func (t MediaType) UsesDate() bool {
+	return t != MediaBook && t != MediaPaper
+}
+
+// UsesPageCount reports whether works of this media type have a number of pages
+func (t MediaType) UsesPageCount() bool {
+	return t == MediaBook || t == MediaPaper
+}
+
+// Online reports whether works of this media type are found at a URL, kept
+// as the work's source
+func (t MediaType) Online() bool {
+	return t == MediaVideo || t == MediaPodcast
+}
+
+// WorkDetails are the fields of a work that depend on its media type: the
+// DOI of a paper, the venue of a speech, the date a speech was given or a
+// letter, video or podcast was released, as YYYY-MM-DD, and the number of
+// pages in a book or paper, 0 if unknown. Books keep their ISBN, and online
+// works their URL, in Book's ISBN and Source.
+type WorkDetails struct {
+	MediaType MediaType `json:"media_type"`
+	DOI       string    `json:"doi"`
+	Venue     string    `json:"venue"`
+	Date      string    `json:"work_date"`
+	PageCount int       `json:"page_count"`
+}
+
+// Kind returns the work's media type, treating works stored before media
+// types existed as books
+func (d WorkDetails) Kind() MediaType {
+	if d.MediaType == "" {
+		return MediaBook
 	}
+	return d.MediaType
+}
+
+// Returns the values written for a work's details, which are never null
+func workData(details WorkDetails, data map[string]interface{}) map[string]interface{} {
+	data["media_type"] = details.Kind()
+	data["doi"] = details.DOI
+	data["venue"] = details.Venue
+	data["work_date"] = details.Date
+	data["page_count"] = nullIfZero(details.PageCount)
+	return data
+}
+
+// ContributorRole is the part an author had in making a book
+type ContributorRole string
+
+// The roles an author can be credited with on a book
+const (
+	ContributorAuthor      ContributorRole = "author"
+	ContributorEditor      ContributorRole = "editor"
+	ContributorTranslator  ContributorRole = "translator"
+	ContributorIllustrator ContributorRole = "illustrator"
+)
+
+// ContributorRoles lists every contributor role in the order they are offered
+var ContributorRoles = []ContributorRole{ContributorAuthor, ContributorEditor, ContributorTranslator, ContributorIllustrator}
 
-	if len(insertedBook) == 0 {
-		return 0, errors.New("no books returned in response")
+// Title returns the role's name for display, such as "Translator"
+func (r ContributorRole) Title() string {
+	if r == "" {
+		return ""
 	}
+	return strings.ToUpper(string(r[:1])) + string(r[1:])
+}
 
-	return insertedBook[0].ID, nil
+// BookContributor credits an author with a role in making a book. A book's
+// contributors are kept in the order they are credited, and its first
+// contributor credited as an author is the book's Author.
+type BookContributor struct {
+	AuthorID int             `json:"author_id"`
+	Role     ContributorRole `json:"role"`
+	Author   Author          `json:"-"`
 }
 
-// Get a single book by ID
-func (m *BookModel) Get(id int) (Book, error) {
-    var books []Book
-
-    idStr := strconv.Itoa(id)
-
-    response, count, err := m.Client.From("books").Select("*", "exact", false).Eq("id", idStr).ExecuteString()
-    if err != nil {
-        log.Printf("Error executing query: %v", err)
-        return Book{}, err
-    }
-
-    if count == 0 {
-        log.Printf("No record found for ID: %d", id)
-        return Book{}, ErrNoRecord
-    }
-
-    err = json.NewDecoder(strings.NewReader(response)).Decode(&books)
-    if err != nil {
-        log.Printf("Error decoding JSON: %v", err)
-        return Book{}, err
-    }
-
-    if len(books) == 0 {
-        log.Printf("No book found for ID: %d", id)
-        return Book{}, ErrNoRecord
-    }
-
-    book := books[0]
-
-	// Get the quotes for this book
-    quotesResponse, count, err := m.Client.From("quotes").Select("*", "exact", false).Eq("book_id", strconv.Itoa(book.ID)).ExecuteString()
-    if err != nil {
-        log.Printf("Error fetching quotes for book %d: %v", book.ID, err)
-        return Book{}, err
-    }
-
-	// Decode the quotes
-	var quotes []Quote
-	err = json.NewDecoder(strings.NewReader(quotesResponse)).Decode(&quotes)
-	if err != nil {
-		log.Printf("Error decoding quotes JSON: %v", err)
-		return Book{}, err
+// PrimaryAuthor returns the first of the contributors credited as an author,
+// or Unknown when none are
+func PrimaryAuthor(contributors []BookContributor) Author {
+	for _, c := range contributors {
+		if c.Role == ContributorAuthor {
+			return c.Author
+		}
 	}
+	return Author{ID: 0, Name: "Unknown"}
+}
+
+// SortChronologically orders books by their publish year, oldest first with
+// B.C. years before A.D. ones and books without a known year last, and then
+// by title
+func SortChronologically(books []Book) {
+	sort.SliceStable(books, func(i, j int) bool {
+		a, b := books[i], books[j]
+		if (a.PublishYear == 0) != (b.PublishYear == 0) {
+			return b.PublishYear == 0
+		}
+		if SignedYear(a) != SignedYear(b) {
+			return SignedYear(a) < SignedYear(b)
+		}
+		return a.Title < b.Title
+	})
+}
 
-	// Set the quotes for this book
-	book.Quotes = quotes
+// The model used in the connection pool
+type BookModel struct {
+	Client *postgrest.Client
+	Timeouts Timeouts
+}
 
-    if count > 0 {
-		// Fetch the author for this book based on the author id
-		authorResponse, _, err := m.Client.From("authors").Select("*", "exact", false).Eq("id", strconv.Itoa(quotes[0].AuthorID)).Single().ExecuteString()
+// Insert adds a new book to the database owned by the given user, crediting its contributors in the order given.
+// It returns ErrDuplicateISBN if another book already has the ISBN.
+func (m *BookModel) Insert(ctx context.Context, title string, publishYear int, calendarTime string, isbn string, source string, details WorkDetails, contributors []BookContributor, userID uuid.UUID) (int, error) {
+	return write(ctx, func() (int, error) {
+		data := workData(details, map[string]interface{}{
+			"title":         title,
+			"publish_year":  publishYear,
+			"calendar_time": calendarTime,
+			"isbn":          nullIfZero(isbn),
+			"source":        source,
+			"user_id":       userID,
+			"created_at":    time.Now(),
+			"updated_at":    time.Now(),
+		})
+
+		response, _, err := m.Client.From("books").Insert(data, false, "", "", "").ExecuteString()
 		if err != nil {
-			log.Printf("Error fetching author for book %d: %v", book.ID, err)
-			return Book{}, err
+			if strings.Contains(err.Error(), "books_uc_isbn") {
+				return 0, ErrDuplicateISBN
+			}
+			return 0, err
+		}
+
+		var insertedBook []Book
+		err = json.NewDecoder(strings.NewReader(string(response))).Decode(&insertedBook)
+		if err != nil {
+			return 0, err
+		}
+
+		if len(insertedBook) == 0 {
+			return 0, errors.New("no books returned in response")
 		}
 
-		var author Author
-		err = json.NewDecoder(strings.NewReader(authorResponse)).Decode(&author)
+		// Credit the book's contributors
+		err = setContributors(m.Client, insertedBook[0].ID, contributors)
+		if err != nil {
+			return 0, err
+		}
+
+		return insertedBook[0].ID, nil
+	})
+}
+
+// Get a single book by ID with its quotes, author and contributors
+func (m *BookModel) Get(ctx context.Context, id int) (Book, error) {
+	return query(ctx, m.Timeouts.Read, func() (Book, error) {
+	    var books []Book
+
+	    idStr := strconv.Itoa(id)
+
+	    response, count, err := m.Client.From("books").Select("*", "exact", false).Eq("id", idStr).ExecuteString()
+	    if err != nil {
+	        log.Printf("Error executing query: %v", err)
+	        return Book{}, err
+	    }
+
+	    if count == 0 {
+	        log.Printf("No record found for ID: %d", id)
+	        return Book{}, ErrNoRecord
+	    }
+
+	    err = json.NewDecoder(strings.NewReader(response)).Decode(&books)
+	    if err != nil {
+	        log.Printf("Error decoding JSON: %v", err)
+	        return Book{}, err
+	    }
+
+	    if len(books) == 0 {
+	        log.Printf("No book found for ID: %d", id)
+	        return Book{}, ErrNoRecord
+	    }
+
+	    book := books[0]
+
+		// Get the quotes for this book
+	    quotesResponse, _, err := selectVisibleQuotes(m.Client, Viewer(ctx), "*", "exact").Eq("book_id", strconv.Itoa(book.ID)).ExecuteString()
+	    if err != nil {
+	        log.Printf("Error fetching quotes for book %d: %v", book.ID, err)
+	        return Book{}, err
+	    }
+
+		// Decode the quotes
+		quotes := []Quote{}
+		err = json.NewDecoder(strings.NewReader(quotesResponse)).Decode(&quotes)
 		if err != nil {
-			log.Printf("Error decoding author JSON for book %d: %v", book.ID, err)
+			log.Printf("Error decoding quotes JSON: %v", err)
 			return Book{}, err
 		}
 
-		book.Author = author
-    } else {
-		log.Printf("No quotes found for book %d", book.ID)
-		book.Quotes = []Quote{}
-		book.Author = Author{ID: 0, Name: "Unknown"}
-	}
+		// Set the quotes for this book
+		books[0].Quotes = quotes
 
-    return book, nil
+		// Credit the book to its contributors
+		err = attachContributors(m.Client, books)
+		if err != nil {
+			return Book{}, err
+		}
+
+	    return books[0], nil
+	})
 }
 
-// Get a list of books by author ID
-func (m *BookModel) GetByAuthorID(authorID int) ([]Book, error) {
-    var books []Book
-
-    // First, get all quotes for this author
-    quotesResponse, count, err := m.Client.From("quotes").Select("*", "exact", false).Eq("author_id", strconv.Itoa(authorID)).ExecuteString()
-    if err != nil {
-        log.Printf("Error fetching quotes for author %d: %v", authorID, err)
-        return nil, err
-    }
-
-    if count == 0 {
-        log.Println("No quotes found for this author")
-        return []Book{}, nil
-    }
-
-    var quotes []Quote
-    err = json.NewDecoder(strings.NewReader(quotesResponse)).Decode(&quotes)
-    if err != nil {
-        log.Printf("Error decoding quotes JSON: %v", err)
-        return nil, err
-    }
-
-    // Create a map to store unique books
-    bookMap := make(map[int]*Book)
-
-    // Fetch book details for each quote and add to the map
-    for _, quote := range quotes {
-        if _, exists := bookMap[quote.BookID]; !exists {
-            bookResponse, _, err := m.Client.From("books").Select("*", "exact", false).Eq("id", strconv.Itoa(quote.BookID)).Single().ExecuteString()
-            if err != nil {
-                log.Printf("Error fetching book for quote %d: %v", quote.ID, err)
-                continue
-            }
-
-            var book Book
-            err = json.NewDecoder(strings.NewReader(bookResponse)).Decode(&book)
-            if err != nil {
-                log.Printf("Error decoding book JSON for quote %d: %v", quote.ID, err)
-                continue
-            }
-
-            bookMap[quote.BookID] = &book
-        }
-
-        // Add the quote to the book's quotes slice
-        bookMap[quote.BookID].Quotes = append(bookMap[quote.BookID].Quotes, quote)
-    }
-
-    // Convert the map to a slice
-    for _, book := range bookMap {
-        books = append(books, *book)
-    }
-
-    // Fetch author details
-    authorResponse, _, err := m.Client.From("authors").Select("*", "exact", false).Eq("id", strconv.Itoa(authorID)).Single().ExecuteString()
-    if err != nil {
-        log.Printf("Error fetching author %d: %v", authorID, err)
-        return books, nil // Return books without author details
-    }
-
-    var author Author
-    err = json.NewDecoder(strings.NewReader(authorResponse)).Decode(&author)
-    if err != nil {
-        log.Printf("Error decoding author JSON: %v", err)
-        return books, nil // Return books without author details
-    }
-
-    // Set the author for all books
-    for i := range books {
-        books[i].Author = author
-    }
-
-    return books, nil
+// Get the books an author is credited on in any role, ordered by title, each
+// with its contributors and quotes
+func (m *BookModel) GetByAuthorID(ctx context.Context, authorID int) ([]Book, error) {
+	return query(ctx, m.Timeouts.Read, func() ([]Book, error) {
+	    // Find the books the author is credited on
+	    bookIDs, err := creditedBookIDs(m.Client, authorID)
+	    if err != nil {
+	        return nil, err
+	    }
+
+	    bookMap, err := booksByID(m.Client, bookIDs)
+	    if err != nil {
+	        return nil, err
+	    }
+	    if len(bookMap) == 0 {
+	        return []Book{}, nil
+	    }
+
+	    // Fetch the quotes of all the books in a single request
+	    var quotes []Quote
+	    _, err = selectVisibleQuotes(m.Client, Viewer(ctx), "*", "").In("book_id", idList(bookIDs)).Order("id", &postgrest.OrderOpts{Ascending: true}).ExecuteTo(&quotes)
+	    if err != nil {
+	        log.Printf("Error fetching quotes for author %d: %v", authorID, err)
+	        return nil, err
+	    }
+
+	    // Group the quotes under their books
+	    for id, book := range bookMap {
+	        book.Quotes = []Quote{}
+	        bookMap[id] = book
+	    }
+	    for _, quote := range quotes {
+	        book := bookMap[quote.BookID]
+	        book.Quotes = append(book.Quotes, quote)
+	        bookMap[quote.BookID] = book
+	    }
+
+	    // Convert the map to a slice ordered by title
+	    books := make([]Book, 0, len(bookMap))
+	    for _, book := range bookMap {
+	        books = append(books, book)
+	    }
+	    sort.Slice(books, func(i, j int) bool {
+	        if books[i].Title != books[j].Title {
+	            return books[i].Title < books[j].Title
+	        }
+	        return books[i].ID < books[j].ID
+	    })
+
+	    // Credit the books to their contributors
+	    err = attachContributors(m.Client, books)
+	    if err != nil {
+	        return nil, err
+	    }
+
+	    return books, nil
+	})
 }
 
-// Get all books with authors
-func (m *BookModel) GetAllWithAuthors() ([]Book, error) {
-    var books []Book
-
-    response, count, err := m.Client.From("books").Select("*", "exact", false).ExecuteString()
-    if err != nil {
-        log.Printf("Error fetching books: %v", err)
-        return nil, err
-    }
-
-    if count == 0 {
-        log.Println("No books found")
-        return []Book{}, nil
-    }
-
-    err = json.NewDecoder(strings.NewReader(string(response))).Decode(&books)
-    if err != nil {
-        log.Printf("Error decoding books JSON: %v", err)
-        return nil, err
-    }
-
-    for i, book := range books {
-
-        // Fetch quotes for this book
-        var quotes []Quote
-        quotesResponse, _, err := m.Client.From("quotes").Select("*", "exact", false).Eq("book_id", strconv.Itoa(book.ID)).ExecuteString()
-        if err != nil {
-            log.Printf("Error fetching quotes for book %d: %v", book.ID, err)
-            continue
-        }
-
-        err = json.NewDecoder(strings.NewReader(quotesResponse)).Decode(&quotes)
-        if err != nil {
-            log.Printf("Error decoding quotes JSON for book %d: %v", book.ID, err)
-            continue
-        }
-
-        if len(quotes) > 0 {
-            // Fetch the author of the first quote
-            var author Author
-            authorResponse, _, err := m.Client.From("authors").Select("*", "exact", false).Eq("id", strconv.Itoa(quotes[0].AuthorID)).Single().ExecuteString()
-            if err != nil {
-                log.Printf("Error fetching author for book %d: %v", book.ID, err)
-                books[i].Author = Author{ID: 0, Name: "Unknown"}
-                continue
-            }
-
-            err = json.NewDecoder(strings.NewReader(authorResponse)).Decode(&author)
-            if err != nil {
-                log.Printf("Error decoding author JSON for book %d: %v", book.ID, err)
-                books[i].Author = Author{ID: 0, Name: "Unknown"}
-                continue
-            }
-
-            books[i].Author = author
-        } else {
-            books[i].Author = Author{ID: 0, Name: "Unknown"}
-        }
-    }
-
-    return books, nil
+// Get a page of books with their authors, ordered by title unless another order is asked for
+func (m *BookModel) GetAllWithAuthors(ctx context.Context, filters Filters) ([]Book, Metadata, error) {
+	page, err := query(ctx, m.Timeouts.Read, func() (listing[Book], error) {
+	    // PostgREST can't order by another table or by a count, so those orders are paged by a function
+	    if filters.Sort == SortAuthor || filters.Sort == SortMostQuoted {
+	        return m.listByQuotes(Viewer(ctx), filters)
+	    }
+
+	    // Query the database for the page of books
+	    builder := m.Client.From("books").Select("*", "exact", false)
+	    switch filters.Sort {
+	    case SortNewest:
+	        builder = builder.Order("created_at", &postgrest.OrderOpts{Ascending: false}).Order("id", &postgrest.OrderOpts{Ascending: false})
+	    case SortOldest:
+	        builder = builder.Order("created_at", &postgrest.OrderOpts{Ascending: true}).Order("id", &postgrest.OrderOpts{Ascending: true})
+	    default:
+	        builder = builder.Order("title", &postgrest.OrderOpts{Ascending: true}).Order("id", &postgrest.OrderOpts{Ascending: true})
+	    }
+
+	    books := []Book{}
+	    total, err := builder.Range(filters.Offset(), filters.Offset()+filters.Limit()-1, "").ExecuteTo(&books)
+	    if err != nil {
+	        log.Printf("Error fetching books: %v", err)
+	        return listing[Book]{}, err
+	    }
+
+	    // Credit the books to their contributors
+	    err = attachContributors(m.Client, books)
+	    if err != nil {
+	        return listing[Book]{}, err
+	    }
+
+	    return listing[Book]{books, CalculateMetadata(int(total), filters.Page, filters.PageSize)}, nil
+	})
+	return page.rows, page.metadata, err
 }
 
-// Update a book by ID
-func (m *BookModel) Update(id int, title string, publishYear int, calendarTime string, isbn string, source string) error {
-	data := map[string]interface{}{
-		"title":         title,
-		"publish_year":  publishYear,
-		"calendar_time": calendarTime,
-		"isbn":          isbn,
-		"source":        source,
-		"updated_at":    time.Now(),
+// Fetch a page of books ordered by author or by the number of quotes the
+// viewer can see, which the list_books function works out on the server
+func (m *BookModel) listByQuotes(viewer uuid.UUID, filters Filters) (listing[Book], error) {
+	rows, total, err := listPage(m.Client, "list_books", listArgs{Viewer: viewer, Sort: filters.Sort, Limit: filters.Limit(), Offset: filters.Offset()})
+	if err != nil {
+		return listing[Book]{}, err
 	}
 
-	idStr := strconv.Itoa(id)
-
-	_, _, err := m.Client.From("books").Update(data, "", "exact").Eq("id", idStr).Execute()
+	// Fetch the books on the page, keeping the function's order, and credit them to their contributors
+	books, err := rowsByID(m.Client, "books", pageIDs(rows), func(b Book) int { return b.ID })
 	if err != nil {
-		log.Printf("Error updating book: %v", err)
-		return err
+		return listing[Book]{}, err
+	}
+	err = attachContributors(m.Client, books)
+	if err != nil {
+		return listing[Book]{}, err
 	}
 
-	return nil
+	return listing[Book]{books, CalculateMetadata(total, filters.Page, filters.PageSize)}, nil
 }
 
-// Delete a book by ID
-func (m *BookModel) Delete(id int) error {
-	idStr := strconv.Itoa(id)
+// Attaches the contributors of each book in two requests, crediting each
+// book to the first of them credited as an author
+func attachContributors(client *postgrest.Client, books []Book) error {
+	bookIDs := make([]int, len(books))
+	for i, book := range books {
+		bookIDs[i] = book.ID
+	}
 
-	_, _, err := m.Client.From("books").Delete("", "exact").Eq("id", idStr).Execute()
+	contributors, err := contributorsByBookID(client, bookIDs)
 	if err != nil {
-		log.Printf("Error deleting book: %v", err)
 		return err
 	}
 
+	for i, book := range books {
+		books[i].Contributors = contributors[book.ID]
+		books[i].Author = PrimaryAuthor(books[i].Contributors)
+	}
 	return nil
 }
 
-// Get all books
-func (m *BookModel) GetAll() ([]Book, error) {
-	response, count, err := m.Client.From("books").Select("*", "exact", false).ExecuteString()
+// Replaces the contributors of a book, crediting them in the order given
+func setContributors(client *postgrest.Client, bookID int, contributors []BookContributor) error {
+	_, _, err := client.From("book_contributors").Delete("", "").Eq("book_id", strconv.Itoa(bookID)).Execute()
 	if err != nil {
-		return nil, err
+		log.Printf("Error deleting contributors: %v", err)
+		return err
 	}
 
-
-	// If no books were found, return an empty slice
-	if count == 0 {
-		return []Book{}, nil
+	// Skip the insert when the book has no contributors
+	if len(contributors) == 0 {
+		return nil
 	}
 
-	var books []Book
-	err = json.NewDecoder(strings.NewReader(string(response))).Decode(&books)
+	rows := make([]bookContributorRow, len(contributors))
+	for i, c := range contributors {
+		rows[i] = bookContributorRow{BookID: bookID, AuthorID: c.AuthorID, Role: c.Role, Position: i}
+	}
+	_, _, err = client.From("book_contributors").Insert(rows, false, "", "", "").Execute()
 	if err != nil {
-		return nil,  err
+		log.Printf("Error inserting contributors: %v", err)
+		return err
 	}
 
+	return nil
+}
+
+// Update a book by ID, replacing its contributors with the ones given. It
+// returns ErrDuplicateISBN if another book already has the ISBN.
+func (m *BookModel) Update(ctx context.Context, id int, title string, publishYear int, calendarTime string, isbn string, source string, details WorkDetails, contributors []BookContributor) error {
+	return exec(ctx, func() error {
+		data := workData(details, map[string]interface{}{
+			"title":         title,
+			"publish_year":  publishYear,
+			"calendar_time": calendarTime,
+			"isbn":          nullIfZero(isbn),
+			"source":        source,
+			"updated_at":    time.Now(),
+		})
+
+		idStr := strconv.Itoa(id)
+
+		_, _, err := m.Client.From("books").Update(data, "", "exact").Eq("id", idStr).Execute()
+		if err != nil {
+			if strings.Contains(err.Error(), "books_uc_isbn") {
+				return ErrDuplicateISBN
+			}
+			log.Printf("Error updating book: %v", err)
+			return err
+		}
+
+		return setContributors(m.Client, id, contributors)
+	})
+}
+
+// Delete a book by ID along with its contributors and editions, refusing with
+// ErrBookHasQuotes while quotes still refer to it
+func (m *BookModel) Delete(ctx context.Context, id int) error {
+	return exec(ctx, func() error {
+		idStr := strconv.Itoa(id)
+
+		// Count the book's quotes, whoever can see them, before anything is removed
+		_, count, err := m.Client.From("quotes").Select("id", "exact", true).Eq("book_id", idStr).Execute()
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrBookHasQuotes
+		}
+
+		// The book's credits and editions go with it through their ON DELETE CASCADE references
+		_, _, err = m.Client.From("books").Delete("", "exact").Eq("id", idStr).Execute()
+		if err != nil {
+			log.Printf("Error deleting book: %v", err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// Get all books
+func (m *BookModel) GetAll(ctx context.Context) ([]Book, error) {
+	return query(ctx, m.Timeouts.Read, func() ([]Book, error) {
+		response, count, err := m.Client.From("books").Select("*", "exact", false).ExecuteString()
+		if err != nil {
+			return nil, err
+		}
+
+		// If no books were found, return an empty slice
+		if count == 0 {
+			return []Book{}, nil
+		}
 
-	return books, nil
+		var books []Book
+		err = json.NewDecoder(strings.NewReader(string(response))).Decode(&books)
+		if err != nil {
+			return nil, err
+		}
+
+		return books, nil
+	})
 }
 
 // Check if the book exists
-func (m *BookModel) Exists(id int) (bool, error) {
-	idStr := strconv.Itoa(id)
+func (m *BookModel) Exists(ctx context.Context, id int) (bool, error) {
+	return query(ctx, m.Timeouts.Read, func() (bool, error) {
+		idStr := strconv.Itoa(id)
 
-	response, count, err := m.Client.From("books").Select("id", "exact", false).Eq("id", idStr).ExecuteString()
-	if err != nil {
-		if strings.Contains(err.Error(), "PGRST116") {
-			// No rows returned
-			return false, nil
+		response, count, err := m.Client.From("books").Select("id", "exact", false).Eq("id", idStr).ExecuteString()
+		if err != nil {
+			if strings.Contains(err.Error(), "PGRST116") {
+				// No rows returned
+				return false, nil
+			}
+			return false, err
 		}
-		return false, err
-	}
 
-	// If count is 0, book doesn't exist
-	if count == 0 {
-		return false, nil
-	}
+		// If count is 0, book doesn't exist
+		if count == 0 {
+			return false, nil
+		}
 
-	// Parse the JSON response
-	var books []struct {
-		ID int `json:"id"`
-	}
+		// Parse the JSON response
+		var books []struct {
+			ID int `json:"id"`
+		}
 
-	// Decode the JSON response
-	err = json.NewDecoder(strings.NewReader(string(response))).Decode(&books)
-	if err != nil {
-		return false, err
-	}
+		// Decode the JSON response
+		err = json.NewDecoder(strings.NewReader(string(response))).Decode(&books)
+		if err != nil {
+			return false, err
+		}
 
-	// Check if any book was found
-	return len(books) > 0, nil
+		// Check if any book was found
+		return len(books) > 0, nil
+	})
 }
 
-// Set the AuthUserID for the book
-func (m *BookModel) SetAuthUserID(id uuid.UUID) {
-	m.AuthUserID = id
-}
\ No newline at end of file
+// GetByISBN returns the book with the ISBN, without its contributors or
+// quotes, or ErrNoRecord if no book has it
+func (m *BookModel) GetByISBN(ctx context.Context, isbn string) (Book, error) {
+	return query(ctx, m.Timeouts.Read, func() (Book, error) {
+		var books []Book
+		_, err := m.Client.From("books").Select("*", "", false).Eq("isbn", isbn).ExecuteTo(&books)
+		if err != nil {
+			return Book{}, err
+		}
+		if len(books) == 0 {
+			return Book{}, ErrNoRecord
+		}
+		return books[0], nil
+	})
+}
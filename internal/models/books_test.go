@@ -1,17 +1,16 @@
 package models
 
 import (
+	"context"
+	"fmt"
+	"strconv"
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/postgresttest"
 )
 
 func TestBookModelExists(t *testing.T) {
-	// Skip the test if the "-short" flag is passed
-	if testing.Short() {
-		t.Skip("models: skipping integration tests in short mode")
-	}
-
 	// Set up a tests struct
 	tests := []struct {
 		name string
@@ -43,7 +42,7 @@ func TestBookModelExists(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Call the Exists method to check if the book exists
-			exists, err := m.Exists(tt.bookID)
+			exists, err := m.Exists(context.Background(), tt.bookID)
 
 			// Check for error first
 			if err != nil {
@@ -58,41 +57,379 @@ func TestBookModelExists(t *testing.T) {
 	}
 }
 
-func TestBookModelSetAuthUserID(t *testing.T) {
-	// Skip the test if the "-short" flag is passed
-	if testing.Short() {
-		t.Skip("models: skipping integration tests in short mode")
+func TestBookModelGetAllWithAuthors(t *testing.T) {
+	// Seed the fake server with a library of books
+	ts, db := newTestServer(t)
+	seedTestLibrary(t, ts, 25)
+
+	// Add a book without any quotes
+	if _, err := ts.Insert("books", postgresttest.Row{"title": "Unquoted"}); err != nil {
+		t.Fatal(err)
 	}
+	ts.ResetRequests()
 
-	// Create a new test database
-	db := newTestDatabase(t)
+	// Create a new BookModel instance
+	m := BookModel{Client: db}
+
+	books, metadata, err := m.GetAllWithAuthors(context.Background(), Filters{Page: 1, PageSize: 50, Sort: SortTitle})
+	if err != nil {
+		t.Fatalf("Error in GetAllWithAuthors method: %v", err)
+	}
+
+	// Each book is credited to its author, or Unknown without one
+	if len(books) != 26 {
+		t.Fatalf("got %d books, want 26", len(books))
+	}
+	for i, book := range books[:25] {
+		want := fmt.Sprintf("Author %d", (i+1)%5+1)
+		if book.Author.Name != want {
+			t.Errorf("book %d has author %q, want %q", book.ID, book.Author.Name, want)
+		}
+	}
+	if books[25].Author.Name != "Unknown" {
+		t.Errorf("got author %q for unquoted book, want %q", books[25].Author.Name, "Unknown")
+	}
+	if metadata.TotalRecords != 26 || metadata.LastPage != 1 {
+		t.Errorf("got metadata %+v, want 26 records on 1 page", metadata)
+	}
+
+	// One request each for the books, their contributors and the authors
+	if got := ts.Requests(); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+}
+
+func TestBookModelGetByAuthorID(t *testing.T) {
+	// Seed the fake server with a library of books
+	ts, db := newTestServer(t)
+	seedTestLibrary(t, ts, 25)
 
 	// Create a new BookModel instance
 	m := BookModel{Client: db}
 
-	// Create a new user
-	userID := uuid.New()
+	books, err := m.GetByAuthorID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Error in GetByAuthorID method: %v", err)
+	}
+
+	// Author 1 is credited with every fifth book, ordered by title
+	if len(books) != 5 {
+		t.Fatalf("got %d books, want 5", len(books))
+	}
+	for i, book := range books {
+		wantTitle := fmt.Sprintf("Book %04d", (i+1)*5)
+		if book.Title != wantTitle || book.Author.ID != 1 || len(book.Quotes) != 1 {
+			t.Errorf("got book %q by %d with %d quotes, want %q by 1 with 1 quote", book.Title, book.Author.ID, len(book.Quotes), wantTitle)
+		}
+	}
+
+	// One request each for the credits, their books, the quotes, the contributors and their authors
+	if got := ts.Requests(); got != 5 {
+		t.Errorf("got %d requests, want 5", got)
+	}
+}
+
+func TestBookModelContributors(t *testing.T) {
+	ts, db := newTestServer(t)
+	_, err := ts.Insert("authors", postgresttest.Row{"name": "Seneca"}, postgresttest.Row{"name": "Robin Campbell"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := BookModel{Client: db}
+	authors := AuthorModel{Client: db}
+	ctx := context.Background()
+
+	// Credit the translator before the author
+	contributors := []BookContributor{{AuthorID: 2, Role: ContributorTranslator}, {AuthorID: 1, Role: ContributorAuthor}}
+	id, err := m.Insert(ctx, "Letters from a Stoic", 65, "A.D.", "", "", WorkDetails{}, contributors, uuid.New())
+	if err != nil {
+		t.Fatalf("Error in Insert method: %v", err)
+	}
+
+	// The first contributor credited as an author is the book's author
+	book, err := m.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("Error in Get method: %v", err)
+	}
+	if book.Author.Name != "Seneca" {
+		t.Errorf("got author %q, want %q", book.Author.Name, "Seneca")
+	}
+	if len(book.Contributors) != 2 || book.Contributors[0].Author.Name != "Robin Campbell" || book.Contributors[0].Role != ContributorTranslator {
+		t.Errorf("got contributors %+v, want the translator first", book.Contributors)
+	}
+
+	// An author credited on a book can't be deleted
+	if err := authors.Delete(ctx, 2); err != ErrAuthorHasBooks {
+		t.Errorf("got error %v deleting a credited author, want %v", err, ErrAuthorHasBooks)
+	}
+
+	// Updating replaces the contributors
+	err = m.Update(ctx, id, "Letters from a Stoic", 65, "A.D.", "", "", WorkDetails{}, []BookContributor{{AuthorID: 1, Role: ContributorAuthor}})
+	if err != nil {
+		t.Fatalf("Error in Update method: %v", err)
+	}
+	books, err := m.GetByAuthorID(ctx, 2)
+	if err != nil {
+		t.Fatalf("Error in GetByAuthorID method: %v", err)
+	}
+	if len(books) != 0 {
+		t.Errorf("got %d books for the uncredited translator, want 0", len(books))
+	}
+
+	// Deleting the book removes its credits
+	if err := m.Delete(ctx, id); err != nil {
+		t.Fatalf("Error in Delete method: %v", err)
+	}
+	if rows := ts.Rows("book_contributors"); len(rows) != 0 {
+		t.Errorf("got %d credits left, want 0", len(rows))
+	}
+}
+
+func TestBookModelWorkDetails(t *testing.T) {
+	ts, db := newTestServer(t)
+	m := BookModel{Client: db}
+	ctx := context.Background()
+
+	// A podcast keeps its release date, with the episode's URL as its source
+	details := WorkDetails{MediaType: MediaPodcast, Date: "2023-05-01"}
+	id, err := m.Insert(ctx, "The Daily Stoic", 2023, "A.D.", "", "https://example.com/episodes/1", details, nil, uuid.New())
+	if err != nil {
+		t.Fatalf("Error in Insert method: %v", err)
+	}
+	if rows := ts.Rows("books"); rows[0]["media_type"] != "podcast" || rows[0]["work_date"] != "2023-05-01" {
+		t.Errorf("got row %v, want a podcast released 2023-05-01", rows[0])
+	}
+
+	book, err := m.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("Error in Get method: %v", err)
+	}
+	if book.WorkDetails != details {
+		t.Errorf("got details %+v, want %+v", book.WorkDetails, details)
+	}
+
+	// Updating without a media type stores a book
+	err = m.Update(ctx, id, "The Daily Stoic", 2016, "A.D.", "", "", WorkDetails{}, nil)
+	if err != nil {
+		t.Fatalf("Error in Update method: %v", err)
+	}
+	book, err = m.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("Error in Get method: %v", err)
+	}
+	if book.MediaType != MediaBook || book.Date != "" {
+		t.Errorf("got details %+v, want a book", book.WorkDetails)
+	}
+}
+
+func TestBookModelDuplicateISBN(t *testing.T) {
+	ts, db := newTestServer(t)
+	m := BookModel{Client: db}
+	ctx := context.Background()
+	details := WorkDetails{MediaType: MediaBook}
+
+	id, err := m.Insert(ctx, "Meditations", 180, "A.D.", "9780140449334", "", details, nil, uuid.New())
+	if err != nil {
+		t.Fatalf("Error in Insert method: %v", err)
+	}
+
+	// Another book can't be added with the same ISBN, or changed to have it
+	_, err = m.Insert(ctx, "Meditations (Hays)", 180, "A.D.", "9780140449334", "", details, nil, uuid.New())
+	if err != ErrDuplicateISBN {
+		t.Errorf("got error %v, want %v", err, ErrDuplicateISBN)
+	}
+
+	other, err := m.Insert(ctx, "Letters from a Stoic", 65, "A.D.", "9780140442106", "", details, nil, uuid.New())
+	if err != nil {
+		t.Fatalf("Error in Insert method: %v", err)
+	}
+	err = m.Update(ctx, other, "Letters from a Stoic", 65, "A.D.", "9780140449334", "", details, nil)
+	if err != ErrDuplicateISBN {
+		t.Errorf("got error %v, want %v", err, ErrDuplicateISBN)
+	}
+
+	// Works without an ISBN store none, so any number of them can be added
+	err = m.Update(ctx, id, "Meditations", 180, "A.D.", "", "", WorkDetails{MediaType: MediaLetter}, nil)
+	if err != nil {
+		t.Fatalf("Error in Update method: %v", err)
+	}
+	_, err = m.Insert(ctx, "Moral Letters", 65, "A.D.", "", "", WorkDetails{MediaType: MediaLetter}, nil, uuid.New())
+	if err != nil {
+		t.Fatalf("Error in Insert method: %v", err)
+	}
+	if rows := ts.Rows("books"); rows[0]["isbn"] != nil {
+		t.Errorf("got ISBN %v, want null", rows[0]["isbn"])
+	}
+}
+
+func TestBookModelGetByISBN(t *testing.T) {
+	_, db := newTestServer(t)
+	m := BookModel{Client: db}
+	ctx := context.Background()
+	details := WorkDetails{MediaType: MediaBook}
+
+	id, err := m.Insert(ctx, "Meditations", 180, "A.D.", "9780140449334", "", details, nil, uuid.New())
+	if err != nil {
+		t.Fatalf("Error in Insert method: %v", err)
+	}
 
-	// Test cases
-	testCases := []struct {
-		name     string
-		userID   uuid.UUID
-		expected uuid.UUID
+	// Check the book with the ISBN is found, and an unused ISBN isn't
+	book, err := m.GetByISBN(ctx, "9780140449334")
+	if err != nil {
+		t.Fatalf("Error in GetByISBN method: %v", err)
+	}
+	if book.ID != id || book.Title != "Meditations" {
+		t.Errorf("got book %d %q, want %d %q", book.ID, book.Title, id, "Meditations")
+	}
+
+	_, err = m.GetByISBN(ctx, "9780000000019")
+	if err != ErrNoRecord {
+		t.Errorf("got error %v, want %v", err, ErrNoRecord)
+	}
+}
+
+func TestBookModelDeleteWithQuotes(t *testing.T) {
+	ts, db := newTestServer(t)
+	m := BookModel{Client: db}
+	ctx := context.Background()
+
+	seedTestLibrary(t, ts, 1)
+	if _, err := ts.Insert("editions", postgresttest.Row{"book_id": 1, "language": "Latin"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Check a book with quotes is refused before any of its credits or editions are removed
+	err := m.Delete(ctx, 1)
+	if err != ErrBookHasQuotes {
+		t.Errorf("got error %v, want %v", err, ErrBookHasQuotes)
+	}
+	for _, table := range []string{"books", "book_contributors", "editions"} {
+		if rows := ts.Rows(table); len(rows) != 1 {
+			t.Errorf("got %d %s rows, want 1", len(rows), table)
+		}
+	}
+
+	// Check the book is deleted with its credits and editions once its quote is gone
+	err = (&QuoteModel{Client: db}).Delete(ctx, 1)
+	if err != nil {
+		t.Fatalf("Error in Delete method: %v", err)
+	}
+	err = m.Delete(ctx, 1)
+	if err != nil {
+		t.Fatalf("Error in Delete method: %v", err)
+	}
+	for _, table := range []string{"books", "book_contributors", "editions"} {
+		if rows := ts.Rows(table); len(rows) != 0 {
+			t.Errorf("got %d %s rows, want 0", len(rows), table)
+		}
+	}
+}
+
+func TestBookModelGetAllWithAuthorsPages(t *testing.T) {
+	// Seed the fake server with a library of books
+	ts, db := newTestServer(t)
+	seedTestLibrary(t, ts, 12)
+
+	// Give the last book a second quote so it is the most quoted
+	_, err := ts.Insert("quotes", postgresttest.Row{"quote": "Another quote", "author_id": 1, "book_id": 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a new BookModel instance
+	m := BookModel{Client: db}
+
+	tests := []struct {
+		name       string
+		filters    Filters
+		wantTitles []string
+		wantLast   int
 	}{
-		{"Set valid user ID", userID, userID},
-		{"Set empty user ID", uuid.Nil, uuid.Nil},
+		{"First page by title", Filters{Page: 1, PageSize: 5, Sort: SortTitle}, []string{"Book 0001", "Book 0002", "Book 0003", "Book 0004", "Book 0005"}, 3},
+		{"Last page by title", Filters{Page: 3, PageSize: 5, Sort: SortTitle}, []string{"Book 0011", "Book 0012"}, 3},
+		{"Past the last page", Filters{Page: 4, PageSize: 5, Sort: SortTitle}, []string{}, 3},
+		{"Newest", Filters{Page: 1, PageSize: 2, Sort: SortNewest}, []string{"Book 0012", "Book 0011"}, 6},
+		{"Oldest", Filters{Page: 1, PageSize: 2, Sort: SortOldest}, []string{"Book 0001", "Book 0002"}, 6},
+		{"Author", Filters{Page: 1, PageSize: 3, Sort: SortAuthor}, []string{"Book 0005", "Book 0010", "Book 0001"}, 4},
+		{"Most quoted", Filters{Page: 1, PageSize: 2, Sort: SortMostQuoted}, []string{"Book 0012", "Book 0001"}, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			books, metadata, err := m.GetAllWithAuthors(context.Background(), tt.filters)
+			if err != nil {
+				t.Fatalf("Error in GetAllWithAuthors method: %v", err)
+			}
+
+			titles := []string{}
+			for _, book := range books {
+				titles = append(titles, book.Title)
+			}
+			if fmt.Sprint(titles) != fmt.Sprint(tt.wantTitles) {
+				t.Errorf("got titles %v, want %v", titles, tt.wantTitles)
+			}
+			if metadata.TotalRecords != 12 || metadata.LastPage != tt.wantLast {
+				t.Errorf("got metadata %+v, want 12 records on %d pages", metadata, tt.wantLast)
+			}
+		})
+	}
+}
+
+func BenchmarkBookModelGetAllWithAuthors(b *testing.B) {
+	for _, size := range []int{10, 100, 1000} {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			ts, db := newTestServer(b)
+			seedTestLibrary(b, ts, size)
+			m := BookModel{Client: db}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := m.GetAllWithAuthors(context.Background(), Filters{Page: 1, PageSize: DefaultPageSize, Sort: SortTitle}); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			// Report the round trips per call, which must not grow with the size
+			b.ReportMetric(float64(ts.Requests())/float64(b.N), "requests/op")
+		})
 	}
+}
 
-	// Loop through each test case
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			// Set the AuthUserID
-			m.SetAuthUserID(tc.userID)
+func BenchmarkBookModelGetByAuthorID(b *testing.B) {
+	for _, size := range []int{10, 100, 1000} {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			ts, db := newTestServer(b)
+			seedTestLibrary(b, ts, size)
+			m := BookModel{Client: db}
 
-			// Check if the AuthUserID was set correctly
-			if m.AuthUserID != tc.expected {
-				t.Errorf("Expected AuthUserID to be %s, but got %s", tc.expected, m.AuthUserID)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := m.GetByAuthorID(context.Background(), 1); err != nil {
+					b.Fatal(err)
+				}
 			}
+
+			// Report the round trips per call, which must not grow with the size
+			b.ReportMetric(float64(ts.Requests())/float64(b.N), "requests/op")
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestSortChronologically(t *testing.T) {
+	books := []Book{
+		{ID: 1, Title: "Natural Questions", PublishYear: 65, CalendarTime: "A.D."},
+		{ID: 2, Title: "Undated"},
+		{ID: 3, Title: "On Anger", PublishYear: 45, CalendarTime: "A.D."},
+		{ID: 4, Title: "Republic", PublishYear: 375, CalendarTime: "B.C."},
+		{ID: 5, Title: "Consolation to Marcia", PublishYear: 45, CalendarTime: "A.D."},
+	}
+
+	// B.C. years come first, books from the same year go by title, and undated books go last
+	SortChronologically(books)
+	for i, id := range []int{4, 5, 3, 1, 2} {
+		if books[i].ID != id {
+			t.Errorf("Expected book %d at position %d, got book %d", id, i, books[i].ID)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package models
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/supabase-community/postgrest-go"
+)
+
+// Define an interface for the EditionModel
+type EditionModelInterface interface {
+	Insert(ctx context.Context, bookID int, details EditionDetails, userID uuid.UUID) (int, error)
+	Get(ctx context.Context, id int) (Edition, error)
+	GetByBookID(ctx context.Context, bookID int) ([]Edition, error)
+	GetByISBN(ctx context.Context, isbn string) (Edition, error)
+	GetAll(ctx context.Context) ([]Edition, error)
+	Update(ctx context.Context, id int, details EditionDetails) error
+	Delete(ctx context.Context, id int) error
+}
+
+// Edition is one published edition or translation of a work. The book itself
+// keeps the details of the edition it was added with, and its editions are
+// the others, each of which quotes can be taken from.
+type Edition struct {
+	ID     int `json:"id"`
+	BookID int `json:"book_id"`
+	EditionDetails
+	// The author credited as the edition's translator, if it has one
+	Translator Author    `json:"-"`
+	UserID     uuid.UUID `json:"user_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// EditionDetails are what is known about an edition, all of which is
+// optional: its ISBN-13, publisher, language, the ID of the author who
+// translated it, the year it was published, A.D., and its number of pages.
+// Unknown numbers are 0.
+type EditionDetails struct {
+	ISBN         string `json:"isbn"`
+	Publisher    string `json:"publisher"`
+	Language     string `json:"language"`
+	TranslatorID int    `json:"translator_id"`
+	PublishYear  int    `json:"publish_year"`
+	PageCount    int    `json:"page_count"`
+}
+
+// Label describes the edition in lists, such as "Penguin Classics, 2006,
+// English, translated by Gregory Hays", falling back to its ISBN
+func (e Edition) Label() string {
+	var parts []string
+	if e.Publisher != "" {
+		parts = append(parts, e.Publisher)
+	}
+	if e.PublishYear != 0 {
+		parts = append(parts, strconv.Itoa(e.PublishYear))
+	}
+	if e.Language != "" {
+		parts = append(parts, e.Language)
+	}
+	if e.Translator.Name != "" {
+		parts = append(parts, "translated by "+e.Translator.Name)
+	}
+
+	switch {
+	case len(parts) > 0:
+		return strings.Join(parts, ", ")
+	case e.ISBN != "":
+		return "ISBN " + e.ISBN
+	}
+	return fmt.Sprintf("Edition %d", e.ID)
+}
+
+// SortEditions orders editions by their book, then oldest first, with the
+// editions whose year isn't known last
+func SortEditions(editions []Edition) {
+	slices.SortFunc(editions, func(a, b Edition) int {
+		return cmp.Or(
+			cmp.Compare(a.BookID, b.BookID),
+			cmp.Compare(boolRank(a.PublishYear == 0), boolRank(b.PublishYear == 0)),
+			cmp.Compare(a.PublishYear, b.PublishYear),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+}
+
+// Returns the values written for an edition's details, with the ones that
+// aren't known left null
+func editionData(details EditionDetails, data map[string]interface{}) map[string]interface{} {
+	data["isbn"] = nullIfZero(details.ISBN)
+	data["publisher"] = nullIfZero(details.Publisher)
+	data["language"] = nullIfZero(details.Language)
+	data["translator_id"] = nullIfZero(details.TranslatorID)
+	data["publish_year"] = nullIfZero(details.PublishYear)
+	data["page_count"] = nullIfZero(details.PageCount)
+	return data
+}
+
+// Define an EditionModel type which wraps a database connection pool
+type EditionModel struct {
+	Client   *postgrest.Client
+	Timeouts Timeouts
+}
+
+// Insert adds an edition of a book owned by the given user, returning
+// ErrDuplicateISBN if another edition already has the ISBN
+func (m *EditionModel) Insert(ctx context.Context, bookID int, details EditionDetails, userID uuid.UUID) (int, error) {
+	return write(ctx, func() (int, error) {
+		data := editionData(details, map[string]interface{}{
+			"book_id":    bookID,
+			"user_id":    userID,
+			"created_at": time.Now(),
+			"updated_at": time.Now(),
+		})
+
+		var inserted []Edition
+		_, err := m.Client.From("editions").Insert(data, false, "", "", "").ExecuteTo(&inserted)
+		if err != nil {
+			if strings.Contains(err.Error(), "editions_uc_isbn") {
+				return 0, ErrDuplicateISBN
+			}
+			return 0, err
+		}
+		if len(inserted) == 0 {
+			return 0, errors.New("no editions returned in response")
+		}
+
+		return inserted[0].ID, nil
+	})
+}
+
+// Get a single edition by ID with its translator
+func (m *EditionModel) Get(ctx context.Context, id int) (Edition, error) {
+	return query(ctx, m.Timeouts.Read, func() (Edition, error) {
+		return m.getOne("id", strconv.Itoa(id))
+	})
+}
+
+// GetByISBN returns the edition with the ISBN, or ErrNoRecord if none has it
+func (m *EditionModel) GetByISBN(ctx context.Context, isbn string) (Edition, error) {
+	return query(ctx, m.Timeouts.Read, func() (Edition, error) {
+		return m.getOne("isbn", isbn)
+	})
+}
+
+// Returns the edition whose column has the value, with its translator
+func (m *EditionModel) getOne(column, value string) (Edition, error) {
+	var editions []Edition
+	_, err := m.Client.From("editions").Select("*", "", false).Eq(column, value).ExecuteTo(&editions)
+	if err != nil {
+		return Edition{}, err
+	}
+	if len(editions) == 0 {
+		return Edition{}, ErrNoRecord
+	}
+
+	err = attachTranslators(m.Client, editions)
+	if err != nil {
+		return Edition{}, err
+	}
+	return editions[0], nil
+}
+
+// GetByBookID returns the editions of a book with their translators, oldest first
+func (m *EditionModel) GetByBookID(ctx context.Context, bookID int) ([]Edition, error) {
+	return query(ctx, m.Timeouts.Read, func() ([]Edition, error) {
+		editions := []Edition{}
+		_, err := m.Client.From("editions").Select("*", "", false).Eq("book_id", strconv.Itoa(bookID)).ExecuteTo(&editions)
+		if err != nil {
+			return nil, err
+		}
+
+		err = attachTranslators(m.Client, editions)
+		if err != nil {
+			return nil, err
+		}
+		SortEditions(editions)
+		return editions, nil
+	})
+}
+
+// GetAll returns every edition with its translator, ordered by book and then oldest first
+func (m *EditionModel) GetAll(ctx context.Context) ([]Edition, error) {
+	return query(ctx, m.Timeouts.Read, func() ([]Edition, error) {
+		editions := []Edition{}
+		_, err := m.Client.From("editions").Select("*", "", false).ExecuteTo(&editions)
+		if err != nil {
+			return nil, err
+		}
+
+		err = attachTranslators(m.Client, editions)
+		if err != nil {
+			return nil, err
+		}
+		SortEditions(editions)
+		return editions, nil
+	})
+}
+
+// Update an edition by ID, returning ErrDuplicateISBN if another edition already has the ISBN
+func (m *EditionModel) Update(ctx context.Context, id int, details EditionDetails) error {
+	return exec(ctx, func() error {
+		data := editionData(details, map[string]interface{}{
+			"updated_at": time.Now(),
+		})
+
+		_, _, err := m.Client.From("editions").Update(data, "", "").Eq("id", strconv.Itoa(id)).Execute()
+		if err != nil && strings.Contains(err.Error(), "editions_uc_isbn") {
+			return ErrDuplicateISBN
+		}
+		return err
+	})
+}
+
+// Delete an edition by ID. Its quotes stay with the book, without an edition.
+func (m *EditionModel) Delete(ctx context.Context, id int) error {
+	return exec(ctx, func() error {
+		idStr := strconv.Itoa(id)
+
+		_, _, err := m.Client.From("quotes").Update(map[string]interface{}{"edition_id": nil}, "", "").Eq("edition_id", idStr).Execute()
+		if err != nil {
+			return err
+		}
+
+		_, _, err = m.Client.From("editions").Delete("", "").Eq("id", idStr).Execute()
+		return err
+	})
+}
+
+// Fills in the translator of each edition that has one, in a single request
+func attachTranslators(client *postgrest.Client, editions []Edition) error {
+	ids := make([]int, 0, len(editions))
+	for _, e := range editions {
+		if e.TranslatorID != 0 {
+			ids = append(ids, e.TranslatorID)
+		}
+	}
+
+	authors, err := authorsByID(client, ids)
+	if err != nil {
+		return err
+	}
+	for i, e := range editions {
+		editions[i].Translator = authors[e.TranslatorID]
+	}
+	return nil
+}
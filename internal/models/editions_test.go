@@ -0,0 +1,129 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+	"github.com/justinbachtell/quote-table-go/internal/postgresttest"
+)
+
+func TestEditionModel(t *testing.T) {
+	ts, db := newTestServer(t)
+	m := EditionModel{Client: db}
+	ctx := context.Background()
+	owner := uuid.New()
+
+	_, err := ts.Insert("authors", postgresttest.Row{"name": "Marcus Aurelius"}, postgresttest.Row{"name": "Gregory Hays"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Insert("books", postgresttest.Row{"title": "Meditations", "isbn": "9780140449334"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add a translation, and an edition whose year isn't known
+	hays := EditionDetails{ISBN: "9780812968255", Publisher: "Modern Library", Language: "English", TranslatorID: 2, PublishYear: 2003, PageCount: 256}
+	haysID, err := m.Insert(ctx, 1, hays, owner)
+	assert.NilError(t, err)
+	undatedID, err := m.Insert(ctx, 1, EditionDetails{Language: "Latin"}, owner)
+	assert.NilError(t, err)
+
+	// The unknown details are stored as nulls
+	row := ts.Rows("editions")[1]
+	assert.Equal(t, row["isbn"], nil)
+	assert.Equal(t, row["translator_id"], nil)
+
+	// An edition is read back with its translator
+	e, err := m.Get(ctx, haysID)
+	assert.NilError(t, err)
+	assert.Equal(t, e.EditionDetails, hays)
+	assert.Equal(t, e.UserID, owner)
+	assert.Equal(t, e.Label(), "Modern Library, 2003, English, translated by Gregory Hays")
+
+	e, err = m.GetByISBN(ctx, "9780812968255")
+	assert.NilError(t, err)
+	assert.Equal(t, e.ID, haysID)
+
+	_, err = m.Get(ctx, 9999)
+	assert.Equal(t, err, ErrNoRecord)
+
+	// A book's editions are listed oldest first, with unknown years last
+	editions, err := m.GetByBookID(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(editions), 2)
+	assert.Equal(t, editions[0].ID, haysID)
+	assert.Equal(t, editions[1].ID, undatedID)
+
+	// No two editions can share an ISBN
+	_, err = m.Insert(ctx, 1, EditionDetails{ISBN: "9780812968255"}, owner)
+	assert.Equal(t, err, ErrDuplicateISBN)
+	err = m.Update(ctx, undatedID, EditionDetails{ISBN: "9780812968255"})
+	assert.Equal(t, err, ErrDuplicateISBN)
+
+	// Deleting an edition leaves its quotes with the book
+	_, err = ts.Insert("quotes", postgresttest.Row{"quote": "Waste no more time.", "author_id": 1, "book_id": 1, "edition_id": haysID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = m.Delete(ctx, haysID)
+	assert.NilError(t, err)
+
+	quote := ts.Rows("quotes")[0]
+	assert.Equal(t, quote["edition_id"], nil)
+	assert.Equal(t, quote["book_id"], any(float64(1)))
+	assert.Equal(t, len(ts.Rows("editions")), 1)
+}
+
+func TestEditionModelTranslator(t *testing.T) {
+	ts, db := newTestServer(t)
+	m := EditionModel{Client: db}
+	authors := AuthorModel{Client: db}
+	ctx := context.Background()
+
+	_, err := ts.Insert("authors", postgresttest.Row{"name": "Gregory Hays"}, postgresttest.Row{"name": "G. Hays"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Insert("books", postgresttest.Row{"title": "Meditations"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	editionID, err := m.Insert(ctx, 1, EditionDetails{TranslatorID: 1}, uuid.New())
+	assert.NilError(t, err)
+
+	// A translator can't be deleted while they are credited on an edition
+	err = authors.Delete(ctx, 1)
+	assert.Equal(t, err, ErrAuthorHasBooks)
+
+	// Merging the translator credits the edition to the author merged into
+	_, err = authors.Merge(ctx, 1, 2)
+	assert.NilError(t, err)
+
+	e, err := m.Get(ctx, editionID)
+	assert.NilError(t, err)
+	assert.Equal(t, e.TranslatorID, 2)
+	assert.Equal(t, e.Translator.Name, "G. Hays")
+}
+
+// Tests editions are ordered by book, then oldest first with unknown years last
+func TestSortEditions(t *testing.T) {
+	editions := []Edition{
+		{ID: 1, BookID: 2, EditionDetails: EditionDetails{PublishYear: 1990}},
+		{ID: 2, BookID: 1},
+		{ID: 3, BookID: 1, EditionDetails: EditionDetails{PublishYear: 2006}},
+		{ID: 4, BookID: 1, EditionDetails: EditionDetails{PublishYear: 1964}},
+		{ID: 5, BookID: 1, EditionDetails: EditionDetails{PublishYear: 2006}},
+	}
+
+	SortEditions(editions)
+
+	want := []int{4, 3, 5, 2, 1}
+	for i, e := range editions {
+		if e.ID != want[i] {
+			t.Fatalf("got edition %d at position %d, want %d", e.ID, i, want[i])
+		}
+	}
+}
@@ -8,4 +8,20 @@ var ErrNoRecord = errors.New("models: no matching record found")
 
 var ErrInvalidCredentials = errors.New("models: invalid credentials")
 
-var ErrDuplicateEmail = errors.New("models: duplicate email")
\ No newline at end of file
+var ErrDuplicateEmail = errors.New("models: duplicate email")
+
+var ErrQueryTimeout = errors.New("models: query timed out")
+
+var ErrQueryCanceled = errors.New("models: query canceled")
+
+var ErrAuthorHasQuotes = errors.New("models: author still has quotes")
+
+var ErrBookHasQuotes = errors.New("models: book still has quotes")
+
+var ErrAuthorHasBooks = errors.New("models: author is still credited on books")
+
+var ErrMergeSameAuthor = errors.New("models: can't merge an author into itself")
+
+var ErrDuplicateAlias = errors.New("models: duplicate alias")
+
+var ErrDuplicateISBN = errors.New("models: duplicate ISBN")
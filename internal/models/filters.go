@@ -0,0 +1,104 @@
+package models
+
+// The sort orders offered by the listings
+const (
+	SortNewest     = "newest"
+	SortOldest     = "oldest"
+	SortTitle      = "title"
+	SortAuthor     = "author"
+	SortMostQuoted = "most-quoted"
+)
+
+// The sort orders each listing accepts, with its default first
+var (
+	QuoteSorts  = []string{SortNewest, SortOldest, SortAuthor}
+	BookSorts   = []string{SortTitle, SortNewest, SortOldest, SortAuthor, SortMostQuoted}
+	AuthorSorts = []string{SortAuthor, SortNewest, SortOldest, SortMostQuoted}
+)
+
+// The number of rows on a page when none is requested
+const DefaultPageSize = 20
+
+// Filters holds the page and sort order requested for a listing
+type Filters struct {
+	Page     int
+	PageSize int
+	Sort     string
+}
+
+// Limit returns the number of rows to fetch for the page
+func (f Filters) Limit() int {
+	return f.PageSize
+}
+
+// Offset returns the number of rows before the page
+func (f Filters) Offset() int {
+	return (f.Page - 1) * f.PageSize
+}
+
+// Metadata describes the page of a listing that was returned
+type Metadata struct {
+	CurrentPage  int
+	PageSize     int
+	FirstPage    int
+	LastPage     int
+	TotalRecords int
+}
+
+// CalculateMetadata returns the metadata for a page of a listing with the given total number of rows
+func CalculateMetadata(totalRecords, page, pageSize int) Metadata {
+	// An empty listing has no pages
+	if totalRecords == 0 {
+		return Metadata{}
+	}
+
+	return Metadata{
+		CurrentPage:  page,
+		PageSize:     pageSize,
+		FirstPage:    1,
+		LastPage:     (totalRecords + pageSize - 1) / pageSize,
+		TotalRecords: totalRecords,
+	}
+}
+
+// HasPrevious reports whether there is a page before the current one
+func (m Metadata) HasPrevious() bool {
+	return m.CurrentPage > m.FirstPage
+}
+
+// HasNext reports whether there is a page after the current one
+func (m Metadata) HasNext() bool {
+	return m.CurrentPage < m.LastPage
+}
+
+// Pages returns the page numbers to link to, which is every page within two of the current one
+func (m Metadata) Pages() []int {
+	if m.LastPage == 0 {
+		return nil
+	}
+
+	var pages []int
+	for page := max(m.FirstPage, m.CurrentPage-2); page <= min(m.LastPage, m.CurrentPage+2); page++ {
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+// A page of a listing, so a query can return the rows and metadata together
+type listing[T any] struct {
+	rows     []T
+	metadata Metadata
+}
+
+// Paginate returns the rows of a page from a full, sorted listing, with the page's metadata
+func Paginate[T any](rows []T, filters Filters) ([]T, Metadata) {
+	metadata := CalculateMetadata(len(rows), filters.Page, filters.PageSize)
+
+	// A page past the end is empty
+	if filters.Offset() >= len(rows) {
+		return []T{}, metadata
+	}
+
+	end := min(filters.Offset()+filters.Limit(), len(rows))
+	return rows[filters.Offset():end], metadata
+}
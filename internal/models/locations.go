@@ -0,0 +1,241 @@
+package models
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidLocation is returned when a quote's location can't be parsed
+var ErrInvalidLocation = errors.New("models: invalid location")
+
+// LocationType is how a quote's place in its work is given
+type LocationType string
+
+// The ways a quote's location can be given
+const (
+	LocationPage      LocationType = "page"
+	LocationChapter   LocationType = "chapter"
+	LocationKindle    LocationType = "kindle"
+	LocationPercent   LocationType = "percent"
+	LocationTimestamp LocationType = "timestamp"
+)
+
+// LocationTypes lists every location type in the order they are offered
+var LocationTypes = []LocationType{LocationPage, LocationChapter, LocationKindle, LocationPercent, LocationTimestamp}
+
+// Title returns the location type's name for display, such as "Kindle location"
+func (t LocationType) Title() string {
+	switch t {
+	case LocationPage:
+		return "Page"
+	case LocationChapter:
+		return "Chapter or section"
+	case LocationKindle:
+		return "Kindle location"
+	case LocationPercent:
+		return "Percentage"
+	case LocationTimestamp:
+		return "Timestamp"
+	}
+	return ""
+}
+
+// Location is where a quote is found in its work. Start is the page or first
+// page of a range, the Kindle location, the percentage or the number of
+// seconds into a recording, End is the last page of a page range, and Label
+// names a chapter or section. A quote without a location has no Type.
+type Location struct {
+	Type  LocationType `json:"location_type"`
+	Start int          `json:"location_start"`
+	End   int          `json:"location_end"`
+	Label string       `json:"location_label"`
+}
+
+// The text of a page or page range, with a hyphen or en dash between the pages
+var pageRangeRX = regexp.MustCompile(`^([0-9]+)(?:\s*[-–]\s*([0-9]+))?$`)
+
+// The text of a timestamp, as m:ss or h:mm:ss
+var timestampRX = regexp.MustCompile(`^(?:([0-9]+):)?([0-9]+):([0-5][0-9])$`)
+
+// ParseLocation parses the text entered for a location of the given type:
+// a page such as 12 or range such as 12-15, a chapter or section name, a
+// Kindle location, a percentage from 0 to 100, or a timestamp such as 4:05
+// or 1:02:03. Blank text is no location.
+func ParseLocation(t LocationType, text string) (Location, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Location{}, nil
+	}
+
+	l := Location{Type: t}
+	switch t {
+	case LocationPage:
+		m := pageRangeRX.FindStringSubmatch(text)
+		if m == nil {
+			return Location{}, ErrInvalidLocation
+		}
+		l.Start, _ = strconv.Atoi(m[1])
+		if m[2] != "" {
+			l.End, _ = strconv.Atoi(m[2])
+			if l.End < l.Start {
+				return Location{}, ErrInvalidLocation
+			}
+			if l.End == l.Start {
+				l.End = 0
+			}
+		}
+		if l.Start < 1 {
+			return Location{}, ErrInvalidLocation
+		}
+	case LocationChapter:
+		l.Label = text
+	case LocationKindle, LocationPercent:
+		if t == LocationPercent {
+			text = strings.TrimSpace(strings.TrimSuffix(text, "%"))
+		}
+		n, err := strconv.Atoi(text)
+		if err != nil || n < 0 || (t == LocationKindle && n < 1) || (t == LocationPercent && n > 100) {
+			return Location{}, ErrInvalidLocation
+		}
+		l.Start = n
+	case LocationTimestamp:
+		m := timestampRX.FindStringSubmatch(text)
+		if m == nil {
+			return Location{}, ErrInvalidLocation
+		}
+		hours, _ := strconv.Atoi(m[1])
+		minutes, _ := strconv.Atoi(m[2])
+		seconds, _ := strconv.Atoi(m[3])
+		if m[1] != "" && minutes > 59 {
+			return Location{}, ErrInvalidLocation
+		}
+		l.Start = hours*3600 + minutes*60 + seconds
+	default:
+		return Location{}, ErrInvalidLocation
+	}
+
+	return l, nil
+}
+
+// IsZero reports whether the quote has no location
+func (l Location) IsZero() bool {
+	return l.Type == ""
+}
+
+// LastPage returns the last page a page location covers, or 0 for other locations
+func (l Location) LastPage() int {
+	if l.Type != LocationPage {
+		return 0
+	}
+	return max(l.Start, l.End)
+}
+
+// Value returns the location as it is entered on the quote forms, which
+// ParseLocation turns back into the location
+func (l Location) Value() string {
+	switch l.Type {
+	case LocationPage:
+		if l.End > l.Start {
+			return fmt.Sprintf("%d-%d", l.Start, l.End)
+		}
+		return strconv.Itoa(l.Start)
+	case LocationChapter:
+		return l.Label
+	case LocationKindle, LocationPercent:
+		return strconv.Itoa(l.Start)
+	case LocationTimestamp:
+		if l.Start >= 3600 {
+			return fmt.Sprintf("%d:%02d:%02d", l.Start/3600, l.Start%3600/60, l.Start%60)
+		}
+		return fmt.Sprintf("%d:%02d", l.Start/60, l.Start%60)
+	}
+	return ""
+}
+
+// Text returns the location for display, such as "pp. 12–15" or "loc. 1234"
+func (l Location) Text() string {
+	switch l.Type {
+	case LocationPage:
+		if l.End > l.Start {
+			return fmt.Sprintf("pp. %d–%d", l.Start, l.End)
+		}
+		return fmt.Sprintf("p. %d", l.Start)
+	case LocationChapter:
+		if _, err := strconv.Atoi(l.Label); err == nil {
+			return "ch. " + l.Label
+		}
+		return l.Label
+	case LocationKindle:
+		return fmt.Sprintf("loc. %d", l.Start)
+	case LocationPercent:
+		return fmt.Sprintf("%d%%", l.Start)
+	case LocationTimestamp:
+		return l.Value()
+	}
+	return ""
+}
+
+// Compare orders locations by where they are in the work: by type in the
+// order of LocationTypes, then by page, position or time, with numbered
+// chapters in number order before named ones. Quotes without a location
+// come last.
+func (l Location) Compare(other Location) int {
+	if l.IsZero() || other.IsZero() {
+		return cmp.Compare(boolRank(l.IsZero()), boolRank(other.IsZero()))
+	}
+	if l.Type != other.Type {
+		return cmp.Compare(slices.Index(LocationTypes, l.Type), slices.Index(LocationTypes, other.Type))
+	}
+	if l.Type == LocationChapter {
+		n, errL := strconv.Atoi(l.Label)
+		o, errO := strconv.Atoi(other.Label)
+		switch {
+		case errL == nil && errO == nil:
+			return cmp.Compare(n, o)
+		case errL == nil || errO == nil:
+			return cmp.Compare(boolRank(errL != nil), boolRank(errO != nil))
+		}
+		return strings.Compare(strings.ToLower(l.Label), strings.ToLower(other.Label))
+	}
+	return cmp.Or(cmp.Compare(l.Start, other.Start), cmp.Compare(l.End, other.End))
+}
+
+// SortByLocation sorts quotes by their location in their work, keeping quotes
+// at the same location in their current order
+func SortByLocation(quotes []Quote) {
+	slices.SortStableFunc(quotes, func(a, b Quote) int {
+		return a.Location.Compare(b.Location)
+	})
+}
+
+// Returns the values written for a quote's location, with the parts the
+// location doesn't have left null
+func locationData(l Location, data map[string]interface{}) map[string]interface{} {
+	data["location_type"] = nullIfZero(l.Type)
+	data["location_start"] = nullIfZero(l.Start)
+	data["location_end"] = nullIfZero(l.End)
+	data["location_label"] = nullIfZero(l.Label)
+	return data
+}
+
+// Returns nil for a zero value, which is written as null
+func nullIfZero[T comparable](value T) any {
+	var zero T
+	if value == zero {
+		return nil
+	}
+	return value
+}
+
+// Returns 1 for true and 0 for false, so booleans can be compared
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"testing"
+)
+
+// Tests parsing each type of location and writing it back out
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		name      string
+		locType   LocationType
+		input     string
+		want      Location
+		wantValue string
+		wantText  string
+		wantErr   bool
+	}{
+		{name: "Blank", locType: LocationPage, input: "  ", want: Location{}},
+		{name: "Page", locType: LocationPage, input: " 12 ", want: Location{Type: LocationPage, Start: 12}, wantValue: "12", wantText: "p. 12"},
+		{name: "Page range", locType: LocationPage, input: "12 – 15", want: Location{Type: LocationPage, Start: 12, End: 15}, wantValue: "12-15", wantText: "pp. 12–15"},
+		{name: "Range of one page", locType: LocationPage, input: "7-7", want: Location{Type: LocationPage, Start: 7}, wantValue: "7", wantText: "p. 7"},
+		{name: "Backwards range", locType: LocationPage, input: "15-12", wantErr: true},
+		{name: "Page zero", locType: LocationPage, input: "0", wantErr: true},
+		{name: "Page with words", locType: LocationPage, input: "p. 12", wantErr: true},
+		{name: "Numbered chapter", locType: LocationChapter, input: "3", want: Location{Type: LocationChapter, Label: "3"}, wantValue: "3", wantText: "ch. 3"},
+		{name: "Named section", locType: LocationChapter, input: "Preface", want: Location{Type: LocationChapter, Label: "Preface"}, wantValue: "Preface", wantText: "Preface"},
+		{name: "Kindle location", locType: LocationKindle, input: "1234", want: Location{Type: LocationKindle, Start: 1234}, wantValue: "1234", wantText: "loc. 1234"},
+		{name: "Kindle location zero", locType: LocationKindle, input: "0", wantErr: true},
+		{name: "Percentage", locType: LocationPercent, input: "45 %", want: Location{Type: LocationPercent, Start: 45}, wantValue: "45", wantText: "45%"},
+		{name: "Percentage over 100", locType: LocationPercent, input: "101", wantErr: true},
+		{name: "Minutes and seconds", locType: LocationTimestamp, input: "4:05", want: Location{Type: LocationTimestamp, Start: 245}, wantValue: "4:05", wantText: "4:05"},
+		{name: "Hours, minutes and seconds", locType: LocationTimestamp, input: "1:02:03", want: Location{Type: LocationTimestamp, Start: 3723}, wantValue: "1:02:03", wantText: "1:02:03"},
+		{name: "Minutes past the hour", locType: LocationTimestamp, input: "1:75:00", wantErr: true},
+		{name: "Unknown type", locType: "line", input: "12", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLocation(tt.locType, tt.input)
+			if tt.wantErr {
+				if err != ErrInvalidLocation {
+					t.Fatalf("got error %v, want %v", err, ErrInvalidLocation)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if got.Value() != tt.wantValue {
+				t.Errorf("got value %q, want %q", got.Value(), tt.wantValue)
+			}
+			if got.Text() != tt.wantText {
+				t.Errorf("got text %q, want %q", got.Text(), tt.wantText)
+			}
+		})
+	}
+}
+
+// Tests quotes are sorted by where they are in their work
+func TestSortByLocation(t *testing.T) {
+	quotes := []Quote{
+		{ID: 1},
+		{ID: 2, Location: Location{Type: LocationChapter, Label: "Epilogue"}},
+		{ID: 3, Location: Location{Type: LocationPage, Start: 140}},
+		{ID: 4, Location: Location{Type: LocationChapter, Label: "10"}},
+		{ID: 5, Location: Location{Type: LocationPage, Start: 12, End: 15}},
+		{ID: 6, Location: Location{Type: LocationChapter, Label: "2"}},
+		{ID: 7, Location: Location{Type: LocationPage, Start: 12}},
+		{ID: 8},
+	}
+
+	SortByLocation(quotes)
+
+	want := []int{7, 5, 3, 6, 4, 2, 1, 8}
+	for i, q := range quotes {
+		if q.ID != want[i] {
+			t.Fatalf("got quote %d at position %d, want %d", q.ID, i, want[i])
+		}
+	}
+}
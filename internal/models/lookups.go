@@ -0,0 +1,310 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/supabase-community/postgrest-go"
+)
+
+// Returns the distinct non-zero IDs as strings for an in.(...) filter
+func idList(ids []int) []string {
+	seen := make(map[int]bool, len(ids))
+	var list []int
+	for _, id := range ids {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			list = append(list, id)
+		}
+	}
+	sort.Ints(list)
+
+	values := make([]string, len(list))
+	for i, id := range list {
+		values[i] = strconv.Itoa(id)
+	}
+	return values
+}
+
+// Fetch the authors with the given IDs in a single request, keyed by ID
+func authorsByID(client *postgrest.Client, ids []int) (map[int]Author, error) {
+	authors := make(map[int]Author)
+
+	// Skip the request when there is nothing to look up
+	values := idList(ids)
+	if len(values) == 0 {
+		return authors, nil
+	}
+
+	var rows []Author
+	_, err := client.From("authors").Select("*", "", false).In("id", values).ExecuteTo(&rows)
+	if err != nil {
+		log.Printf("Error fetching authors: %v", err)
+		return nil, err
+	}
+
+	for _, a := range rows {
+		authors[a.ID] = a
+	}
+	return authors, nil
+}
+
+// Fetch the books with the given IDs in a single request, keyed by ID
+func booksByID(client *postgrest.Client, ids []int) (map[int]Book, error) {
+	books := make(map[int]Book)
+
+	// Skip the request when there is nothing to look up
+	values := idList(ids)
+	if len(values) == 0 {
+		return books, nil
+	}
+
+	var rows []Book
+	_, err := client.From("books").Select("*", "", false).In("id", values).ExecuteTo(&rows)
+	if err != nil {
+		log.Printf("Error fetching books: %v", err)
+		return nil, err
+	}
+
+	for _, b := range rows {
+		books[b.ID] = b
+	}
+	return books, nil
+}
+
+// Fetch the aliases of the authors with the given IDs in a single request, keyed by author ID
+func aliasesByAuthorID(client *postgrest.Client, ids []int) (map[int][]AuthorAlias, error) {
+	aliases := make(map[int][]AuthorAlias)
+
+	// Skip the request when there is nothing to look up
+	values := idList(ids)
+	if len(values) == 0 {
+		return aliases, nil
+	}
+
+	var rows []AuthorAlias
+	_, err := client.From("author_aliases").Select("*", "", false).In("author_id", values).ExecuteTo(&rows)
+	if err != nil {
+		log.Printf("Error fetching aliases: %v", err)
+		return nil, err
+	}
+
+	for _, a := range rows {
+		aliases[a.AuthorID] = append(aliases[a.AuthorID], a)
+	}
+	return aliases, nil
+}
+
+// Fetch the tags of the quotes with the given IDs in a single request, keyed
+// by quote ID and in alphabetical order
+func tagsByQuoteID(client *postgrest.Client, ids []int) (map[int][]string, error) {
+	tags := make(map[int][]string)
+
+	// Skip the request when there is nothing to look up
+	values := idList(ids)
+	if len(values) == 0 {
+		return tags, nil
+	}
+
+	var rows []QuoteTag
+	_, err := client.From("quote_tags").Select("*", "", false).In("quote_id", values).Order("tag", &postgrest.OrderOpts{Ascending: true}).ExecuteTo(&rows)
+	if err != nil {
+		log.Printf("Error fetching quote tags: %v", err)
+		return nil, err
+	}
+
+	for _, t := range rows {
+		tags[t.QuoteID] = append(tags[t.QuoteID], t.Tag)
+	}
+	return tags, nil
+}
+
+// Fetch the rows of a table with the given IDs in a single request, in the order of the IDs
+func rowsByID[T any](client *postgrest.Client, table string, ids []int, id func(T) int) ([]T, error) {
+	// Skip the request when there is nothing to look up
+	if len(ids) == 0 {
+		return []T{}, nil
+	}
+
+	var rows []T
+	_, err := client.From(table).Select("*", "", false).In("id", idList(ids)).ExecuteTo(&rows)
+	if err != nil {
+		log.Printf("Error fetching %s: %v", table, err)
+		return nil, err
+	}
+
+	// Put the rows back in the requested order
+	byID := make(map[int]T, len(rows))
+	for _, row := range rows {
+		byID[id(row)] = row
+	}
+	ordered := make([]T, 0, len(ids))
+	for _, i := range ids {
+		if row, ok := byID[i]; ok {
+			ordered = append(ordered, row)
+		}
+	}
+	return ordered, nil
+}
+
+// A row of the book_contributors table
+type bookContributorRow struct {
+	BookID   int             `json:"book_id"`
+	AuthorID int             `json:"author_id"`
+	Role     ContributorRole `json:"role"`
+	Position int             `json:"position"`
+}
+
+// Fetch the contributors of the books with the given IDs and their authors in
+// two requests, keyed by book ID in the order they are credited
+func contributorsByBookID(client *postgrest.Client, ids []int) (map[int][]BookContributor, error) {
+	contributors := make(map[int][]BookContributor)
+
+	// Skip the requests when there is nothing to look up
+	values := idList(ids)
+	if len(values) == 0 {
+		return contributors, nil
+	}
+
+	var rows []bookContributorRow
+	_, err := client.From("book_contributors").Select("*", "", false).In("book_id", values).ExecuteTo(&rows)
+	if err != nil {
+		log.Printf("Error fetching contributors: %v", err)
+		return nil, err
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].BookID != rows[j].BookID {
+			return rows[i].BookID < rows[j].BookID
+		}
+		return rows[i].Position < rows[j].Position
+	})
+
+	authorIDs := make([]int, len(rows))
+	for i, row := range rows {
+		authorIDs[i] = row.AuthorID
+	}
+	authors, err := authorsByID(client, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		contributors[row.BookID] = append(contributors[row.BookID], BookContributor{AuthorID: row.AuthorID, Role: row.Role, Author: authors[row.AuthorID]})
+	}
+	return contributors, nil
+}
+
+// Fetch the IDs of the books an author is credited on in any role
+func creditedBookIDs(client *postgrest.Client, authorID int) ([]int, error) {
+	var rows []bookContributorRow
+	_, err := client.From("book_contributors").Select("book_id", "", false).Eq("author_id", strconv.Itoa(authorID)).ExecuteTo(&rows)
+	if err != nil {
+		log.Printf("Error fetching credits: %v", err)
+		return nil, err
+	}
+
+	ids := make([]int, len(rows))
+	for i, row := range rows {
+		ids[i] = row.BookID
+	}
+	return ids, nil
+}
+
+// Fetch the number of distinct books each author is credited on, keyed by author ID
+func creditedBookCounts(client *postgrest.Client) (map[int]int, error) {
+	var rows []bookContributorRow
+	_, err := client.From("book_contributors").Select("book_id, author_id", "", false).ExecuteTo(&rows)
+	if err != nil {
+		log.Printf("Error fetching credits: %v", err)
+		return nil, err
+	}
+
+	books := make(map[int]map[int]bool)
+	for _, row := range rows {
+		if books[row.AuthorID] == nil {
+			books[row.AuthorID] = make(map[int]bool)
+		}
+		books[row.AuthorID][row.BookID] = true
+	}
+
+	counts := make(map[int]int, len(books))
+	for authorID, ids := range books {
+		counts[authorID] = len(ids)
+	}
+	return counts, nil
+}
+
+// Calls a Postgres function through PostgREST and decodes the rows it returns
+// into dst. The client's Rpc only hands back the response body, so an error is
+// recognised by the body not being an array of rows.
+func callFunction(client *postgrest.Client, name string, args any, dst any) error {
+	body := strings.TrimSpace(client.Rpc(name, "", args))
+	if strings.HasPrefix(body, "[") {
+		return json.Unmarshal([]byte(body), dst)
+	}
+
+	var perr struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(body), &perr); err != nil || perr.Message == "" {
+		return fmt.Errorf("models: unexpected response from function %s: %q", name, body)
+	}
+	return fmt.Errorf("models: function %s failed: %s (%s)", name, perr.Message, perr.Code)
+}
+
+// The arguments of the listing functions created by the
+// 0017_create_listing_functions migration. Each function only takes some of
+// them, so the optional ones are left out when empty.
+type listArgs struct {
+	Viewer uuid.UUID  `json:"viewer"`
+	Owner  *uuid.UUID `json:"owner_filter,omitempty"`
+	Sort   string     `json:"sort_order,omitempty"`
+	Limit  int        `json:"page_limit"`
+	Offset int        `json:"page_offset"`
+}
+
+// A row returned by a listing function: an ID on the page, with the author's
+// counts from list_authors, or a row without an ID when the page is empty, and
+// the total either way
+type pageRow struct {
+	ID         int `json:"id"`
+	QuoteCount int `json:"quote_count"`
+	BookCount  int `json:"book_count"`
+	Total      int `json:"total_records"`
+}
+
+// Calls a listing function and returns the rows on the page, in order, and
+// the total number of rows
+func listPage(client *postgrest.Client, name string, args listArgs) ([]pageRow, int, error) {
+	var rows []pageRow
+	err := callFunction(client, name, args, &rows)
+	if err != nil {
+		log.Printf("Error listing with %s: %v", name, err)
+		return nil, 0, err
+	}
+
+	page := []pageRow{}
+	total := 0
+	for _, row := range rows {
+		total = row.Total
+		if row.ID != 0 {
+			page = append(page, row)
+		}
+	}
+	return page, total, nil
+}
+
+// Returns the IDs of the rows on a page
+func pageIDs(rows []pageRow) []int {
+	ids := make([]int, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	return ids
+}
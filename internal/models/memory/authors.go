@@ -0,0 +1,412 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+// AuthorModel implements models.AuthorModelInterface in memory
+type AuthorModel struct {
+	DB *DB
+}
+
+// Insert adds a new author with their details, created by the given user
+func (m *AuthorModel) Insert(ctx context.Context, name string, details models.AuthorDetails, userID uuid.UUID) (int, error) {
+	if err := checkContext(ctx); err != nil {
+		return 0, err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	m.DB.lastAuthorID++
+	details.Links = slices.Clone(details.Links)
+	m.DB.authors[m.DB.lastAuthorID] = models.Author{ID: m.DB.lastAuthorID, Name: name, UserID: userID, AuthorDetails: details}
+
+	return m.DB.lastAuthorID, nil
+}
+
+// Get a single author by ID
+func (m *AuthorModel) Get(ctx context.Context, id int) (models.Author, error) {
+	if err := checkContext(ctx); err != nil {
+		return models.Author{}, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	a, ok := m.DB.authors[id]
+	if !ok {
+		return models.Author{}, models.ErrNoRecord
+	}
+
+	return a, nil
+}
+
+// Get a single author by name, preferring the oldest when names repeat, or
+// else the author with an alias of that name
+func (m *AuthorModel) GetByName(ctx context.Context, name string) (models.Author, error) {
+	if err := checkContext(ctx); err != nil {
+		return models.Author{}, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	var found models.Author
+	for _, a := range m.DB.authors {
+		if a.Name == name && (found.ID == 0 || a.ID < found.ID) {
+			found = a
+		}
+	}
+	if found.ID != 0 {
+		return found, nil
+	}
+
+	for _, alias := range m.DB.aliases {
+		if alias.Name == name {
+			if a, ok := m.DB.authors[alias.AuthorID]; ok {
+				return a, nil
+			}
+		}
+	}
+
+	return models.Author{}, models.ErrNoRecord
+}
+
+// Get the books an author is credited on in any role, ordered by title
+func (m *AuthorModel) GetBooksByAuthor(ctx context.Context, authorID int) ([]models.Book, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	books := []models.Book{}
+	for _, b := range m.DB.books {
+		if m.DB.isCredited(b.ID, authorID) {
+			books = append(books, b)
+		}
+	}
+
+	sort.Slice(books, func(i, j int) bool {
+		if books[i].Title != books[j].Title {
+			return books[i].Title < books[j].Title
+		}
+		return books[i].ID < books[j].ID
+	})
+
+	return books, nil
+}
+
+// Get quotes by author, ordered by the quote text
+func (m *AuthorModel) GetQuotesByAuthor(ctx context.Context, authorID int) ([]models.Quote, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	quotes := m.DB.filterQuotes(models.Viewer(ctx), func(q models.Quote) bool { return q.AuthorID == authorID })
+	sort.SliceStable(quotes, func(i, j int) bool {
+		return quotes[i].Quote < quotes[j].Quote
+	})
+
+	return quotes, nil
+}
+
+// Update an author's name and details by ID
+func (m *AuthorModel) Update(ctx context.Context, id int, name string, details models.AuthorDetails) (int, error) {
+	if err := checkContext(ctx); err != nil {
+		return 0, err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	a, ok := m.DB.authors[id]
+	if !ok {
+		return 0, models.ErrNoRecord
+	}
+
+	a.Name = name
+	a.AuthorDetails = details
+	a.Links = slices.Clone(details.Links)
+	m.DB.authors[id] = a
+
+	return id, nil
+}
+
+// Delete an author by ID, refusing while quotes still refer to them or they
+// are credited on books or translated editions of them
+func (m *AuthorModel) Delete(ctx context.Context, id int) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	for _, q := range m.DB.quotes {
+		if q.AuthorID == id {
+			return models.ErrAuthorHasQuotes
+		}
+	}
+	for bookID := range m.DB.contributors {
+		if m.DB.isCredited(bookID, id) {
+			return models.ErrAuthorHasBooks
+		}
+	}
+	for _, e := range m.DB.editions {
+		if e.TranslatorID == id {
+			return models.ErrAuthorHasBooks
+		}
+	}
+
+	// Delete the author along with their aliases
+	for aliasID, alias := range m.DB.aliases {
+		if alias.AuthorID == id {
+			delete(m.DB.aliases, aliasID)
+		}
+	}
+
+	delete(m.DB.authors, id)
+	return nil
+}
+
+// Merge moves every quote, alias, book credit and translation of the author
+// fromID to the author intoID and deletes fromID, returning the number of
+// quotes moved
+func (m *AuthorModel) Merge(ctx context.Context, fromID, intoID int) (int, error) {
+	if err := checkContext(ctx); err != nil {
+		return 0, err
+	}
+	if fromID == intoID {
+		return 0, models.ErrMergeSameAuthor
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	_, fromOK := m.DB.authors[fromID]
+	_, intoOK := m.DB.authors[intoID]
+	if !fromOK || !intoOK {
+		return 0, models.ErrNoRecord
+	}
+
+	moved := 0
+	for id, q := range m.DB.quotes {
+		if q.AuthorID == fromID {
+			q.AuthorID = intoID
+			m.DB.quotes[id] = q
+			moved++
+		}
+	}
+
+	// Keep the author's aliases, which now belong to the author merged into
+	for aliasID, alias := range m.DB.aliases {
+		if alias.AuthorID == fromID {
+			alias.AuthorID = intoID
+			m.DB.aliases[aliasID] = alias
+		}
+	}
+
+	// Move the author's book credits, dropping the ones intoID already has
+	for bookID, contributors := range m.DB.contributors {
+		kept := contributors[:0:0]
+		for _, c := range contributors {
+			if c.AuthorID == fromID {
+				c.AuthorID = intoID
+			}
+			if !slices.ContainsFunc(kept, func(k models.BookContributor) bool { return k.AuthorID == c.AuthorID && k.Role == c.Role }) {
+				kept = append(kept, c)
+			}
+		}
+		m.DB.contributors[bookID] = kept
+	}
+
+	for id, e := range m.DB.editions {
+		if e.TranslatorID == fromID {
+			e.TranslatorID = intoID
+			m.DB.editions[id] = e
+		}
+	}
+
+	delete(m.DB.authors, fromID)
+	return moved, nil
+}
+
+// Get the aliases of an author, ordered by name
+func (m *AuthorModel) GetAliases(ctx context.Context, authorID int) ([]models.AuthorAlias, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	aliases := m.DB.authorAliases(authorID)
+	if aliases == nil {
+		aliases = []models.AuthorAlias{}
+	}
+
+	return aliases, nil
+}
+
+// AddAlias gives an author another name, returning the alias's ID. It returns
+// models.ErrNoRecord if the author doesn't exist and models.ErrDuplicateAlias
+// if another alias already has the name.
+func (m *AuthorModel) AddAlias(ctx context.Context, authorID int, name string) (int, error) {
+	if err := checkContext(ctx); err != nil {
+		return 0, err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	if _, ok := m.DB.authors[authorID]; !ok {
+		return 0, models.ErrNoRecord
+	}
+	for _, alias := range m.DB.aliases {
+		if alias.Name == name {
+			return 0, models.ErrDuplicateAlias
+		}
+	}
+
+	m.DB.lastAliasID++
+	m.DB.aliases[m.DB.lastAliasID] = models.AuthorAlias{ID: m.DB.lastAliasID, AuthorID: authorID, Name: name}
+
+	return m.DB.lastAliasID, nil
+}
+
+// DeleteAlias removes one of an author's aliases, returning models.ErrNoRecord
+// if the author has no alias with the ID
+func (m *AuthorModel) DeleteAlias(ctx context.Context, authorID, aliasID int) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	alias, ok := m.DB.aliases[aliasID]
+	if !ok || alias.AuthorID != authorID {
+		return models.ErrNoRecord
+	}
+
+	delete(m.DB.aliases, aliasID)
+	return nil
+}
+
+// Check if an author exists by ID
+func (m *AuthorModel) Exists(ctx context.Context, id int) (bool, error) {
+	if err := checkContext(ctx); err != nil {
+		return false, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	_, ok := m.DB.authors[id]
+	return ok, nil
+}
+
+// Get all authors ordered by ID
+func (m *AuthorModel) GetAll(ctx context.Context) ([]models.Author, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	authors := make([]models.Author, 0, len(m.DB.authors))
+	for _, a := range m.DB.authors {
+		authors = append(authors, a)
+	}
+
+	sort.Slice(authors, func(i, j int) bool {
+		return authors[i].ID < authors[j].ID
+	})
+
+	return authors, nil
+}
+
+// GetWithCounts returns an author with their quote and book counts
+func (m *AuthorModel) GetWithCounts(ctx context.Context, id int) (models.Author, error) {
+	if err := checkContext(ctx); err != nil {
+		return models.Author{}, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	a, ok := m.DB.authors[id]
+	if !ok {
+		return models.Author{}, models.ErrNoRecord
+	}
+
+	a.QuoteCount, a.BookCount = m.DB.authorCounts(models.Viewer(ctx), id)
+	return a, nil
+}
+
+// GetAllWithCounts returns all authors with their quote and book counts, ordered by name
+func (m *AuthorModel) GetAllWithCounts(ctx context.Context) ([]models.AuthorWithCounts, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	authors := make([]models.AuthorWithCounts, 0, len(m.DB.authors))
+	for _, a := range m.DB.authors {
+		a.QuoteCount, a.BookCount = m.DB.authorCounts(models.Viewer(ctx), a.ID)
+		authors = append(authors, models.AuthorWithCounts{Author: a, QuoteCount: a.QuoteCount, BookCount: a.BookCount})
+	}
+
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].Name != authors[j].Name {
+			return authors[i].Name < authors[j].Name
+		}
+		return authors[i].ID < authors[j].ID
+	})
+
+	return authors, nil
+}
+
+// ListWithCounts returns a page of authors with their quote and book counts, ordered by name unless another order is asked for
+func (m *AuthorModel) ListWithCounts(ctx context.Context, filters models.Filters) ([]models.AuthorWithCounts, models.Metadata, error) {
+	authors, err := m.GetAllWithCounts(ctx)
+	if err != nil {
+		return nil, models.Metadata{}, err
+	}
+
+	// Authors have no creation time, so newest and oldest go by ID
+	sort.SliceStable(authors, func(i, j int) bool {
+		a, b := authors[i], authors[j]
+		switch filters.Sort {
+		case models.SortNewest:
+			return a.ID > b.ID
+		case models.SortOldest:
+			return a.ID < b.ID
+		case models.SortMostQuoted:
+			if a.QuoteCount != b.QuoteCount {
+				return a.QuoteCount > b.QuoteCount
+			}
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+
+	page, metadata := models.Paginate(authors, filters)
+	return page, metadata, nil
+}
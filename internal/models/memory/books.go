@@ -0,0 +1,301 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+// BookModel implements models.BookModelInterface in memory
+type BookModel struct {
+	DB *DB
+}
+
+// Insert adds a new book owned by the given user, crediting its contributors
+// in the order given. It returns models.ErrDuplicateISBN if another book
+// already has the ISBN.
+func (m *BookModel) Insert(ctx context.Context, title string, publishYear int, calendarTime string, isbn string, source string, details models.WorkDetails, contributors []models.BookContributor, userID uuid.UUID) (int, error) {
+	if err := checkContext(ctx); err != nil {
+		return 0, err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	if m.DB.isbnTaken(isbn, 0) {
+		return 0, models.ErrDuplicateISBN
+	}
+
+	now := time.Now()
+	details.MediaType = details.Kind()
+	m.DB.lastBookID++
+	m.DB.books[m.DB.lastBookID] = models.Book{
+		ID:           m.DB.lastBookID,
+		Title:        title,
+		PublishYear:  publishYear,
+		CalendarTime: calendarTime,
+		ISBN:         isbn,
+		Source:       source,
+		WorkDetails:  details,
+		UserID:       userID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	m.DB.setContributors(m.DB.lastBookID, contributors)
+
+	return m.DB.lastBookID, nil
+}
+
+// Get a single book by ID with its quotes, author and contributors
+func (m *BookModel) Get(ctx context.Context, id int) (models.Book, error) {
+	if err := checkContext(ctx); err != nil {
+		return models.Book{}, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	b, ok := m.DB.books[id]
+	if !ok {
+		return models.Book{}, models.ErrNoRecord
+	}
+
+	b = m.DB.bookWithAuthor(b)
+	b.Quotes = m.DB.filterQuotes(models.Viewer(ctx), func(q models.Quote) bool { return q.BookID == id })
+
+	return b, nil
+}
+
+// Get the books an author is credited on in any role, ordered by title, each
+// with its contributors and quotes
+func (m *BookModel) GetByAuthorID(ctx context.Context, authorID int) ([]models.Book, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	books := []models.Book{}
+	for _, b := range m.DB.books {
+		if !m.DB.isCredited(b.ID, authorID) {
+			continue
+		}
+		b = m.DB.bookWithAuthor(b)
+		b.Quotes = m.DB.filterQuotes(models.Viewer(ctx), func(q models.Quote) bool { return q.BookID == b.ID })
+		books = append(books, b)
+	}
+
+	sort.Slice(books, func(i, j int) bool {
+		if books[i].Title != books[j].Title {
+			return books[i].Title < books[j].Title
+		}
+		return books[i].ID < books[j].ID
+	})
+
+	return books, nil
+}
+
+// Get a page of books with their authors, ordered by title unless another order is asked for
+func (m *BookModel) GetAllWithAuthors(ctx context.Context, filters models.Filters) ([]models.Book, models.Metadata, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, models.Metadata{}, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	// Attach each book's author and count the quotes the viewer can see
+	books := m.all()
+	quoteCounts := make(map[int]int)
+	for _, q := range m.DB.filterQuotes(models.Viewer(ctx), func(q models.Quote) bool { return true }) {
+		quoteCounts[q.BookID]++
+	}
+	for i, b := range books {
+		books[i] = m.DB.bookWithAuthor(b)
+	}
+
+	sort.SliceStable(books, func(i, j int) bool {
+		a, b := books[i], books[j]
+		switch filters.Sort {
+		case models.SortNewest:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.After(b.CreatedAt)
+			}
+			return a.ID > b.ID
+		case models.SortOldest:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+			return a.ID < b.ID
+		case models.SortAuthor:
+			// Books without a known author go last
+			if (a.Author.ID == 0) != (b.Author.ID == 0) {
+				return a.Author.ID != 0
+			}
+			if a.Author.Name != b.Author.Name {
+				return a.Author.Name < b.Author.Name
+			}
+		case models.SortMostQuoted:
+			if quoteCounts[a.ID] != quoteCounts[b.ID] {
+				return quoteCounts[a.ID] > quoteCounts[b.ID]
+			}
+		}
+
+		// Fall back to the title and then the ID
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+		return a.ID < b.ID
+	})
+
+	page, metadata := models.Paginate(books, filters)
+	return page, metadata, nil
+}
+
+// Update a book by ID, replacing its contributors with the ones given. It
+// returns models.ErrDuplicateISBN if another book already has the ISBN.
+func (m *BookModel) Update(ctx context.Context, id int, title string, publishYear int, calendarTime string, isbn string, source string, details models.WorkDetails, contributors []models.BookContributor) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	b, ok := m.DB.books[id]
+	if !ok {
+		return models.ErrNoRecord
+	}
+	if m.DB.isbnTaken(isbn, id) {
+		return models.ErrDuplicateISBN
+	}
+
+	b.Title = title
+	b.PublishYear = publishYear
+	b.CalendarTime = calendarTime
+	b.ISBN = isbn
+	b.Source = source
+	b.WorkDetails = details
+	b.MediaType = details.Kind()
+	b.UpdatedAt = time.Now()
+	m.DB.books[id] = b
+	m.DB.setContributors(id, contributors)
+
+	return nil
+}
+
+// Delete a book by ID along with its credits and editions, refusing with
+// models.ErrBookHasQuotes while quotes still refer to it
+func (m *BookModel) Delete(ctx context.Context, id int) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	for _, q := range m.DB.quotes {
+		if q.BookID == id {
+			return models.ErrBookHasQuotes
+		}
+	}
+
+	for editionID, e := range m.DB.editions {
+		if e.BookID == id {
+			delete(m.DB.editions, editionID)
+		}
+	}
+	delete(m.DB.contributors, id)
+	delete(m.DB.books, id)
+	return nil
+}
+
+// Get all books ordered by ID
+func (m *BookModel) GetAll(ctx context.Context) ([]models.Book, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	return m.all(), nil
+}
+
+// Check if the book exists
+func (m *BookModel) Exists(ctx context.Context, id int) (bool, error) {
+	if err := checkContext(ctx); err != nil {
+		return false, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	_, ok := m.DB.books[id]
+	return ok, nil
+}
+
+// GetByISBN returns the book with the ISBN, without its contributors or
+// quotes, or models.ErrNoRecord if no book has it
+func (m *BookModel) GetByISBN(ctx context.Context, isbn string) (models.Book, error) {
+	if err := checkContext(ctx); err != nil {
+		return models.Book{}, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	for _, b := range m.DB.books {
+		if isbn != "" && b.ISBN == isbn {
+			return b, nil
+		}
+	}
+
+	return models.Book{}, models.ErrNoRecord
+}
+
+// Returns every book ordered by ID. The caller must hold the lock.
+func (m *BookModel) all() []models.Book {
+	books := make([]models.Book, 0, len(m.DB.books))
+	for _, b := range m.DB.books {
+		books = append(books, b)
+	}
+
+	sort.Slice(books, func(i, j int) bool {
+		return books[i].ID < books[j].ID
+	})
+
+	return books
+}
+
+// Replaces the contributors of a book, keeping only the author IDs and roles. The caller must hold the lock.
+func (db *DB) setContributors(bookID int, contributors []models.BookContributor) {
+	if len(contributors) == 0 {
+		delete(db.contributors, bookID)
+		return
+	}
+
+	stored := make([]models.BookContributor, len(contributors))
+	for i, c := range contributors {
+		stored[i] = models.BookContributor{AuthorID: c.AuthorID, Role: c.Role}
+	}
+	db.contributors[bookID] = stored
+}
+
+// Reports whether a book other than the one with the given ID has the ISBN.
+// The caller must hold the lock.
+func (db *DB) isbnTaken(isbn string, id int) bool {
+	if isbn == "" {
+		return false
+	}
+	for _, b := range db.books {
+		if b.ISBN == isbn && b.ID != id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+// EditionModel implements models.EditionModelInterface in memory
+type EditionModel struct {
+	DB *DB
+}
+
+// Checks the book and translator, if any, an edition refers to exist and that
+// no edition other than the one with the given ID has its ISBN. The caller
+// must hold the lock.
+func (m *EditionModel) check(id, bookID int, details models.EditionDetails) error {
+	if _, ok := m.DB.books[bookID]; !ok {
+		return fmt.Errorf("memory: book %d does not exist", bookID)
+	}
+	if _, ok := m.DB.authors[details.TranslatorID]; details.TranslatorID != 0 && !ok {
+		return fmt.Errorf("memory: author %d does not exist", details.TranslatorID)
+	}
+	if details.ISBN == "" {
+		return nil
+	}
+	for _, e := range m.DB.editions {
+		if e.ISBN == details.ISBN && e.ID != id {
+			return models.ErrDuplicateISBN
+		}
+	}
+	return nil
+}
+
+// Insert adds an edition of a book owned by the given user. It returns
+// models.ErrDuplicateISBN if another edition already has the ISBN.
+func (m *EditionModel) Insert(ctx context.Context, bookID int, details models.EditionDetails, userID uuid.UUID) (int, error) {
+	if err := checkContext(ctx); err != nil {
+		return 0, err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	err := m.check(0, bookID, details)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	m.DB.lastEditionID++
+	m.DB.editions[m.DB.lastEditionID] = models.Edition{
+		ID:             m.DB.lastEditionID,
+		BookID:         bookID,
+		EditionDetails: details,
+		UserID:         userID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	return m.DB.lastEditionID, nil
+}
+
+// Get a single edition by ID with its translator
+func (m *EditionModel) Get(ctx context.Context, id int) (models.Edition, error) {
+	if err := checkContext(ctx); err != nil {
+		return models.Edition{}, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	e, ok := m.DB.editions[id]
+	if !ok {
+		return models.Edition{}, models.ErrNoRecord
+	}
+
+	return m.DB.editionWithTranslator(e), nil
+}
+
+// GetByBookID returns the editions of a book with their translators, oldest first
+func (m *EditionModel) GetByBookID(ctx context.Context, bookID int) ([]models.Edition, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	return m.filter(func(e models.Edition) bool { return e.BookID == bookID }), nil
+}
+
+// GetByISBN returns the edition with the ISBN, or models.ErrNoRecord if none has it
+func (m *EditionModel) GetByISBN(ctx context.Context, isbn string) (models.Edition, error) {
+	if err := checkContext(ctx); err != nil {
+		return models.Edition{}, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	for _, e := range m.DB.editions {
+		if isbn != "" && e.ISBN == isbn {
+			return m.DB.editionWithTranslator(e), nil
+		}
+	}
+
+	return models.Edition{}, models.ErrNoRecord
+}
+
+// GetAll returns every edition with its translator, ordered by book and then oldest first
+func (m *EditionModel) GetAll(ctx context.Context) ([]models.Edition, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	return m.filter(func(e models.Edition) bool { return true }), nil
+}
+
+// Update an edition by ID. It returns models.ErrDuplicateISBN if another
+// edition already has the ISBN.
+func (m *EditionModel) Update(ctx context.Context, id int, details models.EditionDetails) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	e, ok := m.DB.editions[id]
+	if !ok {
+		return models.ErrNoRecord
+	}
+
+	err := m.check(id, e.BookID, details)
+	if err != nil {
+		return err
+	}
+
+	e.EditionDetails = details
+	e.UpdatedAt = time.Now()
+	m.DB.editions[id] = e
+
+	return nil
+}
+
+// Delete an edition by ID. Its quotes stay with the book, without an edition.
+func (m *EditionModel) Delete(ctx context.Context, id int) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	for quoteID, q := range m.DB.quotes {
+		if q.EditionID == id {
+			q.EditionID = 0
+			m.DB.quotes[quoteID] = q
+		}
+	}
+
+	delete(m.DB.editions, id)
+	return nil
+}
+
+// Returns the editions that match keep with their translators, ordered by
+// book and then oldest first. The caller must hold the lock.
+func (m *EditionModel) filter(keep func(e models.Edition) bool) []models.Edition {
+	editions := []models.Edition{}
+	for _, e := range m.DB.editions {
+		if keep(e) {
+			editions = append(editions, m.DB.editionWithTranslator(e))
+		}
+	}
+
+	models.SortEditions(editions)
+	return editions
+}
+
+// Returns an edition with its translator attached. The caller must hold the lock.
+func (db *DB) editionWithTranslator(e models.Edition) models.Edition {
+	e.Translator = db.authors[e.TranslatorID]
+	return e
+}
@@ -0,0 +1,177 @@
+// Package memory implements the model interfaces on top of thread-safe
+// in-memory maps. It behaves like the SQL backends, with the same not-found
+// errors, ordering, counts and relations, and is used for handler tests and
+// the -storage=memory demo mode. Nothing is persisted.
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+// Check that every model satisfies its interface
+var (
+	_ models.QuoteModelInterface   = (*QuoteModel)(nil)
+	_ models.AuthorModelInterface  = (*AuthorModel)(nil)
+	_ models.BookModelInterface    = (*BookModel)(nil)
+	_ models.EditionModelInterface = (*EditionModel)(nil)
+	_ models.UserModelInterface    = (*UserModel)(nil)
+)
+
+// A stored user with the fields that are never returned by the models
+type user struct {
+	models.User
+	hashedPassword   []byte
+	lastQuoteAddedAt time.Time
+}
+
+// DB holds the tables shared by the models. The zero value is not usable;
+// create one with New.
+type DB struct {
+	mu       sync.RWMutex
+	users    map[uuid.UUID]user
+	authors  map[int]models.Author
+	aliases  map[int]models.AuthorAlias
+	books    map[int]models.Book
+	editions map[int]models.Edition
+	quotes   map[int]models.Quote
+
+	// The authors credited on each book, in the order they are credited
+	contributors map[int][]models.BookContributor
+
+	// The tags of each quote, in alphabetical order
+	tags map[int][]string
+
+	// The last ID handed out for each table
+	lastAuthorID  int
+	lastAliasID   int
+	lastBookID    int
+	lastEditionID int
+	lastQuoteID   int
+}
+
+// New returns an empty database
+func New() *DB {
+	return &DB{
+		users:    make(map[uuid.UUID]user),
+		authors:  make(map[int]models.Author),
+		aliases:  make(map[int]models.AuthorAlias),
+		books:    make(map[int]models.Book),
+		editions: make(map[int]models.Edition),
+		quotes:   make(map[int]models.Quote),
+
+		contributors: make(map[int][]models.BookContributor),
+		tags:         make(map[int][]string),
+	}
+}
+
+// Returns the model error for a context that is done before the operation starts
+func checkContext(ctx context.Context) error {
+	switch {
+	case ctx.Err() == nil:
+		return nil
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return models.ErrQueryTimeout
+	default:
+		return models.ErrQueryCanceled
+	}
+}
+
+// Returns the quotes the viewer can see that match keep, ordered by ID. The caller must hold the lock.
+func (db *DB) filterQuotes(viewer uuid.UUID, keep func(q models.Quote) bool) []models.Quote {
+	quotes := []models.Quote{}
+	for _, q := range db.quotes {
+		if models.CanView(viewer, q) && keep(q) {
+			quotes = append(quotes, q)
+		}
+	}
+
+	sort.Slice(quotes, func(i, j int) bool {
+		return quotes[i].ID < quotes[j].ID
+	})
+
+	return quotes
+}
+
+// Returns a book with its contributors and its first contributor credited as
+// an author attached. The caller must hold the lock.
+func (db *DB) bookWithAuthor(b models.Book) models.Book {
+	b.Contributors = db.bookContributors(b.ID)
+	b.Author = models.PrimaryAuthor(b.Contributors)
+	return b
+}
+
+// Returns the contributors of a book with their authors, in the order they
+// are credited, or nil when it has none. The caller must hold the lock.
+func (db *DB) bookContributors(bookID int) []models.BookContributor {
+	var contributors []models.BookContributor
+	for _, c := range db.contributors[bookID] {
+		c.Author = db.authors[c.AuthorID]
+		contributors = append(contributors, c)
+	}
+	return contributors
+}
+
+// Reports whether an author is credited on a book in any role. The caller must hold the lock.
+func (db *DB) isCredited(bookID, authorID int) bool {
+	for _, c := range db.contributors[bookID] {
+		if c.AuthorID == authorID {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns a quote with its author, the author's aliases and its book attached. The caller must hold the lock.
+func (db *DB) quoteWithRelations(q models.Quote) models.Quote {
+	q.Author = db.authors[q.AuthorID]
+	if q.Author.ID != 0 {
+		q.Author.Aliases = db.authorAliases(q.AuthorID)
+	}
+	q.Book = db.books[q.BookID]
+	return q
+}
+
+// Returns an author's aliases ordered by name, or nil when they have none. The caller must hold the lock.
+func (db *DB) authorAliases(authorID int) []models.AuthorAlias {
+	var aliases []models.AuthorAlias
+	for _, a := range db.aliases {
+		if a.AuthorID == authorID {
+			aliases = append(aliases, a)
+		}
+	}
+
+	sort.Slice(aliases, func(i, j int) bool {
+		if aliases[i].Name != aliases[j].Name {
+			return aliases[i].Name < aliases[j].Name
+		}
+		return aliases[i].ID < aliases[j].ID
+	})
+
+	return aliases
+}
+
+// Counts the author's quotes the viewer can see and the books they are credited on. The caller must hold the lock.
+func (db *DB) authorCounts(viewer uuid.UUID, authorID int) (int, int) {
+	quoteCount := 0
+	for _, q := range db.quotes {
+		if q.AuthorID == authorID && models.CanView(viewer, q) {
+			quoteCount++
+		}
+	}
+
+	bookCount := 0
+	for bookID := range db.contributors {
+		if db.isCredited(bookID, authorID) {
+			bookCount++
+		}
+	}
+
+	return quoteCount, bookCount
+}
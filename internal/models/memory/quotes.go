@@ -0,0 +1,341 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+// QuoteModel implements models.QuoteModelInterface in memory
+type QuoteModel struct {
+	DB *DB
+}
+
+// Checks the author, book and edition, if any, a quote refers to exist. The caller must hold the lock.
+func (m *QuoteModel) checkReferences(authorID int, bookID int, editionID int) error {
+	if _, ok := m.DB.authors[authorID]; !ok {
+		return fmt.Errorf("memory: author %d does not exist", authorID)
+	}
+	if _, ok := m.DB.books[bookID]; !ok {
+		return fmt.Errorf("memory: book %d does not exist", bookID)
+	}
+	if _, ok := m.DB.editions[editionID]; editionID != 0 && !ok {
+		return fmt.Errorf("memory: edition %d does not exist", editionID)
+	}
+	return nil
+}
+
+// Insert a new quote and record when the user last added a quote
+func (m *QuoteModel) Insert(ctx context.Context, quote string, authorID int, bookID int, editionID int, location models.Location, visibility models.Visibility, userID uuid.UUID) (int, error) {
+	if err := checkContext(ctx); err != nil {
+		return 0, err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	err := m.checkReferences(authorID, bookID, editionID)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	m.DB.lastQuoteID++
+	m.DB.quotes[m.DB.lastQuoteID] = models.Quote{
+		ID:         m.DB.lastQuoteID,
+		Quote:      quote,
+		AuthorID:   authorID,
+		BookID:     bookID,
+		EditionID:  editionID,
+		UserID:     userID,
+		Location:   location,
+		Visibility: visibility,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	// Update the user's last quote added at timestamp
+	if u, ok := m.DB.users[userID]; ok {
+		u.lastQuoteAddedAt = now
+		m.DB.users[userID] = u
+	}
+
+	return m.DB.lastQuoteID, nil
+}
+
+// Return a specific quote based on the ID
+func (m *QuoteModel) Get(ctx context.Context, id int) (models.Quote, error) {
+	if err := checkContext(ctx); err != nil {
+		return models.Quote{}, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	q, ok := m.DB.quotes[id]
+	if !ok || !models.CanView(models.Viewer(ctx), q) {
+		return models.Quote{}, models.ErrNoRecord
+	}
+
+	return q, nil
+}
+
+// Return a list of quotes by author ID
+func (m *QuoteModel) GetByAuthorID(ctx context.Context, authorID int) ([]models.Quote, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	return m.DB.filterQuotes(models.Viewer(ctx), func(q models.Quote) bool { return q.AuthorID == authorID }), nil
+}
+
+// Return a page of the quotes added by a user
+func (m *QuoteModel) GetByUserID(ctx context.Context, userID uuid.UUID, filters models.Filters) ([]models.Quote, models.Metadata, error) {
+	return m.list(ctx, filters, func(q models.Quote) bool { return q.UserID == userID })
+}
+
+// Return all quotes for a given book ID
+func (m *QuoteModel) GetByBookID(ctx context.Context, bookID int) ([]models.Quote, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	return m.DB.filterQuotes(models.Viewer(ctx), func(q models.Quote) bool { return q.BookID == bookID }), nil
+}
+
+// Return a quote with the author and book
+func (m *QuoteModel) GetWithAuthorAndBook(ctx context.Context, id int) (models.Quote, error) {
+	if err := checkContext(ctx); err != nil {
+		return models.Quote{}, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	q, ok := m.DB.quotes[id]
+	if !ok || !models.CanView(models.Viewer(ctx), q) {
+		return models.Quote{}, models.ErrNoRecord
+	}
+
+	return m.DB.quoteWithRelations(q), nil
+}
+
+// Return a page of quotes with their authors and books, newest first unless another order is asked for
+func (m *QuoteModel) Latest(ctx context.Context, filters models.Filters) ([]models.Quote, models.Metadata, error) {
+	return m.list(ctx, filters, func(q models.Quote) bool { return true })
+}
+
+// Returns a page of the quotes passing keep, in the requested order, with their authors and books
+func (m *QuoteModel) list(ctx context.Context, filters models.Filters, keep func(q models.Quote) bool) ([]models.Quote, models.Metadata, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, models.Metadata{}, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	quotes := m.DB.filterQuotes(models.Viewer(ctx), keep)
+	for i, q := range quotes {
+		quotes[i] = m.DB.quoteWithRelations(q)
+	}
+
+	sort.SliceStable(quotes, func(i, j int) bool {
+		a, b := quotes[i], quotes[j]
+		switch filters.Sort {
+		case models.SortOldest:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+			return a.ID < b.ID
+		case models.SortAuthor:
+			// Quotes without an author go last
+			if (a.Author.ID == 0) != (b.Author.ID == 0) {
+				return a.Author.ID != 0
+			}
+			if a.Author.Name != b.Author.Name {
+				return a.Author.Name < b.Author.Name
+			}
+			return a.ID < b.ID
+		}
+
+		// Order by newest first, breaking ties by the newest ID
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	})
+
+	page, metadata := models.Paginate(quotes, filters)
+	return page, metadata, nil
+}
+
+// Return a page of the quotes matching the search terms and filters, most relevant first unless another order is asked for
+func (m *QuoteModel) Search(ctx context.Context, sq models.SearchQuery, filters models.Filters) ([]models.SearchResult, models.Metadata, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, models.Metadata{}, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	// Nothing matches an empty search
+	results := []models.SearchResult{}
+	if sq.IsEmpty() {
+		return results, models.Metadata{}, nil
+	}
+
+	// Rank every quote that passes the filters and matches every word and phrase
+	highlights := sq.Highlights()
+	for _, q := range m.DB.filterQuotes(sq.UserID, func(q models.Quote) bool { return true }) {
+		q = m.DB.quoteWithRelations(q)
+		q.Tags = m.DB.tags[q.ID]
+		if rank, ok := sq.Rank(q); ok {
+			results = append(results, models.NewSearchResult(q, rank, highlights))
+		}
+	}
+
+	models.SortResults(results, filters.Sort)
+	page, metadata := models.Paginate(results, filters)
+	return page, metadata, nil
+}
+
+// Return the quote with the given share token, with its author and book, to
+// anyone who has the token. A revoked token, or a private quote, isn't found.
+func (m *QuoteModel) GetByShareToken(ctx context.Context, token string) (models.Quote, error) {
+	if err := checkContext(ctx); err != nil {
+		return models.Quote{}, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	for _, q := range m.DB.quotes {
+		if q.ShareToken == token && models.CanShare(q) {
+			return m.DB.quoteWithRelations(q), nil
+		}
+	}
+
+	return models.Quote{}, models.ErrNoRecord
+}
+
+// Set the token of a quote's share link, replacing any earlier token so its
+// link stops working. An empty token revokes the share link.
+func (m *QuoteModel) SetShareToken(ctx context.Context, id int, token string) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	q, ok := m.DB.quotes[id]
+	if !ok {
+		return models.ErrNoRecord
+	}
+
+	q.ShareToken = token
+	q.UpdatedAt = time.Now()
+	m.DB.quotes[id] = q
+	return nil
+}
+
+// Update a quote on behalf of the given user
+func (m *QuoteModel) Update(ctx context.Context, id int, quote string, authorID int, bookID int, editionID int, location models.Location, visibility models.Visibility, userID uuid.UUID) (int, error) {
+	if err := checkContext(ctx); err != nil {
+		return 0, err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	q, ok := m.DB.quotes[id]
+	if !ok {
+		return 0, models.ErrNoRecord
+	}
+
+	err := m.checkReferences(authorID, bookID, editionID)
+	if err != nil {
+		return 0, err
+	}
+
+	q.Quote = quote
+	q.AuthorID = authorID
+	q.BookID = bookID
+	q.EditionID = editionID
+	q.Location = location
+	q.Visibility = visibility
+	q.UserID = userID
+	q.UpdatedAt = time.Now()
+	m.DB.quotes[id] = q
+
+	return id, nil
+}
+
+// Check if the quote exists
+func (m *QuoteModel) Exists(ctx context.Context, id int) (bool, error) {
+	if err := checkContext(ctx); err != nil {
+		return false, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	q, ok := m.DB.quotes[id]
+	return ok && models.CanView(models.Viewer(ctx), q), nil
+}
+
+// Delete a quote
+func (m *QuoteModel) Delete(ctx context.Context, id int) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	delete(m.DB.quotes, id)
+	delete(m.DB.tags, id)
+	return nil
+}
+
+// Return a quote's tags in alphabetical order
+func (m *QuoteModel) GetTags(ctx context.Context, id int) ([]string, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	return append([]string{}, m.DB.tags[id]...), nil
+}
+
+// Replace a quote's tags with the given lowercase tags
+func (m *QuoteModel) SetTags(ctx context.Context, id int, tags []string) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	if _, ok := m.DB.quotes[id]; !ok {
+		return fmt.Errorf("memory: quote %d does not exist", id)
+	}
+
+	tags = slices.Clone(tags)
+	slices.Sort(tags)
+	m.DB.tags[id] = tags
+	return nil
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+// The user that owns the test data
+var testUserID = uuid.MustParse("a3a6e2b6-7e64-4c3e-9a3b-7d0a3f7f9d11")
+
+func TestQuoteModelInsert(t *testing.T) {
+	db := newTestDB(t)
+	m := QuoteModel{DB: db}
+
+	// Insert a quote for the test user
+	_, err := m.Insert(context.Background(), "You have power over your mind.", 1, 1, 0, models.Location{Type: models.LocationPage, Start: 5}, models.VisibilityPublic, testUserID)
+	assert.NilError(t, err)
+
+	// Check the user's last quote added at timestamp was set
+	assert.Equal(t, db.users[testUserID].lastQuoteAddedAt.IsZero(), false)
+}
+
+func TestQuoteModelConcurrentInserts(t *testing.T) {
+	m := QuoteModel{DB: newTestDB(t)}
+
+	// Insert quotes from many goroutines at once
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := m.Insert(context.Background(), "Concurrent quote.", 1, 1, 0, models.Location{}, models.VisibilityPublic, testUserID)
+			assert.NilError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// Check every insert got its own ID
+	quotes, err := m.GetByBookID(context.Background(), 1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(quotes), 52)
+	assert.Equal(t, quotes[len(quotes)-1].ID, 53)
+}
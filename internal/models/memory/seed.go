@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+// The account created by SeedDemo
+const (
+	DemoEmail    = "demo@example.com"
+	DemoPassword = "demopa$$word"
+)
+
+// A quote loaded by SeedDemo
+type demoQuote struct {
+	quote       string
+	author      string
+	book        string
+	publishYear int
+	isbn        string
+	page        int
+}
+
+// The public domain quotes loaded by SeedDemo
+var demoQuotes = []demoQuote{
+	{"You have power over your mind - not outside events. Realize this, and you will find strength.", "Marcus Aurelius", "Meditations", 180, "9780140449334", 56},
+	{"The happiness of your life depends upon the quality of your thoughts.", "Marcus Aurelius", "Meditations", 180, "9780140449334", 12},
+	{"Luck is what happens when preparation meets opportunity.", "Seneca", "Letters from a Stoic", 65, "9780140442106", 33},
+	{"It is not that we have a short time to live, but that we waste a lot of it.", "Seneca", "On the Shortness of Life", 49, "9780143036326", 1},
+	{"First say to yourself what you would be; and then do what you have to do.", "Epictetus", "Discourses", 108, "9780140446449", 210},
+}
+
+// The details of the demo quotes' authors
+var demoAuthors = map[string]models.AuthorDetails{
+	"Marcus Aurelius": {
+		Bio:               "Roman emperor from 161 to 180 and Stoic philosopher, who wrote the Meditations as a private journal while on campaign.",
+		BirthYear:         121,
+		BirthCalendarTime: "A.D.",
+		DeathYear:         180,
+		DeathCalendarTime: "A.D.",
+		Nationality:       "Roman",
+		Occupation:        "Emperor, philosopher",
+		Links:             []string{"https://en.wikipedia.org/wiki/Marcus_Aurelius"},
+	},
+	"Seneca": {
+		Bio:               "Stoic philosopher, dramatist and adviser to the emperor Nero.",
+		BirthYear:         4,
+		BirthCalendarTime: "B.C.",
+		DeathYear:         65,
+		DeathCalendarTime: "A.D.",
+		Nationality:       "Roman",
+		Occupation:        "Philosopher, statesman, dramatist",
+		Links:             []string{"https://en.wikipedia.org/wiki/Seneca_the_Younger"},
+	},
+	"Epictetus": {
+		Bio:               "Stoic philosopher born into slavery, whose teachings were written down by his pupil Arrian.",
+		BirthYear:         50,
+		BirthCalendarTime: "A.D.",
+		DeathYear:         135,
+		DeathCalendarTime: "A.D.",
+		Nationality:       "Greek",
+		Occupation:        "Philosopher",
+		Links:             []string{"https://en.wikipedia.org/wiki/Epictetus"},
+	},
+}
+
+// SeedDemo adds a demo user and a handful of quotes, with their authors and
+// books, for the -storage=memory demo mode
+func SeedDemo(ctx context.Context, db *DB) error {
+	users := &UserModel{DB: db}
+	authors := &AuthorModel{DB: db}
+	books := &BookModel{DB: db}
+	quotes := &QuoteModel{DB: db}
+
+	userID, err := users.Insert(ctx, "Demo User", DemoEmail, DemoPassword)
+	if err != nil {
+		return err
+	}
+
+	// Reuse the authors and books shared by several quotes
+	authorIDs := make(map[string]int)
+	bookIDs := make(map[string]int)
+	for _, q := range demoQuotes {
+		if _, ok := authorIDs[q.author]; !ok {
+			authorIDs[q.author], err = authors.Insert(ctx, q.author, demoAuthors[q.author], userID)
+			if err != nil {
+				return err
+			}
+		}
+		if _, ok := bookIDs[q.book]; !ok {
+			contributors := []models.BookContributor{{AuthorID: authorIDs[q.author], Role: models.ContributorAuthor}}
+			bookIDs[q.book], err = books.Insert(ctx, q.book, q.publishYear, "A.D.", q.isbn, "", models.WorkDetails{MediaType: models.MediaBook}, contributors, userID)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = quotes.Insert(ctx, q.quote, authorIDs[q.author], bookIDs[q.book], 0, models.Location{Type: models.LocationPage, Start: q.page}, models.VisibilityPublic, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+// Returns a database holding the same test data as the SQL backends' testdata/setup.sql
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	db := New()
+	userID := uuid.MustParse("a3a6e2b6-7e64-4c3e-9a3b-7d0a3f7f9d11")
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 10, 0, 0, 0, time.UTC) }
+
+	// Add Alice, whose password is "pa$$word"
+	db.users[userID] = user{
+		User: models.User{
+			ID:          userID,
+			Name:        "Alice Jones",
+			Email:       "alice@example.com",
+			ProfileSlug: "alice-jones",
+			Role:        models.RoleUser,
+			CreatedAt:   day(1),
+			UpdatedAt:   day(1),
+		},
+		hashedPassword: []byte("$2a$12$WOJEhKYwBc7PYH.wHsk/4erklqktOxYJXkA.E1yhABXMbVyNvg6nC"),
+	}
+
+	db.authors[1] = models.Author{ID: 1, Name: "Marcus Aurelius", UserID: userID}
+	db.authors[2] = models.Author{ID: 2, Name: "Seneca", UserID: userID}
+	db.lastAuthorID = 2
+
+	db.books[1] = models.Book{ID: 1, Title: "Meditations", PublishYear: 180, CalendarTime: "A.D.", ISBN: "9780140449334", WorkDetails: models.WorkDetails{MediaType: models.MediaBook}, UserID: userID, CreatedAt: day(1), UpdatedAt: day(1)}
+	db.books[2] = models.Book{ID: 2, Title: "Letters from a Stoic", PublishYear: 65, CalendarTime: "A.D.", ISBN: "9780140442106", WorkDetails: models.WorkDetails{MediaType: models.MediaBook}, UserID: userID, CreatedAt: day(1), UpdatedAt: day(1)}
+	db.books[3] = models.Book{ID: 3, Title: "An Unquoted Book", PublishYear: 2000, CalendarTime: "A.D.", ISBN: "9780000000002", WorkDetails: models.WorkDetails{MediaType: models.MediaBook}, UserID: userID, CreatedAt: day(1), UpdatedAt: day(1)}
+	db.lastBookID = 3
+
+	// Credit each quoted book to its author, leaving the unquoted book without one
+	db.contributors[1] = []models.BookContributor{{AuthorID: 1, Role: models.ContributorAuthor}}
+	db.contributors[2] = []models.BookContributor{{AuthorID: 2, Role: models.ContributorAuthor}}
+
+	db.quotes[1] = models.Quote{ID: 1, Quote: "The happiness of your life depends upon the quality of your thoughts.", AuthorID: 1, BookID: 1, UserID: userID, Location: models.Location{Type: models.LocationPage, Start: 12}, Visibility: models.VisibilityPublic, CreatedAt: day(2), UpdatedAt: day(2)}
+	db.quotes[2] = models.Quote{ID: 2, Quote: "Waste no more time arguing about what a good man should be. Be one.", AuthorID: 1, BookID: 1, UserID: userID, Location: models.Location{Type: models.LocationPage, Start: 140}, Visibility: models.VisibilityPublic, CreatedAt: day(3), UpdatedAt: day(3)}
+	db.quotes[3] = models.Quote{ID: 3, Quote: "Luck is what happens when preparation meets opportunity.", AuthorID: 2, BookID: 2, UserID: userID, Location: models.Location{Type: models.LocationPage, Start: 33}, Visibility: models.VisibilityPrivate, CreatedAt: day(4), UpdatedAt: day(4)}
+	db.lastQuoteID = 3
+
+	return db
+}
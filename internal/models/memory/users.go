@@ -0,0 +1,288 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UserModel implements models.UserModelInterface in memory
+type UserModel struct {
+	DB *DB
+}
+
+// Returns the profile slug for a user's name
+func profileSlug(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "-")
+}
+
+// Checks no other user has the email address. The caller must hold the lock.
+func (m *UserModel) emailTaken(email string, except uuid.UUID) bool {
+	for id, u := range m.DB.users {
+		if id != except && u.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+// Insert adds a new user
+func (m *UserModel) Insert(ctx context.Context, name, email, password string) (uuid.UUID, error) {
+	if err := checkContext(ctx); err != nil {
+		return uuid.Nil, err
+	}
+
+	// Hash the password with the number of specified salt rounds
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	// Check if the email is already in use
+	if m.emailTaken(email, uuid.Nil) {
+		return uuid.Nil, models.ErrDuplicateEmail
+	}
+
+	now := time.Now()
+	id := uuid.New()
+	m.DB.users[id] = user{
+		User: models.User{
+			ID:          id,
+			Name:        name,
+			Email:       email,
+			ProfileSlug: profileSlug(name),
+			Role:        models.RoleUser,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		},
+		hashedPassword: hashedPassword,
+	}
+
+	return id, nil
+}
+
+// Authenticate verifies the user's email and password
+func (m *UserModel) Authenticate(ctx context.Context, email string, password string) (uuid.UUID, error) {
+	if err := checkContext(ctx); err != nil {
+		return uuid.Nil, err
+	}
+
+	m.DB.mu.RLock()
+	var found *user
+	for _, u := range m.DB.users {
+		if u.Email == email {
+			found = &u
+			break
+		}
+	}
+	m.DB.mu.RUnlock()
+
+	if found == nil {
+		return uuid.Nil, models.ErrInvalidCredentials
+	}
+
+	// Compare the provided password with the stored hash outside the lock
+	err := bcrypt.CompareHashAndPassword(found.hashedPassword, []byte(password))
+	if err != nil {
+		return uuid.Nil, models.ErrInvalidCredentials
+	}
+
+	return found.ID, nil
+}
+
+// Update the user's last signed in at timestamp
+func (m *UserModel) UpdateLastSignedInAt(ctx context.Context, id uuid.UUID) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	if u, ok := m.DB.users[id]; ok {
+		u.LastLoginAt = time.Now()
+		m.DB.users[id] = u
+	}
+
+	return nil
+}
+
+// Check if the user exists
+func (m *UserModel) Exists(ctx context.Context, id uuid.UUID) (bool, error) {
+	if err := checkContext(ctx); err != nil {
+		return false, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	_, ok := m.DB.users[id]
+	return ok, nil
+}
+
+// Update the user's info, clearing the phone verification when the phone changes
+func (m *UserModel) Update(ctx context.Context, id uuid.UUID, name, email, phone string) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	u, ok := m.DB.users[id]
+	if !ok {
+		return models.ErrNoRecord
+	}
+
+	if m.emailTaken(email, id) {
+		return models.ErrDuplicateEmail
+	}
+
+	if u.Phone != phone {
+		u.PhoneVerifiedAt = time.Time{}
+	}
+	u.Name = name
+	u.Email = email
+	u.ProfileSlug = profileSlug(name)
+	u.Phone = phone
+	u.UpdatedAt = time.Now()
+	m.DB.users[id] = u
+
+	return nil
+}
+
+// ChangePassword verifies the current password and stores the new one
+func (m *UserModel) ChangePassword(ctx context.Context, id uuid.UUID, currentPassword, newPassword string) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	// Hold the write lock throughout so a concurrent change can't be overwritten
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	u, ok := m.DB.users[id]
+	if !ok {
+		return models.ErrNoRecord
+	}
+
+	// Verify the current password
+	err := bcrypt.CompareHashAndPassword(u.hashedPassword, []byte(currentPassword))
+	if err != nil {
+		return models.ErrInvalidCredentials
+	}
+
+	// Hash the new password
+	newHashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), 12)
+	if err != nil {
+		return err
+	}
+
+	u.hashedPassword = newHashedPassword
+	u.UpdatedAt = time.Now()
+	m.DB.users[id] = u
+
+	return nil
+}
+
+// Get user by id
+func (m *UserModel) Get(ctx context.Context, id uuid.UUID) (models.User, error) {
+	if err := checkContext(ctx); err != nil {
+		return models.User{}, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	u, ok := m.DB.users[id]
+	if !ok {
+		return models.User{}, models.ErrNoRecord
+	}
+
+	return u.User, nil
+}
+
+// Get user by email
+func (m *UserModel) GetByEmail(ctx context.Context, email string) (models.User, error) {
+	if err := checkContext(ctx); err != nil {
+		return models.User{}, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	for _, u := range m.DB.users {
+		if u.Email == email {
+			return u.User, nil
+		}
+	}
+
+	return models.User{}, models.ErrNoRecord
+}
+
+// Get user by URL name, preferring the oldest account when slugs repeat
+func (m *UserModel) GetByURLName(ctx context.Context, urlName string) (models.User, error) {
+	if err := checkContext(ctx); err != nil {
+		return models.User{}, err
+	}
+
+	m.DB.mu.RLock()
+	defer m.DB.mu.RUnlock()
+
+	var found *models.User
+	for _, u := range m.DB.users {
+		if u.ProfileSlug == urlName && (found == nil || u.CreatedAt.Before(found.CreatedAt)) {
+			found = &u.User
+		}
+	}
+	if found == nil {
+		return models.User{}, models.ErrNoRecord
+	}
+
+	return *found, nil
+}
+
+// Update last quote added at timestamp
+func (m *UserModel) UpdateLastQuoteAddedAt(ctx context.Context, id uuid.UUID) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	if u, ok := m.DB.users[id]; ok {
+		u.lastQuoteAddedAt = time.Now()
+		m.DB.users[id] = u
+	}
+
+	return nil
+}
+
+// SetRole changes the role of a user
+func (m *UserModel) SetRole(ctx context.Context, id uuid.UUID, role models.Role) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	m.DB.mu.Lock()
+	defer m.DB.mu.Unlock()
+
+	u, ok := m.DB.users[id]
+	if !ok {
+		return models.ErrNoRecord
+	}
+
+	u.Role = role
+	u.UpdatedAt = time.Now()
+	m.DB.users[id] = u
+
+	return nil
+}
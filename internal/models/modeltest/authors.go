@@ -0,0 +1,213 @@
+package modeltest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+func testAuthorModelGetAllWithCounts(t *testing.T, db Models) {
+	m := db.Authors
+
+	authors, err := m.GetAllWithCounts(models.WithViewer(context.Background(), testUserID))
+	assert.NilError(t, err)
+	assert.Equal(t, len(authors), 2)
+
+	// Check the counts are computed per author, with books counted once
+	assert.Equal(t, authors[0].Name, "Marcus Aurelius")
+	assert.Equal(t, authors[0].QuoteCount, 2)
+	assert.Equal(t, authors[0].BookCount, 1)
+	assert.Equal(t, authors[1].QuoteCount, 1)
+
+	// Check Seneca's private quote isn't counted for anyone else, while the
+	// book he is credited on still is
+	authors, err = m.GetAllWithCounts(context.Background())
+	assert.NilError(t, err)
+	assert.Equal(t, authors[1].QuoteCount, 0)
+	assert.Equal(t, authors[1].BookCount, 1)
+}
+
+func testAuthorModelGetWithCounts(t *testing.T, db Models) {
+	m := db.Authors
+
+	author, err := m.GetWithCounts(models.WithViewer(context.Background(), testUserID), 2)
+	assert.NilError(t, err)
+	assert.Equal(t, author.QuoteCount, 1)
+
+	author, err = m.GetWithCounts(context.Background(), 2)
+	assert.NilError(t, err)
+	assert.Equal(t, author.QuoteCount, 0)
+}
+
+func testAuthorModelGetBooksByAuthor(t *testing.T, db Models) {
+	m := db.Authors
+
+	books, err := m.GetBooksByAuthor(models.WithViewer(context.Background(), testUserID), 2)
+	assert.NilError(t, err)
+	assert.Equal(t, len(books), 1)
+	assert.Equal(t, books[0].Title, "Letters from a Stoic")
+
+	// Check a book is listed through its credits, even when only quoted privately
+	books, err = m.GetBooksByAuthor(context.Background(), 2)
+	assert.NilError(t, err)
+	assert.Equal(t, len(books), 1)
+
+	// Check an author without credits has no books
+	books, err = m.GetBooksByAuthor(context.Background(), 99)
+	assert.NilError(t, err)
+	assert.Equal(t, len(books), 0)
+}
+
+func testAuthorModelListWithCounts(t *testing.T, db Models) {
+	m := db.Authors
+
+	// Check the default order is by name
+	authors, metadata, err := m.ListWithCounts(context.Background(), models.Filters{Page: 1, PageSize: 1})
+	assert.NilError(t, err)
+	assert.Equal(t, len(authors), 1)
+	assert.Equal(t, authors[0].Name, "Marcus Aurelius")
+	assert.Equal(t, authors[0].QuoteCount, 2)
+	assert.Equal(t, metadata.LastPage, 2)
+
+	// Check the most quoted author comes first
+	authors, _, err = m.ListWithCounts(context.Background(), models.Filters{Page: 1, PageSize: 1, Sort: models.SortMostQuoted})
+	assert.NilError(t, err)
+	assert.Equal(t, authors[0].Name, "Marcus Aurelius")
+
+	// Check the newest author comes first
+	authors, _, err = m.ListWithCounts(context.Background(), models.Filters{Page: 1, PageSize: 20, Sort: models.SortNewest})
+	assert.NilError(t, err)
+	assert.Equal(t, authors[0].Name, "Seneca")
+}
+
+func testAuthorModelMergeAndDelete(t *testing.T, db Models) {
+	m := db.Authors
+	ctx := context.Background()
+
+	// Check an author is kept while they have quotes, even private ones
+	err := m.Delete(ctx, 2)
+	assert.Equal(t, err, models.ErrAuthorHasQuotes)
+
+	// Check an author can't be merged into themselves or a missing author
+	_, err = m.Merge(ctx, 2, 2)
+	assert.Equal(t, err, models.ErrMergeSameAuthor)
+	_, err = m.Merge(ctx, 2, 99)
+	assert.Equal(t, err, models.ErrNoRecord)
+
+	// Check merging moves every quote and deletes the duplicate
+	moved, err := m.Merge(ctx, 2, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, moved, 1)
+
+	exists, err := m.Exists(ctx, 2)
+	assert.NilError(t, err)
+	assert.Equal(t, exists, false)
+
+	author, err := m.GetWithCounts(models.WithViewer(ctx, testUserID), 1)
+	assert.NilError(t, err)
+	assert.Equal(t, author.QuoteCount, 3)
+	assert.Equal(t, author.BookCount, 2)
+
+	// Check an author without quotes can be deleted
+	id, err := m.Insert(ctx, "Epictetus", models.AuthorDetails{}, testUserID)
+	assert.NilError(t, err)
+	assert.NilError(t, m.Delete(ctx, id))
+
+	exists, err = m.Exists(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, exists, false)
+}
+
+func testAuthorModelAliases(t *testing.T, db Models) {
+	m := db.Authors
+	ctx := context.Background()
+
+	// Check an alias can only be added to an author who exists, and only once
+	id, err := m.AddAlias(ctx, 1, "Marcus Annius Verus")
+	assert.NilError(t, err)
+	_, err = m.AddAlias(ctx, 2, "Marcus Annius Verus")
+	assert.Equal(t, err, models.ErrDuplicateAlias)
+	_, err = m.AddAlias(ctx, 99, "Nobody")
+	assert.Equal(t, err, models.ErrNoRecord)
+
+	// Check the name and the alias both find the author
+	author, err := m.GetByName(ctx, "Marcus Annius Verus")
+	assert.NilError(t, err)
+	assert.Equal(t, author.ID, 1)
+	author, err = m.GetByName(ctx, "Marcus Aurelius")
+	assert.NilError(t, err)
+	assert.Equal(t, author.ID, 1)
+
+	// Check searching by author name also matches aliases
+	results, _, err := db.Quotes.Search(ctx, models.SearchQuery{AuthorName: "verus"}, models.Filters{Page: 1, PageSize: 20})
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 2)
+
+	// Check merging keeps the aliases of the author merged
+	fromID, err := m.Insert(ctx, "Marcus Antoninus", models.AuthorDetails{}, testUserID)
+	assert.NilError(t, err)
+	_, err = m.AddAlias(ctx, fromID, "Antoninus")
+	assert.NilError(t, err)
+	_, err = m.Merge(ctx, fromID, 1)
+	assert.NilError(t, err)
+
+	aliases, err := m.GetAliases(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(aliases), 2)
+	assert.Equal(t, aliases[0].Name, "Antoninus")
+
+	// Check an alias is only deleted from its own author
+	err = m.DeleteAlias(ctx, 2, id)
+	assert.Equal(t, err, models.ErrNoRecord)
+	assert.NilError(t, m.DeleteAlias(ctx, 1, id))
+
+	_, err = m.GetByName(ctx, "Marcus Annius Verus")
+	assert.Equal(t, err, models.ErrNoRecord)
+}
+
+func testAuthorModelDetails(t *testing.T, db Models) {
+	m := db.Authors
+	ctx := context.Background()
+
+	// Check the details are saved with a new author, B.C. years included
+	details := models.AuthorDetails{
+		Bio:               "Stoic philosopher and statesman.",
+		BirthYear:         4,
+		BirthCalendarTime: "B.C.",
+		DeathYear:         65,
+		DeathCalendarTime: "A.D.",
+		Nationality:       "Roman",
+		Occupation:        "Philosopher",
+		Links:             []string{"https://en.wikipedia.org/wiki/Seneca_the_Younger", "https://plato.stanford.edu/entries/seneca/"},
+	}
+	id, err := m.Insert(ctx, "Lucius Annaeus Seneca", details, testUserID)
+	assert.NilError(t, err)
+
+	author, err := m.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, author.Bio, details.Bio)
+	assert.Equal(t, author.Lifespan(), "4 B.C. – 65 A.D.")
+	assert.Equal(t, author.Nationality, "Roman")
+	assert.Equal(t, author.Occupation, "Philosopher")
+	assert.Equal(t, len(author.Links), 2)
+	assert.Equal(t, author.Links[1], details.Links[1])
+
+	// Check updating replaces every detail, and the links can be cleared
+	_, err = m.Update(ctx, id, "Seneca the Younger", models.AuthorDetails{BirthYear: 4, BirthCalendarTime: "B.C."})
+	assert.NilError(t, err)
+
+	author, err = m.GetWithCounts(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, author.Name, "Seneca the Younger")
+	assert.Equal(t, author.Bio, "")
+	assert.Equal(t, author.Lifespan(), "Born 4 B.C.")
+	assert.Equal(t, len(author.Links), 0)
+
+	// Check authors added before the details existed have none
+	author, err = m.Get(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, author.Lifespan(), "")
+	assert.Equal(t, author.Links == nil, true)
+}
@@ -0,0 +1,227 @@
+package modeltest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+func testBookModelGet(t *testing.T, db Models) {
+	// Set up a tests struct
+	tests := []struct {
+		name       string
+		bookID     int
+		wantAuthor string
+		wantQuotes int
+	}{
+		{"Quoted book", 1, "Marcus Aurelius", 2},
+		{"Unquoted book", 3, "Unknown", 0},
+	}
+
+	m := db.Books
+	ctx := models.WithViewer(context.Background(), testUserID)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book, err := m.Get(ctx, tt.bookID)
+			assert.NilError(t, err)
+			assert.Equal(t, book.Author.Name, tt.wantAuthor)
+			assert.Equal(t, len(book.Quotes), tt.wantQuotes)
+		})
+	}
+}
+
+func testBookModelGetAllWithAuthors(t *testing.T, db Models) {
+	m := db.Books
+	ctx := models.WithViewer(context.Background(), testUserID)
+
+	books, metadata, err := m.GetAllWithAuthors(ctx, models.Filters{Page: 1, PageSize: 20})
+	assert.NilError(t, err)
+	assert.Equal(t, len(books), 3)
+	// Check the books are ordered by title by default
+	assert.Equal(t, books[0].Author.Name, "Unknown")
+	assert.Equal(t, books[1].Author.Name, "Seneca")
+	assert.Equal(t, metadata.TotalRecords, 3)
+
+	// Check a book's author comes from its credits, not the quotes the viewer can see
+	books, _, err = m.GetAllWithAuthors(context.Background(), models.Filters{Page: 1, PageSize: 20})
+	assert.NilError(t, err)
+	assert.Equal(t, books[1].Author.Name, "Seneca")
+
+	book, err := m.Get(context.Background(), 2)
+	assert.NilError(t, err)
+	assert.Equal(t, len(book.Quotes), 0)
+}
+
+func testBookModelGetAllWithAuthorsPages(t *testing.T, db Models) {
+	// Set up a tests struct
+	tests := []struct {
+		name      string
+		filters   models.Filters
+		wantIDs   []int
+		wantTotal int
+		wantLast  int
+	}{
+		{"First page", models.Filters{Page: 1, PageSize: 2}, []int{3, 2}, 3, 2},
+		{"Second page", models.Filters{Page: 2, PageSize: 2}, []int{1}, 3, 2},
+		{"Past the end", models.Filters{Page: 3, PageSize: 2}, []int{}, 3, 2},
+		{"By author", models.Filters{Page: 1, PageSize: 20, Sort: models.SortAuthor}, []int{1, 2, 3}, 3, 1},
+		{"Most quoted", models.Filters{Page: 1, PageSize: 20, Sort: models.SortMostQuoted}, []int{1, 2, 3}, 3, 1},
+	}
+
+	m := db.Books
+	ctx := models.WithViewer(context.Background(), testUserID)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			books, metadata, err := m.GetAllWithAuthors(ctx, tt.filters)
+			assert.NilError(t, err)
+
+			ids := []int{}
+			for _, b := range books {
+				ids = append(ids, b.ID)
+			}
+			assert.Equal(t, fmt.Sprint(ids), fmt.Sprint(tt.wantIDs))
+			assert.Equal(t, metadata.TotalRecords, tt.wantTotal)
+			assert.Equal(t, metadata.LastPage, tt.wantLast)
+		})
+	}
+}
+
+func testBookModelContributors(t *testing.T, db Models) {
+	m := db.Books
+	authors := db.Authors
+	ctx := context.Background()
+
+	// Credit Seneca as the translator before Marcus Aurelius as the author
+	contributors := []models.BookContributor{
+		{AuthorID: 2, Role: models.ContributorTranslator},
+		{AuthorID: 1, Role: models.ContributorAuthor},
+	}
+	id, err := m.Insert(ctx, "Selected Letters", 60, "A.D.", "", "", models.WorkDetails{}, contributors, testUserID)
+	assert.NilError(t, err)
+
+	// Check the first contributor credited as an author is the book's author
+	book, err := m.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, book.Author.Name, "Marcus Aurelius")
+	assert.Equal(t, len(book.Contributors), 2)
+	assert.Equal(t, book.Contributors[0].Author.Name, "Seneca")
+	assert.Equal(t, book.Contributors[0].Role, models.ContributorTranslator)
+
+	// Check the book is listed for every author credited on it
+	books, err := m.GetByAuthorID(ctx, 2)
+	assert.NilError(t, err)
+	assert.Equal(t, len(books), 2)
+	assert.Equal(t, books[1].Title, "Selected Letters")
+	assert.Equal(t, books[1].Author.Name, "Marcus Aurelius")
+
+	// Check an author credited on books can't be deleted
+	id2, err := authors.Insert(ctx, "Epictetus", models.AuthorDetails{}, testUserID)
+	assert.NilError(t, err)
+	err = m.Update(ctx, id, "Selected Letters", 60, "A.D.", "", "", models.WorkDetails{}, []models.BookContributor{{AuthorID: id2, Role: models.ContributorEditor}})
+	assert.NilError(t, err)
+	assert.Equal(t, authors.Delete(ctx, id2), models.ErrAuthorHasBooks)
+
+	// Check updating replaces the contributors, leaving the book without an author
+	book, err = m.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, book.Author.Name, "Unknown")
+	assert.Equal(t, len(book.Contributors), 1)
+
+	// Check deleting the book removes its credits
+	assert.NilError(t, m.Delete(ctx, id))
+	assert.NilError(t, authors.Delete(ctx, id2))
+}
+
+func testBookModelWorkDetails(t *testing.T, db Models) {
+	m := db.Books
+	ctx := context.Background()
+
+	// Check books stored before media types existed are books
+	book, err := m.Get(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, book.MediaType, models.MediaBook)
+
+	// Check a speech keeps its venue and date
+	details := models.WorkDetails{MediaType: models.MediaSpeech, Venue: "Senate House", Date: "0044-03-15"}
+	id, err := m.Insert(ctx, "On Duties", 44, "B.C.", "", "", details, nil, testUserID)
+	assert.NilError(t, err)
+
+	book, err = m.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, book.WorkDetails, details)
+
+	// Check updating a work can change its media type
+	details = models.WorkDetails{MediaType: models.MediaPaper, DOI: "10.1000/182", PageCount: 48}
+	err = m.Update(ctx, id, "On Duties", 44, "B.C.", "", "", details, nil)
+	assert.NilError(t, err)
+
+	book, err = m.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, book.WorkDetails, details)
+}
+
+func testBookModelDuplicateISBN(t *testing.T, db Models) {
+	m := db.Books
+	ctx := context.Background()
+	details := models.WorkDetails{MediaType: models.MediaBook}
+
+	// Check a book can't be added or changed to have another book's ISBN
+	_, err := m.Insert(ctx, "Meditations (Hays)", 180, "A.D.", "9780140449334", "", details, nil, testUserID)
+	assert.Equal(t, err, models.ErrDuplicateISBN)
+
+	err = m.Update(ctx, 2, "Letters from a Stoic", 65, "A.D.", "9780140449334", "", details, nil)
+	assert.Equal(t, err, models.ErrDuplicateISBN)
+
+	// Check a book can keep its own ISBN, and any number of works can have none
+	err = m.Update(ctx, 1, "Meditations", 180, "A.D.", "9780140449334", "", details, nil)
+	assert.NilError(t, err)
+
+	for range 2 {
+		_, err = m.Insert(ctx, "Moral Letters", 65, "A.D.", "", "", models.WorkDetails{MediaType: models.MediaLetter}, nil, testUserID)
+		assert.NilError(t, err)
+	}
+}
+
+func testBookModelGetByISBN(t *testing.T, db Models) {
+	m := db.Books
+	ctx := context.Background()
+
+	// Check the book with the ISBN is found, and an unused ISBN isn't
+	book, err := m.GetByISBN(ctx, "9780140449334")
+	assert.NilError(t, err)
+	assert.Equal(t, book.ID, 1)
+	assert.Equal(t, book.Title, "Meditations")
+
+	_, err = m.GetByISBN(ctx, "9780000000019")
+	assert.Equal(t, err, models.ErrNoRecord)
+}
+
+func testBookModelDeleteWithQuotes(t *testing.T, db Models) {
+	m := db.Books
+	ctx := models.WithViewer(context.Background(), testUserID)
+
+	// Check a book with quotes is refused, keeping its credits and editions
+	_, err := db.Editions.Insert(ctx, 1, models.EditionDetails{Language: "Latin"}, testUserID)
+	assert.NilError(t, err)
+
+	err = m.Delete(ctx, 1)
+	assert.Equal(t, err, models.ErrBookHasQuotes)
+
+	book, err := m.Get(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(book.Contributors), 1)
+	editions, err := db.Editions.GetByBookID(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(editions), 1)
+
+	// Check a book without quotes is deleted
+	err = m.Delete(ctx, 3)
+	assert.NilError(t, err)
+	_, err = m.Get(ctx, 3)
+	assert.Equal(t, err, models.ErrNoRecord)
+}
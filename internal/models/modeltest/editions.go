@@ -0,0 +1,136 @@
+package modeltest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+func testEditionModel(t *testing.T, db Models) {
+	m := db.Editions
+	ctx := context.Background()
+
+	// Add a translation, and an edition whose year isn't known
+	hays := models.EditionDetails{ISBN: "9780812968255", Publisher: "Modern Library", Language: "English", TranslatorID: 2, PublishYear: 2003, PageCount: 256}
+	haysID, err := m.Insert(ctx, 1, hays, testUserID)
+	assert.NilError(t, err)
+
+	undatedID, err := m.Insert(ctx, 1, models.EditionDetails{Language: "Latin"}, testUserID)
+	assert.NilError(t, err)
+
+	// Check an edition is read back with its translator
+	e, err := m.Get(ctx, haysID)
+	assert.NilError(t, err)
+	assert.Equal(t, e.BookID, 1)
+	assert.Equal(t, e.EditionDetails, hays)
+	assert.Equal(t, e.Translator.Name, "Seneca")
+	assert.Equal(t, e.UserID, testUserID)
+	assert.Equal(t, e.Label(), "Modern Library, 2003, English, translated by Seneca")
+
+	e, err = m.GetByISBN(ctx, "9780812968255")
+	assert.NilError(t, err)
+	assert.Equal(t, e.ID, haysID)
+
+	_, err = m.GetByISBN(ctx, "9780000000019")
+	assert.Equal(t, err, models.ErrNoRecord)
+
+	_, err = m.Get(ctx, 9999)
+	assert.Equal(t, err, models.ErrNoRecord)
+
+	// Check a book's editions are listed oldest first, with unknown years last
+	editions, err := m.GetByBookID(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(editions), 2)
+	assert.Equal(t, editions[0].ID, haysID)
+	assert.Equal(t, editions[1].ID, undatedID)
+	assert.Equal(t, editions[1].Translator.ID, 0)
+
+	editions, err = m.GetByBookID(ctx, 2)
+	assert.NilError(t, err)
+	assert.Equal(t, len(editions), 0)
+
+	// Check no two editions can share an ISBN, though any number can have none
+	_, err = m.Insert(ctx, 2, models.EditionDetails{ISBN: "9780812968255"}, testUserID)
+	assert.Equal(t, err, models.ErrDuplicateISBN)
+
+	err = m.Update(ctx, undatedID, models.EditionDetails{ISBN: "9780812968255"})
+	assert.Equal(t, err, models.ErrDuplicateISBN)
+
+	_, err = m.Insert(ctx, 2, models.EditionDetails{Language: "German"}, testUserID)
+	assert.NilError(t, err)
+
+	// Check an edition can be updated, clearing its translator
+	err = m.Update(ctx, haysID, models.EditionDetails{ISBN: "9780812968255", Publisher: "Modern Library", PublishYear: 2002})
+	assert.NilError(t, err)
+
+	e, err = m.Get(ctx, haysID)
+	assert.NilError(t, err)
+	assert.Equal(t, e.PublishYear, 2002)
+	assert.Equal(t, e.TranslatorID, 0)
+	assert.Equal(t, e.Translator.Name, "")
+
+	all, err := m.GetAll(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(all), 3)
+	assert.Equal(t, all[2].BookID, 2)
+}
+
+func testEditionModelQuotes(t *testing.T, db Models) {
+	m := db.Editions
+	quotes := db.Quotes
+	ctx := context.Background()
+
+	editionID, err := m.Insert(ctx, 1, models.EditionDetails{Publisher: "Modern Library", PublishYear: 2003}, testUserID)
+	assert.NilError(t, err)
+
+	// Check a quote taken from an edition is still one of the book's quotes
+	id, err := quotes.Insert(ctx, "Very little is needed to make a happy life.", 1, 1, editionID, models.Location{Type: models.LocationPage, Start: 90}, models.VisibilityPublic, testUserID)
+	assert.NilError(t, err)
+
+	q, err := quotes.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, q.EditionID, editionID)
+
+	bookQuotes, err := quotes.GetByBookID(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(bookQuotes), 3)
+	assert.Equal(t, bookQuotes[0].EditionID, 0)
+	assert.Equal(t, bookQuotes[2].EditionID, editionID)
+
+	// Check deleting the edition leaves its quotes with the book
+	err = m.Delete(ctx, editionID)
+	assert.NilError(t, err)
+
+	q, err = quotes.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, q.EditionID, 0)
+	assert.Equal(t, q.BookID, 1)
+}
+
+func testEditionModelTranslator(t *testing.T, db Models) {
+	m := db.Editions
+	authors := db.Authors
+	ctx := context.Background()
+
+	translatorID, err := authors.Insert(ctx, "Gregory Hays", models.AuthorDetails{}, testUserID)
+	assert.NilError(t, err)
+	editionID, err := m.Insert(ctx, 1, models.EditionDetails{TranslatorID: translatorID}, testUserID)
+	assert.NilError(t, err)
+
+	// Check a translator can't be deleted while they are credited on an edition
+	err = authors.Delete(ctx, translatorID)
+	assert.Equal(t, err, models.ErrAuthorHasBooks)
+
+	// Check merging the translator credits the edition to the author merged into
+	mergedID, err := authors.Insert(ctx, "G. Hays", models.AuthorDetails{}, testUserID)
+	assert.NilError(t, err)
+	_, err = authors.Merge(ctx, translatorID, mergedID)
+	assert.NilError(t, err)
+
+	e, err := m.Get(ctx, editionID)
+	assert.NilError(t, err)
+	assert.Equal(t, e.TranslatorID, mergedID)
+	assert.Equal(t, e.Translator.Name, "G. Hays")
+}
@@ -0,0 +1,76 @@
+// Package modeltest holds the tests every database backend of the models must
+// pass, so the sqlite, postgres and memory packages run the same checks
+// against their own databases.
+//
+// Each backend seeds its database with the same test data: the user Alice,
+// the authors Marcus Aurelius and Seneca, the books Meditations, Letters from
+// a Stoic and An Unquoted Book, and three quotes, the last of them private.
+package modeltest
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+// The user that owns the test data
+var testUserID = uuid.MustParse("a3a6e2b6-7e64-4c3e-9a3b-7d0a3f7f9d11")
+
+// Models holds the models of one backend, all sharing a database seeded with the test data
+type Models struct {
+	Quotes   models.QuoteModelInterface
+	Authors  models.AuthorModelInterface
+	Books    models.BookModelInterface
+	Editions models.EditionModelInterface
+	Users    models.UserModelInterface
+}
+
+// The shared tests, by name
+var tests = []struct {
+	name string
+	run  func(t *testing.T, db Models)
+}{
+	{"AuthorModelGetAllWithCounts", testAuthorModelGetAllWithCounts},
+	{"AuthorModelGetWithCounts", testAuthorModelGetWithCounts},
+	{"AuthorModelGetBooksByAuthor", testAuthorModelGetBooksByAuthor},
+	{"AuthorModelListWithCounts", testAuthorModelListWithCounts},
+	{"AuthorModelMergeAndDelete", testAuthorModelMergeAndDelete},
+	{"AuthorModelAliases", testAuthorModelAliases},
+	{"AuthorModelDetails", testAuthorModelDetails},
+	{"BookModelGet", testBookModelGet},
+	{"BookModelGetAllWithAuthors", testBookModelGetAllWithAuthors},
+	{"BookModelGetAllWithAuthorsPages", testBookModelGetAllWithAuthorsPages},
+	{"BookModelContributors", testBookModelContributors},
+	{"BookModelWorkDetails", testBookModelWorkDetails},
+	{"BookModelDuplicateISBN", testBookModelDuplicateISBN},
+	{"BookModelGetByISBN", testBookModelGetByISBN},
+	{"BookModelDeleteWithQuotes", testBookModelDeleteWithQuotes},
+	{"EditionModel", testEditionModel},
+	{"EditionModelQuotes", testEditionModelQuotes},
+	{"EditionModelTranslator", testEditionModelTranslator},
+	{"QuoteModelExists", testQuoteModelExists},
+	{"QuoteModelGetWithAuthorAndBook", testQuoteModelGetWithAuthorAndBook},
+	{"QuoteModelLatest", testQuoteModelLatest},
+	{"QuoteModelGetByUserID", testQuoteModelGetByUserID},
+	{"QuoteModelVisibility", testQuoteModelVisibility},
+	{"QuoteModelSearch", testQuoteModelSearch},
+	{"QuoteModelInsert", testQuoteModelInsert},
+	{"QuoteModelLocation", testQuoteModelLocation},
+	{"QuoteModelShareToken", testQuoteModelShareToken},
+	{"QuoteModelTags", testQuoteModelTags},
+	{"UserModelAuthenticate", testUserModelAuthenticate},
+	{"UserModelInsertDuplicateEmail", testUserModelInsertDuplicateEmail},
+	{"UserModelChangePassword", testUserModelChangePassword},
+	{"UserModelSetRole", testUserModelSetRole},
+}
+
+// Run runs every shared test as a subtest of t, calling open for a freshly
+// seeded database each time
+func Run(t *testing.T, open func(t *testing.T) Models) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, open(t))
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package modeltest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+func testQuoteModelExists(t *testing.T, db Models) {
+	// Set up a tests struct
+	tests := []struct {
+		name    string
+		quoteID int
+		want    bool
+	}{
+		{"Valid ID", 1, true},
+		{"Non-existent ID", 9999, false},
+		{"Zero ID", 0, false},
+	}
+
+	// Create a new QuoteModel instance on the test database
+	m := db.Quotes
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exists, err := m.Exists(context.Background(), tt.quoteID)
+			assert.NilError(t, err)
+			assert.Equal(t, exists, tt.want)
+		})
+	}
+}
+
+func testQuoteModelGetWithAuthorAndBook(t *testing.T, db Models) {
+	m := db.Quotes
+	ctx := models.WithViewer(context.Background(), testUserID)
+
+	// Check the quote is returned with its author and book
+	q, err := m.GetWithAuthorAndBook(ctx, 3)
+	assert.NilError(t, err)
+	assert.Equal(t, q.Author.Name, "Seneca")
+	assert.Equal(t, q.Book.Title, "Letters from a Stoic")
+	assert.Equal(t, q.Visibility, models.VisibilityPrivate)
+
+	// Check a missing quote returns ErrNoRecord
+	_, err = m.GetWithAuthorAndBook(ctx, 9999)
+	assert.Equal(t, err, models.ErrNoRecord)
+}
+
+func testQuoteModelLatest(t *testing.T, db Models) {
+	m := db.Quotes
+	ctx := models.WithViewer(context.Background(), testUserID)
+
+	// Check the newest quote comes first and relations are loaded
+	quotes, metadata, err := m.Latest(ctx, models.Filters{Page: 1, PageSize: 20})
+	assert.NilError(t, err)
+	assert.Equal(t, len(quotes), 3)
+	assert.Equal(t, quotes[0].ID, 3)
+	assert.Equal(t, quotes[2].Author.Name, "Marcus Aurelius")
+	assert.Equal(t, metadata.TotalRecords, 3)
+
+	// Check the oldest order and a second page
+	quotes, metadata, err = m.Latest(ctx, models.Filters{Page: 2, PageSize: 2, Sort: models.SortOldest})
+	assert.NilError(t, err)
+	assert.Equal(t, len(quotes), 1)
+	assert.Equal(t, quotes[0].ID, 3)
+	assert.Equal(t, metadata.LastPage, 2)
+
+	// Check the author order
+	quotes, _, err = m.Latest(ctx, models.Filters{Page: 1, PageSize: 20, Sort: models.SortAuthor})
+	assert.NilError(t, err)
+	assert.Equal(t, quotes[2].Author.Name, "Seneca")
+
+	// Check a page past the end still reports the total
+	quotes, metadata, err = m.Latest(ctx, models.Filters{Page: 5, PageSize: 2})
+	assert.NilError(t, err)
+	assert.Equal(t, len(quotes), 0)
+	assert.Equal(t, metadata.TotalRecords, 3)
+}
+
+func testQuoteModelGetByUserID(t *testing.T, db Models) {
+	m := db.Quotes
+	ctx := models.WithViewer(context.Background(), testUserID)
+
+	quotes, metadata, err := m.GetByUserID(ctx, testUserID, models.Filters{Page: 1, PageSize: 2, Sort: models.SortAuthor})
+	assert.NilError(t, err)
+	assert.Equal(t, len(quotes), 2)
+	assert.Equal(t, quotes[0].Author.Name, "Marcus Aurelius")
+	assert.Equal(t, metadata.TotalRecords, 3)
+}
+
+func testQuoteModelVisibility(t *testing.T, db Models) {
+	// Set up a tests struct
+	tests := []struct {
+		name   string
+		viewer uuid.UUID
+		want   bool
+	}{
+		{"Anonymous", uuid.Nil, false},
+		{"Another user", uuid.MustParse("0b7a3c51-2f64-4d8e-9a1b-5c6d7e8f9a0b"), false},
+		{"Owner", testUserID, true},
+	}
+
+	m := db.Quotes
+	filters := models.Filters{Page: 1, PageSize: 20}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := models.WithViewer(context.Background(), tt.viewer)
+
+			// Quote 3 is private, and is Seneca's only quote and the only quote of book 2
+			_, err := m.Get(ctx, 3)
+			assert.Equal(t, err == nil, tt.want)
+			_, err = m.GetWithAuthorAndBook(ctx, 3)
+			assert.Equal(t, err == nil, tt.want)
+			if !tt.want {
+				assert.Equal(t, err, models.ErrNoRecord)
+			}
+
+			exists, err := m.Exists(ctx, 3)
+			assert.NilError(t, err)
+			assert.Equal(t, exists, tt.want)
+
+			quotes, err := m.GetByAuthorID(ctx, 2)
+			assert.NilError(t, err)
+			assert.Equal(t, len(quotes) == 1, tt.want)
+
+			quotes, err = m.GetByBookID(ctx, 2)
+			assert.NilError(t, err)
+			assert.Equal(t, len(quotes) == 1, tt.want)
+
+			// Check the private quote is left out of the listings and their counts
+			_, metadata, err := m.Latest(ctx, filters)
+			assert.NilError(t, err)
+			assert.Equal(t, metadata.TotalRecords == 3, tt.want)
+
+			_, metadata, err = m.GetByUserID(ctx, testUserID, filters)
+			assert.NilError(t, err)
+			assert.Equal(t, metadata.TotalRecords == 3, tt.want)
+		})
+	}
+}
+
+func testQuoteModelSearch(t *testing.T, db Models) {
+	// Set up a tests struct
+	tests := []struct {
+		name    string
+		query   models.SearchQuery
+		wantIDs []int
+	}{
+		{"Quote text", models.SearchQuery{Terms: "happiness"}, []int{1}},
+		{"Word prefix", models.SearchQuery{Terms: "HAPP"}, []int{1}},
+		{"Every word must match", models.SearchQuery{Terms: "happiness waste"}, []int{}},
+		{"Author name", models.SearchQuery{Terms: "marcus"}, []int{1, 2}},
+		{"Short word", models.SearchQuery{Terms: "be"}, []int{2}},
+		{"Private quotes hidden", models.SearchQuery{Terms: "luck"}, []int{}},
+		{"Own private quotes", models.SearchQuery{Terms: "luck", UserID: testUserID}, []int{3}},
+		{"Public scope", models.SearchQuery{Terms: "luck", Scope: models.ScopePublic, UserID: testUserID}, []int{}},
+		{"Mine logged out", models.SearchQuery{Terms: "marcus", Scope: models.ScopeMine}, []int{}},
+		{"Book title", models.SearchQuery{Terms: "stoic", Scope: models.ScopeMine, UserID: testUserID}, []int{3}},
+		{"Author filter", models.SearchQuery{Terms: "the", AuthorID: 2, UserID: testUserID}, []int{}},
+		{"Book filter", models.SearchQuery{Terms: "marcus", BookID: 2}, []int{}},
+		{"Year range", models.SearchQuery{Terms: "marcus", YearFrom: 100, YearTo: 200}, []int{1, 2}},
+		{"Year range excluded", models.SearchQuery{Terms: "marcus", YearTo: 100}, []int{}},
+		{"No words", models.SearchQuery{Terms: " ,. "}, []int{}},
+		{"Phrase", models.SearchQuery{Phrases: []string{"quality of your"}}, []int{1}},
+		{"Phrase out of order", models.SearchQuery{Phrases: []string{"your quality"}}, []int{}},
+		{"Excluded word", models.SearchQuery{Terms: "marcus", Excluded: []string{"happiness"}}, []int{2}},
+		{"Excluded phrase", models.SearchQuery{Terms: "marcus", Excluded: []string{"good man"}}, []int{1}},
+		{"Author name filter", models.SearchQuery{AuthorName: "aurel"}, []int{1, 2}},
+		{"Book title filter", models.SearchQuery{BookTitle: "LETTERS", UserID: testUserID}, []int{3}},
+		{"Private scope", models.SearchQuery{Scope: models.ScopePrivate, UserID: testUserID}, []int{3}},
+		{"Private scope logged out", models.SearchQuery{Scope: models.ScopePrivate}, []int{}},
+	}
+
+	m := db.Quotes
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, metadata, err := m.Search(context.Background(), tt.query, models.Filters{Page: 1, PageSize: 20})
+			assert.NilError(t, err)
+
+			// Each backend ranks a little differently, so only check which quotes were found
+			ids := []int{}
+			for _, r := range results {
+				ids = append(ids, r.Quote.ID)
+			}
+			sort.Ints(ids)
+			assert.Equal(t, fmt.Sprint(ids), fmt.Sprint(tt.wantIDs))
+			assert.Equal(t, metadata.TotalRecords, len(tt.wantIDs))
+		})
+	}
+
+	// Check the matching words are highlighted and the author and book are loaded
+	results, _, err := m.Search(context.Background(), models.SearchQuery{Terms: "meditations quality"}, models.Filters{Page: 1, PageSize: 20, Sort: models.SortOldest})
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 1)
+	assert.Equal(t, results[0].Quote.Author.Name, "Marcus Aurelius")
+	assert.Equal(t, fmt.Sprint(results[0].Title), "[{Meditations true}]")
+	assert.Equal(t, fmt.Sprint(results[0].Snippet[1]), "{quality true}")
+}
+
+func testQuoteModelInsert(t *testing.T, db Models) {
+	m := db.Quotes
+
+	// Insert a quote for the test user
+	id, err := m.Insert(context.Background(), "You have power over your mind.", 1, 1, 0, models.Location{Type: models.LocationPage, Start: 5}, models.VisibilityPublic, testUserID)
+	assert.NilError(t, err)
+	assert.Equal(t, id, 4)
+
+	// Check a quote can't refer to a missing author
+	_, err = m.Insert(context.Background(), "Orphaned quote.", 9999, 1, 0, models.Location{}, models.VisibilityPublic, testUserID)
+	if err == nil {
+		t.Error("got nil error for a missing author")
+	}
+}
+
+func testQuoteModelLocation(t *testing.T, db Models) {
+	m := db.Quotes
+	ctx := context.Background()
+
+	// Check a range of pages is kept whole
+	location := models.Location{Type: models.LocationPage, Start: 12, End: 15}
+	id, err := m.Insert(ctx, "You have power over your mind.", 1, 1, 0, location, models.VisibilityPublic, testUserID)
+	assert.NilError(t, err)
+	quote, err := m.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, quote.Location, location)
+
+	// Check a quote can be moved to a chapter, and then have no location at all
+	location = models.Location{Type: models.LocationChapter, Label: "Book Two"}
+	_, err = m.Update(ctx, id, "You have power over your mind.", 1, 1, 0, location, models.VisibilityPublic, testUserID)
+	assert.NilError(t, err)
+	quote, err = m.GetWithAuthorAndBook(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, quote.Location, location)
+
+	_, err = m.Update(ctx, id, "You have power over your mind.", 1, 1, 0, models.Location{}, models.VisibilityPublic, testUserID)
+	assert.NilError(t, err)
+	quote, err = m.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, quote.Location.IsZero(), true)
+}
+
+func testQuoteModelShareToken(t *testing.T, db Models) {
+	m := db.Quotes
+	ctx := context.Background()
+
+	// Make a quote unlisted, so only its owner and its share link can reach it
+	_, err := m.Update(ctx, 2, "Unlisted quote.", 1, 1, 0, models.Location{Type: models.LocationPage, Start: 140}, models.VisibilityUnlisted, testUserID)
+	assert.NilError(t, err)
+	_, err = m.Get(ctx, 2)
+	assert.Equal(t, err, models.ErrNoRecord)
+
+	// Check the share link finds the quote with its author and book
+	err = m.SetShareToken(ctx, 2, "first-token")
+	assert.NilError(t, err)
+	q, err := m.GetByShareToken(ctx, "first-token")
+	assert.NilError(t, err)
+	assert.Equal(t, q.ID, 2)
+	assert.Equal(t, q.ShareToken, "first-token")
+	assert.Equal(t, q.Author.Name, "Marcus Aurelius")
+	assert.Equal(t, q.Book.Title, "Meditations")
+
+	// Check rotating the token stops the old link working
+	err = m.SetShareToken(ctx, 2, "second-token")
+	assert.NilError(t, err)
+	_, err = m.GetByShareToken(ctx, "first-token")
+	assert.Equal(t, err, models.ErrNoRecord)
+	_, err = m.GetByShareToken(ctx, "second-token")
+	assert.NilError(t, err)
+
+	// Check revoking the token stops the link working
+	err = m.SetShareToken(ctx, 2, "")
+	assert.NilError(t, err)
+	_, err = m.GetByShareToken(ctx, "second-token")
+	assert.Equal(t, err, models.ErrNoRecord)
+	_, err = m.GetByShareToken(ctx, "")
+	assert.Equal(t, err, models.ErrNoRecord)
+
+	// Check a private quote can't be reached through a share link
+	err = m.SetShareToken(ctx, 3, "private-token")
+	assert.NilError(t, err)
+	_, err = m.GetByShareToken(ctx, "private-token")
+	assert.Equal(t, err, models.ErrNoRecord)
+}
+
+func testQuoteModelTags(t *testing.T, db Models) {
+	m := db.Quotes
+	ctx := context.Background()
+	filters := models.Filters{Page: 1, PageSize: 20}
+
+	// Check tags are read back in alphabetical order, and replaced when set again
+	err := m.SetTags(ctx, 1, []string{"stoic", "happiness"})
+	assert.NilError(t, err)
+	err = m.SetTags(ctx, 2, []string{"stoic"})
+	assert.NilError(t, err)
+
+	tags, err := m.GetTags(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, fmt.Sprint(tags), "[happiness stoic]")
+
+	err = m.SetTags(ctx, 1, []string{"mind"})
+	assert.NilError(t, err)
+	tags, err = m.GetTags(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, fmt.Sprint(tags), "[mind]")
+
+	// Check a search by tag only finds the tagged quotes
+	results, _, err := m.Search(ctx, models.SearchQuery{Tag: "stoic"}, filters)
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 1)
+	assert.Equal(t, results[0].Quote.ID, 2)
+
+	results, _, err = m.Search(ctx, models.SearchQuery{Terms: "marcus", Tag: "mind"}, filters)
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 1)
+	assert.Equal(t, results[0].Quote.ID, 1)
+
+	// Check clearing the tags and deleting the quote remove them
+	err = m.SetTags(ctx, 1, nil)
+	assert.NilError(t, err)
+	tags, err = m.GetTags(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(tags), 0)
+
+	err = m.Delete(ctx, 2)
+	assert.NilError(t, err)
+	tags, err = m.GetTags(ctx, 2)
+	assert.NilError(t, err)
+	assert.Equal(t, len(tags), 0)
+}
@@ -0,0 +1,79 @@
+package modeltest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+func testUserModelAuthenticate(t *testing.T, db Models) {
+	// Set up a tests struct
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantID   uuid.UUID
+		wantErr  error
+	}{
+		{"Valid credentials", "alice@example.com", "pa$$word", testUserID, nil},
+		{"Wrong password", "alice@example.com", "wrong", uuid.Nil, models.ErrInvalidCredentials},
+		{"Unknown email", "bob@example.com", "pa$$word", uuid.Nil, models.ErrInvalidCredentials},
+	}
+
+	m := db.Users
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := m.Authenticate(context.Background(), tt.email, tt.password)
+			assert.Equal(t, err, tt.wantErr)
+			assert.Equal(t, id, tt.wantID)
+		})
+	}
+}
+
+func testUserModelInsertDuplicateEmail(t *testing.T, db Models) {
+	m := db.Users
+
+	_, err := m.Insert(context.Background(), "Alice Smith", "alice@example.com", "pa$$word")
+	assert.Equal(t, err, models.ErrDuplicateEmail)
+}
+
+func testUserModelChangePassword(t *testing.T, db Models) {
+	m := db.Users
+
+	// Check the current password must match
+	err := m.ChangePassword(context.Background(), testUserID, "wrong", "newpa$$word")
+	assert.Equal(t, err, models.ErrInvalidCredentials)
+
+	// Check the new password can be used after changing it
+	err = m.ChangePassword(context.Background(), testUserID, "pa$$word", "newpa$$word")
+	assert.NilError(t, err)
+
+	id, err := m.Authenticate(context.Background(), "alice@example.com", "newpa$$word")
+	assert.NilError(t, err)
+	assert.Equal(t, id, testUserID)
+}
+
+func testUserModelSetRole(t *testing.T, db Models) {
+	m := db.Users
+
+	// Check users start with the user role
+	u, err := m.GetByEmail(context.Background(), "alice@example.com")
+	assert.NilError(t, err)
+	assert.Equal(t, u.Role, models.RoleUser)
+
+	// Check the new role is read back
+	err = m.SetRole(context.Background(), testUserID, models.RoleModerator)
+	assert.NilError(t, err)
+
+	u, err = m.Get(context.Background(), testUserID)
+	assert.NilError(t, err)
+	assert.Equal(t, u.Role, models.RoleModerator)
+
+	// Check an unknown user is reported
+	err = m.SetRole(context.Background(), uuid.New(), models.RoleAdmin)
+	assert.Equal(t, err, models.ErrNoRecord)
+}
@@ -0,0 +1,253 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseSearch parses the search query language into a SearchQuery. Besides
+// plain words it understands:
+//
+//	"exact phrase"         the words in order
+//	-word, -"phrase"       leave out quotes matching the word or phrase
+//	author:"Marcus"        the author's name contains the text
+//	book:Meditations       the book's title contains the text
+//	year:1850              published in the year, with B.C. years negative
+//	year:>100, year:<=-50  published after, before or in the bounding year
+//	year:100..200          published between the years
+//	is:public              public quotes
+//	is:unlisted            your own unlisted quotes
+//	is:private             your own private quotes
+//	is:mine                your own quotes
+//	tag:stoic              quotes with the tag
+//
+// The errors returned describe the mistake in the query, for the caller to
+// show the user.
+func ParseSearch(input string) (SearchQuery, error) {
+	var q SearchQuery
+	var words []string
+	seen := make(map[string]bool)
+
+	p := &parser{input: []rune(input)}
+	for {
+		p.skipSpace()
+		if p.done() {
+			break
+		}
+
+		// A leading minus excludes the word or phrase after it
+		excluded := false
+		if p.peek() == '-' && p.pos+1 < len(p.input) && !unicode.IsSpace(p.input[p.pos+1]) {
+			excluded = true
+			p.pos++
+		}
+
+		// A quoted phrase
+		if p.peek() == '"' {
+			phrase, err := p.quoted()
+			if err != nil {
+				return SearchQuery{}, err
+			}
+			if excluded {
+				q.Excluded = append(q.Excluded, phrase)
+			} else {
+				q.Phrases = append(q.Phrases, phrase)
+			}
+			continue
+		}
+
+		// A plain word, or a field with its value
+		word := p.bare()
+		field, value, isField := strings.Cut(word, ":")
+		if !isField || !isFieldName(field) {
+			if excluded {
+				q.Excluded = append(q.Excluded, word)
+			} else {
+				words = append(words, word)
+			}
+			continue
+		}
+
+		field = strings.ToLower(field)
+		if excluded {
+			return SearchQuery{}, fmt.Errorf("only words and phrases can be excluded, not %s:", field)
+		}
+		if seen[field] {
+			return SearchQuery{}, fmt.Errorf("%s: can only be used once", field)
+		}
+		seen[field] = true
+
+		// The value may be quoted to include spaces
+		if value == "" && p.peek() == '"' {
+			var err error
+			value, err = p.quoted()
+			if err != nil {
+				return SearchQuery{}, err
+			}
+		}
+		if strings.TrimSpace(value) == "" {
+			return SearchQuery{}, fmt.Errorf("%s: needs a value", field)
+		}
+
+		err := q.setField(field, strings.TrimSpace(value))
+		if err != nil {
+			return SearchQuery{}, err
+		}
+	}
+
+	q.Terms = strings.Join(words, " ")
+	return q, nil
+}
+
+// Sets the filter for a field of the query language
+func (q *SearchQuery) setField(field, value string) error {
+	switch field {
+	case "author":
+		q.AuthorName = value
+	case "book":
+		q.BookTitle = value
+	case "year":
+		from, to, err := parseYears(value)
+		if err != nil {
+			return err
+		}
+		q.YearFrom, q.YearTo = from, to
+	case "is":
+		switch strings.ToLower(value) {
+		case "public":
+			q.Scope = ScopePublic
+		case "unlisted":
+			q.Scope = ScopeUnlisted
+		case "private":
+			q.Scope = ScopePrivate
+		case "mine":
+			q.Scope = ScopeMine
+		default:
+			return fmt.Errorf("is: must be public, unlisted, private or mine, not %q", value)
+		}
+	case "tag":
+		q.Tag = strings.ToLower(value)
+	}
+	return nil
+}
+
+// Reports whether a word before a colon names a field, rather than being part of the text
+func isFieldName(field string) bool {
+	switch strings.ToLower(field) {
+	case "author", "book", "year", "is", "tag":
+		return true
+	}
+	return false
+}
+
+// Parses the value of a year: field into the bounds of a year range, with
+// B.C. years negative. There is no year 0, so bounds step over it.
+func parseYears(value string) (int, int, error) {
+	invalid := fmt.Errorf("year: must be a year like 1850, >100, <=-50 or 100..200, not %q", value)
+
+	// Parses a year, which can't be 0
+	parse := func(s string) (int, error) {
+		year, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, invalid
+		}
+		if year == 0 {
+			return 0, errors.New("year: can't be 0, as 1 B.C. is followed by 1 A.D.")
+		}
+		return year, nil
+	}
+
+	// Returns the year after or before a year, skipping 0
+	next := func(year, step int) int {
+		if year+step == 0 {
+			return year + 2*step
+		}
+		return year + step
+	}
+
+	switch {
+	case strings.Contains(value, ".."):
+		fromText, toText, _ := strings.Cut(value, "..")
+		from, err := parse(fromText)
+		if err != nil {
+			return 0, 0, err
+		}
+		to, err := parse(toText)
+		if err != nil {
+			return 0, 0, err
+		}
+		if from > to {
+			return 0, 0, fmt.Errorf("year: range %s starts after it ends", value)
+		}
+		return from, to, nil
+	case strings.HasPrefix(value, ">="):
+		from, err := parse(value[2:])
+		return from, 0, err
+	case strings.HasPrefix(value, "<="):
+		to, err := parse(value[2:])
+		return 0, to, err
+	case strings.HasPrefix(value, ">"):
+		from, err := parse(value[1:])
+		return next(from, 1), 0, err
+	case strings.HasPrefix(value, "<"):
+		to, err := parse(value[1:])
+		return 0, next(to, -1), err
+	}
+
+	year, err := parse(value)
+	return year, year, err
+}
+
+// A parser reads the query language one rune at a time
+type parser struct {
+	input []rune
+	pos   int
+}
+
+// Reports whether the whole input has been read
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+// Returns the next rune without reading it, or 0 at the end of the input
+func (p *parser) peek() rune {
+	if p.done() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+// Reads past any spaces
+func (p *parser) skipSpace() {
+	for !p.done() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+// Reads a word up to the next space or quote
+func (p *parser) bare() string {
+	start := p.pos
+	for !p.done() && !unicode.IsSpace(p.peek()) && p.peek() != '"' {
+		p.pos++
+	}
+	return string(p.input[start:p.pos])
+}
+
+// Reads the text between a pair of double quotes
+func (p *parser) quoted() (string, error) {
+	start := p.pos
+	p.pos++
+	for !p.done() && p.peek() != '"' {
+		p.pos++
+	}
+	if p.done() {
+		return "", fmt.Errorf("the quote starting at %s is never closed", string(p.input[start:min(start+20, len(p.input))]))
+	}
+
+	text := string(p.input[start+1 : p.pos])
+	p.pos++
+	return text, nil
+}
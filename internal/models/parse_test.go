@@ -0,0 +1,85 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests parsing the search query language
+func TestParseSearch(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    SearchQuery
+		wantErr string
+	}{
+		{
+			name:  "Words",
+			input: "  happiness  thoughts ",
+			want:  SearchQuery{Terms: "happiness thoughts"},
+		},
+		{
+			name:  "Phrases and exclusions",
+			input: `"exact phrase" -excluded -"not this" word`,
+			want:  SearchQuery{Terms: "word", Phrases: []string{"exact phrase"}, Excluded: []string{"excluded", "not this"}},
+		},
+		{
+			name:  "Every field",
+			input: `author:"Marcus Aurelius" book:Meditations year:>100 is:private tag:Stoic "exact phrase" -excluded`,
+			want:  SearchQuery{AuthorName: "Marcus Aurelius", BookTitle: "Meditations", YearFrom: 101, Scope: ScopePrivate, Tag: "stoic", Phrases: []string{"exact phrase"}, Excluded: []string{"excluded"}},
+		},
+		{
+			name:  "Field names ignore case",
+			input: "Author:seneca IS:Public",
+			want:  SearchQuery{AuthorName: "seneca", Scope: ScopePublic},
+		},
+		{
+			name:  "Unknown fields are words",
+			input: "note:this",
+			want:  SearchQuery{Terms: "note:this"},
+		},
+		{
+			name:  "A lone minus is a word",
+			input: "before - after",
+			want:  SearchQuery{Terms: "before - after"},
+		},
+		{name: "Unlisted scope", input: "is:unlisted", want: SearchQuery{Scope: ScopeUnlisted}},
+		{name: "Exact year", input: "year:1850", want: SearchQuery{YearFrom: 1850, YearTo: 1850}},
+		{name: "B.C. year", input: "year:-65", want: SearchQuery{YearFrom: -65, YearTo: -65}},
+		{name: "From a year", input: "year:>=180", want: SearchQuery{YearFrom: 180}},
+		{name: "Up to a year", input: "year:<=180", want: SearchQuery{YearTo: 180}},
+		{name: "Before a year", input: "year:<180", want: SearchQuery{YearTo: 179}},
+		{name: "After 1 B.C.", input: "year:>-1", want: SearchQuery{YearFrom: 1}},
+		{name: "Before 1 A.D.", input: "year:<1", want: SearchQuery{YearTo: -1}},
+		{name: "Year range", input: "year:-50..50", want: SearchQuery{YearFrom: -50, YearTo: 50}},
+		{name: "Unclosed phrase", input: `"to be`, wantErr: "is never closed"},
+		{name: "Unclosed field value", input: `author:"Marcus`, wantErr: "is never closed"},
+		{name: "Empty field value", input: "book: meditations", wantErr: "book: needs a value"},
+		{name: "Repeated field", input: "author:marcus author:seneca", wantErr: "author: can only be used once"},
+		{name: "Excluded field", input: "-author:marcus", wantErr: "only words and phrases can be excluded"},
+		{name: "Unknown scope", input: "is:shared", wantErr: "is: must be public, unlisted, private or mine"},
+		{name: "Invalid year", input: "year:soon", wantErr: "year: must be a year"},
+		{name: "Year 0", input: "year:0", wantErr: "year: can't be 0"},
+		{name: "Backwards year range", input: "year:200..100", wantErr: "starts after it ends"},
+		{name: "Repeated tag", input: "tag:stoic tag:death", wantErr: "tag: can only be used once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSearch(tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v; want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package postgres
+
+// Tests of the Postgres functions the Supabase backend calls through
+// PostgREST, checking they agree with this backend's own queries
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+func TestSearchQuotesFunction(t *testing.T) {
+	db := newTestDB(t)
+	m := QuoteModel{DB: db}
+	filters := models.Filters{Page: 1, PageSize: 20}
+
+	// Check the Supabase search function finds the same quotes in the same order as Search
+	for _, sq := range []models.SearchQuery{
+		{Terms: "happ marcus"},
+		{Terms: "the", AuthorID: 1, YearFrom: 100},
+		{Terms: "luck"},
+		{Terms: "luck", Scope: models.ScopeMine, UserID: testUserID},
+		{AuthorName: "seneca", UserID: testUserID},
+	} {
+		results, metadata, err := m.Search(context.Background(), sq, filters)
+		assert.NilError(t, err)
+
+		var viewer any
+		if sq.UserID != uuid.Nil {
+			viewer = sq.UserID
+		}
+		ids, total := functionPage(t, db, `SELECT id, total_records FROM search_quotes(
+			search_text => $1, excluded_text => $2, viewer => $3, search_scope => $4,
+			author_filter => $5, author_name => $6, year_from => $7)`,
+			sq.TSQuery(), sq.ExcludedTSQuery(), viewer, sq.Scope, sq.AuthorID, sq.AuthorName, sq.YearFrom)
+		assert.Equal(t, total, metadata.TotalRecords)
+		assert.Equal(t, len(ids), len(results))
+		for i, r := range results {
+			assert.Equal(t, ids[i], r.Quote.ID)
+		}
+	}
+}
+
+func TestListingFunctions(t *testing.T) {
+	db := newTestDB(t)
+	quotes := QuoteModel{DB: db}
+	books := BookModel{DB: db}
+	authors := AuthorModel{DB: db}
+	filters := models.Filters{Page: 1, PageSize: 20}
+
+	// Check the Supabase listing functions page the same rows as the backend,
+	// for a logged out viewer and for the owner of the private quote
+	for _, viewer := range []uuid.UUID{uuid.Nil, testUserID} {
+		ctx := models.WithViewer(context.Background(), viewer)
+
+		filters.Sort = models.SortAuthor
+		quotePage, metadata, err := quotes.Latest(ctx, filters)
+		assert.NilError(t, err)
+		ids, total := functionPage(t, db, `SELECT id, total_records FROM list_quotes_by_author(viewer => $1, page_limit => $2)`, viewer, filters.PageSize)
+		assert.Equal(t, total, metadata.TotalRecords)
+		assert.Equal(t, len(ids), len(quotePage))
+		for i, q := range quotePage {
+			assert.Equal(t, ids[i], q.ID)
+		}
+
+		for _, order := range []string{models.SortAuthor, models.SortMostQuoted} {
+			filters.Sort = order
+			bookPage, metadata, err := books.GetAllWithAuthors(ctx, filters)
+			assert.NilError(t, err)
+			ids, total := functionPage(t, db, `SELECT id, total_records FROM list_books(viewer => $1, sort_order => $2, page_limit => $3)`, viewer, order, filters.PageSize)
+			assert.Equal(t, total, metadata.TotalRecords)
+			assert.Equal(t, len(ids), len(bookPage))
+			for i, b := range bookPage {
+				assert.Equal(t, ids[i], b.ID)
+			}
+		}
+
+		for _, order := range models.AuthorSorts {
+			filters.Sort = order
+			authorPage, metadata, err := authors.ListWithCounts(ctx, filters)
+			assert.NilError(t, err)
+
+			rows, err := db.Query(`SELECT id, quote_count, book_count, total_records FROM list_authors(viewer => $1, sort_order => $2, page_limit => $3)`, viewer, order, filters.PageSize)
+			assert.NilError(t, err)
+			i := 0
+			for rows.Next() {
+				var id, quoteCount, bookCount, total int
+				err = rows.Scan(&id, &quoteCount, &bookCount, &total)
+				assert.NilError(t, err)
+				assert.Equal(t, total, metadata.TotalRecords)
+				assert.Equal(t, id, authorPage[i].ID)
+				assert.Equal(t, quoteCount, authorPage[i].QuoteCount)
+				assert.Equal(t, bookCount, authorPage[i].BookCount)
+				i++
+			}
+			assert.NilError(t, rows.Err())
+			rows.Close()
+			assert.Equal(t, i, len(authorPage))
+		}
+	}
+}
+
+// Runs a query over one of the Supabase paging functions and returns the IDs
+// on the page and the total, skipping the row without an ID of an empty page
+func functionPage(t *testing.T, db *sql.DB, stmt string, args ...any) ([]int, int) {
+	t.Helper()
+
+	rows, err := db.Query(stmt, args...)
+	assert.NilError(t, err)
+	defer rows.Close()
+
+	var ids []int
+	total := 0
+	for rows.Next() {
+		var id *int
+		err = rows.Scan(&id, &total)
+		assert.NilError(t, err)
+		if id != nil {
+			ids = append(ids, *id)
+		}
+	}
+	assert.NilError(t, rows.Err())
+	return ids, total
+}
+
+func TestMergeAuthorsFunction(t *testing.T) {
+	db := newTestDB(t)
+
+	// Check nothing is merged when an author doesn't exist
+	var moved int
+	err := db.QueryRow(`SELECT quotes_moved FROM merge_authors(2, 99)`).Scan(&moved)
+	assert.Equal(t, err, sql.ErrNoRows)
+
+	// Check Seneca's quote and book credit move to Marcus Aurelius and Seneca is deleted
+	err = db.QueryRow(`SELECT quotes_moved FROM merge_authors(2, 1)`).Scan(&moved)
+	assert.NilError(t, err)
+	assert.Equal(t, moved, 1)
+
+	var authors, credits int
+	err = db.QueryRow(`SELECT count(*) FROM authors WHERE id = 2`).Scan(&authors)
+	assert.NilError(t, err)
+	assert.Equal(t, authors, 0)
+	err = db.QueryRow(`SELECT count(*) FROM book_contributors WHERE book_id = 2 AND author_id = 1`).Scan(&credits)
+	assert.NilError(t, err)
+	assert.Equal(t, credits, 1)
+}
@@ -0,0 +1,96 @@
+// Package postgres implements the model interfaces directly on top of a
+// PostgreSQL database using database/sql, without going through PostgREST.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+// Check that every model satisfies its interface
+var (
+	_ models.QuoteModelInterface  = (*QuoteModel)(nil)
+	_ models.AuthorModelInterface = (*AuthorModel)(nil)
+	_ models.BookModelInterface   = (*BookModel)(nil)
+	_ models.UserModelInterface   = (*UserModel)(nil)
+)
+
+// Defines the methods shared by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// Returns the condition that a quote, under the given table alias, can be seen
+// by the viewer bound to the numbered placeholder: it is public, or it is one
+// of the viewer's own quotes
+func visibleTo(alias string, n int) string {
+	return fmt.Sprintf(`(%[1]s.visibility = 'public' OR %[1]s.user_id = $%[2]d)`, alias, n)
+}
+
+// Returns a context bounded by the given per-operation deadline
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// Maps driver and context errors to the matching model errors
+func mapError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sql.ErrNoRows):
+		return models.ErrNoRecord
+	case errors.Is(err, context.DeadlineExceeded):
+		return models.ErrQueryTimeout
+	case errors.Is(err, context.Canceled):
+		return models.ErrQueryCanceled
+	default:
+		return err
+	}
+}
+
+// Runs fn inside a transaction, committing on success and rolling back on error
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return mapError(err)
+	}
+
+	// Roll back is a no-op once the transaction has been committed
+	defer tx.Rollback()
+
+	err = fn(tx)
+	if err != nil {
+		return mapError(err)
+	}
+
+	return mapError(tx.Commit())
+}
+
+// Returns the time or the zero time for a nullable timestamp column
+func nullTime(t sql.NullTime) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+	return t.Time
+}
+
+// Returns the metadata for a page of a listing given the total counted alongside its rows.
+// A page past the end has no rows to carry that count, so the listing is counted separately.
+func pageMetadata(ctx context.Context, db *sql.DB, filters models.Filters, total int, countStmt string, args ...any) (models.Metadata, error) {
+	if total == 0 && filters.Page > 1 {
+		err := db.QueryRowContext(ctx, countStmt, args...).Scan(&total)
+		if err != nil {
+			return models.Metadata{}, mapError(err)
+		}
+	}
+
+	return models.CalculateMetadata(total, filters.Page, filters.PageSize), nil
+}
@@ -0,0 +1,486 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+// The quote columns selected by every quote query
+const quoteColumns = `q.id, q.quote, COALESCE(q.author_id, 0), COALESCE(q.book_id, 0), COALESCE(q.edition_id, 0), q.user_id,
+	COALESCE(q.location_type, ''), COALESCE(q.location_start, 0), COALESCE(q.location_end, 0), COALESCE(q.location_label, ''),
+	q.visibility, COALESCE(q.share_token, ''), q.created_at, q.updated_at`
+
+// The author and book columns selected alongside a quote
+const quoteRelationColumns = `COALESCE(a.id, 0), COALESCE(a.name, ''), a.user_id,
+	COALESCE(b.id, 0), COALESCE(b.title, ''), COALESCE(b.publish_year, 0), COALESCE(b.calendar_time, ''),
+	COALESCE(b.isbn, ''), COALESCE(b.source, ''), b.user_id, b.created_at, b.updated_at,
+	COALESCE(b.media_type, ''), COALESCE(b.doi, ''), COALESCE(b.venue, ''), COALESCE(b.work_date, ''), COALESCE(b.page_count, 0)`
+
+// The joins used to load a quote together with its author and book
+const quoteRelationJoins = `LEFT JOIN authors a ON a.id = q.author_id
+	LEFT JOIN books b ON b.id = q.book_id`
+
+// The ORDER BY clause for each quote sort order, with quotes missing an author last
+var quoteOrders = map[string]string{
+	models.SortNewest: `q.created_at DESC, q.id DESC`,
+	models.SortOldest: `q.created_at, q.id`,
+	models.SortAuthor: `a.name NULLS LAST, q.id`,
+}
+
+// QuoteModel implements models.QuoteModelInterface on a PostgreSQL database
+type QuoteModel struct {
+	DB       *sql.DB
+	Timeouts models.Timeouts
+}
+
+// Scans a row selected with quoteColumns into a quote
+func scanQuote(row scanner, extra ...any) (models.Quote, error) {
+	var q models.Quote
+	var userID uuid.NullUUID
+
+	dest := []any{&q.ID, &q.Quote, &q.AuthorID, &q.BookID, &q.EditionID, &userID,
+		&q.Location.Type, &q.Location.Start, &q.Location.End, &q.Location.Label, &q.Visibility, &q.ShareToken, &q.CreatedAt, &q.UpdatedAt}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	q.UserID = userID.UUID
+	return q, nil
+}
+
+// Scans a row selected with quoteColumns and quoteRelationColumns into a quote with its author and book
+func scanQuoteWithRelations(row scanner, extra ...any) (models.Quote, error) {
+	var a models.Author
+	var b models.Book
+	var authorUserID, bookUserID uuid.NullUUID
+	var bookCreatedAt, bookUpdatedAt sql.NullTime
+
+	relations := []any{&a.ID, &a.Name, &authorUserID,
+		&b.ID, &b.Title, &b.PublishYear, &b.CalendarTime, &b.ISBN, &b.Source, &bookUserID, &bookCreatedAt, &bookUpdatedAt,
+		&b.MediaType, &b.DOI, &b.Venue, &b.Date, &b.PageCount}
+	q, err := scanQuote(row, append(relations, extra...)...)
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	// The author and book are left joined, so they may be missing
+	a.UserID = authorUserID.UUID
+	b.UserID = bookUserID.UUID
+	b.CreatedAt = nullTime(bookCreatedAt)
+	b.UpdatedAt = nullTime(bookUpdatedAt)
+	q.Author = a
+	q.Book = b
+	return q, nil
+}
+
+// Runs a quote query and scans every row
+func (m *QuoteModel) queryQuotes(ctx context.Context, stmt string, args ...any) ([]models.Quote, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	quotes := []models.Quote{}
+	for rows.Next() {
+		q, err := scanQuote(rows)
+		if err != nil {
+			return nil, mapError(err)
+		}
+		quotes = append(quotes, q)
+	}
+
+	return quotes, mapError(rows.Err())
+}
+
+// Insert a new quote and record when the user last added a quote
+func (m *QuoteModel) Insert(ctx context.Context, quote string, authorID int, bookID int, editionID int, location models.Location, visibility models.Visibility, userID uuid.UUID) (int, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	var id int
+	err := inTx(ctx, m.DB, func(tx *sql.Tx) error {
+		now := time.Now()
+
+		// Insert the quote
+		stmt := `INSERT INTO quotes (quote, author_id, book_id, edition_id, location_type, location_start, location_end, location_label,
+			visibility, user_id, created_at, updated_at)
+			VALUES ($1, $2, $3, NULLIF($4, 0), NULLIF($5, ''), NULLIF($6, 0), NULLIF($7, 0), NULLIF($8, ''), $9, $10, $11, $11) RETURNING id`
+		err := tx.QueryRowContext(ctx, stmt, quote, authorID, bookID, editionID,
+			location.Type, location.Start, location.End, location.Label, visibility, userID, now).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		// Update the user's last quote added at timestamp in the same transaction
+		_, err = tx.ExecContext(ctx, `UPDATE users SET last_quote_added_at = $1 WHERE id = $2`, now, userID)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Return a specific quote based on the ID
+func (m *QuoteModel) Get(ctx context.Context, id int) (models.Quote, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	stmt := `SELECT ` + quoteColumns + ` FROM quotes q WHERE q.id = $1 AND ` + visibleTo("q", 2)
+
+	q, err := scanQuote(m.DB.QueryRowContext(ctx, stmt, id, models.Viewer(ctx)))
+	if err != nil {
+		return models.Quote{}, mapError(err)
+	}
+
+	return q, nil
+}
+
+// Return a list of quotes by author ID
+func (m *QuoteModel) GetByAuthorID(ctx context.Context, authorID int) ([]models.Quote, error) {
+	stmt := `SELECT ` + quoteColumns + ` FROM quotes q WHERE q.author_id = $1 AND ` + visibleTo("q", 2) + ` ORDER BY q.id`
+	return m.queryQuotes(ctx, stmt, authorID, models.Viewer(ctx))
+}
+
+// Return a page of quotes by user ID
+func (m *QuoteModel) GetByUserID(ctx context.Context, userID uuid.UUID, filters models.Filters) ([]models.Quote, models.Metadata, error) {
+	return m.listQuotes(ctx, filters, `q.user_id = $1`, userID)
+}
+
+// Return all quotes for a given book ID
+func (m *QuoteModel) GetByBookID(ctx context.Context, bookID int) ([]models.Quote, error) {
+	stmt := `SELECT ` + quoteColumns + ` FROM quotes q WHERE q.book_id = $1 AND ` + visibleTo("q", 2) + ` ORDER BY q.id`
+	return m.queryQuotes(ctx, stmt, bookID, models.Viewer(ctx))
+}
+
+// Return a quote with the author and book in a single query
+func (m *QuoteModel) GetWithAuthorAndBook(ctx context.Context, id int) (models.Quote, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	stmt := `SELECT ` + quoteColumns + `, ` + quoteRelationColumns + `
+		FROM quotes q ` + quoteRelationJoins + `
+		WHERE q.id = $1 AND ` + visibleTo("q", 2)
+
+	q, err := scanQuoteWithRelations(m.DB.QueryRowContext(ctx, stmt, id, models.Viewer(ctx)))
+	if err != nil {
+		return models.Quote{}, mapError(err)
+	}
+
+	return q, nil
+}
+
+// Return a page of quotes with their authors and books, newest first unless another order is asked for
+func (m *QuoteModel) Latest(ctx context.Context, filters models.Filters) ([]models.Quote, models.Metadata, error) {
+	return m.listQuotes(ctx, filters, `true`)
+}
+
+// Runs a page of a quote listing restricted by the WHERE condition, loading each quote's author and book
+func (m *QuoteModel) listQuotes(ctx context.Context, filters models.Filters, where string, args ...any) ([]models.Quote, models.Metadata, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	order, ok := quoteOrders[filters.Sort]
+	if !ok {
+		order = quoteOrders[models.SortNewest]
+	}
+
+	// Only list the quotes the viewer can see
+	args = append(args, models.Viewer(ctx))
+	where = fmt.Sprintf(`(%s) AND %s`, where, visibleTo("q", len(args)))
+
+	stmt := fmt.Sprintf(`SELECT %s, %s, count(*) OVER()
+		FROM quotes q %s
+		WHERE %s
+		ORDER BY %s LIMIT $%d OFFSET $%d`,
+		quoteColumns, quoteRelationColumns, quoteRelationJoins, where, order, len(args)+1, len(args)+2)
+
+	rows, err := m.DB.QueryContext(ctx, stmt, append(args, filters.Limit(), filters.Offset())...)
+	if err != nil {
+		return nil, models.Metadata{}, mapError(err)
+	}
+	defer rows.Close()
+
+	total := 0
+	quotes := []models.Quote{}
+	for rows.Next() {
+		q, err := scanQuoteWithRelations(rows, &total)
+		if err != nil {
+			return nil, models.Metadata{}, mapError(err)
+		}
+		quotes = append(quotes, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.Metadata{}, mapError(err)
+	}
+
+	metadata, err := pageMetadata(ctx, m.DB, filters, total, `SELECT count(*) FROM quotes q WHERE `+where, args...)
+	return quotes, metadata, err
+}
+
+// The ORDER BY clause for each search sort order
+var searchOrders = map[string]string{
+	models.SortRelevance: `search_rank DESC, q.created_at DESC, q.id DESC`,
+	models.SortNewest:    `q.created_at DESC, q.id DESC`,
+	models.SortOldest:    `q.created_at, q.id`,
+}
+
+// The document searched for each quote, weighting the quote above its author's
+// name and book's title. The simple configuration leaves words unstemmed, so
+// searches match the same words as on the other storage backends.
+const searchDocument = `setweight(to_tsvector('simple', q.quote), 'A') ||
+	setweight(to_tsvector('simple', COALESCE(a.name, '')), 'B') ||
+	setweight(to_tsvector('simple', COALESCE(b.title, '')), 'B')`
+
+// Return a page of the quotes matching the search terms and filters, most relevant first unless another order is asked for
+func (m *QuoteModel) Search(ctx context.Context, sq models.SearchQuery, filters models.Filters) ([]models.SearchResult, models.Metadata, error) {
+	// Nothing matches an empty search
+	if sq.IsEmpty() {
+		return []models.SearchResult{}, models.Metadata{}, nil
+	}
+
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	order, ok := searchOrders[filters.Sort]
+	if !ok {
+		order = searchOrders[models.SortRelevance]
+	}
+
+	// Match the document against the words and phrases when there are any
+	var args []any
+	match := ``
+	rank := `0`
+	if sq.HasText() {
+		args = append(args, sq.TSQuery())
+		match = `CROSS JOIN to_tsquery('simple', $1) search_query`
+		rank = `ts_rank(d.document, search_query)`
+	}
+
+	conditions, conditionArgs := searchConditions(sq, len(args)+1)
+	args = append(args, conditionArgs...)
+	if match != "" {
+		conditions = `d.document @@ search_query AND ` + conditions
+	}
+
+	from := fmt.Sprintf(`FROM quotes q %s
+		%s
+		CROSS JOIN LATERAL (SELECT %s AS document) d
+		WHERE %s`, quoteRelationJoins, match, searchDocument, conditions)
+
+	stmt := fmt.Sprintf(`SELECT %s, %s, %s AS search_rank, count(*) OVER() %s
+		ORDER BY %s LIMIT $%d OFFSET $%d`,
+		quoteColumns, quoteRelationColumns, rank, from, order, len(args)+1, len(args)+2)
+
+	rows, err := m.DB.QueryContext(ctx, stmt, append(args, filters.Limit(), filters.Offset())...)
+	if err != nil {
+		return nil, models.Metadata{}, mapError(err)
+	}
+	defer rows.Close()
+
+	total := 0
+	highlights := sq.Highlights()
+	results := []models.SearchResult{}
+	for rows.Next() {
+		var rank float64
+		q, err := scanQuoteWithRelations(rows, &rank, &total)
+		if err != nil {
+			return nil, models.Metadata{}, mapError(err)
+		}
+		results = append(results, models.NewSearchResult(q, rank, highlights))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.Metadata{}, mapError(err)
+	}
+
+	metadata, err := pageMetadata(ctx, m.DB, filters, total, `SELECT count(*) `+from, args...)
+	return results, metadata, err
+}
+
+// Returns the WHERE conditions for a search's filters, exclusions and scope,
+// with their arguments numbered from the given placeholder
+func searchConditions(sq models.SearchQuery, next int) (string, []any) {
+	conditions := []string{`TRUE`}
+	var args []any
+	add := func(condition string, arg any) {
+		conditions = append(conditions, fmt.Sprintf(condition, next+len(args)))
+		args = append(args, arg)
+	}
+
+	if sq.AuthorID != 0 {
+		add(`q.author_id = $%d`, sq.AuthorID)
+	}
+	if sq.BookID != 0 {
+		add(`q.book_id = $%d`, sq.BookID)
+	}
+	if sq.AuthorName != "" {
+		// The author's name or any of their aliases
+		add(`(a.name ILIKE $%[1]d ESCAPE '\' OR EXISTS (SELECT true FROM author_aliases aa WHERE aa.author_id = a.id AND aa.name ILIKE $%[1]d ESCAPE '\'))`, containsPattern(sq.AuthorName))
+	}
+	if sq.BookTitle != "" {
+		add(`b.title ILIKE $%d ESCAPE '\'`, containsPattern(sq.BookTitle))
+	}
+	if sq.Tag != "" {
+		add(`EXISTS (SELECT true FROM quote_tags t WHERE t.quote_id = q.id AND t.tag = $%d)`, sq.Tag)
+	}
+
+	// Compare publish years with B.C. years negative
+	if sq.YearFrom != 0 {
+		add(`CASE WHEN b.calendar_time = 'B.C.' THEN -b.publish_year ELSE b.publish_year END >= $%d`, sq.YearFrom)
+	}
+	if sq.YearTo != 0 {
+		add(`CASE WHEN b.calendar_time = 'B.C.' THEN -b.publish_year ELSE b.publish_year END <= $%d`, sq.YearTo)
+	}
+
+	// Leave out the quotes matching any excluded word or phrase
+	if excluded := sq.ExcludedTSQuery(); excluded != "" {
+		add(`NOT (d.document @@ to_tsquery('simple', $%d))`, excluded)
+	}
+
+	// Unlisted and private quotes are only ever found by the user who added them
+	switch sq.Scope {
+	case models.ScopeMine:
+		add(`q.user_id = $%d`, sq.UserID)
+	case models.ScopeUnlisted, models.ScopePrivate:
+		add(`q.user_id = $%d`, sq.UserID)
+		add(`q.visibility = $%d`, sq.Scope)
+	case models.ScopePublic:
+		conditions = append(conditions, `q.visibility = 'public'`)
+	default:
+		add(`(q.visibility = 'public' OR q.user_id = $%d)`, sq.UserID)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// Returns a LIKE pattern matching text that contains the value, escaping the value's wildcards
+func containsPattern(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+	return "%" + escaped + "%"
+}
+
+// Return the quote with the given share token, with its author and book, to
+// anyone who has the token. A revoked token, or a private quote, isn't found.
+func (m *QuoteModel) GetByShareToken(ctx context.Context, token string) (models.Quote, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	stmt := `SELECT ` + quoteColumns + `, ` + quoteRelationColumns + `
+		FROM quotes q ` + quoteRelationJoins + `
+		WHERE q.share_token = $1 AND q.visibility <> 'private'`
+
+	q, err := scanQuoteWithRelations(m.DB.QueryRowContext(ctx, stmt, token))
+	if err != nil {
+		return models.Quote{}, mapError(err)
+	}
+
+	return q, nil
+}
+
+// Set the token of a quote's share link, replacing any earlier token so its
+// link stops working. An empty token revokes the share link.
+func (m *QuoteModel) SetShareToken(ctx context.Context, id int, token string) error {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	// Store a revoked token as null, so it never matches a share link
+	stmt := `UPDATE quotes SET share_token = NULLIF($1, ''), updated_at = $2 WHERE id = $3`
+
+	_, err := m.DB.ExecContext(ctx, stmt, token, time.Now(), id)
+	return mapError(err)
+}
+
+// Update a quote on behalf of the given user
+func (m *QuoteModel) Update(ctx context.Context, id int, quote string, authorID int, bookID int, editionID int, location models.Location, visibility models.Visibility, userID uuid.UUID) (int, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	stmt := `UPDATE quotes SET quote = $1, author_id = $2, book_id = $3, edition_id = NULLIF($4, 0), location_type = NULLIF($5, ''),
+		location_start = NULLIF($6, 0), location_end = NULLIF($7, 0), location_label = NULLIF($8, ''), visibility = $9,
+		user_id = $10, updated_at = $11 WHERE id = $12 RETURNING id`
+
+	var updatedID int
+	err := m.DB.QueryRowContext(ctx, stmt, quote, authorID, bookID, editionID,
+		location.Type, location.Start, location.End, location.Label, visibility, userID, time.Now(), id).Scan(&updatedID)
+	if err != nil {
+		return 0, mapError(err)
+	}
+
+	return updatedID, nil
+}
+
+// Check if the quote exists
+func (m *QuoteModel) Exists(ctx context.Context, id int) (bool, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	var exists bool
+	stmt := `SELECT EXISTS(SELECT true FROM quotes q WHERE q.id = $1 AND ` + visibleTo("q", 2) + `)`
+	err := m.DB.QueryRowContext(ctx, stmt, id, models.Viewer(ctx)).Scan(&exists)
+	return exists, mapError(err)
+}
+
+// Delete a quote
+func (m *QuoteModel) Delete(ctx context.Context, id int) error {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, `DELETE FROM quotes WHERE id = $1`, id)
+	return mapError(err)
+}
+
+// Return a quote's tags in alphabetical order
+func (m *QuoteModel) GetTags(ctx context.Context, id int) ([]string, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, `SELECT tag FROM quote_tags WHERE quote_id = $1 ORDER BY tag`, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, mapError(err)
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, mapError(rows.Err())
+}
+
+// Replace a quote's tags with the given lowercase tags in one transaction
+func (m *QuoteModel) SetTags(ctx context.Context, id int, tags []string) error {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	return inTx(ctx, m.DB, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `DELETE FROM quote_tags WHERE quote_id = $1`, id)
+		if err != nil {
+			return err
+		}
+
+		for _, tag := range tags {
+			_, err = tx.ExecContext(ctx, `INSERT INTO quote_tags (quote_id, tag) VALUES ($1, $2)`, id, tag)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
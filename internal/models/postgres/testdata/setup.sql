@@ -0,0 +1,27 @@
+INSERT INTO users (id, name, email, hashed_password, profile_slug, created_at, updated_at) VALUES (
+    'a3a6e2b6-7e64-4c3e-9a3b-7d0a3f7f9d11',
+    'Alice Jones',
+    'alice@example.com',
+    '$2a$12$WOJEhKYwBc7PYH.wHsk/4erklqktOxYJXkA.E1yhABXMbVyNvg6nC',
+    'alice-jones',
+    '2024-01-01 10:00:00+00',
+    '2024-01-01 10:00:00+00'
+);
+
+INSERT INTO authors (name, user_id) VALUES
+    ('Marcus Aurelius', 'a3a6e2b6-7e64-4c3e-9a3b-7d0a3f7f9d11'),
+    ('Seneca', 'a3a6e2b6-7e64-4c3e-9a3b-7d0a3f7f9d11');
+
+INSERT INTO books (title, publish_year, calendar_time, isbn, source, user_id) VALUES
+    ('Meditations', 180, 'A.D.', '9780140449334', '', 'a3a6e2b6-7e64-4c3e-9a3b-7d0a3f7f9d11'),
+    ('Letters from a Stoic', 65, 'A.D.', '9780140442106', '', 'a3a6e2b6-7e64-4c3e-9a3b-7d0a3f7f9d11'),
+    ('An Unquoted Book', 2000, 'A.D.', '9780000000002', '', 'a3a6e2b6-7e64-4c3e-9a3b-7d0a3f7f9d11');
+
+INSERT INTO quotes (quote, author_id, book_id, user_id, location_type, location_start, visibility, created_at, updated_at) VALUES
+    ('The happiness of your life depends upon the quality of your thoughts.', 1, 1, 'a3a6e2b6-7e64-4c3e-9a3b-7d0a3f7f9d11', 'page', 12, 'public', '2024-01-02 10:00:00+00', '2024-01-02 10:00:00+00'),
+    ('Waste no more time arguing about what a good man should be. Be one.', 1, 1, 'a3a6e2b6-7e64-4c3e-9a3b-7d0a3f7f9d11', 'page', 140, 'public', '2024-01-03 10:00:00+00', '2024-01-03 10:00:00+00'),
+    ('Luck is what happens when preparation meets opportunity.', 2, 2, 'a3a6e2b6-7e64-4c3e-9a3b-7d0a3f7f9d11', 'page', 33, 'private', '2024-01-04 10:00:00+00', '2024-01-04 10:00:00+00');
+
+INSERT INTO book_contributors (book_id, author_id, role, position) VALUES
+    (1, 1, 'author', 0),
+    (2, 2, 'author', 0);
@@ -0,0 +1,16 @@
+DROP FUNCTION IF EXISTS merge_authors;
+DROP FUNCTION IF EXISTS search_quotes;
+DROP FUNCTION IF EXISTS list_authors;
+DROP FUNCTION IF EXISTS list_books;
+DROP FUNCTION IF EXISTS list_quotes_by_author;
+DROP TABLE sessions;
+DROP TABLE quote_tags;
+DROP TABLE book_contributors;
+DROP TABLE author_aliases;
+DROP TABLE quotes;
+DROP TABLE editions;
+DROP TABLE book_isbn_conflicts;
+DROP TABLE books;
+DROP TABLE authors;
+DROP TABLE users;
+DROP TABLE schema_migrations;
@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/justinbachtell/quote-table-go/internal/migrate"
+	"github.com/justinbachtell/quote-table-go/migrations"
+)
+
+// Opens a connection to the test database, migrates it and loads the test data.
+//
+// The tests are skipped unless TEST_DATABASE_URL points at an empty database
+// the tests are allowed to create and drop tables in.
+func newTestDB(t *testing.T) *sql.DB {
+	// Skip the test if the "-short" flag is passed
+	if testing.Short() {
+		t.Skip("postgres: skipping integration tests in short mode")
+	}
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("postgres: TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create the tables with the embedded migrations
+	files, err := fs.Sub(migrations.Files, "postgres")
+	if err != nil {
+		db.Close()
+		t.Fatal(err)
+	}
+	migrator, err := migrate.New(db, files)
+	if err != nil {
+		db.Close()
+		t.Fatal(err)
+	}
+	_, err = migrator.Up(context.Background())
+	if err != nil {
+		db.Close()
+		t.Fatal(err)
+	}
+
+	// Insert the test data
+	script, err := os.ReadFile("./testdata/setup.sql")
+	if err != nil {
+		db.Close()
+		t.Fatal(err)
+	}
+	_, err = db.Exec(string(script))
+	if err != nil {
+		db.Close()
+		t.Fatal(err)
+	}
+
+	// Drop the tables and close the connection after the test
+	t.Cleanup(func() {
+		defer db.Close()
+
+		script, err := os.ReadFile("./testdata/teardown.sql")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = db.Exec(string(script))
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	return db
+}
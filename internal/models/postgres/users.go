@@ -0,0 +1,242 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+	"github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// The user columns selected by every user query
+const userColumns = `id, name, email, email_verified_at, profile_slug, COALESCE(phone, ''), phone_verified_at,
+	role, created_at, updated_at, last_signed_in_at`
+
+// UserModel implements models.UserModelInterface on a PostgreSQL database
+type UserModel struct {
+	DB       *sql.DB
+	Timeouts models.Timeouts
+}
+
+// Scans a row selected with userColumns into a user
+func scanUser(row scanner) (models.User, error) {
+	var u models.User
+	var emailVerifiedAt, phoneVerifiedAt, lastLoginAt sql.NullTime
+
+	err := row.Scan(&u.ID, &u.Name, &u.Email, &emailVerifiedAt, &u.ProfileSlug, &u.Phone, &phoneVerifiedAt,
+		&u.Role, &u.CreatedAt, &u.UpdatedAt, &lastLoginAt)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	u.EmailVerifiedAt = nullTime(emailVerifiedAt)
+	u.PhoneVerifiedAt = nullTime(phoneVerifiedAt)
+	u.LastLoginAt = nullTime(lastLoginAt)
+	return u, nil
+}
+
+// Returns the profile slug for a user's name
+func profileSlug(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "-")
+}
+
+// Insert adds a new user to the database
+func (m *UserModel) Insert(ctx context.Context, name, email, password string) (uuid.UUID, error) {
+	// Hash the password with the number of specified salt rounds
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	stmt := `INSERT INTO users (id, name, email, hashed_password, profile_slug, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6) RETURNING id`
+
+	var id uuid.UUID
+	err = m.DB.QueryRowContext(ctx, stmt, uuid.New(), name, email, string(hashedPassword), profileSlug(name), time.Now()).Scan(&id)
+	if err != nil {
+		// Check if the error is due to a duplicate email
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" && strings.Contains(pqErr.Constraint, "users_uc_email") {
+			return uuid.Nil, models.ErrDuplicateEmail
+		}
+		return uuid.Nil, mapError(err)
+	}
+
+	return id, nil
+}
+
+// Authenticate verifies the user's email and password
+func (m *UserModel) Authenticate(ctx context.Context, email string, password string) (uuid.UUID, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	var id uuid.UUID
+	var hashedPassword []byte
+	err := m.DB.QueryRowContext(ctx, `SELECT id, hashed_password FROM users WHERE email = $1`, email).Scan(&id, &hashedPassword)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, models.ErrInvalidCredentials
+		}
+		return uuid.Nil, mapError(err)
+	}
+
+	// Compare the provided password with the stored hash
+	err = bcrypt.CompareHashAndPassword(hashedPassword, []byte(password))
+	if err != nil {
+		return uuid.Nil, models.ErrInvalidCredentials
+	}
+
+	return id, nil
+}
+
+// Update the user's last signed in at timestamp
+func (m *UserModel) UpdateLastSignedInAt(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, `UPDATE users SET last_signed_in_at = $1 WHERE id = $2`, time.Now(), id)
+	return mapError(err)
+}
+
+// Check if the user exists
+func (m *UserModel) Exists(ctx context.Context, id uuid.UUID) (bool, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	var exists bool
+	err := m.DB.QueryRowContext(ctx, `SELECT EXISTS(SELECT true FROM users WHERE id = $1)`, id).Scan(&exists)
+	return exists, mapError(err)
+}
+
+// Update the user's info, clearing the phone verification when the phone changes
+func (m *UserModel) Update(ctx context.Context, id uuid.UUID, name, email, phone string) error {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	stmt := `UPDATE users SET name = $1, email = $2, profile_slug = $3, phone = $4, updated_at = $5,
+		phone_verified_at = CASE WHEN COALESCE(phone, '') = $4 THEN phone_verified_at ELSE NULL END
+		WHERE id = $6`
+
+	result, err := m.DB.ExecContext(ctx, stmt, name, email, profileSlug(name), phone, time.Now(), id)
+	if err != nil {
+		return mapError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return models.ErrNoRecord
+	}
+
+	return nil
+}
+
+// ChangePassword verifies the current password and stores the new one in a single transaction
+func (m *UserModel) ChangePassword(ctx context.Context, id uuid.UUID, currentPassword, newPassword string) error {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	return inTx(ctx, m.DB, func(tx *sql.Tx) error {
+		// Lock the user's row while the password is being changed
+		var hashedPassword []byte
+		err := tx.QueryRowContext(ctx, `SELECT hashed_password FROM users WHERE id = $1 FOR UPDATE`, id).Scan(&hashedPassword)
+		if err != nil {
+			return err
+		}
+
+		// Verify the current password
+		err = bcrypt.CompareHashAndPassword(hashedPassword, []byte(currentPassword))
+		if err != nil {
+			return models.ErrInvalidCredentials
+		}
+
+		// Hash the new password
+		newHashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), 12)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE users SET hashed_password = $1, updated_at = $2 WHERE id = $3`,
+			string(newHashedPassword), time.Now(), id)
+		return err
+	})
+}
+
+// Get user by id
+func (m *UserModel) Get(ctx context.Context, id uuid.UUID) (models.User, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	u, err := scanUser(m.DB.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id))
+	if err != nil {
+		return models.User{}, mapError(err)
+	}
+
+	return u, nil
+}
+
+// Get user by email
+func (m *UserModel) GetByEmail(ctx context.Context, email string) (models.User, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	u, err := scanUser(m.DB.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE email = $1`, email))
+	if err != nil {
+		return models.User{}, mapError(err)
+	}
+
+	return u, nil
+}
+
+// Get user by URL name
+func (m *UserModel) GetByURLName(ctx context.Context, urlName string) (models.User, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	u, err := scanUser(m.DB.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE profile_slug = $1 ORDER BY created_at LIMIT 1`, urlName))
+	if err != nil {
+		return models.User{}, mapError(err)
+	}
+
+	return u, nil
+}
+
+// SetRole changes the role of a user
+func (m *UserModel) SetRole(ctx context.Context, id uuid.UUID, role models.Role) error {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, `UPDATE users SET role = $1, updated_at = $2 WHERE id = $3`, role, time.Now().UTC(), id)
+	if err != nil {
+		return mapError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return models.ErrNoRecord
+	}
+
+	return nil
+}
+
+// Update last quote added at timestamp
+func (m *UserModel) UpdateLastQuoteAddedAt(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, `UPDATE users SET last_quote_added_at = $1 WHERE id = $2`, time.Now(), id)
+	return mapError(err)
+}
@@ -0,0 +1,184 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/supabase-community/postgrest-go"
+)
+
+// Timeouts holds the per-operation deadlines applied to model queries. A zero
+// duration leaves the operation bounded only by the caller's context.
+//
+// The Supabase models apply them in the transport of the client made by
+// NewClient, which cancels a request once it passes its deadline.
+type Timeouts struct {
+	Read  time.Duration
+	Write time.Duration
+}
+
+// DefaultTimeouts are the deadlines used when a model is created without explicit timeouts
+var DefaultTimeouts = Timeouts{
+	Read:  3 * time.Second,
+	Write: 5 * time.Second,
+}
+
+// The Postgres functions that only read, whose calls get the read deadline
+var readFunctions = map[string]bool{
+	"search_quotes":         true,
+	"list_quotes_by_author": true,
+	"list_books":            true,
+	"list_authors":          true,
+}
+
+// NewClient connects to the PostgREST api of a Supabase project with the given
+// key. Its requests are cancelled once they pass their deadline: Timeouts.Read
+// for selects and read-only functions, and Timeouts.Write for everything else.
+func NewClient(supabaseURL, key string, timeouts Timeouts) (*postgrest.Client, error) {
+	if supabaseURL == "" || key == "" {
+		return nil, errors.New("models: supabase url and key are required")
+	}
+
+	client := postgrest.NewClient(supabaseURL+"/rest/v1", "public", map[string]string{
+		"Authorization": "Bearer " + key,
+		"apikey":        key,
+	})
+	if client.ClientError != nil {
+		return nil, client.ClientError
+	}
+
+	client.Transport.Parent = &deadlineTransport{base: http.DefaultTransport, timeouts: timeouts}
+	return client, nil
+}
+
+// A transport that gives each request the deadline for its kind of operation.
+// The PostgREST client does not accept a context, so this is what stops a
+// request that has run too long.
+type deadlineTransport struct {
+	base     http.RoundTripper
+	timeouts Timeouts
+}
+
+func (t *deadlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	timeout := t.timeouts.Write
+	if isRead(req) {
+		timeout = t.timeouts.Read
+	}
+	if timeout <= 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	// The deadline covers reading the body too, so it is only released once the body is closed
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// Reports whether a request only reads: a select, or a call to a read-only function
+func isRead(req *http.Request) bool {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return true
+	}
+	dir, name := path.Split(req.URL.Path)
+	return strings.HasSuffix(dir, "/rpc/") && readFunctions[name]
+}
+
+// A response body that releases its request's deadline when it is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// Runs a read under the given deadline and returns as soon as the context is done.
+//
+// The PostgREST client does not accept a context, so a query that is abandoned
+// here, because the caller has gone away, keeps running until the client's
+// transport cancels it at the read deadline, and its result is discarded.
+// Writes go through write or exec instead.
+func query[T any](ctx context.Context, timeout time.Duration, fn func() (T, error)) (T, error) {
+	var zero T
+
+	// Don't start a query for a request that has already gone away
+	if err := ctx.Err(); err != nil {
+		return zero, contextError(err)
+	}
+
+	// Apply the per-operation deadline
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// Run the query in its own goroutine so the caller can stop waiting on it
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn()
+		done <- result{value, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.value, transportError(res.err)
+	case <-ctx.Done():
+		return zero, contextError(ctx.Err())
+	}
+}
+
+// Runs a write, first checking the request hasn't already gone away.
+//
+// Once the write has been sent it is waited on and its own result returned,
+// until the client's transport cancels it at the write deadline. Abandoning it
+// sooner would report a failure for a write that may still be applied.
+func write[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	if err := ctx.Err(); err != nil {
+		var zero T
+		return zero, contextError(err)
+	}
+	value, err := fn()
+	return value, transportError(err)
+}
+
+// Runs a write that only returns an error, the same way as write
+func exec(ctx context.Context, fn func() error) error {
+	_, err := write(ctx, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+// Maps a request cancelled by the client's transport at its deadline to ErrQueryTimeout
+func transportError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrQueryTimeout
+	}
+	return err
+}
+
+// Maps a context error to the matching model error
+func contextError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrQueryTimeout
+	}
+	return ErrQueryCanceled
+}
@@ -0,0 +1,154 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/supabase-community/postgrest-go"
+)
+
+func TestQuery(t *testing.T) {
+	// Set up a tests struct
+	tests := []struct {
+		name    string
+		ctx     func() (context.Context, context.CancelFunc)
+		timeout time.Duration
+		delay   time.Duration
+		wantErr error
+	}{
+		{"Completes in time", func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) }, time.Second, 0, nil},
+		{"Exceeds deadline", func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) }, 10 * time.Millisecond, 200 * time.Millisecond, ErrQueryTimeout},
+		{"Canceled before start", func() (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx, cancel
+		}, time.Second, 0, ErrQueryCanceled},
+	}
+
+	// Loop through each test
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			// Run a query that sleeps for the configured delay
+			got, err := query(ctx, tt.timeout, func() (int, error) {
+				time.Sleep(tt.delay)
+				return 42, nil
+			})
+
+			// Check the error matches the expected model error
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+
+			// Check the value is only returned when the query completed
+			if tt.wantErr == nil && got != 42 {
+				t.Errorf("got %d, want 42", got)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	// Check a write isn't started for a request that has already gone away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	started := false
+	_, err := write(ctx, func() (int, error) {
+		started = true
+		return 42, nil
+	})
+	if !errors.Is(err, ErrQueryCanceled) {
+		t.Fatalf("got error %v, want %v", err, ErrQueryCanceled)
+	}
+	if started {
+		t.Error("write was started after the request was canceled")
+	}
+
+	// Check a write that outlives the request's deadline is waited on, and its
+	// result returned rather than a timeout
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	got, err := write(ctx, func() (int, error) {
+		time.Sleep(100 * time.Millisecond)
+		return 42, nil
+	})
+	if err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+	if got != 42 {
+		t.Errorf("got %d, want 42", got)
+	}
+}
+
+func TestNewClientDeadlines(t *testing.T) {
+	// Start a server that only answers once the request is cancelled, and
+	// reports each cancellation
+	cancelled := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices the client going away once the body is read
+		io.Copy(io.Discard, r.Body)
+
+		select {
+		case <-r.Context().Done():
+			cancelled <- r.Method + " " + r.URL.Path
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	// Set up a tests struct
+	tests := []struct {
+		name     string
+		timeouts Timeouts
+		run      func(client *postgrest.Client) error
+		want     string
+	}{
+		{"Select gets the read deadline", Timeouts{Read: 20 * time.Millisecond, Write: time.Hour}, func(client *postgrest.Client) error {
+			_, _, err := client.From("quotes").Select("*", "", false).Execute()
+			return err
+		}, "GET /rest/v1/quotes"},
+		{"Insert gets the write deadline", Timeouts{Read: time.Hour, Write: 20 * time.Millisecond}, func(client *postgrest.Client) error {
+			_, _, err := client.From("quotes").Insert(map[string]any{"quote": "Test"}, false, "", "", "").Execute()
+			return err
+		}, "POST /rest/v1/quotes"},
+		{"Read-only function gets the read deadline", Timeouts{Read: 20 * time.Millisecond, Write: time.Hour}, func(client *postgrest.Client) error {
+			client.Rpc("search_quotes", "", searchArgs{})
+			return client.ClientError
+		}, "POST /rest/v1/rpc/search_quotes"},
+	}
+
+	// Loop through each test
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(srv.URL, "test-key", tt.timeouts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Check the request fails with a timeout instead of waiting on the server
+			err = tt.run(client)
+			if !errors.Is(transportError(err), ErrQueryTimeout) {
+				t.Fatalf("got error %v, want %v", err, ErrQueryTimeout)
+			}
+
+			// Check the server saw the request cancelled
+			select {
+			case got := <-cancelled:
+				if got != tt.want {
+					t.Errorf("got %q cancelled, want %q", got, tt.want)
+				}
+			case <-time.After(time.Second):
+				t.Error("request was not cancelled on the server")
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"log"
@@ -10,22 +11,25 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/supabase-community/postgrest-go"
-	"github.com/supabase-community/supabase-go"
 )
 
 // Define an interface for the QuoteModel
 type QuoteModelInterface interface {
-	Insert(quote string, authorID int, bookID int, pageNumber string, isPrivate bool, userID uuid.UUID) (int, error)
-	Get(id int) (Quote, error)
-	GetByAuthorID(authorID int) ([]Quote, error)
-	GetByUserID(userID uuid.UUID) ([]Quote, error)
-	GetWithAuthorAndBook(id int) (Quote, error)
-	Update(id int, quote string, authorID int, bookID int, pageNumber string, isPrivate bool) (int, error)
-	Latest() ([]Quote, error)
-	Exists(id int) (bool, error)
-	Delete(id int) error
-	SetAuthUserID(id uuid.UUID)
-	GetByBookID(bookID int) ([]Quote, error)
+	Insert(ctx context.Context, quote string, authorID int, bookID int, editionID int, location Location, visibility Visibility, userID uuid.UUID) (int, error)
+	Get(ctx context.Context, id int) (Quote, error)
+	GetByAuthorID(ctx context.Context, authorID int) ([]Quote, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID, filters Filters) ([]Quote, Metadata, error)
+	GetWithAuthorAndBook(ctx context.Context, id int) (Quote, error)
+	Update(ctx context.Context, id int, quote string, authorID int, bookID int, editionID int, location Location, visibility Visibility, userID uuid.UUID) (int, error)
+	Latest(ctx context.Context, filters Filters) ([]Quote, Metadata, error)
+	Exists(ctx context.Context, id int) (bool, error)
+	Delete(ctx context.Context, id int) error
+	GetByBookID(ctx context.Context, bookID int) ([]Quote, error)
+	Search(ctx context.Context, sq SearchQuery, filters Filters) ([]SearchResult, Metadata, error)
+	GetByShareToken(ctx context.Context, token string) (Quote, error)
+	SetShareToken(ctx context.Context, id int, token string) error
+	GetTags(ctx context.Context, id int) ([]string, error)
+	SetTags(ctx context.Context, id int, tags []string) error
 }
 
 // Define a Quote struct to hold the quote data
@@ -36,308 +40,593 @@ type Quote struct {
 	Author    Author `json:"author"`
 	BookID    int    `json:"book_id"`
 	Book      Book   `json:"book"`
+	// The edition of the book the quote is from, 0 for the book's own edition
+	EditionID int    `json:"edition_id"`
 	UserID  uuid.UUID `json:"user_id"`
-	PageNumber string `json:"page_number"`
-	IsPrivate bool `json:"is_private"`
+	Location
+	Visibility Visibility `json:"visibility"`
+	ShareToken string `json:"share_token"`
+	// The quote's lowercase tags, only loaded where they are needed
+	Tags []string `json:"-"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-// Define a QuoteModel struct to hold the database connection pool
-type QuoteModel struct {
-	Client *supabase.Client
-	AuthClient *supabase.Client
-	AuthUserID uuid.UUID
+// QuoteTag is a tag given to a quote
+type QuoteTag struct {
+	QuoteID int    `json:"quote_id"`
+	Tag     string `json:"tag"`
 }
 
-// Set the authenticated user ID
-func (m *QuoteModel) SetAuthUserID(id uuid.UUID) {
-	m.AuthUserID = id
+// Define a QuoteModel struct to hold the database connection pool
+type QuoteModel struct {
+	Client *postgrest.Client
+	AuthClient *postgrest.Client
+	Timeouts Timeouts
 }
 
 // Return a specific quote based on the ID
-func (m *QuoteModel) Get(id int) (Quote, error) {
-	// Initialize a new Quote struct to hold the data
-	var q Quote
+func (m *QuoteModel) Get(ctx context.Context, id int) (Quote, error) {
+	return query(ctx, m.Timeouts.Read, func() (Quote, error) {
+		// Initialize a new Quote struct to hold the data
+		var q Quote
 
-	// Convert id to string
-	idStr := strconv.Itoa(id)
+		// Convert id to string
+		idStr := strconv.Itoa(id)
 
-	// Query the database for the quote
-	count, err := m.Client.From("quotes").Select("*", "exact", false).Eq("id", idStr).Single().ExecuteTo(&q)
-	if err != nil {
-		log.Printf("Error executing query: %v", err)
-		return Quote{}, ErrNoRecord
-	} else if count > 1 {
-		log.Printf("Unexpected count > 1 for ID: %d", id)
-		return Quote{}, err
-	} else if count == 0 {
-		log.Printf("No record found for ID: %d", id)
-		return Quote{}, ErrNoRecord
-	}
+		// Query the database for the quote
+		count, err := selectVisibleQuotes(m.Client, Viewer(ctx), "*", "exact").Eq("id", idStr).Single().ExecuteTo(&q)
+		if err != nil {
+			log.Printf("Error executing query: %v", err)
+			return Quote{}, ErrNoRecord
+		} else if count > 1 {
+			log.Printf("Unexpected count > 1 for ID: %d", id)
+			return Quote{}, err
+		} else if count == 0 {
+			log.Printf("No record found for ID: %d", id)
+			return Quote{}, ErrNoRecord
+		}
 
-	// Return the Quote struct
-	log.Printf("Retrieved quote: %+v", q)
-	return q, nil
+		// Return the Quote struct
+		log.Printf("Retrieved quote: %+v", q)
+		return q, nil
+	})
 }
 
 // Return a list of quotes by author ID
-func (m *QuoteModel) GetByAuthorID(authorID int) ([]Quote, error) {
-	var quotes []Quote
+func (m *QuoteModel) GetByAuthorID(ctx context.Context, authorID int) ([]Quote, error) {
+	return query(ctx, m.Timeouts.Read, func() ([]Quote, error) {
+		var quotes []Quote
 	
-	_, err := m.Client.From("quotes").Select("*", "exact", false).Eq("author_id", strconv.Itoa(authorID)).ExecuteTo(&quotes)
-	if err != nil {
-		log.Printf("Error executing query: %v", err)
-		return nil, err
-	}
+		_, err := selectVisibleQuotes(m.Client, Viewer(ctx), "*", "exact").Eq("author_id", strconv.Itoa(authorID)).ExecuteTo(&quotes)
+		if err != nil {
+			log.Printf("Error executing query: %v", err)
+			return nil, err
+		}
 
-	return quotes, nil
+		return quotes, nil
+	})
 }
 
-// Return a list of quotes by user ID
-func (m *QuoteModel) GetByUserID(userID uuid.UUID) ([]Quote, error) {
-	var quotes []Quote
-	
-	_, err := m.Client.From("quotes").Select("*", "exact", false).Eq("user_id", userID.String()).Limit(10, "").ExecuteTo(&quotes)
-	if err != nil {
-		log.Printf("Error executing query: %v", err)
-		return nil, err
-	}
-
-	return quotes, nil
+// Return a page of quotes by user ID
+func (m *QuoteModel) GetByUserID(ctx context.Context, userID uuid.UUID, filters Filters) ([]Quote, Metadata, error) {
+	page, err := query(ctx, m.Timeouts.Read, func() (listing[Quote], error) {
+		return m.list(Viewer(ctx), filters, &userID)
+	})
+	return page.rows, page.metadata, err
 }
 
 // Return a quote with the author and book
-func (m *QuoteModel) GetWithAuthorAndBook(id int) (Quote, error) {
-	var q Quote
-	var a Author
-	var b Book
+func (m *QuoteModel) GetWithAuthorAndBook(ctx context.Context, id int) (Quote, error) {
+	return query(ctx, m.Timeouts.Read, func() (Quote, error) {
+		var q Quote
+		var a Author
+		var b Book
+
+		// Query the database for the quote and join with the author
+		_, err := selectVisibleQuotes(m.Client, Viewer(ctx), "*", "exact").Eq("id", strconv.Itoa(id)).Single().ExecuteTo(&q)
+		if err != nil {
+			log.Printf("Error executing query: %v", err)
+			if strings.Contains(err.Error(), "PGRST116") {
+				return Quote{}, ErrNoRecord
+			}
+			return Quote{}, err
+		}
 
-	// Query the database for the quote and join with the author
-	_, err := m.Client.From("quotes").Select("*", "exact", false).Eq("id", strconv.Itoa(id)).Single().ExecuteTo(&q)
+		// Query the database for the author
+		_, err = m.Client.From("authors").Select("*", "exact", false).Eq("id", strconv.Itoa(q.AuthorID)).Single().ExecuteTo(&a)
+		if err != nil {
+			log.Printf("Error executing query: %v", err)
+			return Quote{}, err
+		}
+
+		// Query the database for the book
+		_, err = m.Client.From("books").Select("*", "exact", false).Eq("id", strconv.Itoa(q.BookID)).Single().ExecuteTo(&b)
+		if err != nil {
+			log.Printf("Error executing query: %v", err)
+			return Quote{}, err
+		}
+
+		// Return the quote with the author and book	
+		q.Author = a
+		q.Book = b
+
+		return q, nil
+	})
+}
+
+// Return a page of quotes with their authors and books, newest first unless another order is asked for
+func (m *QuoteModel) Latest(ctx context.Context, filters Filters) ([]Quote, Metadata, error) {
+	page, err := query(ctx, m.Timeouts.Read, func() (listing[Quote], error) {
+		return m.list(Viewer(ctx), filters, nil)
+	})
+	return page.rows, page.metadata, err
+}
+
+// Fetch a page of the quotes the viewer can see, only the given user's if there is one, with their authors and books
+func (m *QuoteModel) list(viewer uuid.UUID, filters Filters, userID *uuid.UUID) (listing[Quote], error) {
+	var quotes []Quote
+	var total int64
+	var err error
+
+	if filters.Sort == SortAuthor {
+		quotes, total, err = m.listByAuthor(viewer, filters, userID)
+	} else {
+		// Query the database for the page of quotes, ordered by when they were added
+		builder := selectVisibleQuotes(m.Client, viewer, "*", "exact")
+		if userID != nil {
+			builder = builder.Eq("user_id", userID.String())
+		}
+		order := &postgrest.OrderOpts{Ascending: filters.Sort == SortOldest}
+		total, err = builder.Order("created_at", order).Order("id", order).Range(filters.Offset(), filters.Offset()+filters.Limit()-1, "").ExecuteTo(&quotes)
+	}
 	if err != nil {
 		log.Printf("Error executing query: %v", err)
-		return Quote{}, err
+		return listing[Quote]{}, err
 	}
 
-	// Query the database for the author
-	_, err = m.Client.From("authors").Select("*", "exact", false).Eq("id", strconv.Itoa(q.AuthorID)).Single().ExecuteTo(&a)
+	// Fetch the authors and books of all the quotes in one request each
+	authorIDs := make([]int, len(quotes))
+	bookIDs := make([]int, len(quotes))
+	for i, q := range quotes {
+		authorIDs[i] = q.AuthorID
+		bookIDs[i] = q.BookID
+	}
+
+	authors, err := authorsByID(m.Client, authorIDs)
 	if err != nil {
-		log.Printf("Error executing query: %v", err)
-		return Quote{}, err
+		return listing[Quote]{}, err
 	}
 
-	// Query the database for the book
-	_, err = m.Client.From("books").Select("*", "exact", false).Eq("id", strconv.Itoa(q.BookID)).Single().ExecuteTo(&b)
+	books, err := booksByID(m.Client, bookIDs)
 	if err != nil {
-		log.Printf("Error executing query: %v", err)
-		return Quote{}, err
+		return listing[Quote]{}, err
 	}
 
-	// Return the quote with the author and book	
-	q.Author = a
-	q.Book = b
+	// Attach each quote's author and book
+	for i := range quotes {
+		quotes[i].Author = authors[quotes[i].AuthorID]
+		quotes[i].Book = books[quotes[i].BookID]
+	}
 
-	return q, nil
+	if quotes == nil {
+		quotes = []Quote{}
+	}
+	return listing[Quote]{quotes, CalculateMetadata(int(total), filters.Page, filters.PageSize)}, nil
 }
 
-// Return a list of the 10 most recent quotes
-func (m *QuoteModel) Latest() ([]Quote, error) {
-	var quotes []Quote
-	
-	// Query the database for the quotes with authors
-	_, err := m.Client.From("quotes").Select("*", "exact", false).Order("created_at", &postgrest.OrderOpts{Ascending: true}).Limit(10, "").ExecuteTo(&quotes)
+// Fetch a page of quotes ordered by author name. PostgREST can't order by a
+// column of another table, so the list_quotes_by_author function pages them.
+func (m *QuoteModel) listByAuthor(viewer uuid.UUID, filters Filters, userID *uuid.UUID) ([]Quote, int64, error) {
+	rows, total, err := listPage(m.Client, "list_quotes_by_author", listArgs{Viewer: viewer, Owner: userID, Limit: filters.Limit(), Offset: filters.Offset()})
 	if err != nil {
-		log.Printf("Error executing query: %v", err)
-		return nil, err
+		return nil, 0, err
 	}
 
-	// Fetch authors for each quote
-	for i := range quotes {
-		var author Author
-		_, err := m.Client.From("authors").Select("*", "exact", false).Eq("id", strconv.Itoa(quotes[i].AuthorID)).Single().ExecuteTo(&author)
-		if err != nil {
-			log.Printf("Error fetching author for quote %d: %v", quotes[i].ID, err)
-			continue
-		}
-		quotes[i].Author = author
+	// Fetch the quotes on the page, keeping the function's order
+	quotes, err := rowsByID(m.Client, "quotes", pageIDs(rows), func(q Quote) int { return q.ID })
+	if err != nil {
+		return nil, 0, err
 	}
 
-	// Fetch books for each quote
-	for i := range quotes {
-		var book Book
-		_, err := m.Client.From("books").Select("*", "exact", false).Eq("id", strconv.Itoa(quotes[i].BookID)).Single().ExecuteTo(&book)
-		if err != nil {
-			log.Printf("Error fetching book for quote %d: %v", quotes[i].ID, err)
-			continue
-		}
-		quotes[i].Book = book
-	}
+	return quotes, int64(total), nil
+}
 
-	// Return the slice of quotes
-	return quotes, nil
+// Return a page of the quotes matching the search terms and filters, most relevant first unless another order is asked for
+func (m *QuoteModel) Search(ctx context.Context, sq SearchQuery, filters Filters) ([]SearchResult, Metadata, error) {
+	page, err := query(ctx, m.Timeouts.Read, func() (listing[SearchResult], error) {
+		return m.search(sq, filters)
+	})
+	return page.rows, page.metadata, err
 }
 
-// Insert a new quote into the database
-func (m *QuoteModel) Insert(quote string, authorID int, bookID int, pageNumber string, isPrivate bool, userID uuid.UUID) (int, error) {
-	// Verify the user exists
-	_, _, err := m.AuthClient.From("users").Select("id", "exact", false).Eq("id", userID.String()).ExecuteString()
-	if err != nil {
-		log.Printf("Error verifying user exists to insert quote: %v", err)
-		return 0, err
-	}
+// The arguments of the search_quotes Postgres function, which is created by
+// the 0016_create_search_quotes migration
+type searchArgs struct {
+	SearchText   string     `json:"search_text"`
+	ExcludedText string     `json:"excluded_text"`
+	Viewer       *uuid.UUID `json:"viewer"`
+	Scope        string     `json:"search_scope"`
+	AuthorID     int        `json:"author_filter"`
+	BookID       int        `json:"book_filter"`
+	AuthorName   string     `json:"author_name"`
+	BookTitle    string     `json:"book_title"`
+	Tag          string     `json:"tag_filter"`
+	YearFrom     int        `json:"year_from"`
+	YearTo       int        `json:"year_to"`
+	Sort         string     `json:"sort_order"`
+	Limit        int        `json:"page_limit"`
+	Offset       int        `json:"page_offset"`
+}
+
+// A row returned by the search_quotes function: a quote on the page with its
+// rank, or a row without an ID when the page is empty, and the total either way
+type searchRow struct {
+	ID    int     `json:"id"`
+	Rank  float64 `json:"search_rank"`
+	Total int     `json:"total_records"`
+}
 
-	// Create a map to hold the quote data
-	data := map[string]interface{}{
-		"quote":   quote,
-		"author_id":  authorID,
-		"book_id": bookID,
-		"page_number": pageNumber,
-		"is_private": isPrivate,
-		"created_at": time.Now(),
-		"updated_at": time.Now(),
-		"user_id": userID,
+// Fetch a page of search results. The search_quotes function matches, ranks
+// and pages the quotes on the server, and the quotes on the page are then
+// fetched with their authors and books.
+func (m *QuoteModel) search(sq SearchQuery, filters Filters) (listing[SearchResult], error) {
+	results := []SearchResult{}
+
+	// Nothing matches an empty search, or a search of your own quotes when logged out
+	own := sq.Scope == ScopeMine || sq.Scope == ScopeUnlisted || sq.Scope == ScopePrivate
+	if sq.IsEmpty() || (own && sq.UserID == uuid.Nil) {
+		return listing[SearchResult]{results, Metadata{}}, nil
 	}
 
-	// Insert the quote into the database
-	response, count, err := m.Client.From("quotes").Insert(data, false, "", "", "").ExecuteString()
-	if err != nil {
-		log.Printf("Error inserting quote: %v", err)
-		return 0, err
-	} else if count > 1 {
-		log.Printf("Unexpected count > 1 for insert")
-		return 0, err
+	args := searchArgs{
+		SearchText:   sq.TSQuery(),
+		ExcludedText: sq.ExcludedTSQuery(),
+		Scope:        sq.Scope,
+		AuthorID:     sq.AuthorID,
+		BookID:       sq.BookID,
+		AuthorName:   sq.AuthorName,
+		BookTitle:    sq.BookTitle,
+		Tag:          sq.Tag,
+		YearFrom:     sq.YearFrom,
+		YearTo:       sq.YearTo,
+		Sort:         filters.Sort,
+		Limit:        filters.Limit(),
+		Offset:       filters.Offset(),
+	}
+	if sq.UserID != uuid.Nil {
+		args.Viewer = &sq.UserID
 	}
 
-	// Parse the JSON response to extract the ID
-	var insertedQuote []Quote
-	err = json.NewDecoder(strings.NewReader(string(response))).Decode(&insertedQuote)
+	var rows []searchRow
+	err := callFunction(m.Client, "search_quotes", args, &rows)
 	if err != nil {
-		log.Printf("Error parsing JSON response: %v", err)
-		return 0, err
+		log.Printf("Error searching quotes: %v", err)
+		return listing[SearchResult]{}, err
 	}
 
-	// Check if the quote was successfully inserted
-	if len(insertedQuote) == 0 {
-		log.Printf("No quotes returned in response")
-		return 0, errors.New("no quotes returned in response")
+	// Fetch the quotes on the page, keeping the function's order
+	total := 0
+	var ids []int
+	ranks := make(map[int]float64, len(rows))
+	for _, row := range rows {
+		total = row.Total
+		if row.ID != 0 {
+			ids = append(ids, row.ID)
+			ranks[row.ID] = row.Rank
+		}
+	}
+	if len(ids) == 0 {
+		return listing[SearchResult]{results, CalculateMetadata(total, filters.Page, filters.PageSize)}, nil
 	}
 
-	// Update the user's last quote added at timestamp
-	_, _, err = m.AuthClient.From("users").Update(map[string]interface{}{"last_quote_added_at": time.Now()}, "", "").Eq("id", userID.String()).Execute()
+	quotes, err := rowsByID(m.Client, "quotes", ids, func(q Quote) int { return q.ID })
 	if err != nil {
-		log.Printf("Error updating user's last quote added at timestamp: %v", err)
-		return 0, err
+		return listing[SearchResult]{}, err
 	}
 
-	// Return the ID of the inserted quote
-	return int(insertedQuote[0].ID), nil
-}
-
-// Update a quote in the database
-func (m *QuoteModel) Update(id int, quote string, authorID int, bookID int, pageNumber string, isPrivate bool) (int, error) {
-	// Create a map to hold the quote data
-	data := map[string]interface{}{
-		"quote":   quote,
-		"author_id":  authorID,
-		"book_id": bookID,
-		"page_number": pageNumber,
-		"is_private": isPrivate,
-		"user_id": m.AuthUserID,
-		"updated_at": time.Now(),
+	// Fetch the authors and books of the quotes in one request each
+	authorIDs := make([]int, len(quotes))
+	bookIDs := make([]int, len(quotes))
+	for i, q := range quotes {
+		authorIDs[i] = q.AuthorID
+		bookIDs[i] = q.BookID
 	}
 
-	// Convert id to string
-	idStr := strconv.Itoa(id)
-
-	// Update the quote in the database
-	response, _, err := m.Client.From("quotes").Update(data, "", "exact").Eq("id", idStr).Execute()
+	authors, err := authorsByID(m.Client, authorIDs)
 	if err != nil {
-		log.Printf("Error updating quote: %v", err)
-		return 0, err
+		return listing[SearchResult]{}, err
 	}
-	
-	// Parse the JSON response
-	var updatedQuote []Quote
-	err = json.NewDecoder(strings.NewReader(string(response))).Decode(&updatedQuote)
+
+	books, err := booksByID(m.Client, bookIDs)
 	if err != nil {
-		log.Printf("Error parsing JSON response: %v", err)
-		return 0, err
+		return listing[SearchResult]{}, err
 	}
 
-	// Check if the quote was successfully updated
-	if len(updatedQuote) == 0 {
-		log.Printf("No quotes returned in response")
-		return 0, errors.New("no quotes returned in response")
+	highlights := sq.Highlights()
+	for _, q := range quotes {
+		q.Author = authors[q.AuthorID]
+		q.Book = books[q.BookID]
+		results = append(results, NewSearchResult(q, ranks[q.ID], highlights))
 	}
 
-	return int(updatedQuote[0].ID), nil
+	return listing[SearchResult]{results, CalculateMetadata(total, filters.Page, filters.PageSize)}, nil
+}
+
+// Insert a new quote into the database
+func (m *QuoteModel) Insert(ctx context.Context, quote string, authorID int, bookID int, editionID int, location Location, visibility Visibility, userID uuid.UUID) (int, error) {
+	return write(ctx, func() (int, error) {
+		// Verify the user exists
+		_, _, err := m.AuthClient.From("users").Select("id", "exact", false).Eq("id", userID.String()).ExecuteString()
+		if err != nil {
+			log.Printf("Error verifying user exists to insert quote: %v", err)
+			return 0, err
+		}
+
+		// Create a map to hold the quote data
+		data := locationData(location, map[string]interface{}{
+			"quote":   quote,
+			"author_id":  authorID,
+			"book_id": bookID,
+			"edition_id": nullIfZero(editionID),
+			"visibility": visibility,
+			"created_at": time.Now(),
+			"updated_at": time.Now(),
+			"user_id": userID,
+		})
+
+		// Insert the quote into the database
+		response, count, err := m.Client.From("quotes").Insert(data, false, "", "", "").ExecuteString()
+		if err != nil {
+			log.Printf("Error inserting quote: %v", err)
+			return 0, err
+		} else if count > 1 {
+			log.Printf("Unexpected count > 1 for insert")
+			return 0, err
+		}
+
+		// Parse the JSON response to extract the ID
+		var insertedQuote []Quote
+		err = json.NewDecoder(strings.NewReader(string(response))).Decode(&insertedQuote)
+		if err != nil {
+			log.Printf("Error parsing JSON response: %v", err)
+			return 0, err
+		}
+
+		// Check if the quote was successfully inserted
+		if len(insertedQuote) == 0 {
+			log.Printf("No quotes returned in response")
+			return 0, errors.New("no quotes returned in response")
+		}
+
+		// Update the user's last quote added at timestamp
+		_, _, err = m.AuthClient.From("users").Update(map[string]interface{}{"last_quote_added_at": time.Now()}, "", "").Eq("id", userID.String()).Execute()
+		if err != nil {
+			log.Printf("Error updating user's last quote added at timestamp: %v", err)
+			return 0, err
+		}
+
+		// Return the ID of the inserted quote
+		return int(insertedQuote[0].ID), nil
+	})
+}
+
+// Update a quote in the database on behalf of the given user
+func (m *QuoteModel) Update(ctx context.Context, id int, quote string, authorID int, bookID int, editionID int, location Location, visibility Visibility, userID uuid.UUID) (int, error) {
+	return write(ctx, func() (int, error) {
+		// Create a map to hold the quote data
+		data := locationData(location, map[string]interface{}{
+			"quote":   quote,
+			"author_id":  authorID,
+			"book_id": bookID,
+			"edition_id": nullIfZero(editionID),
+			"visibility": visibility,
+			"user_id": userID,
+			"updated_at": time.Now(),
+		})
+
+		// Convert id to string
+		idStr := strconv.Itoa(id)
+
+		// Update the quote in the database
+		response, _, err := m.Client.From("quotes").Update(data, "", "exact").Eq("id", idStr).Execute()
+		if err != nil {
+			log.Printf("Error updating quote: %v", err)
+			return 0, err
+		}
+	
+		// Parse the JSON response
+		var updatedQuote []Quote
+		err = json.NewDecoder(strings.NewReader(string(response))).Decode(&updatedQuote)
+		if err != nil {
+			log.Printf("Error parsing JSON response: %v", err)
+			return 0, err
+		}
+
+		// Check if the quote was successfully updated
+		if len(updatedQuote) == 0 {
+			log.Printf("No quotes returned in response")
+			return 0, errors.New("no quotes returned in response")
+		}
+
+		return int(updatedQuote[0].ID), nil
+	})
 }
 
 // Check if the quote exists
-func (m *QuoteModel) Exists(id int) (bool, error) {
-    // Convert id to string
-    idStr := strconv.Itoa(id)
-
-    // Query the database for the user with the given id
-    response, count, err := m.Client.From("quotes").Select("id", "exact", false).Eq("id", idStr).ExecuteString()
-    if err != nil {
-        if strings.Contains(err.Error(), "PGRST116") {
-            // No rows returned
-            return false, nil
-        }
-        return false, err
-    }
-
-    // If count is 0, user doesn't exist
-    if count == 0 {
-        return false, nil
-    }
-
-    // Parse the JSON response
-    var quotes []struct {
-        ID int `json:"id"`
-    }
-
-	// Decode the JSON response
-    err = json.NewDecoder(strings.NewReader(string(response))).Decode(&quotes)
-    if err != nil {
-        return false, err
-    }
-
-    // Check if any user was found
-    return len(quotes) > 0, nil
+func (m *QuoteModel) Exists(ctx context.Context, id int) (bool, error) {
+	return query(ctx, m.Timeouts.Read, func() (bool, error) {
+	    // Convert id to string
+	    idStr := strconv.Itoa(id)
+
+	    // Query the database for the user with the given id
+	    response, count, err := selectVisibleQuotes(m.Client, Viewer(ctx), "id", "exact").Eq("id", idStr).ExecuteString()
+	    if err != nil {
+	        if strings.Contains(err.Error(), "PGRST116") {
+	            // No rows returned
+	            return false, nil
+	        }
+	        return false, err
+	    }
+
+	    // If count is 0, user doesn't exist
+	    if count == 0 {
+	        return false, nil
+	    }
+
+	    // Parse the JSON response
+	    var quotes []struct {
+	        ID int `json:"id"`
+	    }
+
+		// Decode the JSON response
+	    err = json.NewDecoder(strings.NewReader(string(response))).Decode(&quotes)
+	    if err != nil {
+	        return false, err
+	    }
+
+	    // Check if any user was found
+	    return len(quotes) > 0, nil
+	})
 }
 
 // Delete a quote
-func (m *QuoteModel) Delete(id int) error {
-	// Convert id to string
-	idStr := strconv.Itoa(id)
+func (m *QuoteModel) Delete(ctx context.Context, id int) error {
+	return exec(ctx, func() error {
+		// Convert id to string
+		idStr := strconv.Itoa(id)
+
+		// Delete the quote's tags, then the quote
+		_, _, err := m.Client.From("quote_tags").Delete("", "").Eq("quote_id", idStr).Execute()
+		if err != nil {
+			return err
+		}
 
-	// Delete the quote from the database
-	_, _, err := m.Client.From("quotes").Delete("", "exact").Eq("id", idStr).Execute()
-	return err
+		// Delete the quote from the database
+		_, _, err = m.Client.From("quotes").Delete("", "exact").Eq("id", idStr).Execute()
+		return err
+	})
 }
 
 // GetByBookID returns all quotes for a given book ID
-func (m *QuoteModel) GetByBookID(bookID int) ([]Quote, error) {
-    var quotes []Quote
+func (m *QuoteModel) GetByBookID(ctx context.Context, bookID int) ([]Quote, error) {
+	return query(ctx, m.Timeouts.Read, func() ([]Quote, error) {
+	    var quotes []Quote
     
-    response, count, err := m.Client.From("quotes").Select("*", "exact", false).Eq("book_id", strconv.Itoa(bookID)).ExecuteString()
-    if err != nil {
-        log.Printf("Error fetching quotes for book %d: %v", bookID, err)
-        return nil, err
-    }
-
-    if count == 0 {
-        return []Quote{}, nil // Return an empty slice if no quotes found
-    }
-
-    err = json.NewDecoder(strings.NewReader(response)).Decode(&quotes)
-    if err != nil {
-        log.Printf("Error decoding quotes JSON: %v", err)
-        return nil, err
-    }
-
-    return quotes, nil
-}
\ No newline at end of file
+	    response, count, err := selectVisibleQuotes(m.Client, Viewer(ctx), "*", "exact").Eq("book_id", strconv.Itoa(bookID)).ExecuteString()
+	    if err != nil {
+	        log.Printf("Error fetching quotes for book %d: %v", bookID, err)
+	        return nil, err
+	    }
+
+	    if count == 0 {
+	        return []Quote{}, nil // Return an empty slice if no quotes found
+	    }
+
+	    err = json.NewDecoder(strings.NewReader(response)).Decode(&quotes)
+	    if err != nil {
+	        log.Printf("Error decoding quotes JSON: %v", err)
+	        return nil, err
+	    }
+
+	    return quotes, nil
+	})
+}
+
+// Return the quote with the given share token, with its author and book, to
+// anyone who has the token. A revoked token, or a private quote, isn't found.
+func (m *QuoteModel) GetByShareToken(ctx context.Context, token string) (Quote, error) {
+	return query(ctx, m.Timeouts.Read, func() (Quote, error) {
+		var quotes []Quote
+
+		// An empty token never matches
+		if token == "" {
+			return Quote{}, ErrNoRecord
+		}
+
+		// Query the database for the quote, whoever is reading it
+		_, err := m.Client.From("quotes").Select("*", "", false).Eq("share_token", token).Neq("visibility", string(VisibilityPrivate)).ExecuteTo(&quotes)
+		if err != nil {
+			log.Printf("Error executing query: %v", err)
+			return Quote{}, err
+		}
+		if len(quotes) == 0 {
+			return Quote{}, ErrNoRecord
+		}
+		q := quotes[0]
+
+		// Fetch the quote's author and book
+		authors, err := authorsByID(m.Client, []int{q.AuthorID})
+		if err != nil {
+			return Quote{}, err
+		}
+		books, err := booksByID(m.Client, []int{q.BookID})
+		if err != nil {
+			return Quote{}, err
+		}
+
+		q.Author = authors[q.AuthorID]
+		q.Book = books[q.BookID]
+		return q, nil
+	})
+}
+
+// Set the token of a quote's share link, replacing any earlier token so its
+// link stops working. An empty token revokes the share link.
+func (m *QuoteModel) SetShareToken(ctx context.Context, id int, token string) error {
+	return exec(ctx, func() error {
+		// Store a revoked token as null, so it never matches a share link
+		var value any
+		if token != "" {
+			value = token
+		}
+
+		_, _, err := m.Client.From("quotes").Update(map[string]interface{}{"share_token": value, "updated_at": time.Now()}, "", "").Eq("id", strconv.Itoa(id)).Execute()
+		if err != nil {
+			log.Printf("Error updating quote share token: %v", err)
+		}
+		return err
+	})
+}
+
+// Return a quote's tags in alphabetical order
+func (m *QuoteModel) GetTags(ctx context.Context, id int) ([]string, error) {
+	return query(ctx, m.Timeouts.Read, func() ([]string, error) {
+		tags, err := tagsByQuoteID(m.Client, []int{id})
+		if err != nil {
+			return nil, err
+		}
+
+		if tags[id] == nil {
+			return []string{}, nil
+		}
+		return tags[id], nil
+	})
+}
+
+// Replace a quote's tags with the given lowercase tags
+func (m *QuoteModel) SetTags(ctx context.Context, id int, tags []string) error {
+	return exec(ctx, func() error {
+		idStr := strconv.Itoa(id)
+
+		// Remove the old tags, then add the new ones
+		_, _, err := m.Client.From("quote_tags").Delete("", "").Eq("quote_id", idStr).Execute()
+		if err != nil {
+			log.Printf("Error deleting quote tags: %v", err)
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+
+		rows := make([]QuoteTag, len(tags))
+		for i, tag := range tags {
+			rows[i] = QuoteTag{QuoteID: id, Tag: tag}
+		}
+		_, _, err = m.Client.From("quote_tags").Insert(rows, false, "", "", "").Execute()
+		if err != nil {
+			log.Printf("Error inserting quote tags: %v", err)
+		}
+		return err
+	})
+}
@@ -1,18 +1,18 @@
 package models
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strconv"
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+	"github.com/justinbachtell/quote-table-go/internal/postgresttest"
 )
 
 func TestQuoteModelExists(t *testing.T) {
-	// Skip the test if the "-short" flag is passed
-	if testing.Short() {
-		t.Skip("models: skipping integration tests in short mode")
-	}
-
 	// Set up a tests struct
 	tests := []struct {
 		name    string
@@ -44,7 +44,7 @@ func TestQuoteModelExists(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Call the Exists method to check if the quote exists
-			exists, err := m.Exists(tt.quoteID)
+			exists, err := m.Exists(context.Background(), tt.quoteID)
 
 			// Check for error first
 			if err != nil {
@@ -67,41 +67,370 @@ func TestQuoteModelExists(t *testing.T) {
 	})
 }
 
-func TestQuoteModelSetAuthUserID(t *testing.T) {
-	// Skip the test if the "-short" flag is passed
-	if testing.Short() {
-		t.Skip("models: skipping integration tests in short mode")
-	}
 
+func TestQuoteModelGet(t *testing.T) {
 	// Create a new test database
 	db := newTestDatabase(t)
 
 	// Create a new QuoteModel instance
 	m := QuoteModel{Client: db}
 
-	// Create a new user
-	userID := uuid.New()
+	// Insert a test quote and get the ID
+	testQuoteID, err := InsertTestQuote(db, "Test quote", "Test Author")
+	if err != nil {
+		t.Fatalf("Failed to insert test quote: %v", err)
+	}
+
+	// Get the quote back
+	q, err := m.Get(context.Background(), testQuoteID)
+	if err != nil {
+		t.Fatalf("Error in Get method: %v", err)
+	}
+	if q.ID != testQuoteID || q.Quote != "Test quote" {
+		t.Errorf("got quote %d %q, want %d %q", q.ID, q.Quote, testQuoteID, "Test quote")
+	}
+
+	// A missing quote comes back from PostgREST as a PGRST116 error
+	_, err = m.Get(context.Background(), 9999)
+	if !errors.Is(err, ErrNoRecord) {
+		t.Errorf("got error %v, want %v", err, ErrNoRecord)
+	}
+}
+
+func TestQuoteModelLatest(t *testing.T) {
+	// Seed the fake server with a library of quotes
+	ts, db := newTestServer(t)
+	seedTestLibrary(t, ts, 25)
+
+	// Create a new QuoteModel instance
+	m := QuoteModel{Client: db}
+
+	quotes, metadata, err := m.Latest(context.Background(), Filters{Page: 1, PageSize: 10, Sort: SortNewest})
+	if err != nil {
+		t.Fatalf("Error in Latest method: %v", err)
+	}
+
+	// The quotes come back with their authors and books attached
+	if len(quotes) != 10 {
+		t.Fatalf("got %d quotes, want 10", len(quotes))
+	}
+	if quotes[0].Quote != "Quote 25" {
+		t.Errorf("got %q first, want the newest quote", quotes[0].Quote)
+	}
+	if metadata.TotalRecords != 25 || metadata.LastPage != 3 {
+		t.Errorf("got metadata %+v, want 25 records on 3 pages", metadata)
+	}
+	for _, q := range quotes {
+		if q.Author.ID != q.AuthorID || q.Author.Name == "" {
+			t.Errorf("quote %d has author %+v, want ID %d", q.ID, q.Author, q.AuthorID)
+		}
+		if q.Book.ID != q.BookID || q.Book.Title == "" {
+			t.Errorf("quote %d has book %+v, want ID %d", q.ID, q.Book, q.BookID)
+		}
+	}
+
+	// One request for the quotes, one for their authors and one for their books
+	if got := ts.Requests(); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+}
+
+func TestQuoteModelLatestSorts(t *testing.T) {
+	// Seed the fake server with a library of quotes
+	ts, db := newTestServer(t)
+	seedTestLibrary(t, ts, 12)
+
+	// Create a new QuoteModel instance
+	m := QuoteModel{Client: db}
 
-	// Test cases
-	testCases := []struct {
-		name     string
-		userID   uuid.UUID
-		expected uuid.UUID
+	tests := []struct {
+		name       string
+		filters    Filters
+		wantQuotes []string
 	}{
-		{"Set valid user ID", userID, userID},
-		{"Set empty user ID", uuid.Nil, uuid.Nil},
+		{"Newest", Filters{Page: 1, PageSize: 3, Sort: SortNewest}, []string{"Quote 12", "Quote 11", "Quote 10"}},
+		{"Oldest second page", Filters{Page: 2, PageSize: 3, Sort: SortOldest}, []string{"Quote 4", "Quote 5", "Quote 6"}},
+		{"Author", Filters{Page: 1, PageSize: 4, Sort: SortAuthor}, []string{"Quote 5", "Quote 10", "Quote 1", "Quote 6"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quotes, metadata, err := m.Latest(context.Background(), tt.filters)
+			if err != nil {
+				t.Fatalf("Error in Latest method: %v", err)
+			}
+
+			got := []string{}
+			for _, q := range quotes {
+				got = append(got, q.Quote)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.wantQuotes) {
+				t.Errorf("got quotes %v, want %v", got, tt.wantQuotes)
+			}
+			if metadata.TotalRecords != 12 {
+				t.Errorf("got %d records, want 12", metadata.TotalRecords)
+			}
+		})
 	}
+}
 
-	// Loop through each test case
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			// Set the AuthUserID
-			m.SetAuthUserID(tc.userID)
+func BenchmarkQuoteModelLatest(b *testing.B) {
+	for _, size := range []int{10, 100, 1000} {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			ts, db := newTestServer(b)
+			seedTestLibrary(b, ts, size)
+			m := QuoteModel{Client: db}
 
-			// Check if the AuthUserID was set correctly
-			if m.AuthUserID != tc.expected {
-				t.Errorf("Expected AuthUserID to be %s, but got %s", tc.expected, m.AuthUserID)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := m.Latest(context.Background(), Filters{Page: 1, PageSize: DefaultPageSize, Sort: SortNewest}); err != nil {
+					b.Fatal(err)
+				}
 			}
+
+			// Report the round trips per call, which must not grow with the size
+			b.ReportMetric(float64(ts.Requests())/float64(b.N), "requests/op")
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestQuoteModelSearch(t *testing.T) {
+	ts, db := newTestServer(t)
+	owner := uuid.New()
+
+	// Seed two authors with a public quote each and a private quote
+	_, err := ts.Insert("authors", postgresttest.Row{"name": "Marcus Aurelius"}, postgresttest.Row{"name": "Seneca"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Insert("books",
+		postgresttest.Row{"title": "Meditations", "publish_year": 180, "calendar_time": "A.D."},
+		postgresttest.Row{"title": "Letters from a Stoic", "publish_year": 65, "calendar_time": "A.D."},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Insert("quotes",
+		postgresttest.Row{"quote": "The happiness of your life depends upon the quality of your thoughts.", "author_id": 1, "book_id": 1},
+		postgresttest.Row{"quote": "Waste no more time arguing about what a good man should be.", "author_id": 1, "book_id": 1},
+		postgresttest.Row{"quote": "Luck is what happens when preparation meets opportunity.", "author_id": 2, "book_id": 2, "user_id": owner.String(), "visibility": "private"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.ResetRequests()
+
+	m := QuoteModel{Client: db}
+	filters := Filters{Page: 1, PageSize: 20}
+
+	// Check every word has to match, in the quote or in its author and book
+	results, metadata, err := m.Search(context.Background(), SearchQuery{Terms: "happ marcus"}, filters)
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 1)
+	assert.Equal(t, results[0].Quote.Book.Title, "Meditations")
+	assert.Equal(t, metadata.TotalRecords, 1)
+
+	// Check private quotes are only found by their owner
+	results, _, err = m.Search(context.Background(), SearchQuery{Terms: "luck"}, filters)
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 0)
+	results, _, err = m.Search(context.Background(), SearchQuery{Terms: "stoic", Scope: ScopeMine, UserID: owner}, filters)
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 1)
+
+	// Check the filters narrow the results
+	results, _, err = m.Search(context.Background(), SearchQuery{Terms: "the", AuthorID: 1, YearFrom: 100}, filters)
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 1)
+	assert.Equal(t, results[0].Quote.ID, 1)
+
+	// Each search calls the function and then fetches its page's quotes, authors
+	// and books, while a search with no results only calls the function
+	assert.Equal(t, ts.Requests(), 13)
+}
+
+func TestQuoteModelVisibility(t *testing.T) {
+	ts, db := newTestServer(t)
+	owner := uuid.New()
+
+	// Seed a public quote and a private quote
+	_, err := ts.Insert("authors", postgresttest.Row{"name": "Seneca"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Insert("books", postgresttest.Row{"title": "Letters from a Stoic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Insert("quotes",
+		postgresttest.Row{"quote": "We suffer more in imagination than in reality.", "author_id": 1, "book_id": 1, "user_id": owner.String()},
+		postgresttest.Row{"quote": "Luck is what happens when preparation meets opportunity.", "author_id": 1, "book_id": 1, "user_id": owner.String(), "visibility": "private"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Set up a tests struct
+	tests := []struct {
+		name      string
+		viewer    uuid.UUID
+		wantFound bool
+		wantTotal int
+	}{
+		{"Anonymous", uuid.Nil, false, 1},
+		{"Another user", uuid.New(), false, 1},
+		{"Owner", owner, true, 2},
+	}
+
+	m := QuoteModel{Client: db}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithViewer(context.Background(), tt.viewer)
+
+			// Check the private quote is only found by its owner
+			_, err := m.Get(ctx, 2)
+			assert.Equal(t, err == nil, tt.wantFound)
+			_, err = m.GetWithAuthorAndBook(ctx, 2)
+			assert.Equal(t, err == nil, tt.wantFound)
+			exists, err := m.Exists(ctx, 2)
+			assert.NilError(t, err)
+			assert.Equal(t, exists, tt.wantFound)
+
+			// Check the listings only count the quotes the viewer can see
+			quotes, err := m.GetByAuthorID(ctx, 1)
+			assert.NilError(t, err)
+			assert.Equal(t, len(quotes), tt.wantTotal)
+			_, metadata, err := m.Latest(ctx, Filters{Page: 1, PageSize: 20})
+			assert.NilError(t, err)
+			assert.Equal(t, metadata.TotalRecords, tt.wantTotal)
+		})
+	}
+}
+
+func TestQuoteModelLocation(t *testing.T) {
+	ts, db := newTestServer(t)
+	owner := uuid.New()
+	_, err := ts.Insert("users", postgresttest.Row{"id": owner.String(), "name": "Alice", "email": "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := QuoteModel{Client: db, AuthClient: db}
+	ctx := context.Background()
+
+	// A single page is stored without an end page
+	location := Location{Type: LocationPage, Start: 12}
+	id, err := m.Insert(ctx, "We suffer more in imagination than in reality.", 0, 0, 0, location, VisibilityPublic, owner)
+	assert.NilError(t, err)
+	row := ts.Rows("quotes")[0]
+	assert.Equal(t, row["location_type"], any("page"))
+	assert.Equal(t, row["location_end"], nil)
+
+	q, err := m.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, q.Location, location)
+
+	// Updating to a timestamp clears the page
+	location = Location{Type: LocationTimestamp, Start: 245}
+	_, err = m.Update(ctx, id, "We suffer more in imagination than in reality.", 0, 0, 0, location, VisibilityPublic, owner)
+	assert.NilError(t, err)
+	q, err = m.Get(ctx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, q.Location, location)
+}
+
+func TestQuoteModelShareToken(t *testing.T) {
+	ts, db := newTestServer(t)
+	owner := uuid.New()
+
+	// Seed an unlisted quote and a private quote
+	_, err := ts.Insert("authors", postgresttest.Row{"name": "Seneca"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Insert("books", postgresttest.Row{"title": "Letters from a Stoic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Insert("quotes",
+		postgresttest.Row{"quote": "We suffer more in imagination than in reality.", "author_id": 1, "book_id": 1, "user_id": owner.String(), "visibility": "unlisted"},
+		postgresttest.Row{"quote": "Luck is what happens when preparation meets opportunity.", "author_id": 1, "book_id": 1, "user_id": owner.String(), "visibility": "private"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := QuoteModel{Client: db}
+	ctx := context.Background()
+
+	// Check the share link finds the unlisted quote with its author and book
+	assert.NilError(t, m.SetShareToken(ctx, 1, "first-token"))
+	q, err := m.GetByShareToken(ctx, "first-token")
+	assert.NilError(t, err)
+	assert.Equal(t, q.ID, 1)
+	assert.Equal(t, q.Author.Name, "Seneca")
+	assert.Equal(t, q.Book.Title, "Letters from a Stoic")
+
+	// Check rotating and then revoking the token stops the old links working
+	assert.NilError(t, m.SetShareToken(ctx, 1, "second-token"))
+	_, err = m.GetByShareToken(ctx, "first-token")
+	assert.Equal(t, err, ErrNoRecord)
+	assert.NilError(t, m.SetShareToken(ctx, 1, ""))
+	_, err = m.GetByShareToken(ctx, "second-token")
+	assert.Equal(t, err, ErrNoRecord)
+
+	// Check a private quote can't be reached through a share link
+	assert.NilError(t, m.SetShareToken(ctx, 2, "private-token"))
+	_, err = m.GetByShareToken(ctx, "private-token")
+	assert.Equal(t, err, ErrNoRecord)
+}
+
+func TestQuoteModelTags(t *testing.T) {
+	ts, db := newTestServer(t)
+
+	// Seed two quotes
+	_, err := ts.Insert("authors", postgresttest.Row{"name": "Seneca"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Insert("books", postgresttest.Row{"title": "Letters from a Stoic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Insert("quotes",
+		postgresttest.Row{"quote": "We suffer more in imagination than in reality.", "author_id": 1, "book_id": 1},
+		postgresttest.Row{"quote": "Luck is what happens when preparation meets opportunity.", "author_id": 1, "book_id": 1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := QuoteModel{Client: db}
+	ctx := context.Background()
+
+	// Check tags are read back in alphabetical order, and replaced when set again
+	assert.NilError(t, m.SetTags(ctx, 1, []string{"stoic", "fear"}))
+	assert.NilError(t, m.SetTags(ctx, 2, []string{"luck"}))
+	tags, err := m.GetTags(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, fmt.Sprint(tags), "[fear stoic]")
+
+	assert.NilError(t, m.SetTags(ctx, 2, []string{"stoic"}))
+	assert.Equal(t, len(ts.Rows("quote_tags")), 3)
+
+	// Check a search by tag only finds the tagged quotes
+	results, _, err := m.Search(ctx, SearchQuery{Terms: "luck", Tag: "stoic"}, Filters{Page: 1, PageSize: 20})
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 1)
+	assert.Equal(t, results[0].Quote.ID, 2)
+
+	results, _, err = m.Search(ctx, SearchQuery{Tag: "fear"}, Filters{Page: 1, PageSize: 20})
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 1)
+	assert.Equal(t, results[0].Quote.ID, 1)
+
+	// Check deleting a quote deletes its tags
+	assert.NilError(t, m.Delete(ctx, 1))
+	assert.Equal(t, len(ts.Rows("quote_tags")), 1)
+}
@@ -0,0 +1,31 @@
+package models
+
+import "slices"
+
+// Role says what a user is allowed to do beyond managing their own quotes
+type Role string
+
+const (
+	// Every new user starts with the user role
+	RoleUser Role = "user"
+	// Premium users have paid for the premium plan
+	RolePremium Role = "premium"
+	// Moderators can edit and delete quotes and books added by anyone
+	RoleModerator Role = "moderator"
+	// Admins can do anything a moderator can, and manage authors
+	RoleAdmin Role = "admin"
+)
+
+// The roles a user can have, from the least to the most privileged
+var Roles = []Role{RoleUser, RolePremium, RoleModerator, RoleAdmin}
+
+// Valid reports whether r is one of the known roles
+func (r Role) Valid() bool {
+	return slices.Contains(Roles, r)
+}
+
+// AtLeast reports whether r grants everything min does. Roles are ranked, so
+// an admin has every role and an unknown role has none.
+func (r Role) AtLeast(min Role) bool {
+	return r.Valid() && slices.Index(Roles, r) >= slices.Index(Roles, min)
+}
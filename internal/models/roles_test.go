@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+)
+
+func TestRoleAtLeast(t *testing.T) {
+	// Set up a tests struct
+	tests := []struct {
+		name string
+		role Role
+		min  Role
+		want bool
+	}{
+		{"Same role", RoleModerator, RoleModerator, true},
+		{"Higher role", RoleAdmin, RoleModerator, true},
+		{"Lower role", RolePremium, RoleModerator, false},
+		{"Every role is at least a user", RoleUser, RoleUser, true},
+		{"No role", "", RoleUser, false},
+		{"Unknown role", "owner", RoleUser, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.role.AtLeast(tt.min), tt.want)
+		})
+	}
+}
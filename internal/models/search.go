@@ -0,0 +1,431 @@
+package models
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// The quotes a search can be limited to. ScopeUnlisted and ScopePrivate are
+// only reachable through the query language, with is:unlisted and is:private.
+const (
+	ScopeAll      = "all"
+	ScopePublic   = "public"
+	ScopeMine     = "mine"
+	ScopeUnlisted = "unlisted"
+	ScopePrivate  = "private"
+)
+
+// The search scopes offered by the search form, with the default first
+var SearchScopes = []string{ScopeAll, ScopePublic, ScopeMine}
+
+// Search results are ordered by relevance unless another order is asked for
+const SortRelevance = "relevance"
+
+// The sort orders a search accepts, with its default first
+var SearchSorts = []string{SortRelevance, SortNewest, SortOldest}
+
+// How much a match in each field counts towards a result's rank
+const (
+	quoteWeight  = 1.0
+	authorWeight = 0.5
+	titleWeight  = 0.5
+)
+
+// The number of words of a quote shown in a search result
+const snippetWords = 30
+
+// SearchQuery holds the terms and filters of a quote search
+type SearchQuery struct {
+	// The words to match, each of which matches longer words starting with it
+	Terms string
+	// The phrases to match, as whole words in order
+	Phrases []string
+	// The words and phrases that must not match
+	Excluded []string
+	// Text the author's name or one of their aliases, or the book's title,
+	// must contain, ignoring case
+	AuthorName string
+	BookTitle  string
+	AuthorID   int
+	BookID     int
+	// A tag the quote must have, in lowercase
+	Tag string
+	// The publish year bounds, with B.C. years negative. Zero leaves a bound open.
+	YearFrom int
+	YearTo   int
+	Scope    string
+	// The user searching, or uuid.Nil when nobody is logged in
+	UserID uuid.UUID
+}
+
+// Words returns the distinct lowercase words of the search terms
+func (q SearchQuery) Words() []string {
+	var words []string
+	seen := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(q.Terms), isSeparator) {
+		if !seen[w] {
+			seen[w] = true
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// PhraseWords returns the lowercase words of each phrase, leaving out phrases without any words
+func (q SearchQuery) PhraseWords() [][]string {
+	return phraseWords(q.Phrases)
+}
+
+// ExcludedWords returns the lowercase words of each excluded word or phrase
+func (q SearchQuery) ExcludedWords() [][]string {
+	return phraseWords(q.Excluded)
+}
+
+// Returns the lowercase words of each phrase, leaving out phrases without any words
+func phraseWords(phrases []string) [][]string {
+	var words [][]string
+	for _, p := range phrases {
+		if tokens := tokenize(p); len(tokens) > 0 {
+			words = append(words, tokens)
+		}
+	}
+	return words
+}
+
+// HasText reports whether the query has any words or phrases to match
+func (q SearchQuery) HasText() bool {
+	return len(q.Words()) > 0 || len(q.PhraseWords()) > 0
+}
+
+// TSQuery returns a Postgres tsquery, for the simple configuration, matching
+// every word and phrase of the query, or "" when it has none
+func (q SearchQuery) TSQuery() string {
+	var phrases [][]string
+	for _, w := range q.Words() {
+		phrases = append(phrases, []string{w})
+	}
+	phrases = append(phrases, q.PhraseWords()...)
+	return tsqueryExpression(phrases, " & ")
+}
+
+// ExcludedTSQuery returns a Postgres tsquery matching any of the excluded
+// words and phrases, or "" when there are none
+func (q SearchQuery) ExcludedTSQuery() string {
+	return tsqueryExpression(q.ExcludedWords(), " | ")
+}
+
+// Returns a tsquery matching every word and phrase joined with &, or any of
+// them joined with |. A single word also matches longer words starting with it.
+// The words are made of letters and digits only, so they need no quoting.
+func tsqueryExpression(phrases [][]string, join string) string {
+	terms := make([]string, len(phrases))
+	for i, words := range phrases {
+		if len(words) == 1 {
+			terms[i] = words[0] + ":*"
+		} else {
+			terms[i] = "(" + strings.Join(words, " <-> ") + ")"
+		}
+	}
+	return strings.Join(terms, join)
+}
+
+// IsEmpty reports whether the query has nothing to search for, so it would
+// find every quote the user can see
+func (q SearchQuery) IsEmpty() bool {
+	return !q.HasText() && q.AuthorName == "" && q.BookTitle == "" && q.Tag == "" && q.AuthorID == 0 && q.BookID == 0 &&
+		q.YearFrom == 0 && q.YearTo == 0 && (q.Scope == "" || q.Scope == ScopeAll)
+}
+
+// Highlights returns the words to highlight in the results
+func (q SearchQuery) Highlights() []string {
+	words := q.Words()
+	for _, phrase := range q.PhraseWords() {
+		words = append(words, phrase...)
+	}
+	return words
+}
+
+// Matches reports whether a quote loaded with its author and book passes the
+// query's filters and scope. The author's aliases need loading too when the
+// query has an author name, and the quote's tags when it has a tag. It does
+// not look at the words or phrases.
+func (q SearchQuery) Matches(quote Quote) bool {
+	if q.AuthorID != 0 && quote.AuthorID != q.AuthorID {
+		return false
+	}
+	if q.BookID != 0 && quote.BookID != q.BookID {
+		return false
+	}
+	if q.AuthorName != "" && !quote.Author.HasName(q.AuthorName) {
+		return false
+	}
+	if q.BookTitle != "" && !strings.Contains(strings.ToLower(quote.Book.Title), strings.ToLower(q.BookTitle)) {
+		return false
+	}
+	if q.Tag != "" && !slices.Contains(quote.Tags, q.Tag) {
+		return false
+	}
+
+	// Quotes without a book have no year to compare
+	if q.YearFrom != 0 || q.YearTo != 0 {
+		if quote.Book.ID == 0 {
+			return false
+		}
+		year := SignedYear(quote.Book)
+		if q.YearFrom != 0 && year < q.YearFrom {
+			return false
+		}
+		if q.YearTo != 0 && year > q.YearTo {
+			return false
+		}
+	}
+
+	// Unlisted and private quotes are only ever found by the user who added them
+	own := q.UserID != uuid.Nil && quote.UserID == q.UserID
+	switch q.Scope {
+	case ScopeMine:
+		return own
+	case ScopeUnlisted, ScopePrivate:
+		return own && string(quote.Visibility) == q.Scope
+	case ScopePublic:
+		return quote.Visibility == VisibilityPublic
+	}
+	return CanView(q.UserID, quote)
+}
+
+// SignedYear returns a book's publish year with B.C. years negative, so years can be compared
+func SignedYear(b Book) int {
+	return signedYear(b.PublishYear, b.CalendarTime)
+}
+
+// Returns a year with B.C. years negative
+func signedYear(year int, calendarTime string) int {
+	if calendarTime == "B.C." {
+		return -year
+	}
+	return year
+}
+
+// Fragment is a piece of highlighted text, which is a match for a search term or the text between matches
+type Fragment struct {
+	Text  string
+	Match bool
+}
+
+// SearchResult is a quote found by a search, with its rank and the matching text highlighted
+type SearchResult struct {
+	Quote   Quote
+	Rank    float64
+	Snippet []Fragment
+	Author  []Fragment
+	Title   []Fragment
+}
+
+// NewSearchResult highlights the words in a quote loaded with its author and book
+func NewSearchResult(quote Quote, rank float64, words []string) SearchResult {
+	return SearchResult{
+		Quote:   quote,
+		Rank:    rank,
+		Snippet: Highlight(quote.Quote, words, snippetWords),
+		Author:  Highlight(quote.Author.Name, words, 0),
+		Title:   Highlight(quote.Book.Title, words, 0),
+	}
+}
+
+// Rank scores how well a quote loaded with its author and book matches the
+// query, weighting matches in the quote above matches in the author's name and
+// the book's title. It reports false unless the quote passes the filters, every
+// word and phrase matches somewhere and nothing excluded does.
+func (q SearchQuery) Rank(quote Quote) (float64, bool) {
+	if !q.Matches(quote) {
+		return 0, false
+	}
+
+	fields := []struct {
+		tokens []string
+		weight float64
+	}{
+		{tokenize(quote.Quote), quoteWeight},
+		{tokenize(quote.Author.Name), authorWeight},
+		{tokenize(quote.Book.Title), titleWeight},
+	}
+
+	// Scores a word or phrase, where a single word also matches longer words starting with it
+	score := func(words []string, prefix bool) float64 {
+		total := 0.0
+		for _, f := range fields {
+			total += f.weight * float64(countPhrase(f.tokens, words, prefix))
+		}
+		return total
+	}
+
+	for _, excluded := range q.ExcludedWords() {
+		if score(excluded, len(excluded) == 1) > 0 {
+			return 0, false
+		}
+	}
+
+	rank := 0.0
+	for _, w := range q.Words() {
+		s := score([]string{w}, true)
+		if s == 0 {
+			return 0, false
+		}
+		rank += s
+	}
+	for _, phrase := range q.PhraseWords() {
+		s := score(phrase, false)
+		if s == 0 {
+			return 0, false
+		}
+		rank += s
+	}
+	return rank, true
+}
+
+// Counts the places the words appear in order in the tokens. With prefix set,
+// the last word also matches longer tokens starting with it.
+func countPhrase(tokens []string, words []string, prefix bool) int {
+	count := 0
+	for i := 0; i+len(words) <= len(tokens); i++ {
+		match := true
+		for j, w := range words {
+			token := tokens[i+j]
+			if token != w && !(prefix && j == len(words)-1 && strings.HasPrefix(token, w)) {
+				match = false
+				break
+			}
+		}
+		if match {
+			count++
+		}
+	}
+	return count
+}
+
+// Highlight splits text into fragments, marking the words that start with one
+// of the search words. If limit is above zero, only that many words are kept,
+// starting a little before the first match, with an ellipsis for the rest.
+func Highlight(text string, words []string, limit int) []Fragment {
+	// Split the text into runs of word and separator characters
+	var runs []string
+	start := 0
+	separator := false
+	for i, r := range text {
+		if i > start && isSeparator(r) != separator {
+			runs = append(runs, text[start:i])
+			start = i
+		}
+		separator = isSeparator(r)
+	}
+	if start < len(text) {
+		runs = append(runs, text[start:])
+	}
+
+	// Find the words that match and the first of them
+	first := -1
+	wordCount := 0
+	matches := make([]bool, len(runs))
+	for i, run := range runs {
+		if isSeparatorRun(run) {
+			continue
+		}
+		lower := strings.ToLower(run)
+		for _, w := range words {
+			if strings.HasPrefix(lower, w) {
+				matches[i] = true
+				break
+			}
+		}
+		if matches[i] && first == -1 {
+			first = wordCount
+		}
+		wordCount++
+	}
+
+	// Work out which words to keep, showing a few before the first match
+	from, to := 0, wordCount
+	if limit > 0 && wordCount > limit {
+		from = max(0, min(first-limit/3, wordCount-limit))
+		to = from + limit
+	}
+
+	var fragments []Fragment
+	add := func(text string, match bool) {
+		if n := len(fragments); n > 0 && fragments[n-1].Match == match && !match {
+			fragments[n-1].Text += text
+			return
+		}
+		fragments = append(fragments, Fragment{Text: text, Match: match})
+	}
+
+	if from > 0 {
+		add("… ", false)
+	}
+	word := 0
+	for i, run := range runs {
+		if isSeparatorRun(run) {
+			// Keep the separators between the kept words, and at either end of the text if it is kept
+			if (word > from || from == 0) && (word < to || to == wordCount) {
+				add(run, false)
+			}
+			continue
+		}
+		if word >= from && word < to {
+			add(run, matches[i])
+		}
+		word++
+	}
+	if to < wordCount {
+		add(" …", false)
+	}
+
+	return fragments
+}
+
+// Returns the lowercase words of a piece of text
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), isSeparator)
+}
+
+// Reports whether a character separates words
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// Reports whether a run of text is made of separators
+func isSeparatorRun(run string) bool {
+	for _, r := range run {
+		return isSeparator(r)
+	}
+	return true
+}
+
+// SortResults orders search results by the given sort order, most relevant first by default
+func SortResults(results []SearchResult, order string) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i].Quote, results[j].Quote
+		switch order {
+		case SortOldest:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+			return a.ID < b.ID
+		case SortNewest:
+		default:
+			if results[i].Rank != results[j].Rank {
+				return results[i].Rank > results[j].Rank
+			}
+		}
+
+		// Break ties with the newest quote first
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	})
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+)
+
+// Renders fragments with the matches in brackets, so they are easy to compare
+func renderFragments(fragments []Fragment) string {
+	var b strings.Builder
+	for _, f := range fragments {
+		if f.Match {
+			fmt.Fprintf(&b, "[%s]", f.Text)
+		} else {
+			b.WriteString(f.Text)
+		}
+	}
+	return b.String()
+}
+
+func TestSearchQueryWords(t *testing.T) {
+	q := SearchQuery{Terms: `  Know THYSELF, "know" it-self! `}
+	assert.Equal(t, fmt.Sprint(q.Words()), "[know thyself it self]")
+}
+
+func TestHighlight(t *testing.T) {
+	long := strings.Repeat("word ", 40) + "needle " + strings.Repeat("word ", 40)
+
+	// Set up a tests struct
+	tests := []struct {
+		name  string
+		text  string
+		words []string
+		limit int
+		want  string
+	}{
+		{"Whole text", "Memento mori.", []string{"mori"}, 0, "Memento [mori]."},
+		{"Prefix and case", "Amor fati, amor!", []string{"am"}, 0, "[Amor] fati, [amor]!"},
+		{"Leading punctuation", "“Carpe diem”", []string{"carpe"}, 0, "“[Carpe] diem”"},
+		{"Accented words", "Ça ira, élan vital", []string{"élan"}, 0, "Ça ira, [élan] vital"},
+		{"No match", "Memento mori", []string{"fati"}, 0, "Memento mori"},
+		{"Short text kept whole", "a b c", []string{"b"}, 30, "a [b] c"},
+		{"Long text cut around the match", long, []string{"needle"}, 6, "… word word [needle] word word word …"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, renderFragments(Highlight(tt.text, tt.words, tt.limit)), tt.want)
+		})
+	}
+}
+
+func TestSearchQueryRank(t *testing.T) {
+	q := Quote{
+		Quote:      "Waste no more time arguing about what a good man should be. Be one.",
+		Author:     Author{Name: "Marcus Aurelius"},
+		Book:       Book{ID: 1, Title: "Meditations"},
+		Visibility: VisibilityPublic,
+	}
+
+	// Set up a tests struct
+	tests := []struct {
+		name     string
+		query    SearchQuery
+		wantRank float64
+		wantOK   bool
+	}{
+		{"Word in the quote", SearchQuery{Terms: "time"}, 1.0, true},
+		{"Word in the author's name", SearchQuery{Terms: "marcus"}, 0.5, true},
+		{"Repeated word and prefix", SearchQuery{Terms: "be medit"}, 2.5, true},
+		{"Every word has to match", SearchQuery{Terms: "time seneca"}, 0, false},
+		{"Phrase", SearchQuery{Phrases: []string{"a good man"}}, 1.0, true},
+		{"Phrase out of order", SearchQuery{Phrases: []string{"man good"}}, 0, false},
+		{"Phrase words are whole words", SearchQuery{Phrases: []string{"good ma"}}, 0, false},
+		{"Excluded word prefix", SearchQuery{Terms: "time", Excluded: []string{"argu"}}, 0, false},
+		{"Excluded phrase", SearchQuery{Terms: "time", Excluded: []string{"bad man"}}, 1.0, true},
+		{"Author name", SearchQuery{AuthorName: "AURELIUS"}, 0, true},
+		{"Other author name", SearchQuery{AuthorName: "Seneca"}, 0, false},
+		{"Book title", SearchQuery{Terms: "time", BookTitle: "medit"}, 1.0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rank, ok := tt.query.Rank(q)
+			assert.Equal(t, rank, tt.wantRank)
+			assert.Equal(t, ok, tt.wantOK)
+		})
+	}
+}
+
+func TestSearchQueryMatches(t *testing.T) {
+	owner := uuid.New()
+	other := uuid.New()
+	public := Quote{AuthorID: 1, BookID: 1, UserID: owner, Visibility: VisibilityPublic, Book: Book{ID: 1, PublishYear: 65, CalendarTime: "B.C."}}
+	private := Quote{AuthorID: 1, BookID: 1, UserID: owner, Visibility: VisibilityPrivate, Book: Book{ID: 1, PublishYear: 180, CalendarTime: "A.D."}}
+	unlisted := Quote{AuthorID: 1, BookID: 1, UserID: owner, Visibility: VisibilityUnlisted}
+
+	// Set up a tests struct
+	tests := []struct {
+		name  string
+		query SearchQuery
+		quote Quote
+		want  bool
+	}{
+		{"Public quote", SearchQuery{}, public, true},
+		{"Private quote of another user", SearchQuery{UserID: other}, private, false},
+		{"Own private quote", SearchQuery{UserID: owner}, private, true},
+		{"Public scope hides own private quote", SearchQuery{Scope: ScopePublic, UserID: owner}, private, false},
+		{"Unlisted quote of another user", SearchQuery{UserID: other}, unlisted, false},
+		{"Own unlisted quote", SearchQuery{UserID: owner}, unlisted, true},
+		{"Unlisted scope", SearchQuery{Scope: ScopeUnlisted, UserID: owner}, unlisted, true},
+		{"Unlisted scope hides private quotes", SearchQuery{Scope: ScopeUnlisted, UserID: owner}, private, false},
+		{"Mine scope hides other users' quotes", SearchQuery{Scope: ScopeMine, UserID: other}, public, false},
+		{"Mine scope when logged out", SearchQuery{Scope: ScopeMine}, public, false},
+		{"Other author", SearchQuery{AuthorID: 2}, public, false},
+		{"Other book", SearchQuery{BookID: 2}, public, false},
+		{"B.C. year in range", SearchQuery{YearFrom: -100, YearTo: -50}, public, true},
+		{"B.C. year before range", SearchQuery{YearFrom: 1}, public, false},
+		{"A.D. year after range", SearchQuery{YearTo: 100, UserID: owner}, private, false},
+		{"Year range without a book", SearchQuery{YearFrom: 1}, Quote{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.query.Matches(tt.quote), tt.want)
+		})
+	}
+}
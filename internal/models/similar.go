@@ -0,0 +1,181 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// The most suggestions offered for a new author or book
+const maxSuggestions = 5
+
+// Folds the accented Latin letters found in names and titles into plain ones
+var diacritics = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "ā", "a", "ă", "a", "ą", "a",
+	"æ", "ae", "ç", "c", "ć", "c", "č", "c", "ď", "d", "đ", "d", "ð", "d",
+	"è", "e", "é", "e", "ê", "e", "ë", "e", "ē", "e", "ė", "e", "ę", "e", "ě", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ī", "i", "į", "i", "ı", "i",
+	"ł", "l", "ľ", "l", "ñ", "n", "ń", "n", "ň", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "ō", "o", "ő", "o", "œ", "oe",
+	"ŕ", "r", "ř", "r", "ś", "s", "š", "s", "ş", "s", "ß", "ss", "ť", "t", "ţ", "t", "þ", "th",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ū", "u", "ů", "u", "ű", "u", "ų", "u",
+	"ý", "y", "ÿ", "y", "ź", "z", "ż", "z", "ž", "z",
+)
+
+// NormalizeName folds an author's name or a book's title for comparison: it is
+// lower cased, its accents are dropped, and anything but letters and digits
+// becomes a single space, so "Sénèque, L. A." normalizes to "seneque l a".
+func NormalizeName(s string) string {
+	return strings.Join(nameWords(s), " ")
+}
+
+// Splits a name into its normalized words
+func nameWords(s string) []string {
+	s = diacritics.Replace(strings.ToLower(s))
+	// Apostrophes join the parts of a word, as in O'Brien
+	s = strings.NewReplacer("'", "", "’", "").Replace(s)
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// SimilarName reports whether two author names or book titles probably name
+// the same author or book. They are similar when they are the same once
+// normalized, when they are a typo or two apart, or when the words of the
+// shorter one appear in order in the longer one, ending with the same word.
+// Within that last comparison a single letter matches any word it is the
+// initial of, so "Seneca", "L. A. Seneca" and "Lucius Annaeus Seneca" are all
+// similar, while "John Smith" and "Adam Smith" are not.
+func SimilarName(a, b string) bool {
+	wa, wb := nameWords(a), nameWords(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		return false
+	}
+
+	// The same name with a few letters changed
+	na, nb := strings.Join(wa, " "), strings.Join(wb, " ")
+	if editDistance(na, nb) <= allowedEdits(min(len([]rune(na)), len([]rune(nb)))) {
+		return true
+	}
+
+	// The shorter name leaves out or abbreviates some of the longer one's words
+	if len(wa) > len(wb) {
+		wa, wb = wb, wa
+	}
+	if !similarWord(wa[len(wa)-1], wb[len(wb)-1], false) {
+		return false
+	}
+	i := 0
+	for _, w := range wb[:len(wb)-1] {
+		if i < len(wa)-1 && similarWord(wa[i], w, true) {
+			i++
+		}
+	}
+	return i == len(wa)-1
+}
+
+// Reports whether two normalized words match, allowing a typo in long words
+// and, if initials is set, a single letter for a word starting with it
+func similarWord(a, b string, initials bool) bool {
+	if a == b {
+		return true
+	}
+	ra, rb := []rune(a), []rune(b)
+	if initials && (len(ra) == 1 && ra[0] == rb[0] || len(rb) == 1 && rb[0] == ra[0]) {
+		return true
+	}
+	return editDistance(a, b) <= allowedEdits(min(len(ra), len(rb)))
+}
+
+// The typos allowed between two names, which grows with the shorter name's length
+func allowedEdits(length int) int {
+	switch {
+	case length < 5:
+		return 0
+	case length < 10:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Returns the Levenshtein distance between two strings: the fewest letters
+// inserted, deleted or replaced to turn one into the other
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// SimilarAuthors returns the authors whose names are similar to name, closest
+// first, so that adding an author who is already there can be caught
+func SimilarAuthors(name string, authors []Author) []Author {
+	return rankSimilar(name, authors, func(a Author) string { return a.Name }, nil)
+}
+
+// SimilarBooks returns the books whose titles are similar to title or which
+// have the same ISBN, closest first, so that adding a book which is already
+// there can be caught
+func SimilarBooks(title, isbn string, books []Book) []Book {
+	isbn = isbnDigits(isbn)
+	return rankSimilar(title, books, func(b Book) string { return b.Title }, func(b Book) bool {
+		return isbn != "" && isbnDigits(b.ISBN) == isbn
+	})
+}
+
+// Keeps only the digits and check character of an ISBN, so hyphenated and
+// spaced ISBNs compare equal
+func isbnDigits(isbn string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) || r == 'X' || r == 'x' {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, isbn)
+}
+
+// Returns up to maxSuggestions items whose key is similar to name, or which
+// match exactly if exact is set, ordered by how close their keys are to name
+func rankSimilar[T any](name string, items []T, key func(T) string, exact func(T) bool) []T {
+	type match struct {
+		item     T
+		distance int
+	}
+
+	normalized := NormalizeName(name)
+	var matches []match
+	for _, item := range items {
+		k := key(item)
+		switch {
+		case exact != nil && exact(item):
+			matches = append(matches, match{item, -1})
+		case SimilarName(name, k):
+			matches = append(matches, match{item, editDistance(normalized, NormalizeName(k))})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int {
+		return a.distance - b.distance
+	})
+
+	similar := make([]T, 0, min(len(matches), maxSuggestions))
+	for _, m := range matches[:min(len(matches), maxSuggestions)] {
+		similar = append(similar, m.item)
+	}
+	return similar
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"Case", "SENECA", "seneca"},
+		{"Diacritics", "Sénèque", "seneque"},
+		{"Punctuation and spaces", "  J.R.R.  Tolkien, ", "j r r tolkien"},
+		{"Apostrophes", "Flannery O’Connor", "flannery oconnor"},
+		{"Ligatures", "Ælfric", "aelfric"},
+		{"Blank", " .,- ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, NormalizeName(tt.input), tt.want)
+		})
+	}
+}
+
+func TestSimilarName(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want bool
+	}{
+		{"Case", "Seneca", "seneca", true},
+		{"Diacritics", "Simone de Beauvoir", "Simone de Beauvóir", true},
+		{"Typo", "Marcus Aurelius", "Marcus Aurelis", true},
+		{"Two typos in a long name", "Friedrich Nietzsche", "Fredrich Nietzche", true},
+		{"Missing first names", "Seneca", "Lucius Annaeus Seneca", true},
+		{"Initials", "J. R. R. Tolkien", "John Ronald Reuel Tolkien", true},
+		{"Joined initials", "JRR Tolkien", "J.R.R. Tolkien", true},
+		{"Leading article", "Meditations", "The Meditations", true},
+		{"Different first name", "John Smith", "Adam Smith", false},
+		{"Different last name", "Seneca", "Seneca the Younger", false},
+		{"An initial isn't a last name", "Tolkien J", "Tolkien John", false},
+		{"A typo in a short name", "Plato", "Pluto", true},
+		{"Very short names need to be exact", "Zeno", "Xeno", false},
+		{"Different names", "Epictetus", "Marcus Aurelius", false},
+		{"Blank", "", "Seneca", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, SimilarName(tt.a, tt.b), tt.want)
+			assert.Equal(t, SimilarName(tt.b, tt.a), tt.want)
+		})
+	}
+}
+
+func TestSimilarAuthors(t *testing.T) {
+	authors := []Author{
+		{ID: 1, Name: "Lucius Annaeus Seneca"},
+		{ID: 2, Name: "Marcus Aurelius"},
+		{ID: 3, Name: "Seneca"},
+		{ID: 4, Name: "Senecca"},
+	}
+
+	// The closest names come first, and dissimilar ones are left out
+	similar := SimilarAuthors("seneca", authors)
+	assert.Equal(t, len(similar), 3)
+	assert.Equal(t, similar[0].ID, 3)
+	assert.Equal(t, similar[1].ID, 4)
+	assert.Equal(t, similar[2].ID, 1)
+
+	assert.Equal(t, len(SimilarAuthors("Epictetus", authors)), 0)
+}
+
+func TestSimilarBooks(t *testing.T) {
+	books := []Book{
+		{ID: 1, Title: "Meditations", ISBN: "9780140449334"},
+		{ID: 2, Title: "Letters from a Stoic", ISBN: "9780140442106"},
+	}
+
+	// A similar title
+	similar := SimilarBooks("The Meditations", "", books)
+	assert.Equal(t, len(similar), 1)
+	assert.Equal(t, similar[0].ID, 1)
+
+	// The same ISBN, however it is written, under another title
+	similar = SimilarBooks("Moral Letters", "978-0-14-044210-6", books)
+	assert.Equal(t, len(similar), 1)
+	assert.Equal(t, similar[0].ID, 2)
+
+	assert.Equal(t, len(SimilarBooks("On the Shortness of Life", "9780143036326", books)), 0)
+}
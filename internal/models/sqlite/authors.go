@@ -0,0 +1,487 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// The ORDER BY clause for each author sort order. Authors have no creation
+// time, so newest and oldest go by ID.
+var authorOrders = map[string]string{
+	models.SortAuthor:     `a.name, a.id`,
+	models.SortNewest:     `a.id DESC`,
+	models.SortOldest:     `a.id`,
+	models.SortMostQuoted: `COUNT(q.id) DESC, a.name, a.id`,
+}
+
+// AuthorModel implements models.AuthorModelInterface on a SQLite database
+type AuthorModel struct {
+	DB       *sql.DB
+	Timeouts models.Timeouts
+}
+
+// The columns selected for an author, in the order scanAuthor reads them
+const authorColumns = `a.id, a.name, a.user_id, COALESCE(a.bio, ''), COALESCE(a.birth_year, 0),
+	COALESCE(a.birth_calendar_time, ''), COALESCE(a.death_year, 0), COALESCE(a.death_calendar_time, ''),
+	COALESCE(a.nationality, ''), COALESCE(a.occupation, ''), a.links`
+
+// Scans an author row selected with authorColumns
+func scanAuthor(row scanner, extra ...any) (models.Author, error) {
+	var a models.Author
+	var userID uuid.NullUUID
+	var links string
+
+	dest := []any{&a.ID, &a.Name, &userID, &a.Bio, &a.BirthYear, &a.BirthCalendarTime, &a.DeathYear,
+		&a.DeathCalendarTime, &a.Nationality, &a.Occupation, &links}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return models.Author{}, err
+	}
+
+	a.UserID = userID.UUID
+	a.Links = splitLinks(links)
+	return a, nil
+}
+
+// The reference links of an author are stored one per line
+func joinLinks(links []string) string {
+	return strings.Join(links, "\n")
+}
+
+// Splits the stored reference links of an author, which has none when empty
+func splitLinks(links string) []string {
+	if links == "" {
+		return nil
+	}
+	return strings.Split(links, "\n")
+}
+
+// Insert adds a new author with their details, created by the given user
+func (m *AuthorModel) Insert(ctx context.Context, name string, details models.AuthorDetails, userID uuid.UUID) (int, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	stmt := `INSERT INTO authors (name, bio, birth_year, birth_calendar_time, death_year, death_calendar_time,
+		nationality, occupation, links, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+
+	var id int
+	err := m.DB.QueryRowContext(ctx, stmt, name, details.Bio, details.BirthYear, details.BirthCalendarTime, details.DeathYear,
+		details.DeathCalendarTime, details.Nationality, details.Occupation, joinLinks(details.Links), userID).Scan(&id)
+	if err != nil {
+		return 0, mapError(err)
+	}
+
+	return id, nil
+}
+
+// Get a single author by ID
+func (m *AuthorModel) Get(ctx context.Context, id int) (models.Author, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	a, err := scanAuthor(m.DB.QueryRowContext(ctx, `SELECT `+authorColumns+` FROM authors a WHERE a.id = $1`, id))
+	if err != nil {
+		return models.Author{}, mapError(err)
+	}
+
+	return a, nil
+}
+
+// Get a single author by name, or the author with an alias of that name,
+// preferring the oldest when names repeat
+func (m *AuthorModel) GetByName(ctx context.Context, name string) (models.Author, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	stmt := `SELECT ` + authorColumns + ` FROM authors a
+		WHERE a.name = $1 OR a.id = (SELECT aa.author_id FROM author_aliases aa WHERE aa.name = $1)
+		ORDER BY a.name = $1 DESC, a.id LIMIT 1`
+
+	a, err := scanAuthor(m.DB.QueryRowContext(ctx, stmt, name))
+	if err != nil {
+		return models.Author{}, mapError(err)
+	}
+
+	return a, nil
+}
+
+// Get the books an author is credited on in any role, ordered by title
+func (m *AuthorModel) GetBooksByAuthor(ctx context.Context, authorID int) ([]models.Book, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	stmt := `SELECT ` + bookColumns + ` FROM books b
+		WHERE EXISTS (SELECT true FROM book_contributors c WHERE c.book_id = b.id AND c.author_id = $1)
+		ORDER BY b.title, b.id`
+
+	rows, err := m.DB.QueryContext(ctx, stmt, authorID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	books := []models.Book{}
+	for rows.Next() {
+		b, err := scanBook(rows)
+		if err != nil {
+			return nil, mapError(err)
+		}
+		books = append(books, b)
+	}
+
+	return books, mapError(rows.Err())
+}
+
+// Get quotes by author, ordered by the quote text
+func (m *AuthorModel) GetQuotesByAuthor(ctx context.Context, authorID int) ([]models.Quote, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	stmt := `SELECT ` + quoteColumns + ` FROM quotes q WHERE q.author_id = $1 AND ` + visibleTo("q", 2) + ` ORDER BY q.quote`
+
+	rows, err := m.DB.QueryContext(ctx, stmt, authorID, models.Viewer(ctx))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	quotes := []models.Quote{}
+	for rows.Next() {
+		q, err := scanQuote(rows)
+		if err != nil {
+			return nil, mapError(err)
+		}
+		quotes = append(quotes, q)
+	}
+
+	return quotes, mapError(rows.Err())
+}
+
+// Update an author's name and details by ID
+func (m *AuthorModel) Update(ctx context.Context, id int, name string, details models.AuthorDetails) (int, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	stmt := `UPDATE authors SET name = $1, bio = $2, birth_year = $3, birth_calendar_time = $4, death_year = $5,
+		death_calendar_time = $6, nationality = $7, occupation = $8, links = $9
+		WHERE id = $10 RETURNING id`
+
+	var updatedID int
+	err := m.DB.QueryRowContext(ctx, stmt, name, details.Bio, details.BirthYear, details.BirthCalendarTime, details.DeathYear,
+		details.DeathCalendarTime, details.Nationality, details.Occupation, joinLinks(details.Links), id).Scan(&updatedID)
+	if err != nil {
+		return 0, mapError(err)
+	}
+
+	return updatedID, nil
+}
+
+// Delete an author by ID, refusing with models.ErrAuthorHasQuotes while
+// quotes still refer to them and models.ErrAuthorHasBooks while they are
+// credited on books or translated editions of them
+func (m *AuthorModel) Delete(ctx context.Context, id int) error {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	return inTx(ctx, m.DB, func(tx *sql.Tx) error {
+		// Check for quotes inside the transaction so none can be added in between
+		var hasQuotes bool
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT true FROM quotes WHERE author_id = $1)`, id).Scan(&hasQuotes)
+		if err != nil {
+			return err
+		}
+		if hasQuotes {
+			return models.ErrAuthorHasQuotes
+		}
+
+		var hasBooks bool
+		err = tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT true FROM book_contributors WHERE author_id = $1)
+			OR EXISTS(SELECT true FROM editions WHERE translator_id = $1)`, id).Scan(&hasBooks)
+		if err != nil {
+			return err
+		}
+		if hasBooks {
+			return models.ErrAuthorHasBooks
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM authors WHERE id = $1`, id)
+		return err
+	})
+}
+
+// Merge moves every quote, alias, book credit and translation of the author
+// fromID to the author intoID and deletes fromID in a single transaction, returning the
+// number of quotes moved
+func (m *AuthorModel) Merge(ctx context.Context, fromID, intoID int) (int, error) {
+	if fromID == intoID {
+		return 0, models.ErrMergeSameAuthor
+	}
+
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	var moved int64
+	err := inTx(ctx, m.DB, func(tx *sql.Tx) error {
+		// Check both authors exist before moving anything
+		var found int
+		err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM authors WHERE id IN ($1, $2)`, fromID, intoID).Scan(&found)
+		if err != nil {
+			return err
+		}
+		if found < 2 {
+			return models.ErrNoRecord
+		}
+
+		result, err := tx.ExecContext(ctx, `UPDATE quotes SET author_id = $1 WHERE author_id = $2`, intoID, fromID)
+		if err != nil {
+			return err
+		}
+		moved, err = result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		// Keep the author's aliases, which now belong to the author merged into
+		_, err = tx.ExecContext(ctx, `UPDATE author_aliases SET author_id = $1 WHERE author_id = $2`, intoID, fromID)
+		if err != nil {
+			return err
+		}
+
+		// Move the author's book credits, dropping the ones intoID already has
+		stmt := `UPDATE book_contributors SET author_id = $1 WHERE author_id = $2 AND NOT EXISTS (
+			SELECT true FROM book_contributors c
+			WHERE c.book_id = book_contributors.book_id AND c.role = book_contributors.role AND c.author_id = $1
+		)`
+		_, err = tx.ExecContext(ctx, stmt, intoID, fromID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM book_contributors WHERE author_id = $1`, fromID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE editions SET translator_id = $1 WHERE translator_id = $2`, intoID, fromID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM authors WHERE id = $1`, fromID)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(moved), nil
+}
+
+// Get the aliases of an author, ordered by name
+func (m *AuthorModel) GetAliases(ctx context.Context, authorID int) ([]models.AuthorAlias, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, `SELECT id, author_id, name FROM author_aliases WHERE author_id = $1 ORDER BY name, id`, authorID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	aliases := []models.AuthorAlias{}
+	for rows.Next() {
+		var a models.AuthorAlias
+		err := rows.Scan(&a.ID, &a.AuthorID, &a.Name)
+		if err != nil {
+			return nil, mapError(err)
+		}
+		aliases = append(aliases, a)
+	}
+
+	return aliases, mapError(rows.Err())
+}
+
+// AddAlias gives an author another name, returning the alias's ID. It returns
+// models.ErrNoRecord if the author doesn't exist and models.ErrDuplicateAlias
+// if another alias already has the name.
+func (m *AuthorModel) AddAlias(ctx context.Context, authorID int, name string) (int, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	// Insert nothing, and so return no rows, when the author doesn't exist
+	stmt := `INSERT INTO author_aliases (author_id, name)
+		SELECT id, $2 FROM authors WHERE id = $1 RETURNING id`
+
+	var id int
+	err := m.DB.QueryRowContext(ctx, stmt, authorID, name).Scan(&id)
+	if err != nil {
+		var sqliteErr *sqlite.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE && strings.Contains(sqliteErr.Error(), "author_aliases.name") {
+			return 0, models.ErrDuplicateAlias
+		}
+		return 0, mapError(err)
+	}
+
+	return id, nil
+}
+
+// DeleteAlias removes one of an author's aliases, returning models.ErrNoRecord
+// if the author has no alias with the ID
+func (m *AuthorModel) DeleteAlias(ctx context.Context, authorID, aliasID int) error {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, `DELETE FROM author_aliases WHERE id = $1 AND author_id = $2`, aliasID, authorID)
+	if err != nil {
+		return mapError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return mapError(err)
+	}
+	if rows == 0 {
+		return models.ErrNoRecord
+	}
+
+	return nil
+}
+
+// Check if an author exists by ID
+func (m *AuthorModel) Exists(ctx context.Context, id int) (bool, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	var exists bool
+	err := m.DB.QueryRowContext(ctx, `SELECT EXISTS(SELECT true FROM authors WHERE id = $1)`, id).Scan(&exists)
+	return exists, mapError(err)
+}
+
+// Get all authors
+func (m *AuthorModel) GetAll(ctx context.Context) ([]models.Author, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, `SELECT `+authorColumns+` FROM authors a ORDER BY a.id`)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	authors := []models.Author{}
+	for rows.Next() {
+		a, err := scanAuthor(rows)
+		if err != nil {
+			return nil, mapError(err)
+		}
+		authors = append(authors, a)
+	}
+
+	return authors, mapError(rows.Err())
+}
+
+// The aggregate columns selected for an author's quote count and the number of books they are credited on
+const authorCountColumns = authorColumns + `, COUNT(q.id),
+	(SELECT COUNT(DISTINCT c.book_id) FROM book_contributors c WHERE c.author_id = a.id)`
+
+// GetWithCounts returns an author with their quote and book counts
+func (m *AuthorModel) GetWithCounts(ctx context.Context, id int) (models.Author, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	stmt := `SELECT ` + authorCountColumns + ` FROM authors a
+		LEFT JOIN quotes q ON q.author_id = a.id AND ` + visibleTo("q", 2) + `
+		WHERE a.id = $1
+		GROUP BY a.id`
+
+	var quoteCount, bookCount int
+	a, err := scanAuthor(m.DB.QueryRowContext(ctx, stmt, id, models.Viewer(ctx)), &quoteCount, &bookCount)
+	if err != nil {
+		return models.Author{}, mapError(err)
+	}
+
+	a.QuoteCount = quoteCount
+	a.BookCount = bookCount
+	return a, nil
+}
+
+// GetAllWithCounts returns all authors with their quote and book counts
+func (m *AuthorModel) GetAllWithCounts(ctx context.Context) ([]models.AuthorWithCounts, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	stmt := `SELECT ` + authorCountColumns + ` FROM authors a
+		LEFT JOIN quotes q ON q.author_id = a.id AND ` + visibleTo("q", 1) + `
+		GROUP BY a.id
+		ORDER BY a.name`
+
+	rows, err := m.DB.QueryContext(ctx, stmt, models.Viewer(ctx))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	authors := []models.AuthorWithCounts{}
+	for rows.Next() {
+		var quoteCount, bookCount int
+		a, err := scanAuthor(rows, &quoteCount, &bookCount)
+		if err != nil {
+			return nil, mapError(err)
+		}
+
+		a.QuoteCount = quoteCount
+		a.BookCount = bookCount
+		authors = append(authors, models.AuthorWithCounts{Author: a, QuoteCount: quoteCount, BookCount: bookCount})
+	}
+
+	return authors, mapError(rows.Err())
+}
+
+// ListWithCounts returns a page of authors with their quote and book counts, ordered by name unless another order is asked for
+func (m *AuthorModel) ListWithCounts(ctx context.Context, filters models.Filters) ([]models.AuthorWithCounts, models.Metadata, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	order, ok := authorOrders[filters.Sort]
+	if !ok {
+		order = authorOrders[models.SortAuthor]
+	}
+
+	stmt := `SELECT ` + authorCountColumns + `, count(*) OVER() FROM authors a
+		LEFT JOIN quotes q ON q.author_id = a.id AND ` + visibleTo("q", 3) + `
+		GROUP BY a.id
+		ORDER BY ` + order + ` LIMIT $1 OFFSET $2`
+
+	rows, err := m.DB.QueryContext(ctx, stmt, filters.Limit(), filters.Offset(), models.Viewer(ctx))
+	if err != nil {
+		return nil, models.Metadata{}, mapError(err)
+	}
+	defer rows.Close()
+
+	total := 0
+	authors := []models.AuthorWithCounts{}
+	for rows.Next() {
+		var quoteCount, bookCount int
+		a, err := scanAuthor(rows, &quoteCount, &bookCount, &total)
+		if err != nil {
+			return nil, models.Metadata{}, mapError(err)
+		}
+
+		a.QuoteCount = quoteCount
+		a.BookCount = bookCount
+		authors = append(authors, models.AuthorWithCounts{Author: a, QuoteCount: quoteCount, BookCount: bookCount})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.Metadata{}, mapError(err)
+	}
+
+	metadata, err := pageMetadata(ctx, m.DB, filters, total, `SELECT count(*) FROM authors`)
+	return authors, metadata, err
+}
@@ -0,0 +1,423 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// The book columns selected by every book query
+const bookColumns = `b.id, b.title, COALESCE(b.publish_year, 0), COALESCE(b.calendar_time, ''),
+	COALESCE(b.isbn, ''), COALESCE(b.source, ''), b.user_id, b.created_at, b.updated_at,
+	b.media_type, COALESCE(b.doi, ''), COALESCE(b.venue, ''), COALESCE(b.work_date, ''), COALESCE(b.page_count, 0)`
+
+// The ORDER BY clause for each book sort order, with books without a known
+// author last. Only the most quoted order counts quotes, with the viewer as $3.
+var bookOrders = map[string]string{
+	models.SortTitle:      `b.title, b.id`,
+	models.SortNewest:     `b.created_at DESC, b.id DESC`,
+	models.SortOldest:     `b.created_at, b.id`,
+	models.SortAuthor:     `a.name NULLS LAST, b.title, b.id`,
+	models.SortMostQuoted: `(SELECT count(*) FROM quotes qc WHERE qc.book_id = b.id AND ` + visibleTo("qc", 3) + `) DESC, b.title, b.id`,
+}
+
+// BookModel implements models.BookModelInterface on a SQLite database
+type BookModel struct {
+	DB       *sql.DB
+	Timeouts models.Timeouts
+}
+
+// Scans a row selected with bookColumns into a book
+func scanBook(row scanner, extra ...any) (models.Book, error) {
+	var b models.Book
+	var userID uuid.NullUUID
+
+	dest := []any{&b.ID, &b.Title, &b.PublishYear, &b.CalendarTime, &b.ISBN, &b.Source, &userID, &b.CreatedAt, &b.UpdatedAt,
+		&b.MediaType, &b.DOI, &b.Venue, &b.Date, &b.PageCount}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	b.UserID = userID.UUID
+	return b, nil
+}
+
+// Scans a row selected with bookColumns followed by the book's first author
+func scanBookWithAuthor(row scanner, extra ...any) (models.Book, error) {
+	var authorID sql.NullInt64
+	var authorName sql.NullString
+	var authorUserID uuid.NullUUID
+
+	b, err := scanBook(row, append([]any{&authorID, &authorName, &authorUserID}, extra...)...)
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	// A book without a credited author has no known author
+	b.Author = models.Author{ID: 0, Name: "Unknown"}
+	if authorID.Valid {
+		b.Author = models.Author{ID: int(authorID.Int64), Name: authorName.String, UserID: authorUserID.UUID}
+	}
+
+	return b, nil
+}
+
+// Joins the first author credited on each book, which is the book's author
+const bookAuthorJoin = `LEFT JOIN authors a ON a.id = (
+		SELECT c.author_id FROM book_contributors c
+		WHERE c.book_id = b.id AND c.role = 'author'
+		ORDER BY c.position LIMIT 1
+	)`
+
+// Runs a book query selecting bookColumns and the first author and scans every row
+func (m *BookModel) queryBooksWithAuthors(ctx context.Context, stmt string, args ...any) ([]models.Book, error) {
+	rows, err := m.DB.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	books := []models.Book{}
+	for rows.Next() {
+		b, err := scanBookWithAuthor(rows)
+		if err != nil {
+			return nil, mapError(err)
+		}
+		books = append(books, b)
+	}
+
+	return books, mapError(rows.Err())
+}
+
+// Loads the quotes the viewer can see for each of the given books with a single query
+func (m *BookModel) attachQuotes(ctx context.Context, books []models.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	// Index the books by ID and build a placeholder for each one
+	args := []any{}
+	placeholders := make([]string, len(books))
+	index := make(map[int]int, len(books))
+	for i, b := range books {
+		args = append(args, b.ID)
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+		index[b.ID] = i
+		books[i].Quotes = []models.Quote{}
+	}
+
+	stmt := `SELECT ` + quoteColumns + ` FROM quotes q
+		WHERE q.book_id IN (` + strings.Join(placeholders, ", ") + `) AND ` + visibleTo("q", len(args)+1) + `
+		ORDER BY q.id`
+	args = append(args, models.Viewer(ctx))
+
+	rows, err := m.DB.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return mapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		q, err := scanQuote(rows)
+		if err != nil {
+			return mapError(err)
+		}
+		i := index[q.BookID]
+		books[i].Quotes = append(books[i].Quotes, q)
+	}
+
+	return mapError(rows.Err())
+}
+
+// Loads the contributors of each of the given books, in the order they are
+// credited, with a single query
+func (m *BookModel) attachContributors(ctx context.Context, books []models.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	// Index the books by ID and build a placeholder for each one
+	args := make([]any, len(books))
+	placeholders := make([]string, len(books))
+	index := make(map[int]int, len(books))
+	for i, b := range books {
+		args[i] = b.ID
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+		index[b.ID] = i
+		books[i].Contributors = nil
+	}
+
+	stmt := `SELECT ` + authorColumns + `, c.book_id, c.role FROM book_contributors c
+		JOIN authors a ON a.id = c.author_id
+		WHERE c.book_id IN (` + strings.Join(placeholders, ", ") + `)
+		ORDER BY c.book_id, c.position`
+
+	rows, err := m.DB.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return mapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bookID int
+		var role models.ContributorRole
+		a, err := scanAuthor(rows, &bookID, &role)
+		if err != nil {
+			return mapError(err)
+		}
+		i := index[bookID]
+		books[i].Contributors = append(books[i].Contributors, models.BookContributor{AuthorID: a.ID, Role: role, Author: a})
+	}
+
+	return mapError(rows.Err())
+}
+
+// Replaces the contributors of a book, crediting them in the order given
+func setContributors(ctx context.Context, tx *sql.Tx, bookID int, contributors []models.BookContributor) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM book_contributors WHERE book_id = $1`, bookID)
+	if err != nil {
+		return err
+	}
+
+	for i, c := range contributors {
+		_, err = tx.ExecContext(ctx, `INSERT INTO book_contributors (book_id, author_id, role, position) VALUES ($1, $2, $3, $4)`,
+			bookID, c.AuthorID, c.Role, i)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Insert adds a new book owned by the given user, crediting its contributors
+// in the order given. It returns models.ErrDuplicateISBN if another book
+// already has the ISBN.
+func (m *BookModel) Insert(ctx context.Context, title string, publishYear int, calendarTime string, isbn string, source string, details models.WorkDetails, contributors []models.BookContributor, userID uuid.UUID) (int, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	stmt := `INSERT INTO books (title, publish_year, calendar_time, isbn, source, media_type, doi, venue, work_date, page_count,
+		user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, NULLIF($10, 0), $11, $12, $12) RETURNING id`
+
+	var id int
+	err := inTx(ctx, m.DB, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, stmt, title, publishYear, calendarTime, isbn, source, details.Kind(), details.DOI, details.Venue, details.Date, details.PageCount, userID, time.Now().UTC()).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		return setContributors(ctx, tx, id, contributors)
+	})
+	if isDuplicateISBN(err) {
+		return 0, models.ErrDuplicateISBN
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Get a single book by ID with its quotes, author and contributors
+func (m *BookModel) Get(ctx context.Context, id int) (models.Book, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	stmt := `SELECT ` + bookColumns + `, a.id, a.name, a.user_id FROM books b ` + bookAuthorJoin + ` WHERE b.id = $1`
+
+	b, err := scanBookWithAuthor(m.DB.QueryRowContext(ctx, stmt, id))
+	if err != nil {
+		return models.Book{}, mapError(err)
+	}
+
+	books := []models.Book{b}
+	err = m.attachContributors(ctx, books)
+	if err != nil {
+		return models.Book{}, err
+	}
+	err = m.attachQuotes(ctx, books)
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	return books[0], nil
+}
+
+// Get the books an author is credited on in any role, ordered by title, each
+// with its contributors and quotes
+func (m *BookModel) GetByAuthorID(ctx context.Context, authorID int) ([]models.Book, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	stmt := `SELECT ` + bookColumns + `, a.id, a.name, a.user_id FROM books b ` + bookAuthorJoin + `
+		WHERE EXISTS (SELECT true FROM book_contributors c WHERE c.book_id = b.id AND c.author_id = $1)
+		ORDER BY b.title, b.id`
+
+	books, err := m.queryBooksWithAuthors(ctx, stmt, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.attachContributors(ctx, books)
+	if err != nil {
+		return nil, err
+	}
+	err = m.attachQuotes(ctx, books)
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+// Get a page of books with their authors, ordered by title unless another order is asked for
+func (m *BookModel) GetAllWithAuthors(ctx context.Context, filters models.Filters) ([]models.Book, models.Metadata, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	order, ok := bookOrders[filters.Sort]
+	if !ok {
+		order = bookOrders[models.SortTitle]
+	}
+
+	stmt := `SELECT ` + bookColumns + `, a.id, a.name, a.user_id, count(*) OVER() FROM books b ` + bookAuthorJoin + `
+		ORDER BY ` + order + ` LIMIT $1 OFFSET $2`
+
+	// Only counting the quotes needs the viewer
+	args := []any{filters.Limit(), filters.Offset()}
+	if filters.Sort == models.SortMostQuoted {
+		args = append(args, models.Viewer(ctx))
+	}
+
+	rows, err := m.DB.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, models.Metadata{}, mapError(err)
+	}
+	defer rows.Close()
+
+	total := 0
+	books := []models.Book{}
+	for rows.Next() {
+		b, err := scanBookWithAuthor(rows, &total)
+		if err != nil {
+			return nil, models.Metadata{}, mapError(err)
+		}
+		books = append(books, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.Metadata{}, mapError(err)
+	}
+
+	metadata, err := pageMetadata(ctx, m.DB, filters, total, `SELECT count(*) FROM books`)
+	return books, metadata, err
+}
+
+// Update a book by ID, replacing its contributors with the ones given. It
+// returns models.ErrDuplicateISBN if another book already has the ISBN.
+func (m *BookModel) Update(ctx context.Context, id int, title string, publishYear int, calendarTime string, isbn string, source string, details models.WorkDetails, contributors []models.BookContributor) error {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	stmt := `UPDATE books SET title = $1, publish_year = $2, calendar_time = $3, isbn = NULLIF($4, ''), source = $5,
+		media_type = $6, doi = $7, venue = $8, work_date = $9, page_count = NULLIF($10, 0), updated_at = $11
+		WHERE id = $12`
+
+	err := inTx(ctx, m.DB, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, stmt, title, publishYear, calendarTime, isbn, source, details.Kind(), details.DOI, details.Venue, details.Date, details.PageCount, time.Now().UTC(), id)
+		if err != nil {
+			return err
+		}
+
+		return setContributors(ctx, tx, id, contributors)
+	})
+	if isDuplicateISBN(err) {
+		return models.ErrDuplicateISBN
+	}
+	return err
+}
+
+// Delete a book by ID along with its credits and editions, refusing with
+// models.ErrBookHasQuotes while quotes still refer to it
+func (m *BookModel) Delete(ctx context.Context, id int) error {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	return inTx(ctx, m.DB, func(tx *sql.Tx) error {
+		// Check for quotes inside the transaction so none can be added in between
+		var hasQuotes bool
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT true FROM quotes WHERE book_id = $1)`, id).Scan(&hasQuotes)
+		if err != nil {
+			return err
+		}
+		if hasQuotes {
+			return models.ErrBookHasQuotes
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM books WHERE id = $1`, id)
+		return err
+	})
+}
+
+// Get all books
+func (m *BookModel) GetAll(ctx context.Context) ([]models.Book, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, `SELECT `+bookColumns+` FROM books b ORDER BY b.id`)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	books := []models.Book{}
+	for rows.Next() {
+		b, err := scanBook(rows)
+		if err != nil {
+			return nil, mapError(err)
+		}
+		books = append(books, b)
+	}
+
+	return books, mapError(rows.Err())
+}
+
+// Check if the book exists
+func (m *BookModel) Exists(ctx context.Context, id int) (bool, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	var exists bool
+	err := m.DB.QueryRowContext(ctx, `SELECT EXISTS(SELECT true FROM books WHERE id = $1)`, id).Scan(&exists)
+	return exists, mapError(err)
+}
+
+// GetByISBN returns the book with the ISBN, without its contributors or
+// quotes, or models.ErrNoRecord if no book has it
+func (m *BookModel) GetByISBN(ctx context.Context, isbn string) (models.Book, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	b, err := scanBook(m.DB.QueryRowContext(ctx, `SELECT `+bookColumns+` FROM books b WHERE b.isbn = $1`, isbn))
+	if err != nil {
+		return models.Book{}, mapError(err)
+	}
+
+	return b, nil
+}
+
+// Reports whether err is from adding a book with the ISBN of another book
+func isDuplicateISBN(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE && strings.Contains(sqliteErr.Error(), "books.isbn")
+}
@@ -0,0 +1,164 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// The edition columns selected by every edition query, followed by its translator
+const editionColumns = `e.id, e.book_id, COALESCE(e.isbn, ''), COALESCE(e.publisher, ''), COALESCE(e.language, ''),
+	COALESCE(e.translator_id, 0), COALESCE(e.publish_year, 0), COALESCE(e.page_count, 0), e.user_id, e.created_at, e.updated_at,
+	COALESCE(t.name, ''), t.user_id`
+
+// Joins the author who translated each edition, if anyone did
+const editionTranslatorJoin = `LEFT JOIN authors t ON t.id = e.translator_id`
+
+// Editions are ordered by their book, then oldest first with unknown years last
+const editionOrder = `ORDER BY e.book_id, e.publish_year NULLS LAST, e.id`
+
+// EditionModel implements models.EditionModelInterface on a SQLite database
+type EditionModel struct {
+	DB       *sql.DB
+	Timeouts models.Timeouts
+}
+
+// Scans a row selected with editionColumns into an edition with its translator
+func scanEdition(row scanner) (models.Edition, error) {
+	var e models.Edition
+	var userID, translatorUserID uuid.NullUUID
+
+	err := row.Scan(&e.ID, &e.BookID, &e.ISBN, &e.Publisher, &e.Language, &e.TranslatorID, &e.PublishYear, &e.PageCount,
+		&userID, &e.CreatedAt, &e.UpdatedAt, &e.Translator.Name, &translatorUserID)
+	if err != nil {
+		return models.Edition{}, err
+	}
+
+	e.UserID = userID.UUID
+	if e.TranslatorID != 0 {
+		e.Translator.ID = e.TranslatorID
+		e.Translator.UserID = translatorUserID.UUID
+	}
+	return e, nil
+}
+
+// Runs an edition query and scans every row
+func (m *EditionModel) queryEditions(ctx context.Context, stmt string, args ...any) ([]models.Edition, error) {
+	rows, err := m.DB.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	editions := []models.Edition{}
+	for rows.Next() {
+		e, err := scanEdition(rows)
+		if err != nil {
+			return nil, mapError(err)
+		}
+		editions = append(editions, e)
+	}
+
+	return editions, mapError(rows.Err())
+}
+
+// Insert adds an edition of a book owned by the given user. It returns
+// models.ErrDuplicateISBN if another edition already has the ISBN.
+func (m *EditionModel) Insert(ctx context.Context, bookID int, details models.EditionDetails, userID uuid.UUID) (int, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	stmt := `INSERT INTO editions (book_id, isbn, publisher, language, translator_id, publish_year, page_count,
+		user_id, created_at, updated_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, 0), NULLIF($6, 0), NULLIF($7, 0), $8, $9, $9)
+		RETURNING id`
+
+	var id int
+	err := m.DB.QueryRowContext(ctx, stmt, bookID, details.ISBN, details.Publisher, details.Language, details.TranslatorID,
+		details.PublishYear, details.PageCount, userID, time.Now().UTC()).Scan(&id)
+	if isDuplicateEditionISBN(err) {
+		return 0, models.ErrDuplicateISBN
+	}
+	if err != nil {
+		return 0, mapError(err)
+	}
+
+	return id, nil
+}
+
+// Get a single edition by ID with its translator
+func (m *EditionModel) Get(ctx context.Context, id int) (models.Edition, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	stmt := `SELECT ` + editionColumns + ` FROM editions e ` + editionTranslatorJoin + ` WHERE e.id = $1`
+	e, err := scanEdition(m.DB.QueryRowContext(ctx, stmt, id))
+	return e, mapError(err)
+}
+
+// GetByBookID returns the editions of a book with their translators, oldest first
+func (m *EditionModel) GetByBookID(ctx context.Context, bookID int) ([]models.Edition, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	stmt := `SELECT ` + editionColumns + ` FROM editions e ` + editionTranslatorJoin + ` WHERE e.book_id = $1 ` + editionOrder
+	return m.queryEditions(ctx, stmt, bookID)
+}
+
+// GetByISBN returns the edition with the ISBN, or models.ErrNoRecord if none has it
+func (m *EditionModel) GetByISBN(ctx context.Context, isbn string) (models.Edition, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	stmt := `SELECT ` + editionColumns + ` FROM editions e ` + editionTranslatorJoin + ` WHERE e.isbn = $1`
+	e, err := scanEdition(m.DB.QueryRowContext(ctx, stmt, isbn))
+	return e, mapError(err)
+}
+
+// GetAll returns every edition with its translator, ordered by book and then oldest first
+func (m *EditionModel) GetAll(ctx context.Context) ([]models.Edition, error) {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Read)
+	defer cancel()
+
+	return m.queryEditions(ctx, `SELECT `+editionColumns+` FROM editions e `+editionTranslatorJoin+` `+editionOrder)
+}
+
+// Update an edition by ID. It returns models.ErrDuplicateISBN if another
+// edition already has the ISBN.
+func (m *EditionModel) Update(ctx context.Context, id int, details models.EditionDetails) error {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	stmt := `UPDATE editions SET isbn = NULLIF($1, ''), publisher = NULLIF($2, ''), language = NULLIF($3, ''),
+		translator_id = NULLIF($4, 0), publish_year = NULLIF($5, 0), page_count = NULLIF($6, 0), updated_at = $7
+		WHERE id = $8`
+
+	_, err := m.DB.ExecContext(ctx, stmt, details.ISBN, details.Publisher, details.Language, details.TranslatorID,
+		details.PublishYear, details.PageCount, time.Now().UTC(), id)
+	if isDuplicateEditionISBN(err) {
+		return models.ErrDuplicateISBN
+	}
+	return mapError(err)
+}
+
+// Delete an edition by ID. Its quotes stay with the book, without an edition.
+func (m *EditionModel) Delete(ctx context.Context, id int) error {
+	ctx, cancel := withTimeout(ctx, m.Timeouts.Write)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, `DELETE FROM editions WHERE id = $1`, id)
+	return mapError(err)
+}
+
+// Reports whether err is from giving an edition the ISBN of another edition
+func isDuplicateEditionISBN(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE && strings.Contains(sqliteErr.Error(), "editions.isbn")
+}
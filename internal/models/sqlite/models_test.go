@@ -0,0 +1,21 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/justinbachtell/quote-table-go/internal/models/modeltest"
+)
+
+// Runs the tests every backend must pass against a SQLite database
+func TestModels(t *testing.T) {
+	modeltest.Run(t, func(t *testing.T) modeltest.Models {
+		db := newTestDB(t)
+		return modeltest.Models{
+			Quotes:   &QuoteModel{DB: db},
+			Authors:  &AuthorModel{DB: db},
+			Books:    &BookModel{DB: db},
+			Editions: &EditionModel{DB: db},
+			Users:    &UserModel{DB: db},
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+	"github.com/justinbachtell/quote-table-go/internal/models"
+)
+
+// The user that owns the test data
+var testUserID = uuid.MustParse("a3a6e2b6-7e64-4c3e-9a3b-7d0a3f7f9d11")
+
+func TestQuoteModelInsert(t *testing.T) {
+	db := newTestDB(t)
+	m := QuoteModel{DB: db}
+
+	// Insert a quote for the test user
+	_, err := m.Insert(context.Background(), "You have power over your mind.", 1, 1, 0, models.Location{Type: models.LocationPage, Start: 5}, models.VisibilityPublic, testUserID)
+	assert.NilError(t, err)
+
+	// Check the user's last quote added at timestamp was set in the same transaction
+	var set bool
+	err = db.QueryRow(`SELECT last_quote_added_at IS NOT NULL FROM users WHERE id = $1`, testUserID).Scan(&set)
+	assert.NilError(t, err)
+	assert.Equal(t, set, true)
+}
@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/justinbachtell/quote-table-go/internal/migrate"
+	"github.com/justinbachtell/quote-table-go/migrations"
+)
+
+// Creates a migrated database file in a temporary directory and loads the test data
+func newTestDB(t *testing.T) *sql.DB {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Close the connection after the test, before the directory is removed
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	// Create the tables with the embedded migrations
+	files, err := fs.Sub(migrations.Files, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := migrate.New(db, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = migrator.Up(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Insert the test data
+	script, err := os.ReadFile("./testdata/setup.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(string(script))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
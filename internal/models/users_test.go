@@ -1,22 +1,19 @@
 package models
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/google/uuid"
 )
 
 func TestUserModelExists(t *testing.T) {
-    // Skip the test if the "-short" flag is passed
-	if testing.Short() {
-        t.Skip("models: skipping integration tests in short mode")
-    }
-
     // Create a new test database
 	db := newTestDatabase(t)
     
 	// Create a new UserModel instance
-	m := UserModel{Client: db}
+	m := UserModel{AuthClient: db}
 
     // Clean up before and after tests
     cleanupTestUsers(t, db)
@@ -54,7 +51,7 @@ func TestUserModelExists(t *testing.T) {
         // Run each test case
 		t.Run(tt.name, func(t *testing.T) {
             // Check if the user exists
-			exists, err := m.Exists(tt.userID)
+			exists, err := m.Exists(context.Background(), tt.userID)
             if err != nil {
                 t.Fatalf("Error in Exists method: %v", err)
             }
@@ -63,4 +60,117 @@ func TestUserModelExists(t *testing.T) {
             }
         })
     }
-}
\ No newline at end of file
+}
+func TestUserModelInsert(t *testing.T) {
+	// Create a new test database
+	db := newTestDatabase(t)
+
+	// Create a new UserModel instance
+	m := UserModel{AuthClient: db}
+
+	// Insert a new user
+	id, err := m.Insert(context.Background(), "Jane Doe", "jane.doe@example.com", "password123")
+	if err != nil {
+		t.Fatalf("Failed to insert user: %v", err)
+	}
+	if id == uuid.Nil {
+		t.Errorf("got nil ID for inserted user")
+	}
+
+	// Inserting the same email again should be rejected by the unique constraint
+	_, err = m.Insert(context.Background(), "Jane Again", "jane.doe@example.com", "password123")
+	if !errors.Is(err, ErrDuplicateEmail) {
+		t.Errorf("got error %v, want %v", err, ErrDuplicateEmail)
+	}
+}
+
+func TestUserModelAuthenticate(t *testing.T) {
+	// Create a new test database
+	db := newTestDatabase(t)
+
+	// Create a new UserModel instance
+	m := UserModel{AuthClient: db}
+
+	// Insert a user to authenticate as
+	id, err := m.Insert(context.Background(), "Jane Doe", "jane.doe@example.com", "password123")
+	if err != nil {
+		t.Fatalf("Failed to insert user: %v", err)
+	}
+
+	// Define test cases
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantID   uuid.UUID
+		wantErr  error
+	}{
+		{"Valid credentials", "jane.doe@example.com", "password123", id, nil},
+		{"Wrong password", "jane.doe@example.com", "wrong-password", uuid.Nil, ErrInvalidCredentials},
+		{"Unknown email", "nobody@example.com", "password123", uuid.Nil, ErrInvalidCredentials},
+	}
+
+	// Loop through each test case
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotID, err := m.Authenticate(context.Background(), tt.email, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if gotID != tt.wantID {
+				t.Errorf("got ID %v, want %v", gotID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestUserModelSetRole(t *testing.T) {
+	// Create a new test database
+	db := newTestDatabase(t)
+
+	// Create a new UserModel instance
+	m := UserModel{AuthClient: db}
+
+	// Insert a user to change the role of
+	id, err := m.Insert(context.Background(), "Jane Doe", "jane.doe@example.com", "password123")
+	if err != nil {
+		t.Fatalf("Failed to insert user: %v", err)
+	}
+
+	// Check users start with the user role
+	user, err := m.GetByEmail(context.Background(), "jane.doe@example.com")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if user.ID != id || user.Role != RoleUser {
+		t.Errorf("got user %v with role %q, want %v with role %q", user.ID, user.Role, id, RoleUser)
+	}
+
+	// Check the new role is read back
+	err = m.SetRole(context.Background(), id, RoleAdmin)
+	if err != nil {
+		t.Fatalf("Failed to set role: %v", err)
+	}
+
+	user, err = m.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if user.Role != RoleAdmin {
+		t.Errorf("got role %q, want %q", user.Role, RoleAdmin)
+	}
+
+	// Check unknown users are reported
+	err = m.SetRole(context.Background(), uuid.New(), RoleAdmin)
+	if !errors.Is(err, ErrNoRecord) {
+		t.Errorf("got error %v, want %v", err, ErrNoRecord)
+	}
+	_, err = m.Get(context.Background(), uuid.New())
+	if !errors.Is(err, ErrNoRecord) {
+		t.Errorf("got error %v, want %v", err, ErrNoRecord)
+	}
+	_, err = m.GetByEmail(context.Background(), "nobody@example.com")
+	if !errors.Is(err, ErrNoRecord) {
+		t.Errorf("got error %v, want %v", err, ErrNoRecord)
+	}
+}
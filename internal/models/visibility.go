@@ -0,0 +1,76 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/supabase-community/postgrest-go"
+)
+
+// Visibility says who can see a quote
+type Visibility string
+
+const (
+	// Anyone can see a public quote, and it is listed and searchable
+	VisibilityPublic Visibility = "public"
+	// An unlisted quote is only listed for the user who added it, but anyone
+	// with its share link can read it
+	VisibilityUnlisted Visibility = "unlisted"
+	// Only the user who added a private quote can see it
+	VisibilityPrivate Visibility = "private"
+)
+
+// The visibilities a quote can have, in the order they are offered
+var Visibilities = []Visibility{VisibilityPublic, VisibilityUnlisted, VisibilityPrivate}
+
+// Valid reports whether v is one of the known visibilities
+func (v Visibility) Valid() bool {
+	return slices.Contains(Visibilities, v)
+}
+
+// The context key holding the user reading through the models
+type viewerContextKey struct{}
+
+// WithViewer returns a copy of ctx that reads quotes as the given user. Every
+// read of the models, counts included, only sees public quotes and the
+// viewer's own quotes, so a context without a viewer sees public quotes alone.
+func WithViewer(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, viewerContextKey{}, userID)
+}
+
+// Viewer returns the user reading quotes with ctx, or uuid.Nil when nobody is logged in
+func Viewer(ctx context.Context) uuid.UUID {
+	id, _ := ctx.Value(viewerContextKey{}).(uuid.UUID)
+	return id
+}
+
+// CanView reports whether a user can see a quote: anyone can see a public
+// quote, but only the user who added an unlisted or private quote can see it
+// without its share link
+func CanView(userID uuid.UUID, q Quote) bool {
+	return q.Visibility == VisibilityPublic || (userID != uuid.Nil && q.UserID == userID)
+}
+
+// CanShare reports whether a quote can be read through its share link. A
+// private quote's link stops working until the quote is shared again.
+func CanShare(q Quote) bool {
+	return q.ShareToken != "" && q.Visibility != VisibilityPrivate
+}
+
+// NewShareToken returns a random token for a quote's share link
+func NewShareToken() (string, error) {
+	b := make([]byte, 18)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Starts a PostgREST select of the quotes a user can see
+func selectVisibleQuotes(client *postgrest.Client, userID uuid.UUID, columns, count string) *postgrest.FilterBuilder {
+	return client.From("quotes").Select(columns, count, false).Or("visibility.eq.public,user_id.eq."+userID.String(), "")
+}
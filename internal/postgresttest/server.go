@@ -0,0 +1,980 @@
+// Package postgresttest provides an in-process fake of the PostgREST api
+// behind Supabase, for testing the models without a running database.
+//
+// The fake understands the subset of PostgREST used by the models: select
+// with column lists, the eq, neq, gt, gte, lt, lte, is and in filters and
+// or groups of them, order, limit and offset, single objects (with PGRST116 errors when the
+// result is not exactly one row), insert, update and delete with
+// return=representation, Prefer: count=exact, and deletes cascading to the
+// rows that refer to the deleted ones. Postgres functions called through
+// /rpc are stood in for by Go functions registered with Server.Handle, which
+// can read and change the tables.
+package postgresttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The path the Supabase client puts in front of every table
+const restPath = "/rest/v1/"
+
+// The content type the client asks for when it wants a single object
+const objectMediaType = "application/vnd.pgrst.object+json"
+
+// A Row is a table row as it is sent over the wire
+type Row = map[string]any
+
+// Table describes a table of the fake database
+type Table struct {
+	// Name of the table
+	Name string
+
+	// Columns lists every column of the table. When set, requests naming
+	// any other column fail and rows are returned with every column.
+	Columns []string
+
+	// UUIDKey gives new rows a random UUID id instead of the next integer
+	UUIDKey bool
+
+	// Unique maps a column to the name of the unique constraint on it
+	Unique map[string]string
+
+	// Defaults returns the value of a column that an insert leaves out
+	Defaults map[string]func() any
+
+	// Cascade maps another table to its column referring to this table's id.
+	// Deleting a row also deletes the rows referring to it, like a foreign
+	// key with ON DELETE CASCADE.
+	Cascade map[string]string
+}
+
+// Now is a column default returning the current time, like now() in Postgres
+func Now() any {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+// Holds the rows and the key sequence of a table
+type table struct {
+	Table
+	rows   []Row
+	lastID int64
+}
+
+// A Function stands in for a Postgres function called through /rpc/<name>.
+// It gets the JSON arguments of the call and the tables, and returns the rows
+// of its result or an error to report.
+type Function func(args Row, db *DB) ([]Row, error)
+
+// DB is the view of the tables a Function gets. Like a Postgres function
+// running in a transaction, the changes it makes are only kept when the
+// function succeeds.
+type DB struct {
+	tables  map[string]*table
+	changed map[string][]Row
+}
+
+// Rows returns a copy of every row in a table, including the function's changes
+func (db *DB) Rows(tableName string) []Row {
+	if rows, ok := db.changed[tableName]; ok {
+		return copyRows(rows)
+	}
+	if t, ok := db.tables[tableName]; ok {
+		return copyRows(t.rows)
+	}
+	return nil
+}
+
+// SetRows replaces every row in a table
+func (db *DB) SetRows(tableName string, rows []Row) error {
+	if _, ok := db.tables[tableName]; !ok {
+		return fmt.Errorf("postgresttest: table %q does not exist", tableName)
+	}
+
+	// Round trip the rows through JSON so they hold the same types as rows sent over the wire
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+	rows = nil
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return err
+	}
+
+	db.changed[tableName] = rows
+	return nil
+}
+
+// Server is a fake PostgREST server
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	tables    map[string]*table
+	functions map[string]Function
+	requests  int
+}
+
+// NewServer starts a fake PostgREST server holding the given empty tables.
+// Requests for any other table fail like they would for a missing relation.
+func NewServer(tables ...Table) *Server {
+	s := &Server{tables: make(map[string]*table), functions: make(map[string]Function)}
+	for _, t := range tables {
+		s.tables[t.Name] = &table{Table: t}
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Handle serves fn as the Postgres function with the given name
+func (s *Server) Handle(name string, fn Function) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.functions[name] = fn
+}
+
+// Insert adds rows to a table directly, filling in keys and defaults, and returns the stored rows
+func (s *Server) Insert(tableName string, rows ...Row) ([]Row, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tables[tableName]
+	if !ok {
+		return nil, fmt.Errorf("postgresttest: table %q does not exist", tableName)
+	}
+
+	// Round trip the rows through JSON so they hold the same types as rows sent over the wire
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return nil, err
+	}
+	rows = nil
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+
+	inserted, perr := t.insert(rows)
+	if perr != nil {
+		return nil, fmt.Errorf("postgresttest: %s", perr.Message)
+	}
+
+	return inserted, nil
+}
+
+// Rows returns a copy of every row in a table
+func (s *Server) Rows(tableName string) []Row {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tables[tableName]
+	if !ok {
+		return nil
+	}
+
+	return copyRows(t.rows)
+}
+
+// Requests returns the number of requests the server has handled
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+// ResetRequests sets the request count back to zero
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = 0
+}
+
+// An error in the format PostgREST responds with
+type pgrstError struct {
+	status  int
+	Code    string  `json:"code"`
+	Message string  `json:"message"`
+	Details string  `json:"details"`
+	Hint    *string `json:"hint"`
+}
+
+// Writes a PostgREST error response
+func writeError(w http.ResponseWriter, perr *pgrstError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(perr.status)
+	json.NewEncoder(w).Encode(perr)
+}
+
+// Routes a request to the handler for its method
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+
+	// Find the table
+	tableName, ok := strings.CutPrefix(r.URL.Path, restPath)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if name, ok := strings.CutPrefix(tableName, "rpc/"); ok {
+		s.serveFunction(w, r, name)
+		return
+	}
+	t, ok := s.tables[tableName]
+	if !ok {
+		writeError(w, &pgrstError{status: http.StatusNotFound, Code: "42P01",
+			Message: fmt.Sprintf("relation \"public.%s\" does not exist", tableName)})
+		return
+	}
+
+	q, perr := parseQuery(r)
+	if perr == nil {
+		perr = t.checkQuery(q)
+	}
+	if perr != nil {
+		writeError(w, perr)
+		return
+	}
+
+	var rows []Row
+	var total int
+	status := http.StatusOK
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		rows, total = t.selectRows(q)
+
+	case http.MethodPost:
+		var body []Row
+		body, perr = readBody(r)
+		if perr == nil {
+			perr = t.checkBody(body)
+		}
+		if perr == nil {
+			rows, perr = t.insert(body)
+			total = len(rows)
+			status = http.StatusCreated
+		}
+
+	case http.MethodPatch:
+		var body []Row
+		body, perr = readBody(r)
+		if perr == nil && len(body) != 1 {
+			perr = &pgrstError{status: http.StatusBadRequest, Code: "PGRST102", Message: "Expected a single object to update"}
+		}
+		if perr == nil {
+			perr = t.checkBody(body)
+		}
+		if perr == nil {
+			rows, perr = t.update(q, body[0])
+			total = len(rows)
+		}
+
+	case http.MethodDelete:
+		rows = t.delete(q)
+		total = len(rows)
+		s.cascade(t, rows)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if perr != nil {
+		writeError(w, perr)
+		return
+	}
+
+	s.writeRows(w, r, q, rows, total, status)
+}
+
+// Calls a function with the arguments in the request body and writes its rows.
+// The caller must hold the lock.
+func (s *Server) serveFunction(w http.ResponseWriter, r *http.Request, name string) {
+	fn, ok := s.functions[name]
+	if !ok || r.Method != http.MethodPost {
+		writeError(w, &pgrstError{status: http.StatusNotFound, Code: "PGRST202",
+			Message: fmt.Sprintf("Could not find the function public.%s in the schema cache", name)})
+		return
+	}
+
+	args, perr := readBody(r)
+	if perr == nil && len(args) != 1 {
+		perr = &pgrstError{status: http.StatusBadRequest, Code: "PGRST102", Message: "Expected a single object of arguments"}
+	}
+	if perr != nil {
+		writeError(w, perr)
+		return
+	}
+
+	db := &DB{tables: s.tables, changed: make(map[string][]Row)}
+	rows, err := fn(args[0], db)
+	if err != nil {
+		writeError(w, &pgrstError{status: http.StatusBadRequest, Code: "P0001", Message: err.Error()})
+		return
+	}
+
+	// Keep the function's changes now it has succeeded
+	for tableName, changed := range db.changed {
+		s.tables[tableName].rows = changed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rows)
+}
+
+// Deletes the rows of other tables that refer to the deleted rows through a
+// cascading reference, and in turn the rows referring to those
+func (s *Server) cascade(t *table, deleted []Row) {
+	if len(deleted) == 0 {
+		return
+	}
+
+	ids := make(map[string]bool, len(deleted))
+	for _, row := range deleted {
+		ids[fmt.Sprint(row["id"])] = true
+	}
+
+	for name, column := range t.Cascade {
+		child, ok := s.tables[name]
+		if !ok {
+			continue
+		}
+
+		kept := child.rows[:0]
+		removed := []Row{}
+		for _, row := range child.rows {
+			if ids[fmt.Sprint(row[column])] {
+				removed = append(removed, row)
+			} else {
+				kept = append(kept, row)
+			}
+		}
+		child.rows = kept
+
+		s.cascade(child, removed)
+	}
+}
+
+// Writes the rows as the representation the request asked for
+func (s *Server) writeRows(w http.ResponseWriter, r *http.Request, q query, rows []Row, total int, status int) {
+	// A single object must be exactly one row
+	single := strings.Contains(r.Header.Get("Accept"), objectMediaType)
+	if single && len(rows) != 1 {
+		writeError(w, &pgrstError{status: http.StatusNotAcceptable, Code: "PGRST116",
+			Message: "JSON object requested, multiple (or no) rows returned",
+			Details: fmt.Sprintf("The result contains %d rows", len(rows))})
+		return
+	}
+
+	// Report the total in the Content-Range header when a count was asked for
+	rangeStart := "*"
+	if len(rows) > 0 && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+		rangeStart = fmt.Sprintf("%d-%d", q.offset, q.offset+len(rows)-1)
+	}
+	rangeTotal := "*"
+	if preference(r, "count") == "exact" {
+		rangeTotal = strconv.Itoa(total)
+	}
+	w.Header().Set("Content-Range", rangeStart+"/"+rangeTotal)
+
+	// Writes return nothing unless the representation was asked for
+	if r.Method != http.MethodGet && r.Method != http.MethodHead && preference(r, "return") != "representation" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	projected := make([]Row, len(rows))
+	for i, row := range rows {
+		projected[i] = q.project(row)
+	}
+
+	if single {
+		json.NewEncoder(w).Encode(projected[0])
+		return
+	}
+	json.NewEncoder(w).Encode(projected)
+}
+
+// Returns the value of a Prefer header setting such as count or return
+func preference(r *http.Request, name string) string {
+	for _, header := range r.Header.Values("Prefer") {
+		for _, setting := range strings.Split(header, ",") {
+			key, value, ok := strings.Cut(strings.TrimSpace(setting), "=")
+			if ok && key == name {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// Reads a JSON object or array of objects from the request body
+func readBody(r *http.Request) ([]Row, *pgrstError) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, &pgrstError{status: http.StatusBadRequest, Code: "PGRST102", Message: err.Error()}
+	}
+
+	var rows []Row
+	if len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &rows)
+	} else {
+		var row Row
+		err = json.Unmarshal(data, &row)
+		rows = []Row{row}
+	}
+	if err != nil {
+		return nil, &pgrstError{status: http.StatusBadRequest, Code: "PGRST102", Message: "Empty or invalid json"}
+	}
+
+	return rows, nil
+}
+
+// Reports whether the table has a column, which is always true when the columns aren't listed
+func (t *table) hasColumn(column string) bool {
+	if len(t.Columns) == 0 {
+		return true
+	}
+	for _, c := range t.Columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks the columns a query selects, filters and orders by exist
+func (t *table) checkQuery(q query) *pgrstError {
+	columns := append([]string{}, q.columns...)
+	for _, f := range q.filters {
+		if f.operator == "or" {
+			for _, alternative := range f.anyOf {
+				columns = append(columns, alternative.column)
+			}
+			continue
+		}
+		columns = append(columns, f.column)
+	}
+	for _, o := range q.order {
+		columns = append(columns, o.column)
+	}
+
+	for _, column := range columns {
+		if !t.hasColumn(column) {
+			return &pgrstError{status: http.StatusBadRequest, Code: "42703",
+				Message: fmt.Sprintf("column %s.%s does not exist", t.Name, column)}
+		}
+	}
+	return nil
+}
+
+// Checks the columns of the rows in a request body exist
+func (t *table) checkBody(rows []Row) *pgrstError {
+	for _, row := range rows {
+		for column := range row {
+			if !t.hasColumn(column) {
+				return &pgrstError{status: http.StatusBadRequest, Code: "PGRST204",
+					Message: fmt.Sprintf("Could not find the '%s' column of '%s' in the schema cache", column, t.Name)}
+			}
+		}
+	}
+	return nil
+}
+
+// Inserts rows, filling in keys and defaults and checking unique constraints
+func (t *table) insert(rows []Row) ([]Row, *pgrstError) {
+	inserted := make([]Row, 0, len(rows))
+	for _, row := range rows {
+		row = copyRow(row)
+
+		// Fill in the primary key
+		if _, ok := row["id"]; !ok {
+			if t.UUIDKey {
+				row["id"] = uuid.NewString()
+			} else {
+				t.lastID++
+				row["id"] = float64(t.lastID)
+			}
+		} else if id, ok := row["id"].(float64); ok && int64(id) > t.lastID {
+			t.lastID = int64(id)
+		}
+
+		// Fill in the defaults of the missing columns
+		for column, value := range t.Defaults {
+			if _, ok := row[column]; !ok {
+				row[column] = value()
+			}
+		}
+
+		// Give the columns left out a null value
+		for _, column := range t.Columns {
+			if _, ok := row[column]; !ok {
+				row[column] = nil
+			}
+		}
+
+		// Check the key and unique constraints against the stored and pending rows
+		if perr := t.checkUnique(row, append(t.rows, inserted...), nil); perr != nil {
+			return nil, perr
+		}
+
+		inserted = append(inserted, row)
+	}
+
+	t.rows = append(t.rows, inserted...)
+	return copyRows(inserted), nil
+}
+
+// Checks a row against the unique columns of the other rows, skipping the row being replaced
+func (t *table) checkUnique(row Row, others []Row, replacing Row) *pgrstError {
+	unique := map[string]string{"id": t.Name + "_pkey"}
+	for column, constraint := range t.Unique {
+		unique[column] = constraint
+	}
+
+	for column, constraint := range unique {
+		for _, other := range others {
+			if sameRow(other, replacing) || row[column] == nil {
+				continue
+			}
+			if compare(other[column], row[column]) == 0 {
+				return &pgrstError{status: http.StatusConflict, Code: "23505",
+					Message: fmt.Sprintf("duplicate key value violates unique constraint \"%s\"", constraint),
+					Details: fmt.Sprintf("Key (%s)=(%s) already exists.", column, format(row[column]))}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns the rows matching the filters, ordered and paged, and the total before paging
+func (t *table) selectRows(q query) ([]Row, int) {
+	rows := []Row{}
+	for _, row := range t.rows {
+		if q.matches(row) {
+			rows = append(rows, row)
+		}
+	}
+	total := len(rows)
+
+	q.sort(rows)
+
+	// Apply the offset and limit
+	if q.offset >= len(rows) {
+		return []Row{}, total
+	}
+	rows = rows[q.offset:]
+	if q.limit >= 0 && q.limit < len(rows) {
+		rows = rows[:q.limit]
+	}
+
+	return copyRows(rows), total
+}
+
+// Merges the values into the rows matching the filters
+func (t *table) update(q query, values Row) ([]Row, *pgrstError) {
+	updated := []Row{}
+	for i, row := range t.rows {
+		if !q.matches(row) {
+			continue
+		}
+
+		merged := copyRow(row)
+		for column, value := range values {
+			merged[column] = value
+		}
+
+		if perr := t.checkUnique(merged, t.rows, row); perr != nil {
+			return nil, perr
+		}
+
+		t.rows[i] = merged
+		updated = append(updated, merged)
+	}
+
+	return copyRows(updated), nil
+}
+
+// Removes the rows matching the filters
+func (t *table) delete(q query) []Row {
+	kept := t.rows[:0]
+	deleted := []Row{}
+	for _, row := range t.rows {
+		if q.matches(row) {
+			deleted = append(deleted, row)
+		} else {
+			kept = append(kept, row)
+		}
+	}
+	t.rows = kept
+
+	return deleted
+}
+
+// A filter on a single column, or an or group of filters that any may pass
+type filter struct {
+	column   string
+	operator string
+	value    string
+	values   []string
+	anyOf    []filter
+}
+
+// An ordering on a single column
+type ordering struct {
+	column     string
+	descending bool
+	nullsFirst bool
+}
+
+// The parsed query string of a request
+type query struct {
+	columns []string
+	filters []filter
+	order   []ordering
+	limit   int
+	offset  int
+}
+
+// The query string parameters that are not filters
+var reservedParams = map[string]bool{"select": true, "order": true, "limit": true, "offset": true, "on_conflict": true, "columns": true}
+
+// Parses the select, filters, order, limit and offset of a request
+func parseQuery(r *http.Request) (query, *pgrstError) {
+	params := r.URL.Query()
+	q := query{limit: -1}
+
+	// Parse the column list
+	if columns := params.Get("select"); columns != "" && columns != "*" {
+		q.columns = strings.Split(columns, ",")
+	}
+
+	// Parse the filters
+	for column, values := range params {
+		if reservedParams[column] {
+			continue
+		}
+		for _, value := range values {
+			var f filter
+			var perr *pgrstError
+			if column == "or" {
+				f, perr = parseOr(value)
+			} else {
+				f, perr = parseFilter(column, value)
+			}
+			if perr != nil {
+				return q, perr
+			}
+			q.filters = append(q.filters, f)
+		}
+	}
+
+	// Parse the ordering
+	if order := params.Get("order"); order != "" {
+		for _, term := range strings.Split(order, ",") {
+			parts := strings.Split(term, ".")
+			o := ordering{column: parts[0]}
+			for _, modifier := range parts[1:] {
+				switch modifier {
+				case "asc":
+				case "desc":
+					o.descending = true
+				case "nullsfirst":
+					o.nullsFirst = true
+				case "nullslast":
+					o.nullsFirst = false
+				default:
+					return q, badRequest("failed to parse order (%s)", order)
+				}
+			}
+			q.order = append(q.order, o)
+		}
+	}
+
+	// Parse the paging
+	var err error
+	if limit := params.Get("limit"); limit != "" {
+		q.limit, err = strconv.Atoi(limit)
+		if err != nil || q.limit < 0 {
+			return q, badRequest("failed to parse limit (%s)", limit)
+		}
+	}
+	if offset := params.Get("offset"); offset != "" {
+		q.offset, err = strconv.Atoi(offset)
+		if err != nil || q.offset < 0 {
+			return q, badRequest("failed to parse offset (%s)", offset)
+		}
+	}
+
+	return q, nil
+}
+
+// Parses a filter like eq.1 on a column
+func parseFilter(column, value string) (filter, *pgrstError) {
+	operator, operand, ok := strings.Cut(value, ".")
+	if !ok {
+		return filter{}, badRequest("failed to parse filter (%s)", value)
+	}
+
+	f := filter{column: column, operator: operator, value: operand}
+	switch operator {
+	case "eq", "neq", "gt", "gte", "lt", "lte", "is":
+	case "in":
+		list, ok := strings.CutPrefix(operand, "(")
+		list, ok2 := strings.CutSuffix(list, ")")
+		if !ok || !ok2 {
+			return filter{}, badRequest("failed to parse filter (%s)", value)
+		}
+		f.values = splitList(list)
+	default:
+		return filter{}, badRequest("unknown filter operator %q", operator)
+	}
+	return f, nil
+}
+
+// Parses an or group like (is_private.eq.false,user_id.eq.1) of column filters
+func parseOr(value string) (filter, *pgrstError) {
+	list, ok := strings.CutPrefix(value, "(")
+	list, ok2 := strings.CutSuffix(list, ")")
+	if !ok || !ok2 {
+		return filter{}, badRequest("failed to parse logic tree (%s)", value)
+	}
+
+	f := filter{operator: "or"}
+	for _, term := range splitList(list) {
+		column, rest, ok := strings.Cut(term, ".")
+		if !ok {
+			return filter{}, badRequest("failed to parse logic tree (%s)", value)
+		}
+		alternative, perr := parseFilter(column, rest)
+		if perr != nil {
+			return filter{}, perr
+		}
+		f.anyOf = append(f.anyOf, alternative)
+	}
+	return f, nil
+}
+
+// Returns a PGRST100 parse error
+func badRequest(format string, args ...any) *pgrstError {
+	return &pgrstError{status: http.StatusBadRequest, Code: "PGRST100", Message: fmt.Sprintf(format, args...)}
+}
+
+// Splits an in.(...) list on commas outside double quotes
+func splitList(list string) []string {
+	var values []string
+	var current strings.Builder
+	quoted := false
+	for _, c := range list {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			values = append(values, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(c)
+		}
+	}
+	return append(values, current.String())
+}
+
+// Reports whether a row passes every filter
+func (q query) matches(row Row) bool {
+	for _, f := range q.filters {
+		if !f.matches(row) {
+			return false
+		}
+	}
+	return true
+}
+
+// Reports whether a row passes a filter
+func (f filter) matches(row Row) bool {
+	value := row[f.column]
+
+	switch f.operator {
+	case "or":
+		for _, alternative := range f.anyOf {
+			if alternative.matches(row) {
+				return true
+			}
+		}
+		return false
+	case "eq":
+		if value == nil || format(value) != f.value {
+			return false
+		}
+	case "neq":
+		if value == nil || format(value) == f.value {
+			return false
+		}
+	case "gt", "gte", "lt", "lte":
+		if value == nil {
+			return false
+		}
+		c := compare(value, parseLike(value, f.value))
+		if (f.operator == "gt" && c <= 0) || (f.operator == "gte" && c < 0) ||
+			(f.operator == "lt" && c >= 0) || (f.operator == "lte" && c > 0) {
+			return false
+		}
+	case "is":
+		if format(value) != f.value {
+			return false
+		}
+	case "in":
+		found := false
+		for _, v := range f.values {
+			if value != nil && format(value) == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Sorts rows by the ordering, keeping insertion order for ties
+func (q query) sort(rows []Row) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, o := range q.order {
+			a, b := rows[i][o.column], rows[j][o.column]
+
+			// Place nulls first or last regardless of direction
+			if a == nil || b == nil {
+				if a == nil && b == nil {
+					continue
+				}
+				return (a == nil) == o.nullsFirst
+			}
+
+			c := compare(a, b)
+			if c == 0 {
+				continue
+			}
+			if o.descending {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+// Returns the row with only the selected columns
+func (q query) project(row Row) Row {
+	if len(q.columns) == 0 {
+		return row
+	}
+
+	projected := make(Row, len(q.columns))
+	for _, column := range q.columns {
+		projected[column] = row[column]
+	}
+	return projected
+}
+
+// Formats a JSON value the way it appears in a filter
+func format(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		return v
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// Parses a filter operand as the same JSON type as the column value
+func parseLike(value any, operand string) any {
+	if _, ok := value.(float64); ok {
+		if f, err := strconv.ParseFloat(operand, 64); err == nil {
+			return f
+		}
+	}
+	return operand
+}
+
+// Compares two non-null JSON values of the same type
+func compare(a, b any) int {
+	switch av := a.(type) {
+	case float64:
+		if bv, ok := b.(float64); ok {
+			switch {
+			case av < bv:
+				return -1
+			case av > bv:
+				return 1
+			}
+			return 0
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			switch {
+			case av == bv:
+				return 0
+			case !av:
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(format(a), format(b))
+}
+
+// Reports whether two rows are the same stored row
+func sameRow(a, b Row) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	return fmt.Sprintf("%p", a) == fmt.Sprintf("%p", b)
+}
+
+// Returns a shallow copy of a row
+func copyRow(row Row) Row {
+	copied := make(Row, len(row))
+	for k, v := range row {
+		copied[k] = v
+	}
+	return copied
+}
+
+// Returns shallow copies of rows
+func copyRows(rows []Row) []Row {
+	copied := make([]Row, len(rows))
+	for i, row := range rows {
+		copied[i] = copyRow(row)
+	}
+	return copied
+}
@@ -0,0 +1,296 @@
+package postgresttest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/justinbachtell/quote-table-go/internal/assert"
+)
+
+// Starts a server with a seeded quotes table and a users table
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	s := NewServer(
+		Table{Name: "quotes", Columns: []string{"id", "quote", "author_id", "page_number"}},
+		Table{Name: "users", UUIDKey: true, Unique: map[string]string{"email": "users_uc_email"}},
+	)
+	t.Cleanup(s.Close)
+
+	_, err := s.Insert("quotes",
+		Row{"quote": "Carpe diem", "author_id": 2},
+		Row{"quote": "Memento mori", "author_id": 1},
+		Row{"quote": "Amor fati", "author_id": 1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+// Sends a request to the server and returns the status, Content-Range and body
+func do(t *testing.T, s *Server, method, path string, headers map[string]string, body string) (int, string, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, s.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := s.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res.StatusCode, res.Header.Get("Content-Range"), strings.TrimSpace(string(data))
+}
+
+func TestSelect(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		name      string
+		path      string
+		headers   map[string]string
+		wantCode  int
+		wantRange string
+		wantBody  string
+	}{
+		{
+			name:      "All rows",
+			path:      "/rest/v1/quotes?select=id,quote",
+			wantCode:  http.StatusOK,
+			wantRange: "0-2/*",
+			wantBody:  `[{"id":1,"quote":"Carpe diem"},{"id":2,"quote":"Memento mori"},{"id":3,"quote":"Amor fati"}]`,
+		},
+		{
+			name:      "Eq filter with exact count",
+			path:      "/rest/v1/quotes?select=id&author_id=eq.1",
+			headers:   map[string]string{"Prefer": "count=exact"},
+			wantCode:  http.StatusOK,
+			wantRange: "0-1/2",
+			wantBody:  `[{"id":2},{"id":3}]`,
+		},
+		{
+			name:      "In filter",
+			path:      "/rest/v1/quotes?select=id&id=in.(1,3)",
+			wantCode:  http.StatusOK,
+			wantRange: "0-1/*",
+			wantBody:  `[{"id":1},{"id":3}]`,
+		},
+		{
+			name:      "Or filter",
+			path:      "/rest/v1/quotes?select=id&or=(id.eq.1,quote.eq.Amor%20fati)",
+			wantCode:  http.StatusOK,
+			wantRange: "0-1/*",
+			wantBody:  `[{"id":1},{"id":3}]`,
+		},
+		{
+			name:     "Or filter on an unknown column",
+			path:     "/rest/v1/quotes?or=(id.eq.1,title.eq.x)",
+			wantCode: http.StatusBadRequest,
+			wantBody: `column quotes.title does not exist`,
+		},
+		{
+			name:      "Order and limit",
+			path:      "/rest/v1/quotes?select=quote&order=quote.asc&limit=2",
+			headers:   map[string]string{"Prefer": "count=exact"},
+			wantCode:  http.StatusOK,
+			wantRange: "0-1/3",
+			wantBody:  `[{"quote":"Amor fati"},{"quote":"Carpe diem"}]`,
+		},
+		{
+			name:      "Order descending with offset",
+			path:      "/rest/v1/quotes?select=id&order=id.desc&offset=1",
+			wantCode:  http.StatusOK,
+			wantRange: "1-2/*",
+			wantBody:  `[{"id":2},{"id":1}]`,
+		},
+		{
+			name:     "Single row",
+			path:     "/rest/v1/quotes?select=quote&id=eq.2",
+			headers:  map[string]string{"Accept": "application/vnd.pgrst.object+json"},
+			wantCode: http.StatusOK,
+			wantBody: `{"quote":"Memento mori"}`,
+		},
+		{
+			name:     "Single without rows",
+			path:     "/rest/v1/quotes?id=eq.9",
+			headers:  map[string]string{"Accept": "application/vnd.pgrst.object+json"},
+			wantCode: http.StatusNotAcceptable,
+			wantBody: `"code":"PGRST116"`,
+		},
+		{
+			name:     "Single with several rows",
+			path:     "/rest/v1/quotes?author_id=eq.1",
+			headers:  map[string]string{"Accept": "application/vnd.pgrst.object+json"},
+			wantCode: http.StatusNotAcceptable,
+			wantBody: `The result contains 2 rows`,
+		},
+		{
+			name:     "Unknown column",
+			path:     "/rest/v1/quotes?select=title",
+			wantCode: http.StatusBadRequest,
+			wantBody: `column quotes.title does not exist`,
+		},
+		{
+			name:     "Unknown table",
+			path:     "/rest/v1/books",
+			wantCode: http.StatusNotFound,
+			wantBody: `"code":"42P01"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, contentRange, body := do(t, s, http.MethodGet, tt.path, tt.headers, "")
+
+			assert.Equal(t, code, tt.wantCode)
+			if tt.wantRange != "" {
+				assert.Equal(t, contentRange, tt.wantRange)
+			}
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
+
+func TestWrites(t *testing.T) {
+	s := newTestServer(t)
+	representation := map[string]string{"Prefer": "return=representation,count=exact"}
+
+	// Insert a row and get it back with its new key
+	code, contentRange, body := do(t, s, http.MethodPost, "/rest/v1/quotes", representation, `{"quote":"Festina lente","author_id":3}`)
+	assert.Equal(t, code, http.StatusCreated)
+	assert.Equal(t, contentRange, "*/1")
+	assert.Equal(t, body, `[{"author_id":3,"id":4,"page_number":null,"quote":"Festina lente"}]`)
+
+	// Columns that aren't in the table are rejected
+	code, _, body = do(t, s, http.MethodPost, "/rest/v1/quotes", representation, `{"quote":"Festina lente","author":"Augustus"}`)
+	assert.Equal(t, code, http.StatusBadRequest)
+	assert.StringContains(t, body, `"code":"PGRST204"`)
+
+	// Update the rows of an author
+	code, contentRange, body = do(t, s, http.MethodPatch, "/rest/v1/quotes?author_id=eq.1", representation, `{"page_number":"12"}`)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, contentRange, "*/2")
+	assert.StringContains(t, body, `"page_number":"12"`)
+
+	// Delete a row without asking for it back
+	code, _, body = do(t, s, http.MethodDelete, "/rest/v1/quotes?id=eq.1", nil, "")
+	assert.Equal(t, code, http.StatusNoContent)
+	assert.Equal(t, body, "")
+	assert.Equal(t, len(s.Rows("quotes")), 3)
+
+	// Unique constraints are reported like Postgres reports them
+	code, _, _ = do(t, s, http.MethodPost, "/rest/v1/users", representation, `{"email":"jane@example.com"}`)
+	assert.Equal(t, code, http.StatusCreated)
+	code, _, body = do(t, s, http.MethodPost, "/rest/v1/users", representation, `{"email":"jane@example.com"}`)
+	assert.Equal(t, code, http.StatusConflict)
+	assert.StringContains(t, body, `duplicate key value violates unique constraint \"users_uc_email\"`)
+
+	// Every request was counted
+	assert.Equal(t, s.Requests(), 6)
+}
+
+func TestDeleteCascade(t *testing.T) {
+	s := NewServer(
+		Table{Name: "books", Cascade: map[string]string{"editions": "book_id"}},
+		Table{Name: "editions", Cascade: map[string]string{"printings": "edition_id"}},
+		Table{Name: "printings"},
+	)
+	t.Cleanup(s.Close)
+
+	for _, seed := range []struct {
+		table string
+		rows  []Row
+	}{
+		{"books", []Row{{"title": "Meditations"}, {"title": "Letters from a Stoic"}}},
+		{"editions", []Row{{"book_id": 1}, {"book_id": 2}}},
+		{"printings", []Row{{"edition_id": 1}, {"edition_id": 2}}},
+	} {
+		if _, err := s.Insert(seed.table, seed.rows...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Deleting a book deletes its editions, and their printings in turn
+	code, _, _ := do(t, s, http.MethodDelete, "/rest/v1/books?id=eq.1", nil, "")
+	assert.Equal(t, code, http.StatusNoContent)
+	assert.Equal(t, len(s.Rows("books")), 1)
+	assert.Equal(t, len(s.Rows("editions")), 1)
+	assert.Equal(t, len(s.Rows("printings")), 1)
+	assert.Equal(t, s.Rows("printings")[0]["edition_id"], any(float64(2)))
+}
+
+func TestFunctions(t *testing.T) {
+	s := newTestServer(t)
+
+	// A function counting an author's quotes
+	s.Handle("count_quotes", func(args Row, db *DB) ([]Row, error) {
+		if args["author_id"] == nil {
+			return nil, errors.New("author_id is required")
+		}
+		count := 0
+		for _, row := range db.Rows("quotes") {
+			if row["author_id"] == args["author_id"] {
+				count++
+			}
+		}
+		return []Row{{"count": count}}, nil
+	})
+
+	// Call the function with its arguments and get back its rows
+	code, _, body := do(t, s, http.MethodPost, "/rest/v1/rpc/count_quotes", nil, `{"author_id":1}`)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, strings.TrimSpace(body), `[{"count":2}]`)
+
+	// Errors from the function are reported like Postgres reports a raised exception
+	code, _, body = do(t, s, http.MethodPost, "/rest/v1/rpc/count_quotes", nil, `{}`)
+	assert.Equal(t, code, http.StatusBadRequest)
+	assert.StringContains(t, body, `"message":"author_id is required"`)
+
+	// A function moving every quote to another author, failing afterwards when asked to
+	s.Handle("move_quotes", func(args Row, db *DB) ([]Row, error) {
+		quotes := db.Rows("quotes")
+		for _, row := range quotes {
+			row["author_id"] = args["into_id"]
+		}
+		if err := db.SetRows("quotes", quotes); err != nil {
+			return nil, err
+		}
+		if args["fail"] == true {
+			return nil, errors.New("failed after moving the quotes")
+		}
+		return []Row{}, nil
+	})
+
+	// Changes made by a function that fails are thrown away
+	code, _, _ = do(t, s, http.MethodPost, "/rest/v1/rpc/move_quotes", nil, `{"into_id":2,"fail":true}`)
+	assert.Equal(t, code, http.StatusBadRequest)
+	assert.Equal(t, s.Rows("quotes")[1]["author_id"], any(float64(1)))
+
+	// Changes made by a function that succeeds are kept
+	code, _, _ = do(t, s, http.MethodPost, "/rest/v1/rpc/move_quotes", nil, `{"into_id":2}`)
+	assert.Equal(t, code, http.StatusOK)
+	for _, row := range s.Rows("quotes") {
+		assert.Equal(t, row["author_id"], any(float64(2)))
+	}
+
+	// Functions that weren't registered aren't found
+	code, _, body = do(t, s, http.MethodPost, "/rest/v1/rpc/missing", nil, `{}`)
+	assert.Equal(t, code, http.StatusNotFound)
+	assert.StringContains(t, body, `"code":"PGRST202"`)
+}
@@ -0,0 +1,79 @@
+package validator
+
+import "net/url"
+
+// MaxAuthorLinks is the most reference links an author can have
+const MaxAuthorLinks = 10
+
+// ValidateAuthorDetails validates the optional details of the author form
+func ValidateAuthorDetails(v *Validator, bio string, birthYear int, birthCalendarTime string, deathYear int, deathCalendarTime string, nationality string, occupation string, links []string) {
+    ValidateBio(v, bio)
+    ValidateLifeYear(v, "birth_year", birthYear, "birth_calendar_time", birthCalendarTime)
+    ValidateLifeYear(v, "death_year", deathYear, "death_calendar_time", deathCalendarTime)
+    ValidateLifespan(v, birthYear, birthCalendarTime, deathYear, deathCalendarTime)
+    ValidateNationality(v, nationality)
+    ValidateOccupation(v, occupation)
+    ValidateLinks(v, links)
+}
+
+// ValidateBio validates the author's biography
+func ValidateBio(v *Validator, bio string) {
+    v.CheckField(MaxChars(bio, 5000), "bio", "The biography cannot be more than 5,000 characters long")
+}
+
+// ValidateLifeYear validates a year of the author's life, which is either
+// unknown (0) or a year with a calendar time of A.D. or B.C.
+func ValidateLifeYear(v *Validator, yearKey string, year int, calendarTimeKey string, calendarTime string) {
+    if year == 0 {
+        return
+    }
+    v.CheckField(PermittedInt(year, 1, 3000), yearKey, "This field must be between 1 and 3000")
+    v.CheckField(PermittedValues(calendarTime, "A.D.", "B.C."), calendarTimeKey, "This field must be either A.D. or B.C.")
+}
+
+// ValidateLifespan checks the author didn't die before they were born, when both years are known
+func ValidateLifespan(v *Validator, birthYear int, birthCalendarTime string, deathYear int, deathCalendarTime string) {
+    if birthYear == 0 || deathYear == 0 {
+        return
+    }
+    v.CheckField(signedYear(deathYear, deathCalendarTime) >= signedYear(birthYear, birthCalendarTime), "death_year", "The year of death cannot be before the year of birth")
+}
+
+// ValidateNationality validates the author's nationality
+func ValidateNationality(v *Validator, nationality string) {
+    v.CheckField(MaxChars(nationality, 100), "nationality", "The nationality field cannot be more than 100 characters long")
+    v.CheckField(NoInvalidCharacters(nationality), "nationality", "The nationality field contains invalid characters")
+}
+
+// ValidateOccupation validates the author's occupation
+func ValidateOccupation(v *Validator, occupation string) {
+    v.CheckField(MaxChars(occupation, 200), "occupation", "The occupation field cannot be more than 200 characters long")
+    v.CheckField(NoInvalidCharacters(occupation), "occupation", "The occupation field contains invalid characters")
+}
+
+// ValidateLinks validates the author's reference links, which must be http or https web addresses
+func ValidateLinks(v *Validator, links []string) {
+    v.CheckField(len(links) <= MaxAuthorLinks, "links", "An author cannot have more than 10 links")
+    v.CheckField(UniqueValue(links), "links", "Each link can only be given once")
+    for _, link := range links {
+        v.CheckField(MaxChars(link, 500), "links", "Each link cannot be more than 500 characters long")
+        v.CheckField(WebURL(link), "links", "Each link must be a web address starting with http:// or https://")
+    }
+}
+
+// WebURL returns true if a value is an absolute http or https URL
+func WebURL(value string) bool {
+    u, err := url.Parse(value)
+    if err != nil {
+        return false
+    }
+    return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// Returns a year with B.C. years negative, so years can be compared
+func signedYear(year int, calendarTime string) int {
+    if calendarTime == "B.C." {
+        return -year
+    }
+    return year
+}
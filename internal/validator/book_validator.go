@@ -1,9 +1,15 @@
 package validator
 
-import "regexp"
+import (
+    "regexp"
+    "strconv"
+    "strings"
+)
 
-// ISBNRegex is a regular expression for validating ISBN numbers
-var ISBNRegex = regexp.MustCompile("^[0-9]{13}$")
+// ISBNRegex is a regular expression for validating ISBN numbers once their
+// hyphens and spaces are removed: an ISBN-10, whose check digit can be X, or
+// an ISBN-13 starting with 978 or 979
+var ISBNRegex = regexp.MustCompile("^(?:[0-9]{9}[0-9X]|97[89][0-9]{10})$")
 
 // ValidateBook validates the book form
 func ValidateBook(v *Validator, title string, publishYear int, calendarTime string, isbn string, source string) {
@@ -46,14 +52,84 @@ func ValidateCalendarTime(v *Validator, calendarTime string) {
     v.CheckField(PermittedValues(calendarTime, "A.D.", "B.C."), "calendar_time", "This field must be either A.D. or B.C.")
 }
 
-// ValidateISBN validates the book's ISBN
+// ValidateISBN validates the book's ISBN, which can be an ISBN-10 or ISBN-13
+// written with or without hyphens and spaces
 func ValidateISBN(v *Validator, isbn string) {
     v.CheckField(NotBlank(isbn), "isbn", "The ISBN field cannot be blank")
-    v.CheckField(Matches(isbn, ISBNRegex), "isbn", "This field must be a valid ISBN")
+    _, ok := NormalizeISBN(isbn)
+    v.CheckField(ok, "isbn", "This field must be a valid ISBN-10 or ISBN-13")
+}
+
+// NormalizeISBN returns an ISBN as the 13 digits it is stored as, removing
+// hyphens and spaces and converting an ISBN-10 to its ISBN-13. It returns
+// false when the ISBN is malformed or its check digit is wrong.
+func NormalizeISBN(isbn string) (string, bool) {
+    isbn = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(isbn)))
+    if !Matches(isbn, ISBNRegex) {
+        return "", false
+    }
+
+    if len(isbn) == 10 {
+        // The digits of an ISBN-10 weighted from 10 down to 1, with X as 10,
+        // add up to a multiple of 11
+        sum := 0
+        for i, r := range isbn {
+            digit := int(r - '0')
+            if r == 'X' {
+                digit = 10
+            }
+            sum += (10 - i) * digit
+        }
+        if sum%11 != 0 {
+            return "", false
+        }
+        isbn = "978" + isbn[:9]
+        return isbn + isbn13CheckDigit(isbn), true
+    }
+
+    if isbn13CheckDigit(isbn[:12]) != isbn[12:] {
+        return "", false
+    }
+    return isbn, true
+}
+
+// Returns the check digit of an ISBN-13 from its first 12 digits, which are
+// weighted 1 and 3 in turn
+func isbn13CheckDigit(digits string) string {
+    sum := 0
+    for i, r := range digits {
+        weight := 1
+        if i%2 == 1 {
+            weight = 3
+        }
+        sum += weight * int(r-'0')
+    }
+    return strconv.Itoa((10 - sum%10) % 10)
 }
 
 // ValidateSource validates the book's source
 func ValidateSource(v *Validator, source string) {
     v.CheckField(MaxChars(source, 500), "source", "The source field cannot be more than 500 characters long")
     v.CheckField(NoInvalidCharacters(source), "source", "The source field contains invalid characters")
-}
\ No newline at end of file
+}
+// MaxBookContributors is the most contributors a book can be credited with
+const MaxBookContributors = 20
+
+// ValidateContributors validates the book's contributors, given as the author
+// ID and role of each one in the order they are credited. An author can have
+// several roles on a book, but each role only once.
+func ValidateContributors(v *Validator, authorIDs []int, roles []string) {
+    v.CheckField(len(authorIDs) <= MaxBookContributors, "contributors", "A book cannot have more than 20 contributors")
+
+    credits := make([]string, len(authorIDs))
+    for i, authorID := range authorIDs {
+        role := ""
+        if i < len(roles) {
+            role = roles[i]
+        }
+        v.CheckField(authorID > 0, "contributors", "Each contributor must be an author")
+        v.CheckField(PermittedValues(role, "author", "editor", "translator", "illustrator"), "contributors", "Each contributor's role must be author, editor, translator or illustrator")
+        credits[i] = strconv.Itoa(authorID) + " " + role
+    }
+    v.CheckField(UniqueValue(credits), "contributors", "An author can only be credited once in each role")
+}
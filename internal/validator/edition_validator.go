@@ -0,0 +1,28 @@
+package validator
+
+// ValidateEdition validates the edition form. Every field is optional, but
+// an edition must be told apart from the others by at least one of them.
+func ValidateEdition(v *Validator, isbn string, publisher string, language string, translatorID int, publishYear int, pageCount int) {
+    v.CheckField(isbn != "" || publisher != "" || language != "" || translatorID != 0 || publishYear != 0,
+        "edition", "Give at least one of the ISBN, publisher, language, translator or year")
+    if isbn != "" {
+        ValidateISBN(v, isbn)
+    }
+    ValidatePublisher(v, publisher)
+    ValidateLanguage(v, language)
+    v.CheckField(translatorID >= 0, "translator", "Invalid translator selection")
+    v.CheckField(PermittedInt(publishYear, 0, 9999), "publish_year", "This field must be between 1 and 9999, or left blank")
+    v.CheckField(PermittedInt(pageCount, 0, 100000), "page_count", "This field must be between 1 and 100000, or left blank")
+}
+
+// ValidatePublisher validates the edition's publisher
+func ValidatePublisher(v *Validator, publisher string) {
+    v.CheckField(MaxChars(publisher, 200), "publisher", "The publisher field cannot be more than 200 characters long")
+    v.CheckField(NoInvalidCharacters(publisher), "publisher", "The publisher field contains invalid characters")
+}
+
+// ValidateLanguage validates the language the edition is in
+func ValidateLanguage(v *Validator, language string) {
+    v.CheckField(MaxChars(language, 100), "language", "The language field cannot be more than 100 characters long")
+    v.CheckField(NoInvalidCharacters(language), "language", "The language field contains invalid characters")
+}
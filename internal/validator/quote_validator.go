@@ -1,6 +1,13 @@
 package validator
 
-import "unicode"
+import (
+    "fmt"
+    "regexp"
+    "slices"
+    "strconv"
+    "strings"
+    "unicode"
+)
 
 // Checks if the quote meets all the required criteria
 func ValidateQuote(v *Validator, quote string) {
@@ -24,4 +31,77 @@ func ValidateCharacters(s string) bool {
         }
     }
     return true
-}
\ No newline at end of file
+}
+// LocationTypes are the ways a quote's location in its work can be given
+var LocationTypes = []string{"page", "chapter", "kindle", "percent", "timestamp"}
+
+// PageRangeRegex is a regular expression for a page or range of pages, such as 12 or 12-15
+var PageRangeRegex = regexp.MustCompile(`^([0-9]{1,6})(?:\s*[-–]\s*([0-9]{1,6}))?$`)
+
+// TimestampRegex is a regular expression for a time into a recording, such as 4:05 or 1:02:03
+var TimestampRegex = regexp.MustCompile(`^(?:[0-9]{1,3}:[0-5][0-9]|[0-9]{1,4}):[0-5][0-9]$`)
+
+// ValidateLocation checks where a quote is found in its work, given as the
+// location type and the text entered for it. A blank location is allowed.
+// Pages must fit within the work's page count when it is known (not 0).
+func ValidateLocation(v *Validator, locationType string, location string, pageCount int) {
+    location = strings.TrimSpace(location)
+    if location == "" {
+        return
+    }
+
+    switch locationType {
+    case "page":
+        m := PageRangeRegex.FindStringSubmatch(location)
+        if m == nil {
+            v.AddFieldError("location", "A page must be a number or a range of pages, such as 12 or 12-15")
+            return
+        }
+        first, _ := strconv.Atoi(m[1])
+        last := first
+        if m[2] != "" {
+            last, _ = strconv.Atoi(m[2])
+        }
+        v.CheckField(first >= 1, "location", "Pages are numbered from 1")
+        v.CheckField(last >= first, "location", "A range of pages cannot end before it starts")
+        if pageCount > 0 {
+            v.CheckField(last <= pageCount, "location", fmt.Sprintf("This work only has %d pages", pageCount))
+        }
+    case "chapter":
+        v.CheckField(MaxChars(location, 100), "location", "A chapter or section cannot be more than 100 characters long")
+        v.CheckField(NoInvalidCharacters(location), "location", "The chapter or section contains invalid characters")
+    case "kindle":
+        n, err := strconv.Atoi(location)
+        v.CheckField(err == nil && n >= 1, "location", "A Kindle location must be a whole number")
+    case "percent":
+        n, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(location, "%")))
+        v.CheckField(err == nil && PermittedInt(n, 0, 100), "location", "A percentage must be a whole number from 0 to 100")
+    case "timestamp":
+        v.CheckField(Matches(location, TimestampRegex), "location", "A timestamp must be given as minutes and seconds, such as 4:05, or hours, minutes and seconds, such as 1:02:03")
+    default:
+        v.AddFieldError("location", "The location must be a page, chapter or section, Kindle location, percentage or timestamp")
+    }
+}
+
+// TagRegex is a regular expression for a tag: lowercase letters and digits,
+// with hyphens between words
+var TagRegex = regexp.MustCompile(`^[\p{Ll}\p{Lo}0-9]+(?:-[\p{Ll}\p{Lo}0-9]+)*$`)
+
+// NormalizeTags splits a comma or space separated list of tags into distinct
+// lowercase tags in alphabetical order
+func NormalizeTags(text string) []string {
+    tags := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+        return r == ',' || unicode.IsSpace(r)
+    })
+    slices.Sort(tags)
+    return slices.Compact(tags)
+}
+
+// Checks the quote's tags, which are optional
+func ValidateTags(v *Validator, tags []string) {
+    v.CheckField(len(tags) <= 10, "tags", "A quote can have at most 10 tags")
+    for _, tag := range tags {
+        v.CheckField(MaxChars(tag, 30), "tags", "A tag cannot be more than 30 characters long")
+        v.CheckField(Matches(tag, TagRegex), "tags", "A tag can only contain letters, numbers and hyphens between words")
+    }
+}
@@ -0,0 +1,60 @@
+package validator
+
+import (
+    "regexp"
+    "time"
+)
+
+// MediaTypes are the kinds of work a book can be
+var MediaTypes = []string{"book", "article", "paper", "letter", "speech", "film", "video", "podcast"}
+
+// DOIRegex is a regular expression for validating DOIs, such as 10.1000/182
+var DOIRegex = regexp.MustCompile(`^10\.[0-9]{4,9}/\S+$`)
+
+// ValidateWork validates the fields of the book form that depend on the
+// work's media type: a book needs an ISBN, a paper a DOI, a speech a venue
+// and date, and a video or podcast a URL as its source. The page count is
+// optional. Fields the media type doesn't use are expected to have been
+// cleared.
+func ValidateWork(v *Validator, mediaType string, isbn string, source string, doi string, venue string, date string, pageCount int) {
+    v.CheckField(PermittedValues(mediaType, MediaTypes...), "media_type", "This field must be a known media type")
+    ValidateWorkDate(v, date)
+    v.CheckField(PermittedInt(pageCount, 0, 100000), "page_count", "This field must be between 1 and 100000, or left blank")
+
+    switch mediaType {
+    case "book":
+        ValidateISBN(v, isbn)
+    case "paper":
+        ValidateDOI(v, doi)
+    case "speech":
+        ValidateVenue(v, venue)
+        v.CheckField(NotBlank(date), "work_date", "The date of a speech cannot be blank")
+    case "video", "podcast":
+        v.CheckField(NotBlank(source), "source", "The URL cannot be blank")
+        v.CheckField(WebURL(source), "source", "The URL must be a web address starting with http:// or https://")
+    }
+}
+
+// ValidateDOI validates a paper's DOI
+func ValidateDOI(v *Validator, doi string) {
+    v.CheckField(NotBlank(doi), "doi", "The DOI field cannot be blank")
+    v.CheckField(MaxChars(doi, 200), "doi", "The DOI field cannot be more than 200 characters long")
+    v.CheckField(Matches(doi, DOIRegex), "doi", "This field must be a valid DOI, such as 10.1000/182")
+}
+
+// ValidateVenue validates where a speech was given
+func ValidateVenue(v *Validator, venue string) {
+    v.CheckField(NotBlank(venue), "venue", "The venue field cannot be blank")
+    v.CheckField(MaxChars(venue, 200), "venue", "The venue field cannot be more than 200 characters long")
+    v.CheckField(NoInvalidCharacters(venue), "venue", "The venue field contains invalid characters")
+}
+
+// ValidateWorkDate validates a work's date, which is optional but must be a
+// YYYY-MM-DD date when given
+func ValidateWorkDate(v *Validator, date string) {
+    if date == "" {
+        return
+    }
+    _, err := time.Parse(time.DateOnly, date)
+    v.CheckField(err == nil, "work_date", "This field must be a date")
+}
@@ -0,0 +1,8 @@
+package migrations
+
+import (
+	"embed"
+)
+
+//go:embed "postgres" "sqlite"
+var Files embed.FS
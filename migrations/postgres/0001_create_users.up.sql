@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    email_verified_at TIMESTAMPTZ,
+    hashed_password TEXT NOT NULL,
+    profile_slug TEXT NOT NULL,
+    phone TEXT,
+    phone_verified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_signed_in_at TIMESTAMPTZ,
+    last_quote_added_at TIMESTAMPTZ,
+    CONSTRAINT users_uc_email UNIQUE (email)
+);
+
+CREATE INDEX IF NOT EXISTS users_profile_slug_idx ON users (profile_slug);
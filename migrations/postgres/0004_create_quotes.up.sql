@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS quotes (
+    id SERIAL PRIMARY KEY,
+    quote TEXT NOT NULL,
+    author_id INTEGER REFERENCES authors (id),
+    book_id INTEGER REFERENCES books (id),
+    user_id UUID REFERENCES users (id) ON DELETE SET NULL,
+    page_number TEXT,
+    is_private BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS quotes_author_id_idx ON quotes (author_id);
+CREATE INDEX IF NOT EXISTS quotes_book_id_idx ON quotes (book_id);
+CREATE INDEX IF NOT EXISTS quotes_user_id_idx ON quotes (user_id);
+CREATE INDEX IF NOT EXISTS quotes_created_at_idx ON quotes (created_at);
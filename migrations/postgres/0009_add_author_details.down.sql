@@ -0,0 +1,9 @@
+ALTER TABLE authors
+    DROP COLUMN IF EXISTS bio,
+    DROP COLUMN IF EXISTS birth_year,
+    DROP COLUMN IF EXISTS birth_calendar_time,
+    DROP COLUMN IF EXISTS death_year,
+    DROP COLUMN IF EXISTS death_calendar_time,
+    DROP COLUMN IF EXISTS nationality,
+    DROP COLUMN IF EXISTS occupation,
+    DROP COLUMN IF EXISTS links;
@@ -0,0 +1,11 @@
+-- What is known about each author besides their name, all of it optional.
+-- Birth and death years are paired with A.D. or B.C. like a book's publish year.
+ALTER TABLE authors
+    ADD COLUMN IF NOT EXISTS bio TEXT,
+    ADD COLUMN IF NOT EXISTS birth_year INTEGER,
+    ADD COLUMN IF NOT EXISTS birth_calendar_time TEXT,
+    ADD COLUMN IF NOT EXISTS death_year INTEGER,
+    ADD COLUMN IF NOT EXISTS death_calendar_time TEXT,
+    ADD COLUMN IF NOT EXISTS nationality TEXT,
+    ADD COLUMN IF NOT EXISTS occupation TEXT,
+    ADD COLUMN IF NOT EXISTS links TEXT[] NOT NULL DEFAULT '{}';
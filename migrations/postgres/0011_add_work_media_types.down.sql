@@ -0,0 +1,5 @@
+ALTER TABLE books
+    DROP COLUMN IF EXISTS work_date,
+    DROP COLUMN IF EXISTS venue,
+    DROP COLUMN IF EXISTS doi,
+    DROP COLUMN IF EXISTS media_type;
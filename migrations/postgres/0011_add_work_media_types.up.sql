@@ -0,0 +1,10 @@
+-- Books can be any kind of work quotes are taken from. Existing rows are
+-- books; the other columns are only used by some media types: a paper's DOI,
+-- a speech's venue, and the date a speech was given or a letter, video or
+-- podcast was released, kept as YYYY-MM-DD.
+ALTER TABLE books
+    ADD COLUMN IF NOT EXISTS media_type TEXT NOT NULL DEFAULT 'book'
+        CHECK (media_type IN ('book', 'article', 'paper', 'letter', 'speech', 'film', 'video', 'podcast')),
+    ADD COLUMN IF NOT EXISTS doi TEXT,
+    ADD COLUMN IF NOT EXISTS venue TEXT,
+    ADD COLUMN IF NOT EXISTS work_date TEXT;
@@ -0,0 +1,17 @@
+ALTER TABLE books DROP COLUMN IF EXISTS page_count;
+
+-- Write each location back out as the text of a page number
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS page_number TEXT;
+UPDATE quotes SET page_number = CASE location_type
+        WHEN 'page' THEN location_start || COALESCE('-' || location_end, '')
+        WHEN 'chapter' THEN location_label
+        WHEN 'kindle' THEN 'loc. ' || location_start
+        WHEN 'percent' THEN location_start || '%'
+        WHEN 'timestamp' THEN to_char(make_interval(secs => location_start), 'HH24:MI:SS')
+    END;
+
+ALTER TABLE quotes
+    DROP COLUMN IF EXISTS location_label,
+    DROP COLUMN IF EXISTS location_end,
+    DROP COLUMN IF EXISTS location_start,
+    DROP COLUMN IF EXISTS location_type;
@@ -0,0 +1,26 @@
+-- Replace each quote's free-text page number with a structured location:
+-- a page or range of pages, a chapter or section, a Kindle location, a
+-- percentage or a timestamp in seconds. Page numbers and ranges carry over as
+-- pages, and any other text as the name of a chapter or section.
+ALTER TABLE quotes
+    ADD COLUMN IF NOT EXISTS location_type TEXT
+        CHECK (location_type IN ('page', 'chapter', 'kindle', 'percent', 'timestamp')),
+    ADD COLUMN IF NOT EXISTS location_start INTEGER,
+    ADD COLUMN IF NOT EXISTS location_end INTEGER,
+    ADD COLUMN IF NOT EXISTS location_label TEXT;
+
+UPDATE quotes SET location_type = 'page', location_start = CAST(btrim(page_number) AS INTEGER)
+    WHERE btrim(page_number) ~ '^0*[1-9][0-9]{0,8}$';
+UPDATE quotes SET location_type = 'page',
+        location_start = CAST(substring(page_number FROM '^\s*([0-9]+)') AS INTEGER),
+        location_end = CAST(substring(page_number FROM '([0-9]+)\s*$') AS INTEGER)
+    WHERE btrim(page_number) ~ '^0*[1-9][0-9]{0,8}\s*-\s*0*[1-9][0-9]{0,8}$';
+UPDATE quotes SET location_end = NULL
+    WHERE location_type = 'page' AND location_end <= location_start;
+UPDATE quotes SET location_type = 'chapter', location_label = btrim(page_number)
+    WHERE location_type IS NULL AND btrim(page_number) <> '';
+
+ALTER TABLE quotes DROP COLUMN IF EXISTS page_number;
+
+-- The number of pages in a book, which the pages quoted from it must fit within
+ALTER TABLE books ADD COLUMN IF NOT EXISTS page_count INTEGER;
@@ -0,0 +1,18 @@
+-- ISBNs are stored as the 13 digits of the ISBN-13, without hyphens or
+-- spaces, and works without one have none rather than an empty string. A
+-- book whose ISBN was already used by an earlier book has it moved to
+-- book_isbn_conflicts, so the duplicate can be fixed by hand and rolling back
+-- puts it back, and then no two books can share one.
+UPDATE books SET isbn = NULLIF(REPLACE(REPLACE(TRIM(isbn), '-', ''), ' ', ''), '');
+
+CREATE TABLE IF NOT EXISTS book_isbn_conflicts (
+    book_id INTEGER PRIMARY KEY REFERENCES books (id) ON DELETE CASCADE,
+    isbn TEXT NOT NULL
+);
+
+INSERT INTO book_isbn_conflicts (book_id, isbn)
+    SELECT id, isbn FROM books
+    WHERE EXISTS (SELECT 1 FROM books earlier WHERE earlier.isbn = books.isbn AND earlier.id < books.id);
+UPDATE books SET isbn = NULL WHERE id IN (SELECT book_id FROM book_isbn_conflicts);
+
+CREATE UNIQUE INDEX IF NOT EXISTS books_uc_isbn ON books (isbn);
@@ -0,0 +1,2 @@
+ALTER TABLE quotes DROP COLUMN IF EXISTS edition_id;
+DROP TABLE IF EXISTS editions;
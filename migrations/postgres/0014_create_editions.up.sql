@@ -0,0 +1,24 @@
+-- The editions and translations of each work beyond the one it was added
+-- with. Every detail is optional, and an ISBN belongs to one edition only.
+CREATE TABLE IF NOT EXISTS editions (
+    id SERIAL PRIMARY KEY,
+    book_id INTEGER NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    isbn TEXT,
+    publisher TEXT,
+    language TEXT,
+    translator_id INTEGER REFERENCES authors (id),
+    publish_year INTEGER,
+    page_count INTEGER,
+    user_id UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS editions_uc_isbn ON editions (isbn);
+CREATE INDEX IF NOT EXISTS editions_book_id_idx ON editions (book_id);
+CREATE INDEX IF NOT EXISTS editions_translator_id_idx ON editions (translator_id);
+
+-- The edition a quote was taken from, if it is known. Quotes stay with their
+-- work when the edition is deleted.
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS edition_id INTEGER REFERENCES editions (id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS quotes_edition_id_idx ON quotes (edition_id);
@@ -0,0 +1,8 @@
+-- The tags given to each quote, stored lowercase. A quote has each tag once.
+CREATE TABLE IF NOT EXISTS quote_tags (
+    quote_id INTEGER NOT NULL REFERENCES quotes (id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (quote_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS quote_tags_tag_idx ON quote_tags (tag);
@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS search_quotes(TEXT, TEXT, UUID, TEXT, INTEGER, INTEGER, TEXT, TEXT, TEXT, INTEGER, INTEGER, TEXT, INTEGER, INTEGER);
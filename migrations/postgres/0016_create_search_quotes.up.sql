@@ -0,0 +1,74 @@
+-- Searches the quotes for the Supabase backend, which can't rank or page a
+-- search through PostgREST's table filters. It matches the same document as
+-- the postgres backend: the quote weighted above its author's name and its
+-- book's title. search_text and excluded_text are to_tsquery expressions,
+-- empty for none, and the other filters are left out when empty or zero.
+--
+-- It returns one page of quote IDs, best first, each with its rank and the
+-- total number of matches. A page past the end is a single row without an ID,
+-- so the total is still known.
+CREATE OR REPLACE FUNCTION search_quotes(
+    search_text TEXT DEFAULT '',
+    excluded_text TEXT DEFAULT '',
+    viewer UUID DEFAULT NULL,
+    search_scope TEXT DEFAULT 'all',
+    author_filter INTEGER DEFAULT 0,
+    book_filter INTEGER DEFAULT 0,
+    author_name TEXT DEFAULT '',
+    book_title TEXT DEFAULT '',
+    tag_filter TEXT DEFAULT '',
+    year_from INTEGER DEFAULT 0,
+    year_to INTEGER DEFAULT 0,
+    sort_order TEXT DEFAULT 'relevance',
+    page_limit INTEGER DEFAULT 20,
+    page_offset INTEGER DEFAULT 0
+) RETURNS TABLE (id INTEGER, search_rank REAL, total_records BIGINT)
+LANGUAGE sql STABLE
+AS $$
+    WITH matches AS (
+        SELECT q.id, q.created_at,
+            CASE WHEN search_text = '' THEN 0::REAL
+                ELSE ts_rank(d.document, to_tsquery('simple', search_text)) END AS search_rank
+        FROM quotes q
+        LEFT JOIN authors a ON a.id = q.author_id
+        LEFT JOIN books b ON b.id = q.book_id
+        CROSS JOIN LATERAL (SELECT
+            setweight(to_tsvector('simple', q.quote), 'A') ||
+            setweight(to_tsvector('simple', COALESCE(a.name, '')), 'B') ||
+            setweight(to_tsvector('simple', COALESCE(b.title, '')), 'B') AS document) d
+        CROSS JOIN LATERAL (SELECT
+            '%' || replace(replace(replace(author_name, '\', '\\'), '%', '\%'), '_', '\_') || '%' AS author_pattern,
+            '%' || replace(replace(replace(book_title, '\', '\\'), '%', '\%'), '_', '\_') || '%' AS title_pattern) p
+        WHERE (search_text = '' OR d.document @@ to_tsquery('simple', search_text))
+            AND (excluded_text = '' OR NOT d.document @@ to_tsquery('simple', excluded_text))
+            AND (author_filter = 0 OR q.author_id = author_filter)
+            AND (book_filter = 0 OR q.book_id = book_filter)
+            AND (author_name = '' OR a.name ILIKE p.author_pattern ESCAPE '\'
+                OR EXISTS (SELECT true FROM author_aliases aa WHERE aa.author_id = a.id AND aa.name ILIKE p.author_pattern ESCAPE '\'))
+            AND (book_title = '' OR b.title ILIKE p.title_pattern ESCAPE '\')
+            AND (tag_filter = '' OR EXISTS (SELECT true FROM quote_tags t WHERE t.quote_id = q.id AND t.tag = tag_filter))
+            AND (year_from = 0 OR CASE WHEN b.calendar_time = 'B.C.' THEN -b.publish_year ELSE b.publish_year END >= year_from)
+            AND (year_to = 0 OR CASE WHEN b.calendar_time = 'B.C.' THEN -b.publish_year ELSE b.publish_year END <= year_to)
+            -- Unlisted and private quotes are only ever found by the user who added them
+            AND COALESCE(CASE search_scope
+                WHEN 'mine' THEN q.user_id = viewer
+                WHEN 'unlisted' THEN q.user_id = viewer AND q.visibility = 'unlisted'
+                WHEN 'private' THEN q.user_id = viewer AND q.visibility = 'private'
+                WHEN 'public' THEN q.visibility = 'public'
+                ELSE q.visibility = 'public' OR q.user_id = viewer
+            END, false)
+    ), page AS (
+        SELECT m.id, m.search_rank, row_number() OVER (ORDER BY
+            CASE WHEN sort_order IN ('newest', 'oldest') THEN 0 ELSE m.search_rank END DESC,
+            CASE WHEN sort_order = 'oldest' THEN m.created_at END,
+            CASE WHEN sort_order = 'oldest' THEN m.id END,
+            m.created_at DESC, m.id DESC) AS position
+        FROM matches m
+        ORDER BY position
+        LIMIT page_limit OFFSET page_offset
+    )
+    SELECT page.id, page.search_rank, (SELECT count(*) FROM matches)
+    FROM (SELECT true) one
+    LEFT JOIN page ON true
+    ORDER BY page.position;
+$$;
@@ -0,0 +1,3 @@
+DROP FUNCTION IF EXISTS list_authors(UUID, TEXT, INTEGER, INTEGER);
+DROP FUNCTION IF EXISTS list_books(UUID, TEXT, INTEGER, INTEGER);
+DROP FUNCTION IF EXISTS list_quotes_by_author(UUID, UUID, INTEGER, INTEGER);
@@ -0,0 +1,102 @@
+-- Page through the listings the Supabase backend can't order through
+-- PostgREST's table filters: quotes by author name, and books and authors by
+-- a count of quotes or a name in another table. Each orders the same way as
+-- the postgres backend and counts only the quotes the viewer can see.
+--
+-- Each returns one page of IDs with the total number of rows. A page past the
+-- end is a single row without an ID, so the total is still known.
+
+-- Quotes by author name, with quotes missing an author last, and only the
+-- owner's quotes when there is one
+CREATE OR REPLACE FUNCTION list_quotes_by_author(
+    viewer UUID DEFAULT NULL,
+    owner_filter UUID DEFAULT NULL,
+    page_limit INTEGER DEFAULT 20,
+    page_offset INTEGER DEFAULT 0
+) RETURNS TABLE (id INTEGER, total_records BIGINT)
+LANGUAGE sql STABLE
+AS $$
+    WITH matches AS (
+        SELECT q.id, a.name
+        FROM quotes q
+        LEFT JOIN authors a ON a.id = q.author_id
+        WHERE (q.visibility = 'public' OR q.user_id = viewer)
+            AND (owner_filter IS NULL OR q.user_id = owner_filter)
+    ), page AS (
+        SELECT m.id, row_number() OVER (ORDER BY m.name NULLS LAST, m.id) AS position
+        FROM matches m
+        ORDER BY position
+        LIMIT page_limit OFFSET page_offset
+    )
+    SELECT page.id, (SELECT count(*) FROM matches)
+    FROM (SELECT true) one
+    LEFT JOIN page ON true
+    ORDER BY page.position;
+$$;
+
+-- Books by their first credited author's name, with books without a known
+-- author last, or by their number of quotes, then by title
+CREATE OR REPLACE FUNCTION list_books(
+    viewer UUID DEFAULT NULL,
+    sort_order TEXT DEFAULT 'author',
+    page_limit INTEGER DEFAULT 20,
+    page_offset INTEGER DEFAULT 0
+) RETURNS TABLE (id INTEGER, total_records BIGINT)
+LANGUAGE sql STABLE
+AS $$
+    WITH matches AS (
+        SELECT b.id, b.title, a.name,
+            (SELECT count(*) FROM quotes q
+                WHERE q.book_id = b.id AND (q.visibility = 'public' OR q.user_id = viewer)) AS quote_count
+        FROM books b
+        LEFT JOIN authors a ON a.id = (
+            SELECT c.author_id FROM book_contributors c
+            WHERE c.book_id = b.id AND c.role = 'author'
+            ORDER BY c.position LIMIT 1
+        )
+    ), page AS (
+        SELECT m.id, row_number() OVER (ORDER BY
+            CASE WHEN sort_order = 'most-quoted' THEN m.quote_count END DESC,
+            CASE WHEN sort_order = 'author' THEN m.name END NULLS LAST,
+            m.title, m.id) AS position
+        FROM matches m
+        ORDER BY position
+        LIMIT page_limit OFFSET page_offset
+    )
+    SELECT page.id, (SELECT count(*) FROM matches)
+    FROM (SELECT true) one
+    LEFT JOIN page ON true
+    ORDER BY page.position;
+$$;
+
+-- Authors with their quote and book counts, by name, by ID for newest and
+-- oldest, or by their number of quotes
+CREATE OR REPLACE FUNCTION list_authors(
+    viewer UUID DEFAULT NULL,
+    sort_order TEXT DEFAULT 'author',
+    page_limit INTEGER DEFAULT 20,
+    page_offset INTEGER DEFAULT 0
+) RETURNS TABLE (id INTEGER, quote_count BIGINT, book_count BIGINT, total_records BIGINT)
+LANGUAGE sql STABLE
+AS $$
+    WITH matches AS (
+        SELECT a.id, a.name,
+            (SELECT count(*) FROM quotes q
+                WHERE q.author_id = a.id AND (q.visibility = 'public' OR q.user_id = viewer)) AS quote_count,
+            (SELECT count(DISTINCT c.book_id) FROM book_contributors c WHERE c.author_id = a.id) AS book_count
+        FROM authors a
+    ), page AS (
+        SELECT m.id, m.quote_count, m.book_count, row_number() OVER (ORDER BY
+            CASE WHEN sort_order = 'newest' THEN m.id END DESC,
+            CASE WHEN sort_order = 'oldest' THEN m.id END,
+            CASE WHEN sort_order = 'most-quoted' THEN m.quote_count END DESC,
+            m.name, m.id) AS position
+        FROM matches m
+        ORDER BY position
+        LIMIT page_limit OFFSET page_offset
+    )
+    SELECT page.id, page.quote_count, page.book_count, (SELECT count(*) FROM matches)
+    FROM (SELECT true) one
+    LEFT JOIN page ON true
+    ORDER BY page.position;
+$$;
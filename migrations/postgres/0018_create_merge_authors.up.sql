@@ -0,0 +1,38 @@
+-- Merges one author into another for the Supabase backend, which can only
+-- make one change per PostgREST request. It moves every quote, alias, book
+-- credit and translation of from_author to into_author, dropping the credits
+-- into_author already has, and deletes from_author, all in the transaction
+-- PostgREST runs the call in, the same way the postgres backend does.
+--
+-- It returns the number of quotes moved, or no rows when either author
+-- doesn't exist.
+CREATE OR REPLACE FUNCTION merge_authors(from_author INTEGER, into_author INTEGER)
+RETURNS TABLE (quotes_moved INTEGER)
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    moved INTEGER;
+BEGIN
+    IF (SELECT count(*) FROM authors a WHERE a.id IN (from_author, into_author)) < 2 THEN
+        RETURN;
+    END IF;
+
+    UPDATE quotes q SET author_id = into_author WHERE q.author_id = from_author;
+    GET DIAGNOSTICS moved = ROW_COUNT;
+
+    UPDATE author_aliases aa SET author_id = into_author WHERE aa.author_id = from_author;
+
+    UPDATE book_contributors c SET author_id = into_author WHERE c.author_id = from_author AND NOT EXISTS (
+        SELECT true FROM book_contributors d
+        WHERE d.book_id = c.book_id AND d.role = c.role AND d.author_id = into_author
+    );
+    DELETE FROM book_contributors c WHERE c.author_id = from_author;
+
+    UPDATE editions e SET translator_id = into_author WHERE e.translator_id = from_author;
+
+    DELETE FROM authors a WHERE a.id = from_author;
+
+    quotes_moved := moved;
+    RETURN NEXT;
+END;
+$$;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS users (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    email_verified_at TIMESTAMP,
+    hashed_password TEXT NOT NULL,
+    profile_slug TEXT NOT NULL,
+    phone TEXT,
+    phone_verified_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_signed_in_at TIMESTAMP,
+    last_quote_added_at TIMESTAMP,
+    CONSTRAINT users_uc_email UNIQUE (email)
+);
+
+CREATE INDEX IF NOT EXISTS users_profile_slug_idx ON users (profile_slug);
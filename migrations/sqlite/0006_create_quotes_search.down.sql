@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS books_search_update;
+DROP TRIGGER IF EXISTS authors_search_update;
+DROP TRIGGER IF EXISTS quotes_search_delete;
+DROP TRIGGER IF EXISTS quotes_search_update;
+DROP TRIGGER IF EXISTS quotes_search_insert;
+DROP TABLE IF EXISTS quotes_search;
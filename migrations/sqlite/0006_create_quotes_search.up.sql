@@ -0,0 +1,47 @@
+-- A full-text index of each quote with its author's name and book's title,
+-- keyed by the quote ID. Diacritics are kept so matches agree with the other
+-- storage backends.
+CREATE VIRTUAL TABLE IF NOT EXISTS quotes_search USING fts5 (
+    quote,
+    author,
+    title,
+    tokenize = 'unicode61 remove_diacritics 0'
+);
+
+INSERT INTO quotes_search (rowid, quote, author, title)
+    SELECT q.id, q.quote, COALESCE(a.name, ''), COALESCE(b.title, '')
+    FROM quotes q
+    LEFT JOIN authors a ON a.id = q.author_id
+    LEFT JOIN books b ON b.id = q.book_id;
+
+-- Keep the index up to date as quotes, authors and books change
+CREATE TRIGGER IF NOT EXISTS quotes_search_insert AFTER INSERT ON quotes BEGIN
+    INSERT INTO quotes_search (rowid, quote, author, title) VALUES (
+        new.id,
+        new.quote,
+        COALESCE((SELECT name FROM authors WHERE id = new.author_id), ''),
+        COALESCE((SELECT title FROM books WHERE id = new.book_id), '')
+    );
+END;
+
+CREATE TRIGGER IF NOT EXISTS quotes_search_update AFTER UPDATE OF quote, author_id, book_id ON quotes BEGIN
+    UPDATE quotes_search SET
+        quote = new.quote,
+        author = COALESCE((SELECT name FROM authors WHERE id = new.author_id), ''),
+        title = COALESCE((SELECT title FROM books WHERE id = new.book_id), '')
+    WHERE rowid = new.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS quotes_search_delete AFTER DELETE ON quotes BEGIN
+    DELETE FROM quotes_search WHERE rowid = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS authors_search_update AFTER UPDATE OF name ON authors BEGIN
+    UPDATE quotes_search SET author = new.name
+    WHERE rowid IN (SELECT id FROM quotes WHERE author_id = new.id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS books_search_update AFTER UPDATE OF title ON books BEGIN
+    UPDATE quotes_search SET title = new.title
+    WHERE rowid IN (SELECT id FROM quotes WHERE book_id = new.id);
+END;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS quotes_share_token_idx;
+ALTER TABLE quotes DROP COLUMN share_token;
+
+-- Unlisted quotes become private, so rolling back never publishes a quote
+ALTER TABLE quotes ADD COLUMN is_private BOOLEAN NOT NULL DEFAULT false;
+UPDATE quotes SET is_private = visibility <> 'public';
+ALTER TABLE quotes DROP COLUMN visibility;
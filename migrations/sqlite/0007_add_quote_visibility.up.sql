@@ -0,0 +1,10 @@
+-- Replace the is_private flag with a visibility, adding unlisted quotes that
+-- only their owner and people with the share link can read
+ALTER TABLE quotes ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'unlisted', 'private'));
+UPDATE quotes SET visibility = 'private' WHERE is_private;
+ALTER TABLE quotes DROP COLUMN is_private;
+
+-- The token in each quote's share link, null while the quote isn't shared
+ALTER TABLE quotes ADD COLUMN share_token TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS quotes_share_token_idx ON quotes (share_token);
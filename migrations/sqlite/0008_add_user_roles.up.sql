@@ -0,0 +1,4 @@
+-- The role of each user, which decides what they can do beyond managing
+-- their own quotes. Every existing user starts as a plain user.
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'premium', 'moderator', 'admin'));
@@ -0,0 +1,10 @@
+-- Other names an author is known by, such as pen names. A name can only be
+-- the alias of one author, so looking an alias up finds a single author.
+CREATE TABLE IF NOT EXISTS author_aliases (
+    id INTEGER PRIMARY KEY,
+    author_id INTEGER NOT NULL REFERENCES authors (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    CONSTRAINT author_aliases_uc_name UNIQUE (name)
+);
+
+CREATE INDEX IF NOT EXISTS author_aliases_author_id_idx ON author_aliases (author_id);
@@ -0,0 +1,8 @@
+ALTER TABLE authors DROP COLUMN links;
+ALTER TABLE authors DROP COLUMN occupation;
+ALTER TABLE authors DROP COLUMN nationality;
+ALTER TABLE authors DROP COLUMN death_calendar_time;
+ALTER TABLE authors DROP COLUMN death_year;
+ALTER TABLE authors DROP COLUMN birth_calendar_time;
+ALTER TABLE authors DROP COLUMN birth_year;
+ALTER TABLE authors DROP COLUMN bio;
@@ -0,0 +1,11 @@
+-- What is known about each author besides their name, all of it optional.
+-- Birth and death years are paired with A.D. or B.C. like a book's publish
+-- year, and the reference links are kept one per line.
+ALTER TABLE authors ADD COLUMN bio TEXT;
+ALTER TABLE authors ADD COLUMN birth_year INTEGER;
+ALTER TABLE authors ADD COLUMN birth_calendar_time TEXT;
+ALTER TABLE authors ADD COLUMN death_year INTEGER;
+ALTER TABLE authors ADD COLUMN death_calendar_time TEXT;
+ALTER TABLE authors ADD COLUMN nationality TEXT;
+ALTER TABLE authors ADD COLUMN occupation TEXT;
+ALTER TABLE authors ADD COLUMN links TEXT NOT NULL DEFAULT '';
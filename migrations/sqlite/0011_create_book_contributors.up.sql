@@ -0,0 +1,22 @@
+-- The authors credited on each book and the part each had in it, in the
+-- order they are credited. An author can only be deleted once they are no
+-- longer credited on any book.
+CREATE TABLE IF NOT EXISTS book_contributors (
+    book_id INTEGER NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    author_id INTEGER NOT NULL REFERENCES authors (id),
+    role TEXT NOT NULL DEFAULT 'author'
+        CHECK (role IN ('author', 'editor', 'translator', 'illustrator')),
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (book_id, author_id, role)
+);
+
+CREATE INDEX IF NOT EXISTS book_contributors_author_id_idx ON book_contributors (author_id);
+
+-- Credit each book as written by the author of its first quote, who it was
+-- shown as written by before books had contributors
+INSERT INTO book_contributors (book_id, author_id, role, position)
+SELECT q.book_id, q.author_id, 'author', 0 FROM quotes q
+WHERE q.id = (
+    SELECT min(fq.id) FROM quotes fq
+    WHERE fq.book_id = q.book_id AND fq.author_id IS NOT NULL
+);
@@ -0,0 +1,4 @@
+ALTER TABLE books DROP COLUMN work_date;
+ALTER TABLE books DROP COLUMN venue;
+ALTER TABLE books DROP COLUMN doi;
+ALTER TABLE books DROP COLUMN media_type;
@@ -0,0 +1,9 @@
+-- Books can be any kind of work quotes are taken from. Existing rows are
+-- books; the other columns are only used by some media types: a paper's DOI,
+-- a speech's venue, and the date a speech was given or a letter, video or
+-- podcast was released, kept as YYYY-MM-DD.
+ALTER TABLE books ADD COLUMN media_type TEXT NOT NULL DEFAULT 'book'
+    CHECK (media_type IN ('book', 'article', 'paper', 'letter', 'speech', 'film', 'video', 'podcast'));
+ALTER TABLE books ADD COLUMN doi TEXT;
+ALTER TABLE books ADD COLUMN venue TEXT;
+ALTER TABLE books ADD COLUMN work_date TEXT;
@@ -0,0 +1,16 @@
+ALTER TABLE books DROP COLUMN page_count;
+
+-- Write each location back out as the text of a page number
+ALTER TABLE quotes ADD COLUMN page_number TEXT;
+UPDATE quotes SET page_number = CASE location_type
+        WHEN 'page' THEN location_start || COALESCE('-' || location_end, '')
+        WHEN 'chapter' THEN location_label
+        WHEN 'kindle' THEN 'loc. ' || location_start
+        WHEN 'percent' THEN location_start || '%'
+        WHEN 'timestamp' THEN time(location_start, 'unixepoch')
+    END;
+
+ALTER TABLE quotes DROP COLUMN location_label;
+ALTER TABLE quotes DROP COLUMN location_end;
+ALTER TABLE quotes DROP COLUMN location_start;
+ALTER TABLE quotes DROP COLUMN location_type;
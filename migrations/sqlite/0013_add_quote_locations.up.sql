@@ -0,0 +1,30 @@
+-- Replace each quote's free-text page number with a structured location:
+-- a page or range of pages, a chapter or section, a Kindle location, a
+-- percentage or a timestamp in seconds. Page numbers and ranges carry over as
+-- pages, and any other text as the name of a chapter or section.
+ALTER TABLE quotes ADD COLUMN location_type TEXT
+    CHECK (location_type IN ('page', 'chapter', 'kindle', 'percent', 'timestamp'));
+ALTER TABLE quotes ADD COLUMN location_start INTEGER;
+ALTER TABLE quotes ADD COLUMN location_end INTEGER;
+ALTER TABLE quotes ADD COLUMN location_label TEXT;
+
+UPDATE quotes SET location_type = 'page', location_start = CAST(trim(page_number) AS INTEGER)
+    WHERE trim(page_number) <> '' AND trim(page_number) NOT GLOB '*[^0-9]*'
+        AND length(trim(page_number)) <= 9 AND CAST(trim(page_number) AS INTEGER) > 0;
+UPDATE quotes SET location_type = 'page',
+        location_start = CAST(trim(substr(page_number, 1, instr(page_number, '-') - 1)) AS INTEGER),
+        location_end = CAST(trim(substr(page_number, instr(page_number, '-') + 1)) AS INTEGER)
+    WHERE trim(page_number) GLOB '[0-9]*-*[0-9]'
+        AND replace(replace(page_number, '-', ''), ' ', '') NOT GLOB '*[^0-9]*'
+        AND length(page_number) - length(replace(page_number, '-', '')) = 1
+        AND length(trim(page_number)) <= 19
+        AND CAST(trim(substr(page_number, 1, instr(page_number, '-') - 1)) AS INTEGER) > 0;
+UPDATE quotes SET location_end = NULL
+    WHERE location_type = 'page' AND location_end <= location_start;
+UPDATE quotes SET location_type = 'chapter', location_label = trim(page_number)
+    WHERE location_type IS NULL AND trim(page_number) <> '';
+
+ALTER TABLE quotes DROP COLUMN page_number;
+
+-- The number of pages in a book, which the pages quoted from it must fit within
+ALTER TABLE books ADD COLUMN page_count INTEGER;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS books_uc_isbn;
+
+-- Give the duplicates that haven't been fixed by hand their ISBNs back
+UPDATE books SET isbn = (SELECT c.isbn FROM book_isbn_conflicts c WHERE c.book_id = books.id)
+    WHERE isbn IS NULL AND id IN (SELECT book_id FROM book_isbn_conflicts);
+DROP TABLE IF EXISTS book_isbn_conflicts;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS quotes_edition_id_idx;
+ALTER TABLE quotes DROP COLUMN edition_id;
+DROP TABLE IF EXISTS editions;
@@ -195,14 +195,31 @@ document.addEventListener("DOMContentLoaded", function () {
         });
     }
 
+    // Copies the new author or book into the quote form's hidden fields, and
+    // shows it as the selected option
+    function useNewRecord(select, fields, label) {
+        fields.forEach((name) => {
+            const hidden = select.form.querySelector(`input[type="hidden"][name="${name}"]`);
+            if (hidden) {
+                hidden.value = document.getElementById(name).value;
+            }
+        });
+        const previous = select.querySelector("option[data-new]");
+        if (previous) {
+            previous.remove();
+        }
+        const option = new Option(label + " (new)", "");
+        option.dataset.new = "true";
+        select.add(option, 1);
+        select.value = "";
+        option.selected = true;
+    }
+
     if (newAuthorForm) {
         newAuthorForm.addEventListener("submit", (e) => {
             e.preventDefault();
             const authorName = document.getElementById("new_author_name").value;
-            // Here you would typically send an AJAX request to create the new author
-            const option = new Option(authorName, "new_" + Date.now());
-            selectAuthor.add(option);
-            selectAuthor.value = option.value;
+            useNewRecord(selectAuthor, ["new_author_name"], authorName);
             newAuthorDialog.close();
         });
     }
@@ -211,11 +228,7 @@ document.addEventListener("DOMContentLoaded", function () {
         newBookForm.addEventListener("submit", (e) => {
             e.preventDefault();
             const bookTitle = document.getElementById("new_book_title").value;
-            // Here you would typically send an AJAX request to create the new book
-            // For now, we'll just add it to the select options
-            const option = new Option(bookTitle, "new_" + Date.now());
-            selectBook.add(option);
-            selectBook.value = option.value;
+            useNewRecord(selectBook, ["new_book_title", "new_book_publish_year", "new_book_calendar_time", "new_book_isbn", "new_book_source"], bookTitle);
             newBookDialog.close();
         });
     }
@@ -257,6 +270,63 @@ document.addEventListener('DOMContentLoaded', function() {
     }
 });
 
+// Delete book
+document.addEventListener('DOMContentLoaded', function() {
+    const deleteBookButton = document.querySelector('#deleteBookButton');
+    if (deleteBookButton) {
+        deleteBookButton.addEventListener('click', function() {
+            if (confirm('Are you sure you want to delete this book?')) {
+                const deleteBookForm = document.querySelector('#deleteBookForm');
+                if (deleteBookForm) {
+                    deleteBookForm.submit();
+                }
+            }
+        });
+    }
+});
+
+// Delete edition
+document.addEventListener('DOMContentLoaded', function() {
+    const deleteEditionButton = document.querySelector('#deleteEditionButton');
+    if (deleteEditionButton) {
+        deleteEditionButton.addEventListener('click', function() {
+            if (confirm('Are you sure you want to delete this edition? Its quotes will stay with the book.')) {
+                const deleteEditionForm = document.querySelector('#deleteEditionForm');
+                if (deleteEditionForm) {
+                    deleteEditionForm.submit();
+                }
+            }
+        });
+    }
+});
+
+// Delete author
+document.addEventListener('DOMContentLoaded', function() {
+    const deleteAuthorButton = document.querySelector('#deleteAuthorButton');
+    if (deleteAuthorButton) {
+        deleteAuthorButton.addEventListener('click', function() {
+            if (confirm('Are you sure you want to delete this author?')) {
+                const deleteAuthorForm = document.querySelector('#deleteAuthorForm');
+                if (deleteAuthorForm) {
+                    deleteAuthorForm.submit();
+                }
+            }
+        });
+    }
+});
+
+// Merge author
+document.addEventListener('DOMContentLoaded', function() {
+    const mergeAuthorForm = document.querySelector('#mergeAuthorForm');
+    if (mergeAuthorForm) {
+        mergeAuthorForm.addEventListener('submit', function(event) {
+            if (!confirm('Are you sure you want to merge this author? Their quotes will be moved and they will be deleted.')) {
+                event.preventDefault();
+            }
+        });
+    }
+});
+
 // Filter
 document.addEventListener('DOMContentLoaded', function() {
     const multiSelectButtons = document.querySelectorAll('#multi-select-toggle-author, #multi-select-toggle-book, #multi-select-toggle-genre, #multi-select-toggle-topic, #multi-select-toggle-type, #multi-select-toggle-tag');